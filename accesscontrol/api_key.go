@@ -0,0 +1,246 @@
+package accesscontrol
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/internal/seetie"
+	"github.com/coupergateway/couper/resource"
+)
+
+const defaultAPIKeyHeader = "X-API-Key"
+
+var (
+	_ AccessControl = &APIKey{}
+
+	apiKeyHashRegex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// apiKeys maps the hex encoded SHA-256 hash of a key to its metadata.
+type apiKeys map[string]map[string]interface{}
+
+// APIKey represents an AC-APIKey object
+type APIKey struct {
+	file                *resource.WatchedFile
+	keys                apiKeys
+	name                string
+	permissionsProperty string
+	source              func(req *http.Request) string
+}
+
+// NewAPIKey creates a new AC-APIKey object
+func NewAPIKey(ctx context.Context, conf *config.APIKey, log *logrus.Entry) (*APIKey, error) {
+	source, err := newAPIKeySource(conf.Cookie, conf.Header, conf.QueryParam)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionsProperty := conf.PermissionsProperty
+	if permissionsProperty == "" {
+		permissionsProperty = "permissions"
+	}
+
+	a := &APIKey{
+		keys:                make(apiKeys),
+		name:                conf.Name,
+		permissionsProperty: permissionsProperty,
+		source:              source,
+	}
+
+	var inlineKeys map[string]cty.Value
+	if !conf.Keys.IsNull() {
+		t := conf.Keys.Type()
+		if !t.IsObjectType() && !t.IsMapType() {
+			return nil, fmt.Errorf("keys must be an object")
+		}
+		inlineKeys = conf.Keys.AsValueMap()
+	}
+
+	for hash, metadata := range inlineKeys {
+		t := metadata.Type()
+		if !metadata.IsNull() && !t.IsObjectType() && !t.IsMapType() {
+			return nil, fmt.Errorf("invalid metadata for key hash %q: object expected", hash)
+		}
+		a.keys[hash] = seetie.ValueToMap(metadata)
+	}
+	if err = a.keys.validate(permissionsProperty); err != nil {
+		return nil, err
+	}
+
+	if conf.KeysFile != "" {
+		a.file, err = resource.NewWatchedFile(ctx, "api_key keys_file", conf.KeysFile,
+			&apiKeysUnmarshaller{permissionsProperty: permissionsProperty}, log)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(a.keys) == 0 && a.file == nil {
+		return nil, fmt.Errorf("keys or keys_file attribute required")
+	}
+
+	return a, nil
+}
+
+func newAPIKeySource(cookie, header, queryParam string) (func(req *http.Request) string, error) {
+	c, h, q := strings.TrimSpace(cookie), strings.TrimSpace(header), strings.TrimSpace(queryParam)
+
+	var configured int
+	for _, name := range []string{c, h, q} {
+		if name != "" {
+			configured++
+		}
+	}
+	if configured > 1 {
+		return nil, fmt.Errorf("only one of cookie, header or query_param attributes is allowed")
+	}
+
+	switch {
+	case c != "":
+		return func(req *http.Request) string {
+			if ck, err := req.Cookie(c); err == nil {
+				return ck.Value
+			}
+			return ""
+		}, nil
+	case q != "":
+		return func(req *http.Request) string {
+			return req.URL.Query().Get(q)
+		}, nil
+	}
+
+	if h == "" {
+		h = defaultAPIKeyHeader
+	}
+	return func(req *http.Request) string {
+		return req.Header.Get(h)
+	}, nil
+}
+
+// Validate implements the AccessControl interface
+func (a *APIKey) Validate(req *http.Request) error {
+	if a == nil {
+		return errors.Configuration
+	}
+
+	key := a.source(req)
+	if key == "" {
+		return errors.ApiKeyMissing.Message("key required")
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	metadata, ok := a.lookup(hex.EncodeToString(sum[:]))
+	if !ok {
+		return errors.ApiKey.Message("unknown key")
+	}
+
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	acMap[a.name] = metadata
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+
+	granted, _ := ctx.Value(request.GrantedPermissions).([]string)
+	for _, p := range permissionsFromValue(metadata[a.permissionsProperty]) {
+		granted, _ = addPermission(granted, p)
+	}
+	ctx = context.WithValue(ctx, request.GrantedPermissions, granted)
+
+	*req = *req.WithContext(ctx)
+
+	return nil
+}
+
+// lookup prefers inline keys over the ones from the keys file.
+func (a *APIKey) lookup(hash string) (map[string]interface{}, bool) {
+	if metadata, ok := a.keys[hash]; ok {
+		return metadata, true
+	}
+
+	if a.file == nil {
+		return nil, false
+	}
+
+	fileKeys, _ := a.file.Data().(apiKeys)
+	metadata, ok := fileKeys[hash]
+	return metadata, ok
+}
+
+func (k apiKeys) validate(permissionsProperty string) error {
+	for hash, metadata := range k {
+		if !apiKeyHashRegex.MatchString(hash) {
+			return fmt.Errorf("invalid key hash %q: lower-case hex encoded SHA-256 hash expected", hash)
+		}
+
+		value, exists := metadata[permissionsProperty]
+		if !exists {
+			continue
+		}
+		if _, ok := value.(string); ok {
+			continue
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("invalid %s value for key hash %q: %#v", permissionsProperty, hash, value)
+		}
+		for _, v := range list {
+			if _, ok = v.(string); !ok {
+				return fmt.Errorf("invalid %s value for key hash %q: %#v", permissionsProperty, hash, value)
+			}
+		}
+	}
+	return nil
+}
+
+// permissionsFromValue reads a space-separated string or a list of strings.
+func permissionsFromValue(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Split(v, " ")
+	case []interface{}:
+		var permissions []string
+		for _, p := range v {
+			if s, ok := p.(string); ok {
+				permissions = append(permissions, s)
+			}
+		}
+		return permissions
+	}
+	return nil
+}
+
+type apiKeysUnmarshaller struct {
+	permissionsProperty string
+}
+
+func (u *apiKeysUnmarshaller) Unmarshal(raw []byte) (interface{}, error) {
+	keys := make(apiKeys)
+	if err := json.Unmarshal(raw, &keys); err != nil {
+		return nil, fmt.Errorf("invalid keys file content: %w", err)
+	}
+
+	for hash, metadata := range keys {
+		if metadata == nil {
+			keys[hash] = make(map[string]interface{})
+		}
+	}
+
+	if err := keys.validate(u.permissionsProperty); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
@@ -0,0 +1,180 @@
+package accesscontrol_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	couperErr "github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/internal/test"
+	"github.com/coupergateway/couper/resource"
+)
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func Test_NewAPIKey(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		conf      *config.APIKey
+		expErrMsg string
+	}{
+		{"inline keys", &config.APIKey{Keys: cty.ObjectVal(map[string]cty.Value{hashAPIKey("k"): cty.EmptyObjectVal})}, ""},
+		{"missing keys", &config.APIKey{}, "keys or keys_file attribute required"},
+		{"multiple sources", &config.APIKey{Header: "X-Key", Cookie: "key", Keys: cty.ObjectVal(map[string]cty.Value{hashAPIKey("k"): cty.EmptyObjectVal})}, "only one of cookie, header or query_param attributes is allowed"},
+		{"plain key", &config.APIKey{Keys: cty.ObjectVal(map[string]cty.Value{"my-key": cty.EmptyObjectVal})}, `invalid key hash "my-key": lower-case hex encoded SHA-256 hash expected`},
+		{"invalid metadata", &config.APIKey{Keys: cty.ObjectVal(map[string]cty.Value{hashAPIKey("k"): cty.StringVal("acme")})}, `invalid metadata for key hash "` + hashAPIKey("k") + `": object expected`},
+		{"invalid permissions", &config.APIKey{Keys: cty.ObjectVal(map[string]cty.Value{hashAPIKey("k"): cty.ObjectVal(map[string]cty.Value{
+			"permissions": cty.NumberIntVal(1),
+		})})}, `invalid permissions value for key hash "` + hashAPIKey("k") + `": 1`},
+		{"missing file", &config.APIKey{KeysFile: "testdata/missing.json"}, "configuration error"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := ac.NewAPIKey(context.Background(), tc.conf, nil)
+			if tc.expErrMsg == "" {
+				if err != nil {
+					st.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				st.Fatalf("expected error %q", tc.expErrMsg)
+			}
+			if msg := err.Error(); len(msg) < len(tc.expErrMsg) || msg[:len(tc.expErrMsg)] != tc.expErrMsg {
+				st.Errorf("expected error %q, got: %q", tc.expErrMsg, msg)
+			}
+		})
+	}
+}
+
+func Test_APIKey_Validate(t *testing.T) {
+	keys := cty.ObjectVal(map[string]cty.Value{
+		hashAPIKey("acme-secret"): cty.ObjectVal(map[string]cty.Value{
+			"owner":       cty.StringVal("acme"),
+			"permissions": cty.TupleVal([]cty.Value{cty.StringVal("orders:read")}),
+		}),
+	})
+
+	for _, tc := range []struct {
+		name       string
+		conf       *config.APIKey
+		setKey     func(req *http.Request)
+		expErr     *couperErr.Error
+		expGranted []string
+	}{
+		{"default header", &config.APIKey{Keys: keys}, func(req *http.Request) {
+			req.Header.Set("X-API-Key", "acme-secret")
+		}, nil, []string{"orders:read"}},
+		{"custom header", &config.APIKey{Header: "X-Partner-Key", Keys: keys}, func(req *http.Request) {
+			req.Header.Set("X-Partner-Key", "acme-secret")
+		}, nil, []string{"orders:read"}},
+		{"query param", &config.APIKey{QueryParam: "key", Keys: keys}, func(req *http.Request) {
+			req.URL.RawQuery = "key=acme-secret"
+		}, nil, []string{"orders:read"}},
+		{"cookie", &config.APIKey{Cookie: "key", Keys: keys}, func(req *http.Request) {
+			req.AddCookie(&http.Cookie{Name: "key", Value: "acme-secret"})
+		}, nil, []string{"orders:read"}},
+		{"missing key", &config.APIKey{Keys: keys}, func(req *http.Request) {}, couperErr.ApiKeyMissing, nil},
+		{"wrong source", &config.APIKey{QueryParam: "key", Keys: keys}, func(req *http.Request) {
+			req.Header.Set("X-API-Key", "acme-secret")
+		}, couperErr.ApiKeyMissing, nil},
+		{"unknown key", &config.APIKey{Keys: keys}, func(req *http.Request) {
+			req.Header.Set("X-API-Key", "acme-secrets")
+		}, couperErr.ApiKey, nil},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			tc.conf.Name = "partners"
+			apiKey, err := ac.NewAPIKey(context.Background(), tc.conf, nil)
+			h.Must(err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			tc.setKey(req)
+
+			err = apiKey.Validate(req)
+			if tc.expErr != nil {
+				if !couperErr.Equals(err, tc.expErr) {
+					st.Errorf("expected error %v, got: %v", tc.expErr, err)
+				}
+				return
+			}
+			h.Must(err)
+
+			acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+			metadata, _ := acMap["partners"].(map[string]interface{})
+			if metadata["owner"] != "acme" {
+				st.Errorf("expected owner metadata in context, got: %#v", acMap)
+			}
+
+			granted, _ := req.Context().Value(request.GrantedPermissions).([]string)
+			if !reflect.DeepEqual(granted, tc.expGranted) {
+				st.Errorf("expected granted permissions %v, got: %v", tc.expGranted, granted)
+			}
+		})
+	}
+}
+
+func Test_APIKey_KeysFileReload(t *testing.T) {
+	helper := test.New(t)
+
+	interval := resource.WatchInterval
+	resource.WatchInterval = time.Millisecond * 50
+	defer func() { resource.WatchInterval = interval }()
+
+	keysFile := filepath.Join(t.TempDir(), "keys.json")
+	writeKeys := func(key, owner string) {
+		helper.Must(os.WriteFile(keysFile, []byte(`{"`+hashAPIKey(key)+`": {"owner": "`+owner+`", "permissions": "a b"}}`), 0600))
+	}
+	writeKeys("first", "acme")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	apiKey, err := ac.NewAPIKey(ctx, &config.APIKey{Name: "partners", KeysFile: keysFile}, nil)
+	helper.Must(err)
+
+	validate := func(key string) error {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", key)
+		return apiKey.Validate(req)
+	}
+
+	helper.Must(validate("first"))
+
+	// ensure a different modification time on coarse grained file systems
+	time.Sleep(time.Millisecond * 10)
+	writeKeys("second", "globex")
+	helper.Must(os.Chtimes(keysFile, time.Now(), time.Now().Add(time.Second)))
+
+	deadline := time.Now().Add(time.Second * 2)
+	for validate("second") != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected reloaded keys file")
+		}
+		time.Sleep(time.Millisecond * 20)
+	}
+
+	if err = validate("first"); !couperErr.Equals(err, couperErr.ApiKey) {
+		t.Errorf("expected removed key to be rejected, got: %v", err)
+	}
+
+	// an invalid file keeps the previous keys
+	helper.Must(os.WriteFile(keysFile, []byte(`{`), 0600))
+	helper.Must(os.Chtimes(keysFile, time.Now(), time.Now().Add(time.Second*2)))
+	time.Sleep(time.Millisecond * 200)
+	helper.Must(validate("second"))
+}
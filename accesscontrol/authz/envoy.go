@@ -0,0 +1,366 @@
+package authz
+
+import (
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/internal/seetie"
+)
+
+// envoyCheckPath is the gRPC method path of the Envoy ext_authz Check call.
+const envoyCheckPath = "/envoy.service.auth.v3.Authorization/Check"
+
+// upstreamMutation holds the client request modifications of an Envoy ok_response
+// which are forwarded to the upstream.
+type upstreamMutation struct {
+	headers         []*corev3.HeaderValueOption
+	headersToRemove []string
+	queryToRemove   []string
+	queryToSet      []*corev3.QueryParameter
+}
+
+// deniedResponse is the client response of an Envoy denied_response.
+type deniedResponse struct {
+	body    string
+	headers http.Header
+	status  int
+}
+
+// calloutEnvoy calls the Envoy ext_authz gRPC Check method over the configured
+// (HTTP/2) backend transport. Check responses carry no cache directives, so
+// decisions are cached for cache_ttl.
+func (e *External) calloutEnvoy(req *http.Request) (*decision, time.Duration, error) {
+	message, err := proto.Marshal(newCheckRequest(req, e.includeTLS))
+	if err != nil {
+		return nil, 0, errors.ExternalAuthz.Label(e.name).With(err)
+	}
+
+	// gRPC length-prefixed message: uncompressed flag and big endian message size
+	body := make([]byte, 5+len(message))
+	binary.BigEndian.PutUint32(body[1:5], uint32(len(message)))
+	copy(body[5:], message)
+
+	calloutURL, err := url.Parse(e.url)
+	if err != nil {
+		return nil, 0, errors.ExternalAuthz.Label(e.name).With(err)
+	}
+	calloutURL.Path = strings.TrimSuffix(calloutURL.Path, "/") + envoyCheckPath
+
+	outreq, err := http.NewRequest(http.MethodPost, calloutURL.String(), nil)
+	if err != nil {
+		return nil, 0, errors.ExternalAuthz.Label(e.name).With(err)
+	}
+
+	outreq.Header.Set("Content-Type", "application/grpc")
+	outreq.Header.Set("TE", "trailers")
+	if e.timeout > 0 {
+		outreq.Header.Set("Grpc-Timeout", strconv.FormatInt(e.timeout.Milliseconds(), 10)+"m")
+	}
+	eval.SetBody(outreq, body)
+
+	ctx, cancel := e.newCalloutContext(req)
+	defer cancel()
+
+	res, err := e.transport.RoundTrip(outreq.WithContext(ctx))
+	if err != nil {
+		return nil, 0, errors.ExternalAuthz.Label(e.name).With(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, 0, errors.ExternalAuthz.Label(e.name).Messagef("unexpected authorization service response status: %d", res.StatusCode)
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, 0, errors.ExternalAuthz.Label(e.name).With(err)
+	}
+
+	if err = grpcStatusError(res); err != nil {
+		return nil, 0, errors.ExternalAuthz.Label(e.name).With(err)
+	}
+
+	checkResponse := &authv3.CheckResponse{}
+	if err = unmarshalGRPCMessage(resBody, checkResponse); err != nil {
+		return nil, 0, errors.ExternalAuthz.Label(e.name).With(err)
+	}
+
+	d, err := e.newEnvoyDecision(checkResponse)
+	if err != nil {
+		return nil, 0, err
+	}
+	return d, e.cacheTTL, nil
+}
+
+// newEnvoyDecision maps a CheckResponse to a decision: an OK status allows the request
+// with the ok_response mutations and the dynamic metadata as context, any other status
+// denies it with the denied_response, defaulting to 403.
+func (e *External) newEnvoyDecision(checkResponse *authv3.CheckResponse) (*decision, error) {
+	if checkResponse.GetErrorResponse() != nil {
+		return nil, errors.ExternalAuthz.Label(e.name).Message("authorization service error response")
+	}
+
+	if codes.Code(checkResponse.GetStatus().GetCode()) != codes.OK {
+		denied := checkResponse.GetDeniedResponse()
+		d := &decision{
+			denied: &deniedResponse{
+				body:    denied.GetBody(),
+				headers: make(http.Header),
+				status:  int(denied.GetStatus().GetCode()),
+			},
+		}
+		if d.denied.status == 0 {
+			d.denied.status = http.StatusForbidden
+		}
+		mutateHeader(d.denied.headers, denied.GetHeaders())
+		d.challenge = d.denied.headers.Get("WWW-Authenticate")
+		d.status = d.denied.status
+		return d, nil
+	}
+
+	ok := checkResponse.GetOkResponse()
+
+	data := make(map[string]interface{})
+	for name, value := range ok.GetDynamicMetadata().AsMap() {
+		data[name] = value
+	}
+	for name, value := range checkResponse.GetDynamicMetadata().AsMap() {
+		data[name] = value
+	}
+
+	permissions, err := e.parsePermissions(data)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(http.Header)
+	mutateHeader(headers, ok.GetHeaders())
+	data["headers"] = seetie.HeaderToMap(headers)
+
+	responseHeaders := make(http.Header)
+	mutateHeader(responseHeaders, ok.GetResponseHeadersToAdd())
+	data["response_headers"] = seetie.HeaderToMap(responseHeaders)
+
+	return &decision{
+		allowed:     true,
+		data:        data,
+		permissions: permissions,
+		status:      http.StatusOK,
+		upstream: &upstreamMutation{
+			headers:         ok.GetHeaders(),
+			headersToRemove: ok.GetHeadersToRemove(),
+			queryToRemove:   ok.GetQueryParametersToRemove(),
+			queryToSet:      ok.GetQueryParametersToSet(),
+		},
+	}, nil
+}
+
+// apply modifies the client request which is forwarded to the upstream.
+func (u *upstreamMutation) apply(req *http.Request) {
+	if u == nil {
+		return
+	}
+
+	for _, name := range u.headersToRemove {
+		req.Header.Del(name)
+	}
+	mutateHeader(req.Header, u.headers)
+
+	if len(u.queryToRemove) == 0 && len(u.queryToSet) == 0 {
+		return
+	}
+
+	query := req.URL.Query()
+	for _, key := range u.queryToRemove {
+		query.Del(key)
+	}
+	for _, param := range u.queryToSet {
+		query.Set(param.GetKey(), param.GetValue())
+	}
+	req.URL.RawQuery = query.Encode()
+}
+
+// contextValue exposes the denied response for the default error handler,
+// keeping all values of repeated headers.
+func (d *deniedResponse) contextValue() map[string]interface{} {
+	headers := make(map[string]interface{}, len(d.headers))
+	for name, values := range d.headers {
+		headers[strings.ToLower(name)] = values
+	}
+
+	return map[string]interface{}{
+		"body":    d.body,
+		"headers": headers,
+		"status":  int64(d.status),
+	}
+}
+
+// mutateHeader applies the header value options. Like Envoy's ext_authz filter, an option
+// without explicit append field or append_action overwrites an existing header.
+func mutateHeader(header http.Header, options []*corev3.HeaderValueOption) {
+	for _, option := range options {
+		name := option.GetHeader().GetKey()
+		value := option.GetHeader().GetValue()
+		if value == "" {
+			value = string(option.GetHeader().GetRawValue())
+		}
+
+		_, exists := header[http.CanonicalHeaderKey(name)]
+
+		// the deprecated append field is still sent by ext_authz servers
+		if option.GetAppend() != nil {
+			if option.GetAppend().GetValue() {
+				header.Add(name, value)
+			} else {
+				header.Set(name, value)
+			}
+			continue
+		}
+
+		switch option.GetAppendAction() {
+		case corev3.HeaderValueOption_ADD_IF_ABSENT:
+			if !exists {
+				header.Set(name, value)
+			}
+		case corev3.HeaderValueOption_OVERWRITE_IF_EXISTS:
+			if exists {
+				header.Set(name, value)
+			}
+		default:
+			header.Set(name, value)
+		}
+	}
+}
+
+// newCheckRequest describes the client request in the Envoy attribute context.
+func newCheckRequest(req *http.Request, includeTLS bool) *authv3.CheckRequest {
+	headers := map[string]string{
+		":authority": req.Host,
+		":method":    req.Method,
+		":path":      req.URL.RequestURI(),
+	}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	id, _ := req.Context().Value(request.UID).(string)
+
+	attributes := &authv3.AttributeContext{
+		Source: &authv3.AttributeContext_Peer{
+			Address: newSocketAddress(req.RemoteAddr),
+		},
+		Destination: &authv3.AttributeContext_Peer{},
+		Request: &authv3.AttributeContext_Request{
+			Time: timestamppb.Now(),
+			Http: &authv3.AttributeContext_HttpRequest{
+				Headers:  headers,
+				Host:     req.Host,
+				Id:       id,
+				Method:   req.Method,
+				Path:     req.URL.RequestURI(),
+				Protocol: req.Proto,
+				Scheme:   scheme,
+				Size:     req.ContentLength,
+			},
+		},
+	}
+
+	if localAddr, ok := req.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		attributes.Destination.Address = newSocketAddress(localAddr.String())
+	}
+
+	if includeTLS && req.TLS != nil {
+		attributes.TlsSession = &authv3.AttributeContext_TLSSession{Sni: req.TLS.ServerName}
+		if len(req.TLS.PeerCertificates) > 0 {
+			cert := req.TLS.PeerCertificates[0]
+			attributes.Source.Certificate = url.QueryEscape(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})))
+			attributes.Source.Principal = cert.Subject.String()
+			if len(cert.URIs) > 0 {
+				attributes.Source.Principal = cert.URIs[0].String()
+			}
+		}
+	}
+
+	return &authv3.CheckRequest{Attributes: attributes}
+}
+
+func newSocketAddress(hostPort string) *corev3.Address {
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return nil
+	}
+	portValue, _ := strconv.ParseUint(port, 10, 32)
+
+	return &corev3.Address{
+		Address: &corev3.Address_SocketAddress{
+			SocketAddress: &corev3.SocketAddress{
+				Address:       host,
+				PortSpecifier: &corev3.SocketAddress_PortValue{PortValue: uint32(portValue)},
+			},
+		},
+	}
+}
+
+// grpcStatusError returns the error of a non-OK grpc-status trailer, or header for
+// trailers-only responses.
+func grpcStatusError(res *http.Response) error {
+	status := res.Trailer.Get("Grpc-Status")
+	message := res.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status = res.Header.Get("Grpc-Status")
+		message = res.Header.Get("Grpc-Message")
+	}
+
+	if status == "" {
+		return fmt.Errorf("missing grpc-status")
+	}
+
+	code, err := strconv.ParseUint(status, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid grpc-status: %q", status)
+	}
+	if codes.Code(code) == codes.OK {
+		return nil
+	}
+
+	if decoded, derr := url.PathUnescape(message); derr == nil {
+		message = decoded
+	}
+	return fmt.Errorf("grpc status %s: %s", codes.Code(code), message)
+}
+
+// unmarshalGRPCMessage reads a single uncompressed length-prefixed gRPC message.
+func unmarshalGRPCMessage(body []byte, message proto.Message) error {
+	if len(body) < 5 {
+		return fmt.Errorf("invalid grpc message: %d bytes", len(body))
+	}
+	if body[0] != 0 {
+		return fmt.Errorf("compressed grpc messages are not supported")
+	}
+	if size := binary.BigEndian.Uint32(body[1:5]); int(size) != len(body)-5 {
+		return fmt.Errorf("invalid grpc message size: %d", size)
+	}
+	return proto.Unmarshal(body[5:], message)
+}
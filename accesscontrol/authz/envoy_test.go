@@ -0,0 +1,217 @@
+package authz_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/coupergateway/couper/accesscontrol/authz"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+type checkFunc func(context.Context, *authv3.CheckRequest) (*authv3.CheckResponse, error)
+
+func (f checkFunc) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	return f(ctx, req)
+}
+
+func newEnvoyExternal(t *testing.T, check checkFunc, permissionsProperty string) *authz.External {
+	t.Helper()
+
+	grpcServer := grpc.NewServer()
+	authv3.RegisterAuthorizationServer(grpcServer, check)
+
+	srv := httptest.NewUnstartedServer(grpcServer)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	return authz.NewExternal("test_ac", srv.URL, false, permissionsProperty, srv.Client().Transport).
+		WithOptions(authz.ExternalOptions{Protocol: authz.ProtocolEnvoyGRPC})
+}
+
+func TestExternal_Validate_EnvoyAllow(t *testing.T) {
+	var checkRequest *authv3.CheckRequest
+
+	external := newEnvoyExternal(t, func(_ context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+		checkRequest = req
+
+		metadata, err := structpb.NewStruct(map[string]interface{}{
+			"sub":         "clark.kent",
+			"permissions": []interface{}{"read", "write"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &authv3.CheckResponse{
+			Status:          &status.Status{Code: int32(codes.OK)},
+			DynamicMetadata: metadata,
+			HttpResponse: &authv3.CheckResponse_OkResponse{OkResponse: &authv3.OkHttpResponse{
+				Headers: []*corev3.HeaderValueOption{
+					{Header: &corev3.HeaderValue{Key: "x-user", Value: "clark.kent"}},
+					{Header: &corev3.HeaderValue{Key: "x-trace", Value: "authz"}, Append: wrapperspb.Bool(true)},
+					{Header: &corev3.HeaderValue{Key: "x-tenant", Value: "planet"}, AppendAction: corev3.HeaderValueOption_ADD_IF_ABSENT},
+				},
+				HeadersToRemove:         []string{"authorization"},
+				QueryParametersToRemove: []string{"token"},
+				QueryParametersToSet:    []*corev3.QueryParameter{{Key: "user", Value: "clark.kent"}},
+				ResponseHeadersToAdd: []*corev3.HeaderValueOption{
+					{Header: &corev3.HeaderValue{Key: "x-authz", Value: "allowed"}},
+				},
+			}},
+		}, nil
+	}, "permissions")
+
+	req := httptest.NewRequest(http.MethodPost, "http://client.request/protected?token=secret", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-User", "lex.luthor")
+	req.Header.Set("X-Trace", "client")
+	req.Header.Set("X-Tenant", "daily")
+
+	if err := external.Validate(req); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	httpAttributes := checkRequest.GetAttributes().GetRequest().GetHttp()
+	if httpAttributes.GetMethod() != http.MethodPost || httpAttributes.GetPath() != "/protected?token=secret" ||
+		httpAttributes.GetHost() != "client.request" {
+		t.Errorf("unexpected check request attributes: %v", httpAttributes)
+	}
+	if authorization := httpAttributes.GetHeaders()["authorization"]; authorization != "Bearer secret" {
+		t.Errorf("expected authorization header in check request, got: %q", authorization)
+	}
+
+	for name, expValues := range map[string][]string{
+		"Authorization": nil,
+		"X-User":        {"clark.kent"},
+		"X-Trace":       {"client", "authz"},
+		"X-Tenant":      {"daily"},
+	} {
+		if values := req.Header.Values(name); len(values) != len(expValues) || (len(values) > 0 && values[len(values)-1] != expValues[len(expValues)-1]) {
+			t.Errorf("expected %s header %v, got: %v", name, expValues, values)
+		}
+	}
+	if query := req.URL.RawQuery; query != "user=clark.kent" {
+		t.Errorf("expected mutated query, got: %q", query)
+	}
+
+	acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+	data, _ := acMap["test_ac"].(map[string]interface{})
+	if data["sub"] != "clark.kent" {
+		t.Errorf("expected dynamic metadata in context, got: %v", data)
+	}
+	if responseHeaders, _ := data["response_headers"].(map[string]interface{}); responseHeaders["x-authz"] != "allowed" {
+		t.Errorf("expected response headers in context, got: %v", data["response_headers"])
+	}
+
+	granted, _ := req.Context().Value(request.GrantedPermissions).([]string)
+	if len(granted) != 2 || granted[0] != "read" || granted[1] != "write" {
+		t.Errorf("expected granted permissions [read write], got: %v", granted)
+	}
+}
+
+func TestExternal_Validate_EnvoyDeny(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		denied    *authv3.DeniedHttpResponse
+		expKind   string
+		expStatus int
+	}{
+		{"default status", nil, "external_authz_insufficient_permissions", http.StatusForbidden},
+		{"unauthorized", &authv3.DeniedHttpResponse{
+			Status:  &typev3.HttpStatus{Code: typev3.StatusCode_Unauthorized},
+			Headers: []*corev3.HeaderValueOption{{Header: &corev3.HeaderValue{Key: "www-authenticate", Value: "Bearer"}}},
+		}, "external_authz_invalid_credentials", http.StatusUnauthorized},
+		{"too many requests", &authv3.DeniedHttpResponse{
+			Status: &typev3.HttpStatus{Code: typev3.StatusCode_TooManyRequests},
+			Body:   "slow down",
+		}, "external_authz_insufficient_permissions", http.StatusTooManyRequests},
+		{"ok http status", &authv3.DeniedHttpResponse{
+			Status: &typev3.HttpStatus{Code: typev3.StatusCode_OK},
+			Body:   "denied",
+		}, "external_authz_insufficient_permissions", http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			external := newEnvoyExternal(t, func(_ context.Context, _ *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+				return &authv3.CheckResponse{
+					Status:       &status.Status{Code: int32(codes.PermissionDenied)},
+					HttpResponse: &authv3.CheckResponse_DeniedResponse{DeniedResponse: tc.denied},
+				}, nil
+			}, "")
+
+			req := httptest.NewRequest(http.MethodGet, "http://client.request/protected", nil)
+			err := external.Validate(req)
+			if granted := req.Context().Value(request.GrantedPermissions); granted != nil {
+				t.Errorf("expected no granted permissions, got: %v", granted)
+			}
+
+			cErr, ok := err.(*errors.Error)
+			if !ok {
+				t.Fatalf("expected *errors.Error, got: %T", err)
+			}
+			if kinds := cErr.Kinds(); len(kinds) == 0 || kinds[0] != tc.expKind {
+				t.Errorf("expected most specific error kind %q, got: %v", tc.expKind, kinds)
+			}
+			if httpStatus := cErr.HTTPStatus(); httpStatus != tc.expStatus {
+				t.Errorf("expected error status %d, got: %d", tc.expStatus, httpStatus)
+			}
+
+			acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+			data, _ := acMap["test_ac"].(map[string]interface{})
+			denied, _ := data["denied_response"].(map[string]interface{})
+			if denied["status"] != int64(tc.expStatus) || denied["body"] != tc.denied.GetBody() {
+				t.Errorf("unexpected denied_response context: %v", denied)
+			}
+		})
+	}
+}
+
+func TestExternal_Validate_EnvoyError(t *testing.T) {
+	external := newEnvoyExternal(t, func(_ context.Context, _ *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+		return nil, grpcstatus.Error(codes.Unavailable, "policy not loaded")
+	}, "")
+
+	req := httptest.NewRequest(http.MethodGet, "http://client.request/protected", nil)
+	err := external.Validate(req)
+
+	cErr, ok := err.(*errors.Error)
+	if !ok {
+		t.Fatalf("expected *errors.Error, got: %T", err)
+	}
+	if kinds := cErr.Kinds(); len(kinds) == 0 || kinds[0] != "external_authz" {
+		t.Errorf("expected error kind external_authz, got: %v", kinds)
+	}
+	if expMsg := "grpc status Unavailable: policy not loaded"; !strings.Contains(cErr.LogError(), expMsg) {
+		t.Errorf("expected error message containing %q, got: %q", expMsg, cErr.LogError())
+	}
+}
+
+func TestParseProtocol(t *testing.T) {
+	for value, expProtocol := range map[string]authz.Protocol{
+		"":           authz.ProtocolHTTP,
+		"http":       authz.ProtocolHTTP,
+		"envoy_grpc": authz.ProtocolEnvoyGRPC,
+	} {
+		if protocol, err := authz.ParseProtocol(value); err != nil || protocol != expProtocol {
+			t.Errorf("%q: expected protocol %d, got: %d, %v", value, expProtocol, protocol, err)
+		}
+	}
+
+	if _, err := authz.ParseProtocol("grpc"); err == nil {
+		t.Error("expected an error for an invalid protocol value")
+	}
+}
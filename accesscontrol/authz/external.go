@@ -6,13 +6,19 @@ import (
 	"crypto/tls"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io"
 	"mime"
 	"net/http"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/hcl/v2"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/coupergateway/couper/cache"
 	"github.com/coupergateway/couper/config/request"
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/eval"
@@ -22,16 +28,93 @@ import (
 
 const roundTripName = "external_authz"
 
+// OnError defines how a failed authorization callout is handled.
+type OnError uint8
+
+const (
+	// OnErrorDeny denies the request with an external_authz error.
+	OnErrorDeny OnError = iota
+	// OnErrorAllow allows the request without granting permissions.
+	OnErrorAllow
+	// OnErrorAllowWithFlag allows the request and exposes the error as request.context.<label>.error.
+	OnErrorAllowWithFlag
+)
+
+// ParseOnError parses the on_error attribute value.
+func ParseOnError(value string) (OnError, error) {
+	switch value {
+	case "", "deny":
+		return OnErrorDeny, nil
+	case "allow":
+		return OnErrorAllow, nil
+	case "allow_with_flag":
+		return OnErrorAllowWithFlag, nil
+	default:
+		return OnErrorDeny, fmt.Errorf(`on_error must be one of "deny", "allow" or "allow_with_flag": %q`, value)
+	}
+}
+
+// Protocol defines the wire format of the authorization callout.
+type Protocol uint8
+
+const (
+	// ProtocolHTTP posts a JSON description of the client request.
+	ProtocolHTTP Protocol = iota
+	// ProtocolEnvoyGRPC calls the Envoy ext_authz gRPC Check method.
+	ProtocolEnvoyGRPC
+)
+
+// ParseProtocol parses the protocol attribute value.
+func ParseProtocol(value string) (Protocol, error) {
+	switch value {
+	case "", "http":
+		return ProtocolHTTP, nil
+	case "envoy_grpc":
+		return ProtocolEnvoyGRPC, nil
+	default:
+		return ProtocolHTTP, fmt.Errorf(`protocol must be one of "http" or "envoy_grpc": %q`, value)
+	}
+}
+
 // External authorization calls out to a service which decides whether the
 // client request is allowed: 200 allows, 401 and 403 map to distinct error types.
 type External struct {
+	cacheKey            hcl.Expression
+	cacheTTL            time.Duration
 	includeTLS          bool
+	memStore            *cache.MemoryStore
 	name                string
+	onError             OnError
 	permissionsProperty string
+	protocol            Protocol
+	timeout             time.Duration
 	transport           http.RoundTripper
 	url                 string
 }
 
+// ExternalOptions configures the optional decision cache, callout timeout and error handling.
+type ExternalOptions struct {
+	// CacheKey enables the decision cache, evaluated per client request.
+	CacheKey hcl.Expression
+	// CacheTTL applies to decisions without Cache-Control max-age response directive.
+	CacheTTL time.Duration
+	MemStore *cache.MemoryStore
+	OnError  OnError
+	Protocol Protocol
+	Timeout  time.Duration
+}
+
+// decision is the outcome of an authorization callout which can be cached.
+type decision struct {
+	allowed     bool
+	challenge   string
+	data        map[string]interface{}
+	denied      *deniedResponse
+	permissions []string
+	status      int
+	upstream    *upstreamMutation
+}
+
 // simplified form of http.Request for serialization
 type clientRequest struct {
 	Method  string      `json:"method"`
@@ -82,6 +165,17 @@ func NewExternal(name, calloutURL string, includeTLS bool, permissionsProperty s
 	}
 }
 
+// WithOptions sets the optional behavior of the External access control.
+func (e *External) WithOptions(opts ExternalOptions) *External {
+	e.cacheKey = opts.CacheKey
+	e.cacheTTL = opts.CacheTTL
+	e.memStore = opts.MemStore
+	e.onError = opts.OnError
+	e.protocol = opts.Protocol
+	e.timeout = opts.Timeout
+	return e
+}
+
 func newMetadataTLS(state *tls.ConnectionState) *metadataTLS {
 	if state == nil {
 		return nil
@@ -123,6 +217,59 @@ func newMetadataTLS(state *tls.ConnectionState) *metadataTLS {
 }
 
 func (e *External) Validate(req *http.Request) error {
+	storageKey, err := e.storageKey(req)
+	if err != nil {
+		return e.handleError(req, err)
+	}
+
+	if storageKey != "" {
+		if d, ok := e.memStore.Get(storageKey).(*decision); ok {
+			return e.apply(req, d)
+		}
+	}
+
+	d, ttl, err := e.callout(req)
+	if err != nil {
+		return e.handleError(req, err)
+	}
+
+	if seconds := int64(ttl / time.Second); storageKey != "" && seconds > 0 {
+		e.memStore.Set(storageKey, d, seconds)
+	}
+
+	return e.apply(req, d)
+}
+
+// storageKey returns the decision cache key for the client request,
+// an empty string disables caching.
+func (e *External) storageKey(req *http.Request) (string, error) {
+	if e.cacheKey == nil || e.memStore == nil {
+		return "", nil
+	}
+
+	value, err := eval.Value(eval.ContextFromRequest(req).HCLContext(), e.cacheKey)
+	if err != nil {
+		return "", errors.ExternalAuthz.Label(e.name).Message("cache_key").With(err)
+	}
+	if value.IsNull() || !value.IsWhollyKnown() {
+		return "", nil
+	}
+
+	raw, err := ctyjson.SimpleJSONValue{Value: value}.MarshalJSON()
+	if err != nil {
+		return "", errors.ExternalAuthz.Label(e.name).Message("cache_key").With(err)
+	}
+
+	sum := sha256.Sum256(raw)
+	return "external_authz_" + e.name + "_" + hex.EncodeToString(sum[:]), nil
+}
+
+// callout requests the decision of the authorization service and returns its cache time-to-live.
+func (e *External) callout(req *http.Request) (*decision, time.Duration, error) {
+	if e.protocol == ProtocolEnvoyGRPC {
+		return e.calloutEnvoy(req)
+	}
+
 	authCtx := authContext{
 		ClientRequest: clientRequest{
 			Method:  req.Method,
@@ -136,49 +283,129 @@ func (e *External) Validate(req *http.Request) error {
 
 	body, err := json.Marshal(authCtx)
 	if err != nil {
-		return errors.ExternalAuthz.Label(e.name).With(err)
+		return nil, 0, errors.ExternalAuthz.Label(e.name).With(err)
 	}
 
 	outreq, err := http.NewRequest(http.MethodPost, e.url, nil)
 	if err != nil {
-		return errors.ExternalAuthz.Label(e.name).With(err)
+		return nil, 0, errors.ExternalAuthz.Label(e.name).With(err)
 	}
 
 	outreq.Header.Set("Accept", "application/json")
 	outreq.Header.Set("Content-Type", "application/json")
 	eval.SetBody(outreq, body)
 
-	outCtx := context.WithValue(req.Context(), request.RoundTripName, roundTripName)
-	// keep the response body readable with a non default roundtrip name
-	outCtx = context.WithValue(outCtx, request.BufferOptions, buffer.Option(buffer.Response))
-	ctx, cancel := context.WithCancel(outCtx)
+	ctx, cancel := e.newCalloutContext(req)
 	defer cancel()
 
 	res, err := e.transport.RoundTrip(outreq.WithContext(ctx))
 	if err != nil {
-		return errors.ExternalAuthz.Label(e.name).With(err)
+		return nil, 0, errors.ExternalAuthz.Label(e.name).With(err)
 	}
 	defer res.Body.Close()
 
+	d := &decision{status: res.StatusCode}
+
 	switch res.StatusCode {
 	case http.StatusOK:
+		d.allowed = true
 		data, derr := e.parseResponseBody(res)
 		if derr != nil {
-			return derr
+			return nil, 0, derr
+		}
+		if d.permissions, derr = e.parsePermissions(data); derr != nil {
+			return nil, 0, derr
 		}
-		e.storeContext(req, withResponseHeaders(data, res.Header))
-		return e.grantPermissions(req, data)
+		d.data = withResponseHeaders(data, res.Header)
 	case http.StatusUnauthorized:
 		// The service's challenge tells the client how to authenticate (e.g. an RFC 9728
 		// resource_metadata pointer); expose it so the default error handler forwards it.
-		if challenge := res.Header.Get("WWW-Authenticate"); challenge != "" {
-			e.storeContext(req, map[string]interface{}{"www_authenticate": challenge})
+		d.challenge = res.Header.Get("WWW-Authenticate")
+	case http.StatusForbidden:
+	default:
+		return nil, 0, errors.ExternalAuthz.Label(e.name).Messagef("unexpected authorization service response status: %d", res.StatusCode)
+	}
+
+	return d, e.decisionTTL(res.Header), nil
+}
+
+// newCalloutContext derives the callout context from the client request, limited by the configured timeout.
+func (e *External) newCalloutContext(req *http.Request) (context.Context, context.CancelFunc) {
+	outCtx := context.WithValue(req.Context(), request.RoundTripName, roundTripName)
+	// keep the response body readable with a non default roundtrip name
+	outCtx = context.WithValue(outCtx, request.BufferOptions, buffer.Option(buffer.Response))
+	if e.timeout > 0 {
+		return context.WithTimeout(outCtx, e.timeout)
+	}
+	return context.WithCancel(outCtx)
+}
+
+// decisionTTL returns the Cache-Control max-age of the authorization service response,
+// or the configured cache_ttl without max-age directive. The no-store and no-cache
+// directives prevent caching.
+func (e *External) decisionTTL(header http.Header) time.Duration {
+	ttl := e.cacheTTL
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0
+		case "max-age":
+			seconds, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+			if err != nil || seconds < 0 {
+				return 0
+			}
+			ttl = time.Duration(seconds) * time.Second
 		}
+	}
+	return ttl
+}
+
+// apply stores the context, mutates the upstream request and grants the permissions
+// of an allow decision or returns the error of a deny decision.
+func (e *External) apply(req *http.Request, d *decision) error {
+	if d.allowed {
+		e.storeContext(req, d.data)
+		d.upstream.apply(req)
+		e.grantPermissions(req, d.permissions)
+		return nil
+	}
+
+	data := map[string]interface{}{}
+	if d.challenge != "" {
+		data["www_authenticate"] = d.challenge
+	}
+	if d.denied != nil {
+		data["denied_response"] = d.denied.contextValue()
+	}
+	if len(data) > 0 {
+		e.storeContext(req, data)
+	}
+
+	if d.status == http.StatusUnauthorized {
 		return errors.ExternalAuthzInvalidCredentials.Label(e.name).Message("invalid credentials")
-	case http.StatusForbidden:
-		return errors.ExternalAuthzInsufficientPermissions.Label(e.name).Message("insufficient permissions")
+	}
+	err := errors.ExternalAuthzInsufficientPermissions.Label(e.name).Message("insufficient permissions")
+	if d.denied != nil {
+		err = err.Status(d.status)
+	}
+	return err
+}
+
+// handleError denies the request or lets it pass without permissions, depending on on_error.
+func (e *External) handleError(req *http.Request, err error) error {
+	switch e.onError {
+	case OnErrorAllow:
+		return nil
+	case OnErrorAllowWithFlag:
+		message := err.Error()
+		if gerr, ok := err.(errors.GoError); ok {
+			message = gerr.LogError()
+		}
+		e.storeContext(req, map[string]interface{}{"error": message})
+		return nil
 	default:
-		return errors.ExternalAuthz.Label(e.name).Messagef("unexpected authorization service response status: %d", res.StatusCode)
+		return err
 	}
 }
 
@@ -234,12 +461,12 @@ func withResponseHeaders(data map[string]interface{}, header http.Header) map[st
 	return ctx
 }
 
-// grantPermissions appends the permissions from the configured response body
-// property to the request's granted permissions with the same value semantics
-// as the jwt block's permissions_claim: a space-separated string or a list of strings.
-func (e *External) grantPermissions(req *http.Request, data map[string]interface{}) error {
+// parsePermissions reads the permissions from the configured response body
+// property with the same value semantics as the jwt block's permissions_claim:
+// a space-separated string or a list of strings.
+func (e *External) parsePermissions(data map[string]interface{}) ([]string, error) {
 	if e.permissionsProperty == "" {
-		return nil
+		return nil, nil
 	}
 
 	value, exists := data[e.permissionsProperty]
@@ -247,7 +474,7 @@ func (e *External) grantPermissions(req *http.Request, data map[string]interface
 		// A configured permissions property expresses a contract with the authorization
 		// service; its absence on an allow is a broken service, not an empty grant —
 		// failing loudly beats a puzzling 403 at required_permission.
-		return errors.ExternalAuthz.Label(e.name).
+		return nil, errors.ExternalAuthz.Label(e.name).
 			Messagef("missing %s permissions property in authorization service response", e.permissionsProperty)
 	}
 
@@ -264,12 +491,21 @@ func (e *External) grantPermissions(req *http.Request, data map[string]interface
 		for _, entry := range v {
 			p, ok := entry.(string)
 			if !ok {
-				return invalidErr()
+				return nil, invalidErr()
 			}
 			permissions = append(permissions, p)
 		}
 	default:
-		return invalidErr()
+		return nil, invalidErr()
+	}
+
+	return permissions, nil
+}
+
+// grantPermissions appends the permissions to the request's granted permissions.
+func (e *External) grantPermissions(req *http.Request, permissions []string) {
+	if len(permissions) == 0 {
+		return
 	}
 
 	ctx := req.Context()
@@ -282,6 +518,4 @@ func (e *External) grantPermissions(req *http.Request, data map[string]interface
 		granted = append(granted, p)
 	}
 	*req = *req.WithContext(context.WithValue(ctx, request.GrantedPermissions, granted))
-
-	return nil
 }
@@ -21,9 +21,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+
 	"github.com/coupergateway/couper/accesscontrol/authz"
+	"github.com/coupergateway/couper/cache"
 	"github.com/coupergateway/couper/config/request"
 	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
 )
 
 type roundTripperFunc func(*http.Request) (*http.Response, error)
@@ -549,3 +555,132 @@ func TestExternal_Validate_EmptyURL(t *testing.T) {
 		t.Errorf("expected callout path %q for backend-provided origin, got: %q", "/", calloutURL)
 	}
 }
+
+func TestExternal_Validate_DecisionCache(t *testing.T) {
+	logger, _ := logrustest.NewNullLogger()
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+	memStore := cache.New(logger.WithContext(context.Background()), quitCh)
+
+	cacheKey, diags := hclsyntax.ParseExpression([]byte("request.headers.authorization"), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	newRequest := func(authorization string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "http://client.request/protected", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return req.WithContext(eval.NewDefaultContext().WithClientRequest(req))
+	}
+
+	for _, tc := range []struct {
+		name         string
+		status       int
+		cacheControl string
+		cacheTTL     time.Duration
+		requests     []string
+		expCallouts  int
+		expKind      string
+	}{
+		{"cache_ttl", http.StatusOK, "", time.Minute, []string{"a", "a", "a"}, 1, ""},
+		{"cache key per client", http.StatusOK, "", time.Minute, []string{"a", "b", "a", "b"}, 2, ""},
+		{"null cache key", http.StatusOK, "", time.Minute, []string{"", ""}, 2, ""},
+		{"max-age", http.StatusOK, "max-age=60", 0, []string{"a", "a"}, 1, ""},
+		{"no cache_ttl", http.StatusOK, "", 0, []string{"a", "a"}, 2, ""},
+		{"no-store", http.StatusOK, "no-store", time.Minute, []string{"a", "a"}, 2, ""},
+		{"no-cache", http.StatusOK, "max-age=60, no-cache", time.Minute, []string{"a", "a"}, 2, ""},
+		{"deny decision", http.StatusForbidden, "", time.Minute, []string{"a", "a"}, 1, "external_authz_insufficient_permissions"},
+		{"unexpected status", http.StatusBadGateway, "", time.Minute, []string{"a", "a"}, 2, "external_authz"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var callouts int
+			external := authz.NewExternal("test_ac_"+strings.ReplaceAll(tc.name, " ", "_"), "http://authz.service/check", false, "",
+				roundTripperFunc(func(_ *http.Request) (*http.Response, error) {
+					callouts++
+					rec := httptest.NewRecorder()
+					if tc.cacheControl != "" {
+						rec.Header().Set("Cache-Control", tc.cacheControl)
+					}
+					rec.WriteHeader(tc.status)
+					return rec.Result(), nil
+				})).WithOptions(authz.ExternalOptions{
+				CacheKey: cacheKey,
+				CacheTTL: tc.cacheTTL,
+				MemStore: memStore,
+			})
+
+			for _, authorization := range tc.requests {
+				err := external.Validate(newRequest(authorization))
+				if tc.expKind == "" {
+					if err != nil {
+						t.Fatalf("expected no error, got: %v", err)
+					}
+					continue
+				}
+
+				cErr, ok := err.(*errors.Error)
+				if !ok {
+					t.Fatalf("expected *errors.Error, got: %T", err)
+				}
+				if kinds := cErr.Kinds(); len(kinds) == 0 || kinds[0] != tc.expKind {
+					t.Errorf("expected most specific error kind %q, got: %v", tc.expKind, kinds)
+				}
+			}
+
+			if callouts != tc.expCallouts {
+				t.Errorf("expected %d callouts, got: %d", tc.expCallouts, callouts)
+			}
+		})
+	}
+}
+
+func TestExternal_Validate_OnError(t *testing.T) {
+	unavailable := respondStatus(http.StatusBadGateway)
+	slow := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+
+	for _, tc := range []struct {
+		name      string
+		onError   string
+		transport http.RoundTripper
+		expErr    bool
+		expFlag   bool
+	}{
+		{"deny", "deny", unavailable, true, false},
+		{"default deny on timeout", "", slow, true, false},
+		{"allow", "allow", unavailable, false, false},
+		{"allow on timeout", "allow", slow, false, false},
+		{"allow_with_flag", "allow_with_flag", unavailable, false, true},
+		{"allow_with_flag on timeout", "allow_with_flag", slow, false, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			onError, err := authz.ParseOnError(tc.onError)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			external := authz.NewExternal("test_ac", "http://authz.service/check", false, "", tc.transport).
+				WithOptions(authz.ExternalOptions{OnError: onError, Timeout: 10 * time.Millisecond})
+
+			req := httptest.NewRequest(http.MethodGet, "http://client.request/protected", nil)
+			err = external.Validate(req)
+			if tc.expErr != (err != nil) {
+				t.Fatalf("expected error: %t, got: %v", tc.expErr, err)
+			}
+
+			acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+			data, _ := acMap["test_ac"].(map[string]interface{})
+			if flag, _ := data["error"].(string); tc.expFlag != (flag != "") {
+				t.Errorf("expected error flag: %t, got: %v", tc.expFlag, data)
+			}
+		})
+	}
+
+	if _, err := authz.ParseOnError("ignore"); err == nil {
+		t.Error("expected an error for an invalid on_error value")
+	}
+}
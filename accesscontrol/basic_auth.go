@@ -2,6 +2,7 @@ package accesscontrol
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"crypto/subtle"
 	"fmt"
@@ -9,40 +10,59 @@ import (
 	"os"
 	"strings"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/coupergateway/couper/config/request"
 	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/resource"
 )
 
 var _ AccessControl = &BasicAuth{}
 
 // BasicAuth represents an AC-BasicAuth object
 type BasicAuth struct {
-	htFile htData
+	htFile *resource.WatchedFile
+	ldap   *LDAP
 	name   string
 	user   string
 	pass   string
 }
 
-// NewBasicAuth creates a new AC-BasicAuth object
-func NewBasicAuth(name, user, pass, file string) (*BasicAuth, error) {
+// htpasswdUnmarshaller parses htpasswd files.
+type htpasswdUnmarshaller struct{}
+
+// NewBasicAuth creates a new AC-BasicAuth object. The htpasswd file is reloaded on modification.
+func NewBasicAuth(ctx context.Context, name, user, pass, file string, log *logrus.Entry) (*BasicAuth, error) {
 	ba := &BasicAuth{
-		htFile: make(htData),
-		name:   name,
-		user:   user,
-		pass:   pass,
+		name: name,
+		user: user,
+		pass: pass,
 	}
 
 	if file == "" {
 		return ba, nil
 	}
 
+	// report a missing or unreadable file with the plain os error
 	fp, err := os.Open(file)
 	if err != nil {
 		return nil, err
 	}
-	defer fp.Close()
+	_ = fp.Close()
+
+	htFile, err := resource.NewWatchedFile(ctx, "htpasswd file", file, htpasswdUnmarshaller{}, log)
+	if err != nil {
+		return nil, err
+	}
+	ba.htFile = htFile
 
-	scanner := bufio.NewScanner(fp)
+	return ba, nil
+}
+
+func (htpasswdUnmarshaller) Unmarshal(raw []byte) (interface{}, error) {
+	data := make(htData)
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
 	var lineNr int
 	for scanner.Scan() {
 		lineNr++
@@ -62,7 +82,7 @@ func NewBasicAuth(name, user, pass, file string) (*BasicAuth, error) {
 
 		username, password := up[0], up[1]
 
-		if _, ok := ba.htFile[username]; ok {
+		if _, ok := data[username]; ok {
 			return nil, fmt.Errorf("multiple user: %s", username)
 		}
 
@@ -80,14 +100,14 @@ func NewBasicAuth(name, user, pass, file string) (*BasicAuth, error) {
 				return nil, fmt.Errorf("parse error: malformed password for user: %s", username)
 			}
 
-			ba.htFile[username] = pwd{
+			data[username] = pwd{
 				pwdOrig:   []byte(password),
 				pwdPrefix: prefix,
 				pwdSalt:   parts[0],
 				pwdType:   pwdType,
 			}
 		case pwdTypeBcrypt:
-			ba.htFile[username] = pwd{
+			data[username] = pwd{
 				pwdOrig: []byte(password),
 				pwdType: pwdType,
 			}
@@ -100,14 +120,49 @@ func NewBasicAuth(name, user, pass, file string) (*BasicAuth, error) {
 			if pErr != nil {
 				return nil, fmt.Errorf("parse error: malformed password for user: %s: %w", username, pErr)
 			}
-			ba.htFile[username] = p
+			data[username] = p
+		case pwdTypeSHA:
+			p, pErr := parseSHA(password)
+			if pErr != nil {
+				return nil, fmt.Errorf("parse error: malformed password for user: %s: %w", username, pErr)
+			}
+			data[username] = p
+		case pwdTypeSHA256, pwdTypeSHA512:
+			prefix := pwdPrefixSHA256
+			if pwdType == pwdTypeSHA512 {
+				prefix = pwdPrefixSHA512
+			}
+			p, pErr := parseSHACrypt(password, prefix)
+			if pErr != nil {
+				return nil, fmt.Errorf("parse error: malformed password for user: %s: %w", username, pErr)
+			}
+			data[username] = p
+		case pwdTypeScrypt:
+			p, pErr := parseScrypt(password)
+			if pErr != nil {
+				return nil, fmt.Errorf("parse error: malformed password for user: %s: %w", username, pErr)
+			}
+			data[username] = p
 		default:
 			return nil, fmt.Errorf("parse error: algorithm not supported")
 		}
 	}
 
-	err = scanner.Err()
-	return ba, err
+	return data, scanner.Err()
+}
+
+// htData returns the most recently loaded htpasswd file content.
+func (ba *BasicAuth) htData() htData {
+	if ba.htFile == nil {
+		return nil
+	}
+	return ba.htFile.Data().(htData)
+}
+
+// WithLDAP checks credentials not matching the user or htpasswd file against the given LDAP.
+func (ba *BasicAuth) WithLDAP(l *LDAP) *BasicAuth {
+	ba.ldap = l
+	return ba
 }
 
 // Validate implements the AccessControl interface
@@ -125,6 +180,7 @@ func (ba *BasicAuth) Validate(req *http.Request) error {
 		return errors.BasicAuth.Message("reading authorization failed")
 	}
 
+	htFile := ba.htData()
 	if subtle.ConstantTimeCompare([]byte(ba.user), []byte(user)) == 1 {
 		if ba.pass != "" {
 			if subtle.ConstantTimeCompare([]byte(ba.pass), []byte(pass)) == 1 {
@@ -133,16 +189,26 @@ func (ba *BasicAuth) Validate(req *http.Request) error {
 			return errors.BasicAuth.Message("credential mismatch")
 		}
 
-		if len(ba.htFile) == 0 {
+		if len(htFile) == 0 && ba.ldap == nil {
 			return errors.BasicAuth.Message("no password configured")
 		}
 	}
 
-	if len(ba.htFile) > 0 {
-		if validateAccessData(user, pass, ba.htFile) {
+	if len(htFile) > 0 {
+		if validateAccessData(user, pass, htFile) {
 			return ba.withUsername(req, user)
 		}
-		return errors.BasicAuth.Message("file: credential mismatch")
+		if ba.ldap == nil {
+			return errors.BasicAuth.Message("file: credential mismatch")
+		}
+	}
+
+	if ba.ldap != nil {
+		ldapUser, err := ba.ldap.authenticate(user, pass)
+		if err != nil {
+			return err
+		}
+		return ba.withLDAPUser(req, user, ldapUser)
 	}
 
 	return errors.BasicAuth.Message("credential mismatch")
@@ -152,14 +218,38 @@ func (ba *BasicAuth) withUsername(req *http.Request, user string) error {
 	u := make(map[string]interface{})
 	u["user"] = user
 
+	return ba.withContext(req, u, nil)
+}
+
+// withLDAPUser additionally exposes the DN and groups of the user and grants the mapped permissions.
+func (ba *BasicAuth) withLDAPUser(req *http.Request, user string, ldapUser *ldapUser) error {
+	u := make(map[string]interface{})
+	u["user"] = user
+	u["dn"] = ldapUser.dn
+	if ldapUser.groups != nil {
+		u["groups"] = ldapUser.groups
+	}
+
+	return ba.withContext(req, u, ldapUser.permissions)
+}
+
+func (ba *BasicAuth) withContext(req *http.Request, u map[string]interface{}, permissions []string) error {
 	ctx := req.Context()
 	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
 	if !ok {
 		acMap = make(map[string]interface{})
 	}
 	acMap[ba.name] = u
-
 	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+
+	if len(permissions) > 0 {
+		granted, _ := ctx.Value(request.GrantedPermissions).([]string)
+		for _, p := range permissions {
+			granted, _ = addPermission(granted, p)
+		}
+		ctx = context.WithValue(ctx, request.GrantedPermissions, granted)
+	}
+
 	*req = *req.WithContext(ctx)
 
 	return nil
@@ -3,14 +3,19 @@ package accesscontrol
 import (
 	"bytes"
 	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/subtle"
 	"encoding/base64"
 	"fmt"
+	"hash"
 	"strconv"
 	"strings"
 
 	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
 )
 
 const (
@@ -22,6 +27,10 @@ const (
 	pwdPrefixMD5      = "$1$"
 	pwdPrefixArgon2id = "$argon2id$"
 	pwdPrefixArgon2i  = "$argon2i$"
+	pwdPrefixSHA      = "{SHA}"
+	pwdPrefixSHA256   = "$5$"
+	pwdPrefixSHA512   = "$6$"
+	pwdPrefixScrypt   = "$scrypt$"
 )
 
 const (
@@ -31,12 +40,21 @@ const (
 	pwdTypeMD5
 	pwdTypeArgon2id
 	pwdTypeArgon2i
+	pwdTypeSHA
+	pwdTypeSHA256
+	pwdTypeSHA512
+	pwdTypeScrypt
 )
 
 const (
 	aprCharacters    = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
 	aprMd5DigestSize = 16
 	aprMuddleRounds  = 1000
+
+	shaCryptDefaultRounds = 5000
+	shaCryptMinRounds     = 1000
+	shaCryptMaxRounds     = 999999999
+	shaCryptMaxSaltLen    = 16
 )
 
 var pwdPrefixes = map[string]int{
@@ -48,6 +66,10 @@ var pwdPrefixes = map[string]int{
 	pwdPrefixMD5:      pwdTypeMD5,
 	pwdPrefixArgon2id: pwdTypeArgon2id,
 	pwdPrefixArgon2i:  pwdTypeArgon2i,
+	pwdPrefixSHA:      pwdTypeSHA,
+	pwdPrefixSHA256:   pwdTypeSHA256,
+	pwdPrefixSHA512:   pwdTypeSHA512,
+	pwdPrefixScrypt:   pwdTypeScrypt,
 }
 
 type htData map[string]pwd
@@ -62,6 +84,13 @@ type pwd struct {
 	argon2Threads uint8
 	argon2KeyLen  uint32
 	argon2Salt    []byte
+	// SHA-crypt rounds, appended to the prefix if not the default
+	shaCryptRounds       int
+	shaCryptCustomRounds bool
+	scryptN              int
+	scryptR              int
+	scryptP              int
+	scryptSalt           []byte
 }
 
 func getPwdType(pass string) int {
@@ -92,6 +121,19 @@ func validateAccessData(plainUser, plainPass string, data htData) bool {
 				if validateArgon2(plainPass, pass) {
 					return true
 				}
+			case pwdTypeSHA:
+				sum := sha1.Sum([]byte(plainPass))
+				if subtle.ConstantTimeCompare(sum[:], pass.pwdOrig) == 1 {
+					return true
+				}
+			case pwdTypeSHA256, pwdTypeSHA512:
+				if subtle.ConstantTimeCompare(shaCrypt(plainPass, pass), pass.pwdOrig) == 1 {
+					return true
+				}
+			case pwdTypeScrypt:
+				if validateScrypt(plainPass, pass) {
+					return true
+				}
 			}
 		}
 	}
@@ -199,6 +241,258 @@ func parseArgon2(password, prefix string) (pwd, error) {
 	}, nil
 }
 
+func parseSHA(password string) (pwd, error) {
+	sum, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(password, pwdPrefixSHA))
+	if err != nil {
+		return pwd{}, fmt.Errorf("invalid sha encoding: %w", err)
+	}
+	if len(sum) != sha1.Size {
+		return pwd{}, fmt.Errorf("invalid sha length: %d", len(sum))
+	}
+
+	return pwd{
+		pwdOrig:   sum,
+		pwdPrefix: pwdPrefixSHA,
+		pwdType:   pwdTypeSHA,
+	}, nil
+}
+
+func parseSHACrypt(password, prefix string) (pwd, error) {
+	// $5$rounds=<rounds>$<salt>$<hash> with optional rounds parameter
+	parts := strings.Split(strings.TrimPrefix(password, prefix), "$")
+
+	p := pwd{
+		pwdOrig:        []byte(password),
+		pwdPrefix:      prefix,
+		pwdType:        pwdTypeSHA256,
+		shaCryptRounds: shaCryptDefaultRounds,
+	}
+	if prefix == pwdPrefixSHA512 {
+		p.pwdType = pwdTypeSHA512
+	}
+
+	// out of range rounds and too long salts are adjusted like glibc does
+	if len(parts) == 3 && strings.HasPrefix(parts[0], "rounds=") {
+		rounds, err := strconv.ParseUint(strings.TrimPrefix(parts[0], "rounds="), 10, 64)
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			rounds = shaCryptMaxRounds
+		} else if err != nil {
+			return pwd{}, fmt.Errorf("invalid rounds: %s", parts[0])
+		}
+		if rounds < shaCryptMinRounds {
+			rounds = shaCryptMinRounds
+		} else if rounds > shaCryptMaxRounds {
+			rounds = shaCryptMaxRounds
+		}
+		p.shaCryptRounds = int(rounds)
+		p.shaCryptCustomRounds = true
+		parts = parts[1:]
+	}
+
+	if len(parts) != 2 {
+		return pwd{}, fmt.Errorf("expected 2 parts, got %d", len(parts))
+	}
+	p.pwdSalt = parts[0]
+	if len(p.pwdSalt) > shaCryptMaxSaltLen {
+		p.pwdSalt = p.pwdSalt[:shaCryptMaxSaltLen]
+	}
+
+	// compare with the hash as crypt(3) would have written it
+	normalized := prefix
+	if p.shaCryptCustomRounds {
+		normalized += "rounds=" + strconv.Itoa(p.shaCryptRounds) + "$"
+	}
+	p.pwdOrig = []byte(normalized + p.pwdSalt + "$" + parts[1])
+
+	return p, nil
+}
+
+func parseScrypt(password string) (pwd, error) {
+	// $scrypt$ln=<log2 N>,r=<block size>,p=<parallelism>$<base64-salt>$<base64-hash>
+	parts := strings.Split(strings.TrimPrefix(password, pwdPrefixScrypt), "$")
+	if len(parts) != 3 {
+		return pwd{}, fmt.Errorf("expected 3 parts, got %d", len(parts))
+	}
+
+	params := make(map[string]int)
+	for _, kv := range strings.Split(parts[0], ",") {
+		pair := strings.SplitN(kv, "=", 2)
+		if len(pair) != 2 {
+			return pwd{}, fmt.Errorf("invalid scrypt parameter: %s", kv)
+		}
+		v, err := strconv.Atoi(pair[1])
+		if err != nil || v < 1 {
+			return pwd{}, fmt.Errorf("invalid scrypt parameter %s: %s", pair[0], pair[1])
+		}
+		params[pair[0]] = v
+	}
+
+	for _, name := range []string{"ln", "r", "p"} {
+		if _, ok := params[name]; !ok {
+			return pwd{}, fmt.Errorf("missing scrypt parameter: %s", name)
+		}
+	}
+	if params["ln"] > 30 {
+		return pwd{}, fmt.Errorf("invalid scrypt parameter ln: %d", params["ln"])
+	}
+
+	// passlib encodes "+" as "."
+	decode := func(s string) ([]byte, error) {
+		return base64.RawStdEncoding.DecodeString(strings.ReplaceAll(strings.TrimRight(s, "="), ".", "+"))
+	}
+
+	salt, err := decode(parts[1])
+	if err != nil {
+		return pwd{}, fmt.Errorf("invalid scrypt salt encoding: %w", err)
+	}
+
+	key, err := decode(parts[2])
+	if err != nil {
+		return pwd{}, fmt.Errorf("invalid scrypt hash encoding: %w", err)
+	}
+
+	return pwd{
+		pwdOrig:    key,
+		pwdPrefix:  pwdPrefixScrypt,
+		pwdType:    pwdTypeScrypt,
+		scryptN:    1 << params["ln"],
+		scryptR:    params["r"],
+		scryptP:    params["p"],
+		scryptSalt: salt,
+	}, nil
+}
+
+func validateScrypt(plainPass string, p pwd) bool {
+	key, err := scrypt.Key([]byte(plainPass), p.scryptSalt, p.scryptN, p.scryptR, p.scryptP, len(p.pwdOrig))
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(key, p.pwdOrig) == 1
+}
+
+// shaCrypt implements the SHA-256 and SHA-512 based crypt(3) variants,
+// see https://www.akkadia.org/drepper/SHA-crypt.txt.
+func shaCrypt(pass string, p pwd) []byte {
+	newHash, order := sha256.New, shaCrypt256Order
+	if p.pwdType == pwdTypeSHA512 {
+		newHash, order = sha512.New, shaCrypt512Order
+	}
+
+	password, salt := []byte(pass), []byte(p.pwdSalt)
+
+	h := newHash()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(password)
+	b := h.Sum(nil)
+
+	h = newHash()
+	h.Write(password)
+	h.Write(salt)
+	writeRepeated(h, b, len(password))
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 == 1 {
+			h.Write(b)
+		} else {
+			h.Write(password)
+		}
+	}
+	a := h.Sum(nil)
+
+	h = newHash()
+	for range password {
+		h.Write(password)
+	}
+	pSeq := repeated(h.Sum(nil), len(password))
+
+	h = newHash()
+	for i := 0; i < 16+int(a[0]); i++ {
+		h.Write(salt)
+	}
+	sSeq := repeated(h.Sum(nil), len(salt))
+
+	c := a
+	for i := 0; i < p.shaCryptRounds; i++ {
+		h = newHash()
+		if i&1 == 1 {
+			h.Write(pSeq)
+		} else {
+			h.Write(c)
+		}
+		if i%3 != 0 {
+			h.Write(sSeq)
+		}
+		if i%7 != 0 {
+			h.Write(pSeq)
+		}
+		if i&1 == 1 {
+			h.Write(c)
+		} else {
+			h.Write(pSeq)
+		}
+		c = h.Sum(nil)
+	}
+
+	buf := bytes.Buffer{}
+	buf.WriteString(p.pwdPrefix)
+	if p.shaCryptCustomRounds {
+		buf.WriteString("rounds=" + strconv.Itoa(p.shaCryptRounds) + "$")
+	}
+	buf.WriteString(p.pwdSalt)
+	buf.WriteByte('$')
+
+	for i := 0; i+2 < len(order); i += 3 {
+		v := uint(c[order[i]])<<16 | uint(c[order[i+1]])<<8 | uint(c[order[i+2]])
+		for n := 0; n < 4; n++ {
+			buf.WriteByte(aprCharacters[v&0x3f])
+			v >>= 6
+		}
+	}
+
+	// remaining bytes
+	var v uint
+	rest := order[len(order)-len(order)%3:]
+	for _, idx := range rest {
+		v = v<<8 | uint(c[idx])
+	}
+	for n := 0; n <= len(rest); n++ {
+		buf.WriteByte(aprCharacters[v&0x3f])
+		v >>= 6
+	}
+
+	return buf.Bytes()
+}
+
+// byte order of the SHA-crypt encoding, grouped by three (and the remaining bytes)
+var (
+	shaCrypt256Order = []int{
+		0, 10, 20, 21, 1, 11, 12, 22, 2, 3, 13, 23, 24, 4, 14, 15, 25, 5, 6, 16, 26, 27, 7, 17, 18, 28, 8, 9, 19, 29,
+		31, 30,
+	}
+	shaCrypt512Order = []int{
+		0, 21, 42, 22, 43, 1, 44, 2, 23, 3, 24, 45, 25, 46, 4, 47, 5, 26, 6, 27, 48, 28, 49, 7, 50, 8, 29, 9, 30, 51,
+		31, 52, 10, 53, 11, 32, 12, 33, 54, 34, 55, 13, 56, 14, 35, 15, 36, 57, 37, 58, 16, 59, 17, 38, 18, 39, 60,
+		40, 61, 19, 62, 20, 41,
+		63,
+	}
+)
+
+func writeRepeated(h hash.Hash, b []byte, length int) {
+	for ; length > len(b); length -= len(b) {
+		h.Write(b)
+	}
+	h.Write(b[:length])
+}
+
+func repeated(b []byte, length int) []byte {
+	seq := make([]byte, 0, length)
+	for len(seq) < length {
+		seq = append(seq, b[:min(len(b), length-len(seq))]...)
+	}
+	return seq
+}
+
 func apr1MD5(pass, salt, pref string) []byte {
 	var passLen int = len(pass)
 
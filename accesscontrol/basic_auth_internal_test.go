@@ -23,6 +23,70 @@ func Test_Apr1MD5(t *testing.T) {
 	}
 }
 
+func Test_SHACrypt(t *testing.T) {
+	// test vectors of https://www.akkadia.org/drepper/SHA-crypt.txt
+	for _, tc := range []struct {
+		pass, exp string
+	}{
+		{"Hello world!", "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5"},
+		{"Hello world!", "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA"},
+		{"Hello world!", "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+		{"we have a short salt string but not a short password", "$5$rounds=77777$short$JiO1O3ZpDAxGJeaDIuqCoEFysAe1mZNJRs3pw0KQRd/"},
+	} {
+		prefix := tc.exp[:3]
+		p, err := parseSHACrypt(tc.exp, prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res := string(shaCrypt(tc.pass, p)); res != tc.exp {
+			t.Errorf("Got unexpected password: '%s', want '%s'", res, tc.exp)
+		}
+	}
+
+	// rounds are clamped and salts are truncated like crypt(3) does
+	for _, tc := range []struct {
+		pass, password string
+		expRounds      int
+	}{
+		{"the minimum number is still observed", "$5$rounds=10$roundstoolow$yfvwcWrQ8l/K0DAWyuPMDNHpIVlTQebY9l/gL972bIC", shaCryptMinRounds},
+		{"Hello world!", "$5$rounds=10000$saltstringsaltstring$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA", 10000},
+		{"the minimum number is still observed", "$6$rounds=10$roundstoolow$kUMsbe306n21p9R.FRkW3IGn.S9NPN0x50YhH1xhLsPuWGsUSklZt58jaTfF4ZEQpyUNGc0dqbpBYYBaHHrsX.", shaCryptMinRounds},
+		{"Hello world!", "$6$rounds=10000$saltstringsaltstring$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v.", 10000},
+	} {
+		p, err := parseSHACrypt(tc.password, tc.password[:3])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.shaCryptRounds != tc.expRounds {
+			t.Errorf("Expected %d rounds, got %d", tc.expRounds, p.shaCryptRounds)
+		}
+		if len(p.pwdSalt) > shaCryptMaxSaltLen {
+			t.Errorf("Expected salt to be truncated, got: %s", p.pwdSalt)
+		}
+		if res := shaCrypt(tc.pass, p); string(res) != string(p.pwdOrig) {
+			t.Errorf("Got unexpected password: '%s', want '%s'", res, p.pwdOrig)
+		}
+	}
+
+	p, err := parseSHACrypt("$5$rounds=99999999999999999999$salt$hash", "$5$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.shaCryptRounds != shaCryptMaxRounds {
+		t.Errorf("Expected %d rounds, got %d", shaCryptMaxRounds, p.shaCryptRounds)
+	}
+
+	for _, password := range []string{
+		"$5$rounds=ten$salt$yfvwcWrQ8l/K0DAWyuPMDNHpIVlTQebY9l/gL972bIC",
+		"$6$saltstring",
+	} {
+		if _, err := parseSHACrypt(password, password[:3]); err == nil {
+			t.Errorf("Expected parse error for: %s", password)
+		}
+	}
+}
+
 func Test_ValidateAccessData(t *testing.T) {
 	var data htData = make(htData)
 	var pass string = "my-pass"
@@ -0,0 +1,271 @@
+package accesscontrol
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/errors"
+)
+
+const (
+	ldapDefaultGroupFilter        = "(member={dn})"
+	ldapDefaultGroupNameAttribute = "cn"
+	ldapDefaultTimeout            = 5 * time.Second
+)
+
+// LDAP authenticates basic auth credentials by binding to an LDAP server, either with
+// a DN created from the bind_dn template or with the DN of a searched user.
+type LDAP struct {
+	bindDN             string
+	cacheTTL           int64
+	groupBaseDN        string
+	groupFilter        string
+	groupNameAttribute string
+	groupsMap          map[string][]string
+	memStore           *cache.MemoryStore
+	name               string
+	searchBindDN       string
+	searchBindPassword string
+	startTLS           bool
+	timeout            time.Duration
+	tlsConfig          *tls.Config
+	url                string
+	userBaseDN         string
+	userFilter         string
+}
+
+// ldapUser is the result of a successful LDAP authentication.
+type ldapUser struct {
+	dn          string
+	groups      []string
+	permissions []string
+}
+
+// NewLDAP creates a new LDAP authenticator for the basic_auth block with the given name.
+func NewLDAP(name string, conf *config.BasicAuthLDAP, tlsConfig *tls.Config, memStore *cache.MemoryStore) (*LDAP, error) {
+	u, err := url.Parse(conf.URL)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: url: %w", err)
+	}
+	if u.Scheme != "ldap" && u.Scheme != "ldaps" {
+		return nil, fmt.Errorf("ldap: url: unsupported scheme: %q", u.Scheme)
+	}
+	if conf.StartTLS && u.Scheme == "ldaps" {
+		return nil, fmt.Errorf("ldap: start_tls requires an ldap:// url")
+	}
+
+	if conf.BindDN == "" && conf.UserFilter == "" {
+		return nil, fmt.Errorf("ldap: missing bind_dn or user_filter")
+	}
+	if conf.BindDN != "" && conf.UserFilter != "" {
+		return nil, fmt.Errorf("ldap: bind_dn and user_filter are mutually exclusive")
+	}
+	if conf.UserFilter != "" && conf.UserBaseDN == "" {
+		return nil, fmt.Errorf("ldap: user_filter requires user_base_dn")
+	}
+	if conf.GroupsMap != nil && conf.GroupBaseDN == "" {
+		return nil, fmt.Errorf("ldap: groups_map requires group_base_dn")
+	}
+
+	timeout, err := config.ParseDuration("timeout", conf.Timeout, ldapDefaultTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: %w", err)
+	}
+	cacheTTL, err := config.ParseDuration("cache_ttl", conf.CacheTTL, 0)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: %w", err)
+	}
+
+	l := &LDAP{
+		bindDN:             conf.BindDN,
+		cacheTTL:           int64(cacheTTL / time.Second),
+		groupBaseDN:        conf.GroupBaseDN,
+		groupFilter:        conf.GroupFilter,
+		groupNameAttribute: conf.GroupNameAttribute,
+		groupsMap:          conf.GroupsMap,
+		memStore:           memStore,
+		name:               name,
+		searchBindDN:       conf.SearchBindDN,
+		searchBindPassword: conf.SearchBindPassword,
+		startTLS:           conf.StartTLS,
+		timeout:            timeout,
+		url:                conf.URL,
+		userBaseDN:         conf.UserBaseDN,
+		userFilter:         conf.UserFilter,
+	}
+
+	if l.groupFilter == "" {
+		l.groupFilter = ldapDefaultGroupFilter
+	}
+	if l.groupNameAttribute == "" {
+		l.groupNameAttribute = ldapDefaultGroupNameAttribute
+	}
+
+	if tlsConfig != nil {
+		l.tlsConfig = tlsConfig.Clone()
+		// StartTLS does not derive the server name from the dialed address
+		if l.tlsConfig.ServerName == "" {
+			l.tlsConfig.ServerName = u.Hostname()
+		}
+	}
+
+	return l, nil
+}
+
+// authenticate binds with the given credentials and looks up the group memberships.
+// Successful authentications are cached for cache_ttl. Only credential mismatches
+// result in basic_auth errors, directory failures are backend errors.
+func (l *LDAP) authenticate(user, pass string) (*ldapUser, error) {
+	// an empty password would result in an unauthenticated bind which succeeds
+	if pass == "" {
+		return nil, errors.BasicAuth.Message("ldap: credential mismatch")
+	}
+
+	storageKey := l.storageKey(user, pass)
+	if l.cacheTTL > 0 && l.memStore != nil {
+		if cached, ok := l.memStore.Get(storageKey).(*ldapUser); ok {
+			return cached, nil
+		}
+	}
+
+	conn, err := l.dial()
+	if err != nil {
+		return nil, errors.Backend.Message("ldap").With(err)
+	}
+	defer conn.Close()
+
+	dn, err := l.userDN(conn, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = conn.Bind(dn, pass); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, errors.BasicAuth.Message("ldap: credential mismatch")
+		}
+		return nil, errors.Backend.Message("ldap: bind").With(err)
+	}
+
+	result := &ldapUser{dn: dn}
+	if l.groupBaseDN != "" {
+		if result.groups, err = l.groups(conn, user, dn); err != nil {
+			return nil, err
+		}
+		result.permissions = l.mapGroups(result.groups)
+	}
+
+	if l.cacheTTL > 0 && l.memStore != nil {
+		l.memStore.Set(storageKey, result, l.cacheTTL)
+	}
+
+	return result, nil
+}
+
+func (l *LDAP) dial() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(l.url,
+		ldap.DialWithDialer(&net.Dialer{Timeout: l.timeout}),
+		ldap.DialWithTLSConfig(l.tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(l.timeout)
+
+	if l.startTLS {
+		if err = conn.StartTLS(l.tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// userDN returns the DN of the given user, either from the bind_dn template
+// or by a search for exactly one entry matching the user_filter.
+func (l *LDAP) userDN(conn *ldap.Conn, user string) (string, error) {
+	if l.bindDN != "" {
+		return strings.ReplaceAll(l.bindDN, "{user}", ldap.EscapeDN(user)), nil
+	}
+
+	if err := l.searchBind(conn); err != nil {
+		return "", err
+	}
+
+	filter := strings.ReplaceAll(l.userFilter, "{user}", ldap.EscapeFilter(user))
+	res, err := conn.Search(ldap.NewSearchRequest(l.userBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(l.timeout/time.Second), false, filter, []string{"dn"}, nil))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return "", errors.Backend.Message("ldap: user search").With(err)
+	}
+	if res == nil || len(res.Entries) != 1 {
+		return "", errors.BasicAuth.Message("ldap: credential mismatch")
+	}
+
+	return res.Entries[0].DN, nil
+}
+
+// groups returns the names of the groups matching the group_filter. The search
+// runs with the search_bind_dn, if configured, or with the bound user otherwise.
+func (l *LDAP) groups(conn *ldap.Conn, user, dn string) ([]string, error) {
+	if l.searchBindDN != "" {
+		if err := l.searchBind(conn); err != nil {
+			return nil, err
+		}
+	}
+
+	filter := strings.NewReplacer("{user}", ldap.EscapeFilter(user), "{dn}", ldap.EscapeFilter(dn)).Replace(l.groupFilter)
+	res, err := conn.Search(ldap.NewSearchRequest(l.groupBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, int(l.timeout/time.Second), false, filter, []string{l.groupNameAttribute}, nil))
+	if err != nil {
+		return nil, errors.Backend.Message("ldap: group search").With(err)
+	}
+
+	groups := make([]string, 0, len(res.Entries))
+	for _, entry := range res.Entries {
+		if name := entry.GetAttributeValue(l.groupNameAttribute); name != "" {
+			groups = append(groups, name)
+		}
+	}
+	return groups, nil
+}
+
+// searchBind binds with the search_bind_dn, searches are anonymous otherwise.
+func (l *LDAP) searchBind(conn *ldap.Conn) error {
+	if l.searchBindDN == "" {
+		return nil
+	}
+
+	if err := conn.Bind(l.searchBindDN, l.searchBindPassword); err != nil {
+		return errors.Backend.Message("ldap: search bind").With(err)
+	}
+	return nil
+}
+
+func (l *LDAP) mapGroups(groups []string) []string {
+	var permissions []string
+	for _, group := range groups {
+		for _, p := range l.groupsMap[group] {
+			permissions, _ = addPermission(permissions, p)
+		}
+	}
+
+	for _, p := range l.groupsMap["*"] {
+		permissions, _ = addPermission(permissions, p)
+	}
+	return permissions
+}
+
+func (l *LDAP) storageKey(user, pass string) string {
+	sum := sha256.Sum256([]byte(user + "\x00" + pass))
+	return "basic_auth_ldap_" + l.name + "_" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,253 @@
+package accesscontrol_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	couperErr "github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/internal/test"
+)
+
+var ldapEntries = []test.LDAPEntry{
+	{DN: "cn=couper,ou=services,dc=example,dc=com", Password: "service-secret"},
+	{
+		DN:         "uid=clark,ou=people,dc=example,dc=com",
+		Password:   "kryptonite",
+		Attributes: map[string][]string{"uid": {"clark"}, "objectClass": {"person"}},
+	},
+	{
+		DN:         "uid=lois,ou=people,dc=example,dc=com",
+		Password:   "daily-planet",
+		Attributes: map[string][]string{"uid": {"lois"}, "objectClass": {"person"}},
+	},
+	{
+		DN: "cn=editors,ou=groups,dc=example,dc=com",
+		Attributes: map[string][]string{"cn": {"editors"}, "member": {
+			"uid=clark,ou=people,dc=example,dc=com",
+			"uid=lois,ou=people,dc=example,dc=com",
+		}},
+	},
+	{
+		DN:         "cn=admins,ou=groups,dc=example,dc=com",
+		Attributes: map[string][]string{"cn": {"admins"}, "member": {"uid=lois,ou=people,dc=example,dc=com"}},
+	},
+}
+
+func newLDAPBasicAuth(t *testing.T, conf *config.BasicAuthLDAP, tlsConfig *tls.Config, memStore *cache.MemoryStore) *ac.BasicAuth {
+	t.Helper()
+
+	ldap, err := ac.NewLDAP("ldap_auth", conf, tlsConfig, memStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ba, err := ac.NewBasicAuth(context.Background(), "ldap_auth", "", "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ba.WithLDAP(ldap)
+}
+
+func newLDAPRequest(user, pass string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth(user, pass)
+	return req
+}
+
+func Test_BasicAuth_LDAP(t *testing.T) {
+	srv := test.NewLDAPServer(ldapEntries, nil)
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name string
+		conf *config.BasicAuthLDAP
+	}{
+		{"bind dn", &config.BasicAuthLDAP{
+			URL:    srv.URL,
+			BindDN: "uid={user},ou=people,dc=example,dc=com",
+		}},
+		{"search and bind", &config.BasicAuthLDAP{
+			URL:                srv.URL,
+			SearchBindDN:       "cn=couper,ou=services,dc=example,dc=com",
+			SearchBindPassword: "service-secret",
+			UserBaseDN:         "ou=people,dc=example,dc=com",
+			UserFilter:         "(&(objectClass=person)(uid={user}))",
+		}},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			tc.conf.GroupBaseDN = "ou=groups,dc=example,dc=com"
+			tc.conf.GroupsMap = map[string][]string{
+				"admins":  {"orders:write"},
+				"editors": {"orders:read"},
+			}
+			ba := newLDAPBasicAuth(st, tc.conf, nil, nil)
+
+			for _, c := range []struct {
+				user, pass     string
+				expGroups      []string
+				expPermissions []string
+			}{
+				{"clark", "kryptonite", []string{"editors"}, []string{"orders:read"}},
+				{"lois", "daily-planet", []string{"editors", "admins"}, []string{"orders:read", "orders:write"}},
+			} {
+				req := newLDAPRequest(c.user, c.pass)
+				if err := ba.Validate(req); err != nil {
+					st.Fatalf("%s: expected no error, got: %v", c.user, err)
+				}
+
+				acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+				data, _ := acMap["ldap_auth"].(map[string]interface{})
+				if expDN := "uid=" + c.user + ",ou=people,dc=example,dc=com"; data["user"] != c.user || data["dn"] != expDN {
+					st.Errorf("%s: unexpected context: %v", c.user, data)
+				}
+				if !reflect.DeepEqual(data["groups"], c.expGroups) {
+					st.Errorf("%s: expected groups %v, got: %v", c.user, c.expGroups, data["groups"])
+				}
+
+				granted, _ := req.Context().Value(request.GrantedPermissions).([]string)
+				if !reflect.DeepEqual(granted, c.expPermissions) {
+					st.Errorf("%s: expected permissions %v, got: %v", c.user, c.expPermissions, granted)
+				}
+			}
+
+			for _, c := range []struct{ user, pass string }{
+				{"clark", "wrong"},
+				{"clark", ""},
+				{"bruce", "kryptonite"},
+				{"*", "kryptonite"},
+			} {
+				err := ba.Validate(newLDAPRequest(c.user, c.pass))
+				if !couperErr.Equals(err, couperErr.BasicAuth) {
+					st.Errorf("%s:%s: expected basic_auth error, got: %v", c.user, c.pass, err)
+				}
+			}
+		})
+	}
+}
+
+func Test_BasicAuth_LDAP_BackendErrors(t *testing.T) {
+	srv := test.NewLDAPServer(ldapEntries, nil)
+	unreachable := test.NewLDAPServer(nil, nil)
+	unreachable.Close()
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name string
+		conf *config.BasicAuthLDAP
+	}{
+		{"unreachable", &config.BasicAuthLDAP{
+			URL:    unreachable.URL,
+			BindDN: "uid={user},ou=people,dc=example,dc=com",
+		}},
+		{"search bind", &config.BasicAuthLDAP{
+			URL:                srv.URL,
+			SearchBindDN:       "cn=couper,ou=services,dc=example,dc=com",
+			SearchBindPassword: "wrong",
+			UserBaseDN:         "ou=people,dc=example,dc=com",
+			UserFilter:         "(uid={user})",
+		}},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			ba := newLDAPBasicAuth(st, tc.conf, nil, nil)
+			err := ba.Validate(newLDAPRequest("clark", "kryptonite"))
+			if !couperErr.Equals(err, couperErr.Backend) {
+				st.Errorf("expected backend error, got: %v", err)
+			}
+			if cErr, ok := err.(*couperErr.Error); !ok || cErr.HTTPStatus() != http.StatusBadGateway {
+				st.Errorf("expected status 502, got: %v", err)
+			}
+		})
+	}
+}
+
+func Test_BasicAuth_LDAP_Cache(t *testing.T) {
+	srv := test.NewLDAPServer(ldapEntries, nil)
+	defer srv.Close()
+
+	memStore := cache.New(nil, context.Background().Done())
+	ba := newLDAPBasicAuth(t, &config.BasicAuthLDAP{
+		URL:      srv.URL,
+		BindDN:   "uid={user},ou=people,dc=example,dc=com",
+		CacheTTL: "1m",
+	}, nil, memStore)
+
+	for i := 0; i < 3; i++ {
+		if err := ba.Validate(newLDAPRequest("clark", "kryptonite")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if binds := srv.Binds(); binds != 1 {
+		t.Errorf("expected one bind for cached authentications, got: %d", binds)
+	}
+
+	// failed authentications are not cached and a changed password must not match the cache
+	for i := 0; i < 2; i++ {
+		if err := ba.Validate(newLDAPRequest("clark", "wrong")); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+	if binds := srv.Binds(); binds != 3 {
+		t.Errorf("expected three binds, got: %d", binds)
+	}
+}
+
+func Test_BasicAuth_LDAP_StartTLS(t *testing.T) {
+	tlsSrv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer tlsSrv.Close()
+
+	srv := test.NewLDAPServer(ldapEntries, tlsSrv.TLS)
+	defer srv.Close()
+
+	conf := &config.BasicAuthLDAP{
+		URL:      srv.URL,
+		BindDN:   "uid={user},ou=people,dc=example,dc=com",
+		StartTLS: true,
+	}
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(tlsSrv.Certificate())
+
+	ba := newLDAPBasicAuth(t, conf, &tls.Config{RootCAs: rootCAs}, nil)
+	if err := ba.Validate(newLDAPRequest("clark", "kryptonite")); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+
+	ba = newLDAPBasicAuth(t, conf, &tls.Config{RootCAs: x509.NewCertPool()}, nil)
+	err := ba.Validate(newLDAPRequest("clark", "kryptonite"))
+	if cErr, ok := err.(*couperErr.Error); !ok || !strings.Contains(cErr.LogError(), "certificate") {
+		t.Errorf("expected certificate error, got: %v", err)
+	}
+}
+
+func Test_NewLDAP(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		conf      *config.BasicAuthLDAP
+		expErrMsg string
+	}{
+		{"scheme", &config.BasicAuthLDAP{URL: "http://ldap", BindDN: "uid={user}"}, `ldap: url: unsupported scheme: "http"`},
+		{"start_tls with ldaps", &config.BasicAuthLDAP{URL: "ldaps://ldap", BindDN: "uid={user}", StartTLS: true}, "ldap: start_tls requires an ldap:// url"},
+		{"missing dn", &config.BasicAuthLDAP{URL: "ldap://ldap"}, "ldap: missing bind_dn or user_filter"},
+		{"both", &config.BasicAuthLDAP{URL: "ldap://ldap", BindDN: "uid={user}", UserFilter: "(uid={user})", UserBaseDN: "dc=example"}, "ldap: bind_dn and user_filter are mutually exclusive"},
+		{"missing user_base_dn", &config.BasicAuthLDAP{URL: "ldap://ldap", UserFilter: "(uid={user})"}, "ldap: user_filter requires user_base_dn"},
+		{"missing group_base_dn", &config.BasicAuthLDAP{URL: "ldap://ldap", BindDN: "uid={user}", GroupsMap: map[string][]string{}}, "ldap: groups_map requires group_base_dn"},
+		{"timeout", &config.BasicAuthLDAP{URL: "ldap://ldap", BindDN: "uid={user}", Timeout: "1"}, `ldap: timeout: time: missing unit in duration "1"`},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := ac.NewLDAP("test", tc.conf, nil, nil)
+			if err == nil || err.Error() != tc.expErrMsg {
+				st.Errorf("expected error %q, got: %v", tc.expErrMsg, err)
+			}
+		})
+	}
+}
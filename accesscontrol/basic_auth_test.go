@@ -1,13 +1,18 @@
 package accesscontrol_test
 
 import (
+	"context"
 	b64 "encoding/base64"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	ac "github.com/coupergateway/couper/accesscontrol"
 	couperErr "github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/resource"
 )
 
 func Test_NewBasicAuth(t *testing.T) {
@@ -40,7 +45,7 @@ func Test_NewBasicAuth(t *testing.T) {
 		{"name", "user", "pass", "testdata/htpasswd_err_multi", `multiple user: foo`, true},
 		{"name", "user", "pass", "testdata/htpasswd_err_unsupported", "parse error: algorithm not supported", true},
 	} {
-		ba, err = ac.NewBasicAuth(tc.name, tc.user, tc.pass, tc.file)
+		ba, err = ac.NewBasicAuth(context.Background(), tc.name, tc.user, tc.pass, tc.file, nil)
 		if tc.shouldFail && ba != nil {
 			t.Error("Expected no successful basic auth creation")
 		}
@@ -56,7 +61,7 @@ func Test_NewBasicAuth(t *testing.T) {
 }
 
 func Test_BasicAuth_Validate(t *testing.T) {
-	ba, err := ac.NewBasicAuth("name", "user", "pass", "testdata/htpasswd")
+	ba, err := ac.NewBasicAuth(context.Background(), "name", "user", "pass", "testdata/htpasswd", nil)
 	if err != nil || ba == nil {
 		t.Fatal("Expected a basic auth object")
 	}
@@ -93,22 +98,105 @@ func Test_BasicAuth_Validate(t *testing.T) {
 	}
 }
 
+func Test_BasicAuth_HashFormats(t *testing.T) {
+	ba, err := ac.NewBasicAuth(context.Background(), "name", "", "", "testdata/htpasswd", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, user := range []string{"jeff", "joe", "jay", "jean"} {
+		t.Run(user, func(subT *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.SetBasicAuth(user, "my-pass")
+			if err = ba.Validate(req); err != nil {
+				subT.Errorf("Expected no error, got: %v", err)
+			}
+
+			req.SetBasicAuth(user, "my-bass")
+			if err = ba.Validate(req); !couperErr.Equals(err, couperErr.BasicAuth) {
+				subT.Errorf("Expected basic_auth error, got: %v", err)
+			}
+		})
+	}
+}
+
+func Test_BasicAuth_FileReload(t *testing.T) {
+	interval := resource.WatchInterval
+	resource.WatchInterval = time.Millisecond * 50
+	defer func() { resource.WatchInterval = interval }()
+
+	file := filepath.Join(t.TempDir(), "htpasswd")
+	if err := os.WriteFile(file, []byte("jeff:{SHA}FcrlzJC8GZi2VJ8fE+4LjKSPmvc=\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ba, err := ac.NewBasicAuth(ctx, "name", "", "", file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validate := func(user string) error {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(user, "my-pass")
+		return ba.Validate(req)
+	}
+
+	if err = validate("jeff"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// replace jeff by joe
+	content := "joe:$5$roundstest$s1hm2044CPnOeezgTprLlUiIJjKDarGxBBRVgGwf1PB\n"
+	if err = os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chtimes(file, time.Now(), time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second * 2)
+	for validate("joe") != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected reloaded htpasswd file")
+		}
+		time.Sleep(time.Millisecond * 20)
+	}
+	if err = validate("jeff"); err == nil {
+		t.Error("Expected removed user to be rejected")
+	}
+
+	// an invalid file keeps the previous users
+	if err = os.WriteFile(file, []byte("joe:$unsupported$\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chtimes(file, time.Now(), time.Now().Add(time.Second*2)); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 200)
+	if err = validate("joe"); err != nil {
+		t.Errorf("Expected previous htpasswd content, got: %v", err)
+	}
+}
+
 func Test_BasicAuth_ValidateCases(t *testing.T) {
 	req := &http.Request{Header: make(http.Header)}
 
-	ba1, err := ac.NewBasicAuth("name", "", "pass", "")
+	ba1, err := ac.NewBasicAuth(context.Background(), "name", "", "pass", "", nil)
 	if err != nil || ba1 == nil {
 		t.Fatal("Expected a basic auth object")
 	}
-	ba2, err := ac.NewBasicAuth("name", "user", "", "")
+	ba2, err := ac.NewBasicAuth(context.Background(), "name", "user", "", "", nil)
 	if err != nil || ba2 == nil {
 		t.Fatal("Expected a basic auth object")
 	}
-	ba3, err := ac.NewBasicAuth("name", "", "", "")
+	ba3, err := ac.NewBasicAuth(context.Background(), "name", "", "", "", nil)
 	if err != nil || ba3 == nil {
 		t.Fatal("Expected a basic auth object")
 	}
-	ba4, err := ac.NewBasicAuth("name", "", "", "testdata/htpasswd")
+	ba4, err := ac.NewBasicAuth(context.Background(), "name", "", "", "testdata/htpasswd", nil)
 	if err != nil || ba4 == nil {
 		t.Fatal("Expected a basic auth object")
 	}
@@ -0,0 +1,196 @@
+package accesscontrol
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+var _ AccessControl = &ClientCertificate{}
+
+// ClientCertificate authorizes a request based on the content of the client certificate
+// presented in the TLS handshake. The certificate verification itself is done by the
+// server's tls client_certificate configuration.
+type ClientCertificate struct {
+	dnsNames     []string
+	fingerprints []string
+	issuers      []string
+	name         string
+	rolesMap     map[string][]string
+	subjects     []string
+	uris         []string
+}
+
+// NewClientCertificate creates a new AC-ClientCertificate object
+func NewClientCertificate(conf *config.ClientCertificateAC) (*ClientCertificate, error) {
+	c := &ClientCertificate{
+		dnsNames: conf.DNSNames,
+		issuers:  conf.Issuers,
+		name:     conf.Name,
+		rolesMap: conf.RolesMap,
+		subjects: conf.Subjects,
+		uris:     conf.URIs,
+	}
+
+	for _, fp := range conf.Fingerprints {
+		normalized := normalizeFingerprint(fp)
+		if len(normalized) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid SHA-256 fingerprint: %q", fp)
+		}
+		if _, err := hex.DecodeString(normalized); err != nil {
+			return nil, fmt.Errorf("invalid SHA-256 fingerprint: %q", fp)
+		}
+		c.fingerprints = append(c.fingerprints, normalized)
+	}
+
+	for _, uri := range c.uris {
+		if idx := strings.Index(uri, "*"); idx != -1 && idx != len(uri)-1 {
+			return nil, fmt.Errorf("invalid uri pattern %q: wildcard is only allowed as last character", uri)
+		}
+	}
+
+	return c, nil
+}
+
+// Validate implements the AccessControl interface
+func (c *ClientCertificate) Validate(req *http.Request) error {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return errors.ClientCertificateMissing.Message("client certificate required")
+	}
+
+	cert := req.TLS.PeerCertificates[0]
+	sum := sha256.Sum256(cert.Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	var uris []string
+	for _, u := range cert.URIs {
+		uris = append(uris, u.String())
+	}
+
+	if len(c.subjects) > 0 && !containsAny(c.subjects, cert.Subject.String()) {
+		return errors.ClientCertificate.Messagef("subject not accepted: %s", cert.Subject.String())
+	}
+	if len(c.issuers) > 0 && !containsAny(c.issuers, cert.Issuer.String()) {
+		return errors.ClientCertificate.Messagef("issuer not accepted: %s", cert.Issuer.String())
+	}
+	if len(c.dnsNames) > 0 && !containsAny(c.dnsNames, cert.DNSNames...) {
+		return errors.ClientCertificate.Message("dns names not accepted")
+	}
+	if len(c.uris) > 0 && !matchesURI(c.uris, uris) {
+		return errors.ClientCertificate.Message("uris not accepted")
+	}
+	if len(c.fingerprints) > 0 && !containsAny(c.fingerprints, fingerprint) {
+		return errors.ClientCertificate.Messagef("fingerprint not accepted: %s", fingerprint)
+	}
+
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	acMap[c.name] = newClientCertificateContext(cert, fingerprint, uris)
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+
+	granted, _ := ctx.Value(request.GrantedPermissions).([]string)
+	granted = c.addPermissionsFromRoles(cert, fingerprint, uris, granted)
+	ctx = context.WithValue(ctx, request.GrantedPermissions, granted)
+
+	*req = *req.WithContext(ctx)
+
+	return nil
+}
+
+// addPermissionsFromRoles treats each identity of the certificate as a role.
+func (c *ClientCertificate) addPermissionsFromRoles(cert *x509.Certificate, fingerprint string, uris, permissions []string) []string {
+	if c.rolesMap == nil {
+		return permissions
+	}
+
+	identities := []string{cert.Subject.String(), fingerprint}
+	identities = append(identities, cert.DNSNames...)
+	identities = append(identities, cert.EmailAddresses...)
+	identities = append(identities, uris...)
+
+	for _, identity := range identities {
+		if perms, exist := c.rolesMap[identity]; exist {
+			for _, p := range perms {
+				permissions, _ = addPermission(permissions, p)
+			}
+		}
+	}
+
+	if perms, exist := c.rolesMap["*"]; exist {
+		for _, p := range perms {
+			permissions, _ = addPermission(permissions, p)
+		}
+	}
+	return permissions
+}
+
+func newClientCertificateContext(cert *x509.Certificate, fingerprint string, uris []string) map[string]interface{} {
+	certCtx := map[string]interface{}{
+		"dns_names":          cert.DNSNames,
+		"email_addresses":    cert.EmailAddresses,
+		"fingerprint_sha256": fingerprint,
+		"issuer":             cert.Issuer.String(),
+		"not_after":          cert.NotAfter.Unix(),
+		"not_before":         cert.NotBefore.Unix(),
+		"subject":            cert.Subject.String(),
+		"uris":               uris,
+	}
+
+	if cert.SerialNumber != nil {
+		certCtx["serial_number"] = cert.SerialNumber.Text(16)
+	}
+
+	var ips []string
+	for _, ip := range cert.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+	certCtx["ip_addresses"] = ips
+
+	for _, uri := range uris {
+		if strings.HasPrefix(uri, "spiffe://") {
+			certCtx["spiffe_id"] = uri
+			break
+		}
+	}
+
+	return certCtx
+}
+
+func containsAny(accepted []string, values ...string) bool {
+	for _, v := range values {
+		for _, a := range accepted {
+			if a == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesURI(patterns, uris []string) bool {
+	for _, uri := range uris {
+		for _, p := range patterns {
+			if prefix, wildcard := strings.CutSuffix(p, "*"); wildcard && strings.HasPrefix(uri, prefix) {
+				return true
+			} else if p == uri {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func normalizeFingerprint(fp string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fp), ":", ""))
+}
@@ -0,0 +1,145 @@
+package accesscontrol_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	couperErr "github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func newClientCertificate(t *testing.T) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spiffeID, _ := url.Parse("spiffe://acme.org/ns/prod/sa/svc-a")
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "svc-a", Organization: []string{"Acme"}},
+		Issuer:       pkix.Name{CommonName: "svc-a", Organization: []string{"Acme"}},
+		DNSNames:     []string{"svc-a.acme.org"},
+		URIs:         []*url.URL{spiffeID},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func Test_ClientCertificate_Validate(t *testing.T) {
+	cert := newClientCertificate(t)
+	sum := sha256.Sum256(cert.Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	for _, tc := range []struct {
+		name       string
+		conf       *config.ClientCertificateAC
+		noCert     bool
+		expErr     *couperErr.Error
+		expGranted []string
+	}{
+		{"any certificate", &config.ClientCertificateAC{}, false, nil, nil},
+		{"no certificate", &config.ClientCertificateAC{}, true, couperErr.ClientCertificateMissing, nil},
+		{"subject", &config.ClientCertificateAC{Subjects: []string{"CN=svc-a,O=Acme"}}, false, nil, nil},
+		{"subject mismatch", &config.ClientCertificateAC{Subjects: []string{"CN=svc-b,O=Acme"}}, false, couperErr.ClientCertificate, nil},
+		{"issuer", &config.ClientCertificateAC{Issuers: []string{"CN=other", "CN=svc-a,O=Acme"}}, false, nil, nil},
+		{"dns name mismatch", &config.ClientCertificateAC{DNSNames: []string{"svc-b.acme.org"}}, false, couperErr.ClientCertificate, nil},
+		{"spiffe id prefix", &config.ClientCertificateAC{URIs: []string{"spiffe://acme.org/ns/prod/*"}}, false, nil, nil},
+		{"spiffe id mismatch", &config.ClientCertificateAC{URIs: []string{"spiffe://acme.org/ns/dev/*"}}, false, couperErr.ClientCertificate, nil},
+		{"fingerprint", &config.ClientCertificateAC{Fingerprints: []string{fingerprint}}, false, nil, nil},
+		{"combined mismatch", &config.ClientCertificateAC{
+			Subjects: []string{"CN=svc-a,O=Acme"},
+			DNSNames: []string{"svc-b.acme.org"},
+		}, false, couperErr.ClientCertificate, nil},
+		{"roles map", &config.ClientCertificateAC{RolesMap: map[string][]string{
+			"spiffe://acme.org/ns/prod/sa/svc-a": {"orders:read"},
+			"CN=svc-a,O=Acme":                    {"orders:write", "orders:read"},
+			"CN=svc-b,O=Acme":                    {"admin"},
+			"*":                                  {"health"},
+		}}, false, nil, []string{"orders:write", "orders:read", "health"}},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			tc.conf.Name = "mtls"
+			clientCert, err := ac.NewClientCertificate(tc.conf)
+			h.Must(err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.TLS = &tls.ConnectionState{}
+			if !tc.noCert {
+				req.TLS.PeerCertificates = []*x509.Certificate{cert}
+			}
+
+			err = clientCert.Validate(req)
+			if tc.expErr != nil {
+				if !couperErr.Equals(err, tc.expErr) {
+					st.Errorf("expected error %v, got: %v", tc.expErr, err)
+				}
+				return
+			}
+			h.Must(err)
+
+			acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+			certCtx, _ := acMap["mtls"].(map[string]interface{})
+			if certCtx["subject"] != "CN=svc-a,O=Acme" || certCtx["fingerprint_sha256"] != fingerprint ||
+				certCtx["spiffe_id"] != "spiffe://acme.org/ns/prod/sa/svc-a" || certCtx["serial_number"] != "2a" {
+				st.Errorf("unexpected certificate context: %#v", certCtx)
+			}
+
+			granted, _ := req.Context().Value(request.GrantedPermissions).([]string)
+			if !reflect.DeepEqual(granted, tc.expGranted) {
+				st.Errorf("expected granted permissions %v, got: %v", tc.expGranted, granted)
+			}
+		})
+	}
+}
+
+func Test_NewClientCertificate(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		conf      *config.ClientCertificateAC
+		expErrMsg string
+	}{
+		{"colon fingerprint", &config.ClientCertificateAC{Fingerprints: []string{"AB:" + hex.EncodeToString(make([]byte, 31))}}, ""},
+		{"short fingerprint", &config.ClientCertificateAC{Fingerprints: []string{"abcd"}}, `invalid SHA-256 fingerprint: "abcd"`},
+		{"inner wildcard", &config.ClientCertificateAC{URIs: []string{"spiffe://*/svc"}}, `invalid uri pattern "spiffe://*/svc": wildcard is only allowed as last character`},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := ac.NewClientCertificate(tc.conf)
+			if tc.expErrMsg == "" && err != nil {
+				st.Errorf("unexpected error: %v", err)
+			} else if tc.expErrMsg != "" && (err == nil || err.Error() != tc.expErrMsg) {
+				st.Errorf("expected error %q, got: %v", tc.expErrMsg, err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package accesscontrol
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rsa"
 	"crypto/sha256"
@@ -165,9 +166,8 @@ func newDPoPTokenSource() TokenSource {
 	// 5. the alg JOSE header parameter indicates a registered asymmetric
 	//    digital signature algorithm [IANA.JOSE.ALGS], is not none, is
 	//    supported by the application, and is acceptable per local policy
-	algorithms := append(acjwt.RSAAlgorithms, acjwt.ECDSAlgorithms...)
 	var algos []string
-	for _, a := range algorithms {
+	for _, a := range acjwt.AsymmetricAlgorithms() {
 		algos = append(algos, a.String())
 	}
 	parserConfig := parserConfig{
@@ -283,6 +283,8 @@ func getJwkAndPubKey(proof *jwt.Token) (interface{}, error) {
 		pubKey, err = getRSAPubKey(jwk)
 	case "ECDSA":
 		pubKey, err = getECDSAPubKey(jwk)
+	case "OKP":
+		pubKey, err = getEdDSAPubKey(jwk)
 	default:
 		// unsupported algorithms are already handled by JWT parser
 	}
@@ -369,6 +371,32 @@ func getECDSAPubKey(jwk map[string]interface{}) (*ecdsa.PublicKey, error) {
 	}, nil
 }
 
+func getEdDSAPubKey(jwk map[string]interface{}) (ed25519.PublicKey, error) {
+	if crv, ok := jwk["crv"].(string); !ok || crv != "Ed25519" {
+		return nil, fmt.Errorf("jwk JOSE header parameter missing crv property, wrong type or unsupported curve")
+	}
+
+	x, err := getX(jwk)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(x) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("jwk JOSE header parameter has invalid x property")
+	}
+
+	// remove non-required members
+	for k := range jwk {
+		switch k {
+		case "kty", "crv", "x":
+		default:
+			delete(jwk, k)
+		}
+	}
+
+	return ed25519.PublicKey(x), nil
+}
+
 func getCurve(jwk map[string]interface{}) (elliptic.Curve, error) {
 	crv, ok := jwk["crv"].(string)
 	if !ok {
@@ -506,6 +534,11 @@ var requiredMembers = map[string]map[string]struct{}{
 		"x":   {},
 		"y":   {},
 	},
+	"OKP": {
+		"kty": {},
+		"crv": {},
+		"x":   {},
+	},
 }
 
 func JwkToString(jwk map[string]interface{}) string {
@@ -0,0 +1,91 @@
+package accesscontrol
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/coupergateway/couper/accesscontrol/httpsig"
+	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+var _ AccessControl = &HTTPMessageSignature{}
+
+var defaultRequiredComponents = []string{"@method", "@authority", "@path"}
+
+// HTTPMessageSignature verifies HTTP message signatures (RFC 9421) of client requests.
+type HTTPMessageSignature struct {
+	name     string
+	verifier *httpsig.Verifier
+}
+
+// NewHTTPMessageSignature creates a new AC-HTTPMessageSignature object
+func NewHTTPMessageSignature(conf *config.HTTPMessageSignatureAC, key []byte) (*HTTPMessageSignature, error) {
+	algorithm := acjwt.NewAlgorithm(conf.SignatureAlgorithm)
+	if algorithm == acjwt.AlgorithmUnknown {
+		return nil, fmt.Errorf("algorithm %q is not supported", conf.SignatureAlgorithm)
+	}
+
+	var verificationKey interface{} = key
+	if !algorithm.IsHMAC() {
+		pubKey, err := ParsePublicPEMKey(key)
+		if err != nil {
+			return nil, err
+		}
+		verificationKey = pubKey
+	}
+
+	maxAge, err := config.ParseDuration("max_age", conf.MaxAge, time.Minute*5)
+	if err != nil {
+		return nil, err
+	}
+
+	required := conf.RequiredComponents
+	if required == nil {
+		required = defaultRequiredComponents
+	}
+
+	verifier, err := httpsig.NewVerifier(algorithm, verificationKey, conf.KeyID, conf.Label, required, maxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HTTPMessageSignature{
+		name:     conf.Name,
+		verifier: verifier,
+	}, nil
+}
+
+// Validate implements the AccessControl interface
+func (h *HTTPMessageSignature) Validate(req *http.Request) error {
+	params, err := h.verifier.Verify(req)
+	if err == httpsig.ErrMissingSignature {
+		return errors.HttpMessageSignatureMissing.Message(err.Error())
+	} else if err != nil {
+		return errors.HttpMessageSignature.Message(err.Error())
+	}
+
+	sigCtx := map[string]interface{}{
+		"components": params.Components,
+		"created":    params.Created,
+		"keyid":      params.KeyID,
+	}
+	if params.Expires > 0 {
+		sigCtx["expires"] = params.Expires
+	}
+
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	acMap[h.name] = sigCtx
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+	*req = *req.WithContext(ctx)
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package accesscontrol_test
+
+import (
+	"testing"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/config"
+)
+
+func Test_NewHTTPMessageSignature(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		conf      *config.HTTPMessageSignatureAC
+		key       string
+		expErrMsg string
+	}{
+		{"hmac", &config.HTTPMessageSignatureAC{SignatureAlgorithm: "HS256"}, "secret", ""},
+		{"unknown algorithm", &config.HTTPMessageSignatureAC{SignatureAlgorithm: "none"}, "secret", `algorithm "none" is not supported`},
+		{"invalid public key", &config.HTTPMessageSignatureAC{SignatureAlgorithm: "ES256"}, "secret", "invalid key: Key must be a PEM encoded PKCS1 or PKCS8 key"},
+		{"invalid max_age", &config.HTTPMessageSignatureAC{SignatureAlgorithm: "HS256", MaxAge: "5"}, "secret", `max_age: time: missing unit in duration "5"`},
+		{"invalid component", &config.HTTPMessageSignatureAC{SignatureAlgorithm: "HS256", RequiredComponents: []string{"@status"}}, "secret", `unsupported component identifier: "@status"`},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := ac.NewHTTPMessageSignature(tc.conf, []byte(tc.key))
+			if tc.expErrMsg == "" && err != nil {
+				st.Errorf("unexpected error: %v", err)
+			} else if tc.expErrMsg != "" && (err == nil || err.Error() != tc.expErrMsg) {
+				st.Errorf("expected error %q, got: %v", tc.expErrMsg, err)
+			}
+		})
+	}
+}
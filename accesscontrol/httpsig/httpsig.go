@@ -0,0 +1,318 @@
+// Package httpsig implements HTTP Message Signatures (RFC 9421) for requests.
+package httpsig
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
+)
+
+const (
+	HeaderSignature      = "Signature"
+	HeaderSignatureInput = "Signature-Input"
+	HeaderContentDigest  = "Content-Digest"
+
+	componentContentDigest = "content-digest"
+)
+
+// DefaultComponents are covered if no components are configured.
+var DefaultComponents = []string{"@method", "@authority", "@path", "@query"}
+
+// algorithmNames maps the JWT algorithms to their names in the HTTP Signature Algorithms registry.
+// Signatures with other algorithms omit the alg parameter.
+var algorithmNames = map[acjwt.Algorithm]string{
+	acjwt.AlgorithmRSA256:    "rsa-v1_5-sha256",
+	acjwt.AlgorithmRSAPSS512: "rsa-pss-sha512",
+	acjwt.AlgorithmHMAC256:   "hmac-sha256",
+	acjwt.AlgorithmECDSA256:  "ecdsa-p256-sha256",
+	acjwt.AlgorithmECDSA384:  "ecdsa-p384-sha384",
+	acjwt.AlgorithmEdDSA:     "ed25519",
+}
+
+// AlgorithmName returns the registered HTTP signature algorithm name, if any.
+func AlgorithmName(alg acjwt.Algorithm) string {
+	return algorithmNames[alg]
+}
+
+// ValidateComponents checks for supported component identifiers.
+func ValidateComponents(components []string) error {
+	for _, c := range components {
+		switch c {
+		case "@method", "@target-uri", "@authority", "@scheme", "@request-target", "@path", "@query":
+		default:
+			if c == "" || strings.HasPrefix(c, "@") || c != strings.ToLower(c) || strings.ContainsAny(c, "\"; ") {
+				return fmt.Errorf("unsupported component identifier: %q", c)
+			}
+		}
+	}
+	return nil
+}
+
+// componentValue returns the value of a derived component or the combined field value.
+func componentValue(req *http.Request, component string) (string, error) {
+	switch component {
+	case "@method":
+		return req.Method, nil
+	case "@target-uri":
+		return scheme(req) + "://" + authority(req) + requestTarget(req), nil
+	case "@authority":
+		return authority(req), nil
+	case "@scheme":
+		return scheme(req), nil
+	case "@request-target":
+		return requestTarget(req), nil
+	case "@path":
+		if p := req.URL.EscapedPath(); p != "" {
+			return p, nil
+		}
+		return "/", nil
+	case "@query":
+		return "?" + req.URL.RawQuery, nil
+	}
+
+	fieldValues := req.Header.Values(component)
+	if len(fieldValues) == 0 {
+		return "", fmt.Errorf("missing header field %q", component)
+	}
+	values := make([]string, len(fieldValues))
+	for i, v := range fieldValues {
+		values[i] = strings.TrimSpace(v)
+	}
+	return strings.Join(values, ", "), nil
+}
+
+func authority(req *http.Request) string {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	host = strings.ToLower(host)
+
+	s := scheme(req)
+	if (s == "http" && strings.HasSuffix(host, ":80")) || (s == "https" && strings.HasSuffix(host, ":443")) {
+		host = host[:strings.LastIndex(host, ":")]
+	}
+	return host
+}
+
+func scheme(req *http.Request) string {
+	if req.URL.Scheme != "" {
+		return strings.ToLower(req.URL.Scheme)
+	}
+	if req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+func requestTarget(req *http.Request) string {
+	target := req.URL.EscapedPath()
+	if target == "" {
+		target = "/"
+	}
+	if req.URL.RawQuery != "" {
+		target += "?" + req.URL.RawQuery
+	}
+	return target
+}
+
+// signatureBase creates the signature base (RFC 9421, section 2.5) with the
+// serialized signature parameters as the final line.
+func signatureBase(req *http.Request, components []string, params string) (string, error) {
+	var sb strings.Builder
+	for _, c := range components {
+		value, err := componentValue(req, c)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(strconv.Quote(c))
+		sb.WriteString(": ")
+		sb.WriteString(value)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(`"@signature-params": `)
+	sb.WriteString(params)
+	return sb.String(), nil
+}
+
+// Params represents the parsed signature parameters of a Signature-Input member.
+type Params struct {
+	Algorithm  string
+	Components []string
+	Created    int64
+	Expires    int64
+	KeyID      string
+	Nonce      string
+	Tag        string
+
+	raw string
+}
+
+func (p *Params) serialize() string {
+	quoted := make([]string, len(p.Components))
+	for i, c := range p.Components {
+		quoted[i] = strconv.Quote(c)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("(" + strings.Join(quoted, " ") + ")")
+	if p.Created > 0 {
+		sb.WriteString(";created=" + strconv.FormatInt(p.Created, 10))
+	}
+	if p.Expires > 0 {
+		sb.WriteString(";expires=" + strconv.FormatInt(p.Expires, 10))
+	}
+	if p.KeyID != "" {
+		sb.WriteString(";keyid=" + strconv.Quote(p.KeyID))
+	}
+	if p.Algorithm != "" {
+		sb.WriteString(";alg=" + strconv.Quote(p.Algorithm))
+	}
+	return sb.String()
+}
+
+// splitDictionary splits a structured field dictionary into its raw member values by key.
+func splitDictionary(field string) (map[string]string, []string, error) {
+	members := make(map[string]string)
+	var keys []string
+
+	var (
+		depth   int
+		inQuote bool
+		start   int
+	)
+
+	add := func(member string) error {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			return nil
+		}
+		key, value, found := strings.Cut(member, "=")
+		if !found || key == "" {
+			return fmt.Errorf("invalid dictionary member: %q", member)
+		}
+		if _, exists := members[key]; !exists {
+			keys = append(keys, key)
+		}
+		members[key] = value
+		return nil
+	}
+
+	for i := 0; i < len(field); i++ {
+		switch c := field[i]; {
+		case inQuote && c == '\\':
+			i++
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			if err := add(field[start:i]); err != nil {
+				return nil, nil, err
+			}
+			start = i + 1
+		}
+	}
+	if inQuote || depth != 0 {
+		return nil, nil, fmt.Errorf("invalid dictionary: %q", field)
+	}
+	if err := add(field[start:]); err != nil {
+		return nil, nil, err
+	}
+
+	return members, keys, nil
+}
+
+// parseParams parses an inner list of component identifiers with its parameters.
+func parseParams(raw string) (*Params, error) {
+	p := &Params{raw: raw}
+
+	if !strings.HasPrefix(raw, "(") {
+		return nil, fmt.Errorf("inner list expected: %q", raw)
+	}
+	end := strings.Index(raw, ")")
+	if end == -1 {
+		return nil, fmt.Errorf("inner list expected: %q", raw)
+	}
+
+	for _, item := range strings.Fields(raw[1:end]) {
+		c, err := strconv.Unquote(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid component identifier: %s", item)
+		}
+		p.Components = append(p.Components, c)
+	}
+
+	rest := raw[end+1:]
+	for rest != "" {
+		if rest[0] != ';' {
+			return nil, fmt.Errorf("invalid signature parameters: %q", raw)
+		}
+		rest = rest[1:]
+
+		name, value, found := strings.Cut(rest, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid signature parameters: %q", raw)
+		}
+
+		var (
+			str string
+			err error
+		)
+		if strings.HasPrefix(value, `"`) {
+			n := closingQuote(value)
+			if n == -1 {
+				return nil, fmt.Errorf("invalid signature parameters: %q", raw)
+			}
+			str, err = strconv.Unquote(value[:n+1])
+			rest = value[n+1:]
+		} else {
+			n := strings.IndexByte(value, ';')
+			if n == -1 {
+				n = len(value)
+			}
+			str, rest = value[:n], value[n:]
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch name {
+		case "alg":
+			p.Algorithm = str
+		case "created":
+			p.Created, err = strconv.ParseInt(str, 10, 64)
+		case "expires":
+			p.Expires, err = strconv.ParseInt(str, 10, 64)
+		case "keyid":
+			p.KeyID = str
+		case "nonce":
+			p.Nonce = str
+		case "tag":
+			p.Tag = str
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s parameter: %w", name, err)
+		}
+	}
+
+	return p, nil
+}
+
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
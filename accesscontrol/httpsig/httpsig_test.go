@@ -0,0 +1,174 @@
+package httpsig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
+)
+
+// newTestRequest returns the example request of RFC 9421, appendix B.2.
+func newTestRequest() *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/foo?param=Value&Pet=dog", strings.NewReader(`{"hello": "world"}`))
+	req.Header.Set("Date", "Tue, 20 Apr 2021 02:07:55 GMT")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Digest", "sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:")
+	return req
+}
+
+func TestVerifier_RFC9421_HMAC(t *testing.T) {
+	secret, err := base64.StdEncoding.DecodeString("uzvJfB4u3N0Jy4T7NZ75MDVcr8zSTInedJtkgcu46YW4XByzNJjxBdtjUkdJPBtbmHhIDi6pcl8jsasjlTMtDQ==")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := newTestRequest()
+	req.Header.Set(HeaderSignatureInput, `sig-b25=("date" "@authority" "content-type");created=1618884473;keyid="test-shared-secret"`)
+	req.Header.Set(HeaderSignature, `sig-b25=:pxcQw6G3AjtMBQjwo8XzkZf/bws5LelbaMk5rGIGtE8=:`)
+
+	v, err := NewVerifier(acjwt.AlgorithmHMAC256, secret, "test-shared-secret", "", []string{"@authority"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params, err := v.Verify(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.KeyID != "test-shared-secret" || params.Created != 1618884473 {
+		t.Errorf("unexpected params: %#v", params)
+	}
+}
+
+func TestVerifier_RFC9421_Ed25519(t *testing.T) {
+	pkix, err := base64.StdEncoding.DecodeString("MCowBQYDK2VwAyEAJrQLj5P/89iXES9+vFgrIy29clF9CC/oPPsw3c5D0bs=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := x509.ParsePKIXPublicKey(pkix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := newTestRequest()
+	req.Header.Set("Content-Length", "18")
+	req.Header.Set(HeaderSignatureInput, `sig-b26=("date" "@method" "@path" "@authority" "content-type" "content-length");created=1618884473;keyid="test-key-ed25519"`)
+	req.Header.Set(HeaderSignature, `sig-b26=:wqcAqbmYJ2ji2glfAMaRy4gruYYnx2nEFN2HN6jrnDnQCK1u02Gb04v9EDgwUPiu4A0w6vuQv5lIp5WPpBKRCw==:`)
+
+	v, err := NewVerifier(acjwt.AlgorithmEdDSA, key, "test-key-ed25519", "", []string{"@method", "@path"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = v.Verify(req); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSigner_Verifier(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	components := []string{"@method", "@target-uri", "content-type", "content-digest"}
+	signer, err := NewSigner(acjwt.AlgorithmECDSA256, key, "partner", "couper", components, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newVerifier := func(required []string, maxAge time.Duration) *Verifier {
+		v, verr := NewVerifier(acjwt.AlgorithmECDSA256, &key.PublicKey, "partner", "couper", required, maxAge)
+		if verr != nil {
+			t.Fatal(verr)
+		}
+		return v
+	}
+
+	for _, tc := range []struct {
+		name     string
+		modify   func(req *http.Request)
+		verifier *Verifier
+		expErr   string
+	}{
+		{"valid", func(*http.Request) {}, newVerifier([]string{"@method", "content-digest"}, time.Minute), ""},
+		{"tampered query", func(req *http.Request) {
+			req.URL.RawQuery = "param=other"
+		}, newVerifier(nil, 0), "signature mismatch: crypto/ecdsa: verification error"},
+		{"tampered body", func(req *http.Request) {
+			req.Body = http.NoBody
+			req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader("{}")), nil }
+		}, newVerifier(nil, 0), "content digest mismatch"},
+		{"missing header", func(req *http.Request) {
+			req.Header.Del("Content-Type")
+		}, newVerifier(nil, 0), `missing header field "content-type"`},
+		{"required component", func(*http.Request) {}, newVerifier([]string{"@method", "date"}, 0), `component "date" not covered`},
+		{"missing signature", func(req *http.Request) {
+			req.Header.Del(HeaderSignature)
+			req.Header.Del(HeaderSignatureInput)
+		}, newVerifier(nil, 0), ErrMissingSignature.Error()},
+		{"unknown label", func(req *http.Request) {
+			req.Header.Set(HeaderSignatureInput, strings.Replace(req.Header.Get(HeaderSignatureInput), "couper=", "other=", 1))
+		}, newVerifier(nil, 0), `missing signature input "couper"`},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			req := newTestRequest()
+			req.Header.Del("Content-Digest")
+			if err = signer.Sign(req); err != nil {
+				st.Fatal(err)
+			}
+
+			if !strings.HasPrefix(req.Header.Get(HeaderSignatureInput), `couper=("@method" "@target-uri" "content-type" "content-digest");created=`) ||
+				!strings.HasSuffix(req.Header.Get(HeaderSignatureInput), `;keyid="partner";alg="ecdsa-p256-sha256"`) {
+				st.Errorf("unexpected signature input: %q", req.Header.Get(HeaderSignatureInput))
+			}
+			if digest := req.Header.Get(HeaderContentDigest); digest != ContentDigest([]byte(`{"hello": "world"}`)) {
+				st.Errorf("unexpected content digest: %q", digest)
+			}
+
+			tc.modify(req)
+
+			_, verr := tc.verifier.Verify(req)
+			if tc.expErr == "" && verr != nil {
+				st.Errorf("unexpected error: %v", verr)
+			} else if tc.expErr != "" && (verr == nil || verr.Error() != tc.expErr) {
+				st.Errorf("expected error %q, got: %v", tc.expErr, verr)
+			}
+		})
+	}
+}
+
+func TestVerifier_Time(t *testing.T) {
+	now := time.Now().Unix()
+	v := &Verifier{maxAge: time.Minute}
+
+	for _, tc := range []struct {
+		name   string
+		params *Params
+		expErr string
+	}{
+		{"valid", &Params{Created: now}, ""},
+		{"missing created", &Params{}, "missing created parameter"},
+		{"too old", &Params{Created: now - 120}, "signature too old"},
+		{"future", &Params{Created: now + 120}, "signature created in the future"},
+		{"expired", &Params{Created: now - 10, Expires: now - 5}, "signature expired"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			err := v.checkTime(tc.params)
+			if tc.expErr == "" && err != nil {
+				st.Errorf("unexpected error: %v", err)
+			} else if tc.expErr != "" && (err == nil || err.Error() != tc.expErr) {
+				st.Errorf("expected error %q, got: %v", tc.expErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package httpsig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
+)
+
+// Signer signs requests with the configured key and covered components.
+type Signer struct {
+	algorithm  acjwt.Algorithm
+	components []string
+	key        interface{}
+	keyID      string
+	label      string
+	method     jwt.SigningMethod
+	ttl        time.Duration
+}
+
+// NewSigner creates a new Signer. The key must be the private key for RSA and
+// ECDSA algorithms or the shared secret for HMAC algorithms.
+func NewSigner(alg acjwt.Algorithm, key interface{}, keyID, label string, components []string, ttl time.Duration) (*Signer, error) {
+	method := jwt.GetSigningMethod(alg.String())
+	if alg == acjwt.AlgorithmUnknown || method == nil {
+		return nil, fmt.Errorf("algorithm is not supported")
+	}
+
+	if len(components) == 0 {
+		components = DefaultComponents
+	}
+	if err := ValidateComponents(components); err != nil {
+		return nil, err
+	}
+
+	if label == "" {
+		label = "sig1"
+	}
+
+	return &Signer{
+		algorithm:  alg,
+		components: components,
+		key:        key,
+		keyID:      keyID,
+		label:      label,
+		method:     method,
+		ttl:        ttl,
+	}, nil
+}
+
+// Sign adds the Signature-Input and Signature header fields to the given request.
+// A covered but missing Content-Digest field is created from the request body.
+func (s *Signer) Sign(req *http.Request) error {
+	for _, c := range s.components {
+		if c == componentContentDigest && req.Header.Get(HeaderContentDigest) == "" {
+			if err := setContentDigest(req); err != nil {
+				return err
+			}
+		}
+	}
+
+	now := time.Now()
+	params := &Params{
+		Algorithm:  AlgorithmName(s.algorithm),
+		Components: s.components,
+		Created:    now.Unix(),
+		KeyID:      s.keyID,
+	}
+	if s.ttl > 0 {
+		params.Expires = now.Add(s.ttl).Unix()
+	}
+
+	serialized := params.serialize()
+	base, err := signatureBase(req, s.components, serialized)
+	if err != nil {
+		return err
+	}
+
+	signature, err := s.method.Sign(base, s.key)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(HeaderSignatureInput, s.label+"="+serialized)
+	req.Header.Set(HeaderSignature, s.label+"=:"+base64.StdEncoding.EncodeToString(signature)+":")
+	return nil
+}
+
+func setContentDigest(req *http.Request) error {
+	var body []byte
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		if body, err = io.ReadAll(rc); err != nil {
+			return err
+		}
+	} else if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return err
+		}
+		_ = req.Body.Close()
+
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.Body, _ = req.GetBody()
+		req.ContentLength = int64(len(body))
+	}
+
+	req.Header.Set(HeaderContentDigest, ContentDigest(body))
+	return nil
+}
+
+// ContentDigest returns the SHA-256 Content-Digest field value (RFC 9530) for the given body.
+func ContentDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
@@ -0,0 +1,212 @@
+package httpsig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
+)
+
+// ErrMissingSignature is returned if a request has no signature.
+var ErrMissingSignature = errors.New("signature required")
+
+// Verifier verifies request signatures with the configured key.
+type Verifier struct {
+	algorithm acjwt.Algorithm
+	key       interface{}
+	keyID     string
+	label     string
+	maxAge    time.Duration
+	method    jwt.SigningMethod
+	required  []string
+}
+
+// NewVerifier creates a new Verifier. The key must be the public key for RSA and
+// ECDSA algorithms or the shared secret for HMAC algorithms.
+func NewVerifier(alg acjwt.Algorithm, key interface{}, keyID, label string, required []string, maxAge time.Duration) (*Verifier, error) {
+	method := jwt.GetSigningMethod(alg.String())
+	if alg == acjwt.AlgorithmUnknown || method == nil {
+		return nil, fmt.Errorf("algorithm is not supported")
+	}
+
+	if err := ValidateComponents(required); err != nil {
+		return nil, err
+	}
+
+	return &Verifier{
+		algorithm: alg,
+		key:       key,
+		keyID:     keyID,
+		label:     label,
+		maxAge:    maxAge,
+		method:    method,
+		required:  required,
+	}, nil
+}
+
+// Verify verifies the signature with the configured label or the first one.
+func (v *Verifier) Verify(req *http.Request) (*Params, error) {
+	inputField := strings.Join(req.Header.Values(HeaderSignatureInput), ", ")
+	signatureField := strings.Join(req.Header.Values(HeaderSignature), ", ")
+	if inputField == "" && signatureField == "" {
+		return nil, ErrMissingSignature
+	}
+
+	inputs, labels, err := splitDictionary(inputField)
+	if err != nil {
+		return nil, err
+	}
+	signatures, _, err := splitDictionary(signatureField)
+	if err != nil {
+		return nil, err
+	}
+
+	label := v.label
+	if label == "" && len(labels) > 0 {
+		label = labels[0]
+	}
+
+	rawInput, exists := inputs[label]
+	if !exists {
+		return nil, fmt.Errorf("missing signature input %q", label)
+	}
+	rawSignature, exists := signatures[label]
+	if !exists {
+		return nil, fmt.Errorf("missing signature %q", label)
+	}
+
+	params, err := parseParams(rawInput)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.keyID != "" && params.KeyID != v.keyID {
+		return nil, fmt.Errorf("unexpected keyid: %q", params.KeyID)
+	}
+
+	if params.Algorithm != "" && params.Algorithm != AlgorithmName(v.algorithm) {
+		return nil, fmt.Errorf("unexpected alg: %q", params.Algorithm)
+	}
+
+	for _, r := range v.required {
+		if !contains(params.Components, r) {
+			return nil, fmt.Errorf("component %q not covered", r)
+		}
+	}
+
+	if err = v.checkTime(params); err != nil {
+		return nil, err
+	}
+
+	if contains(params.Components, componentContentDigest) {
+		if err = verifyContentDigest(req); err != nil {
+			return nil, err
+		}
+	}
+
+	if !strings.HasPrefix(rawSignature, ":") || !strings.HasSuffix(rawSignature, ":") || len(rawSignature) < 2 {
+		return nil, fmt.Errorf("invalid signature: byte sequence expected")
+	}
+	signature, err := base64.StdEncoding.DecodeString(rawSignature[1 : len(rawSignature)-1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	base, err := signatureBase(req, params.Components, params.raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = v.method.Verify(base, signature, v.key); err != nil {
+		return nil, fmt.Errorf("signature mismatch: %w", err)
+	}
+
+	return params, nil
+}
+
+func (v *Verifier) checkTime(params *Params) error {
+	if params.Created == 0 {
+		return fmt.Errorf("missing created parameter")
+	}
+
+	now := time.Now()
+	created := time.Unix(params.Created, 0)
+	if created.After(now.Add(time.Minute)) { // tolerate clock skew
+		return fmt.Errorf("signature created in the future")
+	}
+	if v.maxAge > 0 && now.Sub(created) > v.maxAge {
+		return fmt.Errorf("signature too old")
+	}
+	if params.Expires > 0 && now.After(time.Unix(params.Expires, 0)) {
+		return fmt.Errorf("signature expired")
+	}
+	return nil
+}
+
+// verifyContentDigest compares the sha-256 or sha-512 digests with the request body.
+func verifyContentDigest(req *http.Request) error {
+	field := req.Header.Get(HeaderContentDigest)
+	digests, _, err := splitDictionary(field)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	if req.GetBody != nil {
+		rc, gerr := req.GetBody()
+		if gerr != nil {
+			return gerr
+		}
+		defer rc.Close()
+		if body, err = io.ReadAll(rc); err != nil {
+			return err
+		}
+	} else if req.Body != nil && req.Body != http.NoBody {
+		return fmt.Errorf("request body is not buffered")
+	}
+
+	var verified bool
+	for alg, value := range digests {
+		var sum []byte
+		switch alg {
+		case "sha-256":
+			s := sha256.Sum256(body)
+			sum = s[:]
+		case "sha-512":
+			s := sha512.Sum512(body)
+			sum = s[:]
+		default:
+			continue
+		}
+
+		digest, derr := base64.StdEncoding.DecodeString(strings.Trim(value, ":"))
+		if derr != nil || !bytes.Equal(digest, sum) {
+			return fmt.Errorf("content digest mismatch")
+		}
+		verified = true
+	}
+
+	if !verified {
+		return fmt.Errorf("missing supported content digest")
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
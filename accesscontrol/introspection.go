@@ -19,6 +19,8 @@ import (
 	"github.com/coupergateway/couper/oauth2"
 )
 
+const introspectionKeyPrefix = "ir:"
+
 // IntrospectionResponse represents the response body to a token introspection request.
 type IntrospectionResponse map[string]interface{}
 
@@ -48,6 +50,28 @@ func (ir IntrospectionResponse) exp() int64 {
 	return exp
 }
 
+// InvalidateIntrospections removes the cached introspection responses for the given subject
+// of the given issuer, e.g. after a back-channel logout. A subject is unique per issuer only.
+func InvalidateIntrospections(memStore *cache.MemoryStore, issuer, subject string) {
+	if memStore == nil || issuer == "" || subject == "" {
+		return
+	}
+
+	memStore.DelAllWithPrefix(introspectionKeyPrefixFor(issuer), func(v interface{}) bool {
+		ir, ok := v.(IntrospectionResponse)
+		if !ok {
+			return false
+		}
+		sub, _ := ir["sub"].(string)
+		return sub == subject
+	})
+}
+
+// introspectionKeyPrefixFor returns the prefix of the cache keys for the tokens of the given issuer.
+func introspectionKeyPrefixFor(issuer string) string {
+	return introspectionKeyPrefix + url.QueryEscape(issuer) + ":"
+}
+
 type lock struct {
 	mu sync.Mutex
 }
@@ -75,8 +99,8 @@ func NewIntrospector(evalCtx *hcl.EvalContext, conf *config.Introspection, trans
 	}, nil
 }
 
-// Introspect retrieves introspection data for the given token using either cached or fresh information.
-func (i *Introspector) Introspect(ctx context.Context, token string, exp, nbf int64) (IntrospectionResponse, error) {
+// Introspect retrieves introspection data for the given token of the given issuer using either cached or fresh information.
+func (i *Introspector) Introspect(ctx context.Context, token, issuer string, exp, nbf int64) (IntrospectionResponse, error) {
 	var (
 		introspectionData IntrospectionResponse
 		key               string
@@ -92,7 +116,7 @@ func (i *Introspector) Introspect(ctx context.Context, token string, exp, nbf in
 			l.mu.Unlock()
 		}()
 
-		key = "ir:" + token
+		key = introspectionKeyPrefixFor(issuer) + token
 		cachedIntrospection, _ := i.memStore.Get(key).(IntrospectionResponse)
 		if cachedIntrospection != nil {
 			return cachedIntrospection, nil
@@ -0,0 +1,39 @@
+package accesscontrol
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/coupergateway/couper/cache"
+)
+
+func Test_InvalidateIntrospections(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+	memStore := cache.New(logger.WithContext(context.TODO()), quitCh)
+
+	const issA, issB = "https://a.example.com", "https://b.example.com"
+	keys := map[string]string{
+		"token-a1": introspectionKeyPrefixFor(issA) + "token-a1",
+		"token-a2": introspectionKeyPrefixFor(issA) + "token-a2",
+		"token-b1": introspectionKeyPrefixFor(issB) + "token-b1",
+	}
+	memStore.Set(keys["token-a1"], IntrospectionResponse{"active": true, "sub": "alice"}, 60)
+	memStore.Set(keys["token-a2"], IntrospectionResponse{"active": true, "sub": "bob"}, 60)
+	memStore.Set(keys["token-b1"], IntrospectionResponse{"active": true, "sub": "alice"}, 60)
+
+	InvalidateIntrospections(memStore, issA, "alice")
+
+	for token, expCached := range map[string]bool{
+		"token-a1": false,
+		"token-a2": true,
+		"token-b1": true,
+	} {
+		if cached := memStore.Get(keys[token]) != nil; cached != expCached {
+			t.Errorf("%s: expected cached %t, got %t", token, expCached, cached)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package jwe
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"hash"
+)
+
+// contentKeySize returns the content encryption key size in bytes.
+func contentKeySize(enc string) (int, error) {
+	switch enc {
+	case EncryptionA128GCM:
+		return 16, nil
+	case EncryptionA192GCM:
+		return 24, nil
+	case EncryptionA256GCM, EncryptionA128CBCHS256:
+		return 32, nil
+	case EncryptionA192CBCHS384:
+		return 48, nil
+	case EncryptionA256CBCHS512:
+		return 64, nil
+	}
+	return 0, fmt.Errorf("jwe: unsupported content encryption algorithm %q", enc)
+}
+
+func isGCM(enc string) bool {
+	return enc == EncryptionA128GCM || enc == EncryptionA192GCM || enc == EncryptionA256GCM
+}
+
+func decryptContent(enc string, cek, iv, ciphertext, tag, aad []byte) ([]byte, error) {
+	if isGCM(enc) {
+		aead, err := newGCM(cek)
+		if err != nil {
+			return nil, err
+		}
+		if len(iv) != aead.NonceSize() {
+			return nil, ErrDecryption
+		}
+		plaintext, err := aead.Open(nil, iv, append(ciphertext, tag...), aad)
+		if err != nil {
+			return nil, ErrDecryption
+		}
+		return plaintext, nil
+	}
+
+	macKey, encKey := cek[:len(cek)/2], cek[len(cek)/2:]
+	if !hmac.Equal(tag, cbcTag(enc, macKey, aad, iv, ciphertext)) {
+		return nil, ErrDecryption
+	}
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() || len(ciphertext) == 0 || len(ciphertext)%block.BlockSize() != 0 {
+		return nil, ErrDecryption
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > block.BlockSize() ||
+		!bytes.Equal(plaintext[len(plaintext)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, ErrDecryption
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
+
+func encryptContent(enc string, cek, plaintext, aad []byte) (iv, ciphertext, tag []byte, err error) {
+	if isGCM(enc) {
+		aead, gerr := newGCM(cek)
+		if gerr != nil {
+			return nil, nil, nil, gerr
+		}
+		iv = make([]byte, aead.NonceSize())
+		if _, err = rand.Read(iv); err != nil {
+			return nil, nil, nil, err
+		}
+		sealed := aead.Seal(nil, iv, plaintext, aad)
+		split := len(sealed) - aead.Overhead()
+		return iv, sealed[:split], sealed[split:], nil
+	}
+
+	macKey, encKey := cek[:len(cek)/2], cek[len(cek)/2:]
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	iv = make([]byte, block.BlockSize())
+	if _, err = rand.Read(iv); err != nil {
+		return nil, nil, nil, err
+	}
+
+	padding := block.BlockSize() - len(plaintext)%block.BlockSize()
+	padded := append(append([]byte{}, plaintext...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	ciphertext = make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+
+	return iv, ciphertext, cbcTag(enc, macKey, aad, iv, ciphertext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// cbcTag creates the authentication tag according to RFC 7518, section 5.2.2.1.
+func cbcTag(enc string, macKey, aad, iv, ciphertext []byte) []byte {
+	var h func() hash.Hash
+	switch enc {
+	case EncryptionA128CBCHS256:
+		h = sha256.New
+	case EncryptionA192CBCHS384:
+		h = sha512.New384
+	default:
+		h = sha512.New
+	}
+
+	al := make([]byte, 8)
+	binary.BigEndian.PutUint64(al, uint64(len(aad))*8)
+
+	mac := hmac.New(h, macKey)
+	mac.Write(aad)
+	mac.Write(iv)
+	mac.Write(ciphertext)
+	mac.Write(al)
+	return mac.Sum(nil)[:len(macKey)]
+}
@@ -0,0 +1,218 @@
+// Package jwe implements the compact serialization of JSON Web Encryption (RFC 7516)
+// with the key management algorithms RSA-OAEP, RSA-OAEP-256, ECDH-ES and dir and
+// the AES GCM and AES CBC HMAC SHA-2 content encryption algorithms (RFC 7518).
+package jwe
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	AlgorithmRSAOAEP    = "RSA-OAEP"
+	AlgorithmRSAOAEP256 = "RSA-OAEP-256"
+	AlgorithmECDHES     = "ECDH-ES"
+	AlgorithmDirect     = "dir"
+
+	EncryptionA128GCM       = "A128GCM"
+	EncryptionA192GCM       = "A192GCM"
+	EncryptionA256GCM       = "A256GCM"
+	EncryptionA128CBCHS256  = "A128CBC-HS256"
+	EncryptionA192CBCHS384  = "A192CBC-HS384"
+	EncryptionA256CBCHS512  = "A256CBC-HS512"
+	DefaultContentAlgorithm = EncryptionA256GCM
+)
+
+var ErrDecryption = errors.New("jwe: decryption failed")
+
+// Header represents the JOSE header of an encrypted token.
+type Header map[string]interface{}
+
+// IsJWE checks whether the given token has the five parts of the JWE compact serialization.
+func IsJWE(token string) bool {
+	return strings.Count(token, ".") == 4
+}
+
+// ValidateAlgorithms checks for supported key management and content encryption algorithms.
+func ValidateAlgorithms(alg, enc string) error {
+	switch alg {
+	case AlgorithmRSAOAEP, AlgorithmRSAOAEP256, AlgorithmECDHES, AlgorithmDirect:
+	default:
+		return fmt.Errorf("jwe: unsupported key management algorithm %q", alg)
+	}
+
+	if _, err := contentKeySize(enc); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Decrypt decrypts the compact serialized token with the given private key
+// (*rsa.PrivateKey, *ecdsa.PrivateKey) or shared symmetric key ([]byte).
+func Decrypt(token string, key interface{}) ([]byte, Header, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, nil, fmt.Errorf("jwe: invalid compact serialization")
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("jwe: invalid protected header: %w", err)
+	}
+
+	header := Header{}
+	if err = json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, nil, fmt.Errorf("jwe: invalid protected header: %w", err)
+	}
+
+	alg, _ := header["alg"].(string)
+	enc, _ := header["enc"].(string)
+	if err = ValidateAlgorithms(alg, enc); err != nil {
+		return nil, nil, err
+	}
+	if _, exists := header["zip"]; exists {
+		return nil, nil, fmt.Errorf("jwe: compression is not supported")
+	}
+	if _, exists := header["crit"]; exists {
+		return nil, nil, fmt.Errorf("jwe: critical header parameters are not supported")
+	}
+
+	var segments [4][]byte
+	for i, part := range parts[1:] {
+		if segments[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return nil, nil, fmt.Errorf("jwe: invalid encoding: %w", err)
+		}
+	}
+	encryptedKey, iv, ciphertext, tag := segments[0], segments[1], segments[2], segments[3]
+
+	cek, err := decryptKey(alg, enc, header, encryptedKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plaintext, err := decryptContent(enc, cek, iv, ciphertext, tag, []byte(parts[0]))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return plaintext, header, nil
+}
+
+// Encrypt encrypts the plaintext with the given public key (*rsa.PublicKey, *ecdsa.PublicKey)
+// or shared symmetric key ([]byte). The additional header fields must not contain "alg" or "enc".
+func Encrypt(plaintext []byte, alg, enc string, key interface{}, additionalHeader map[string]interface{}) (string, error) {
+	if err := ValidateAlgorithms(alg, enc); err != nil {
+		return "", err
+	}
+
+	header := Header{}
+	for k, v := range additionalHeader {
+		header[k] = v
+	}
+	header["alg"] = alg
+	header["enc"] = enc
+
+	cek, encryptedKey, err := encryptKey(alg, enc, header, key)
+	if err != nil {
+		return "", err
+	}
+
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	protected := base64.RawURLEncoding.EncodeToString(headerBytes)
+
+	iv, ciphertext, tag, err := encryptContent(enc, cek, plaintext, []byte(protected))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{
+		protected,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+func decryptKey(alg, enc string, header Header, encryptedKey []byte, key interface{}) ([]byte, error) {
+	size, _ := contentKeySize(enc)
+
+	switch alg {
+	case AlgorithmRSAOAEP, AlgorithmRSAOAEP256:
+		privKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("jwe: %s requires an RSA private key", alg)
+		}
+		cek, err := rsa.DecryptOAEP(oaepHash(alg), nil, privKey, encryptedKey, nil)
+		if err != nil || len(cek) != size {
+			return nil, ErrDecryption
+		}
+		return cek, nil
+	case AlgorithmECDHES:
+		privKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("jwe: %s requires an EC private key", alg)
+		}
+		if len(encryptedKey) != 0 {
+			return nil, fmt.Errorf("jwe: %s requires an empty encrypted key", alg)
+		}
+		return deriveECDHESKey(privKey, header, enc, size)
+	case AlgorithmDirect:
+		secret, ok := key.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("jwe: %s requires a symmetric key", alg)
+		}
+		if len(encryptedKey) != 0 {
+			return nil, fmt.Errorf("jwe: %s requires an empty encrypted key", alg)
+		}
+		if len(secret) != size {
+			return nil, fmt.Errorf("jwe: %s requires a %d byte key for %s", alg, size, enc)
+		}
+		return secret, nil
+	}
+	return nil, fmt.Errorf("jwe: unsupported key management algorithm %q", alg)
+}
+
+func encryptKey(alg, enc string, header Header, key interface{}) (cek, encryptedKey []byte, err error) {
+	size, _ := contentKeySize(enc)
+
+	switch alg {
+	case AlgorithmRSAOAEP, AlgorithmRSAOAEP256:
+		pubKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, nil, fmt.Errorf("jwe: %s requires an RSA public key", alg)
+		}
+		cek = make([]byte, size)
+		if _, err = rand.Read(cek); err != nil {
+			return nil, nil, err
+		}
+		encryptedKey, err = rsa.EncryptOAEP(oaepHash(alg), rand.Reader, pubKey, cek, nil)
+		return cek, encryptedKey, err
+	case AlgorithmECDHES:
+		pubKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, nil, fmt.Errorf("jwe: %s requires an EC public key", alg)
+		}
+		cek, err = agreeECDHESKey(pubKey, header, enc, size)
+		return cek, nil, err
+	case AlgorithmDirect:
+		secret, ok := key.([]byte)
+		if !ok {
+			return nil, nil, fmt.Errorf("jwe: %s requires a symmetric key", alg)
+		}
+		if len(secret) != size {
+			return nil, nil, fmt.Errorf("jwe: %s requires a %d byte key for %s", alg, size, enc)
+		}
+		return secret, nil, nil
+	}
+	return nil, nil, fmt.Errorf("jwe: unsupported key management algorithm %q", alg)
+}
@@ -0,0 +1,145 @@
+package jwe
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// RFC 7518, appendix C
+func TestConcatKDF_RFC7518(t *testing.T) {
+	decode := func(s string) *big.Int {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return new(big.Int).SetBytes(b)
+	}
+
+	bob := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     decode("weNJy2HscCSM6AEDTDg04biOvhFhyyWvOHQfeF_PxMQ"),
+			Y:     decode("e8lnCO-AlStT-NJVX-crhB7QRYhiix03illJOVAOyck"),
+		},
+		D: decode("VEmDZpDXXK8p8N0Cndsxs924q6nS1RXFASRl6BfUqdw"),
+	}
+
+	header := Header{
+		"alg": AlgorithmECDHES,
+		"enc": EncryptionA128GCM,
+		"apu": "QWxpY2U",
+		"apv": "Qm9i",
+		"epk": map[string]interface{}{
+			"kty": "EC",
+			"crv": "P-256",
+			"x":   "gI0GAILBdu7T53akrFmMyGcsF3n5dO7MmwNBHKW5SV0",
+			"y":   "SLW_xSffzlPWrHEVI30DHM_4egVwt3NQqeUD7nMFpps",
+		},
+	}
+
+	key, err := deriveECDHESKey(bob, header, EncryptionA128GCM, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := base64.RawURLEncoding.EncodeToString(key); got != "VqqN6vgjbSBcIijNcacQGg" {
+		t.Errorf("expected derived key %q, got: %q", "VqqN6vgjbSBcIijNcacQGg", got)
+	}
+}
+
+func TestEncrypt_Decrypt(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte("eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiJjb3VwZXIifQ.signature")
+
+	for _, tc := range []struct {
+		alg        string
+		enc        string
+		encryptKey interface{}
+		decryptKey interface{}
+	}{
+		{AlgorithmRSAOAEP, EncryptionA128GCM, &rsaKey.PublicKey, rsaKey},
+		{AlgorithmRSAOAEP256, EncryptionA256GCM, &rsaKey.PublicKey, rsaKey},
+		{AlgorithmRSAOAEP256, EncryptionA128CBCHS256, &rsaKey.PublicKey, rsaKey},
+		{AlgorithmECDHES, EncryptionA256GCM, &ecKey.PublicKey, ecKey},
+		{AlgorithmECDHES, EncryptionA256CBCHS512, &ecKey.PublicKey, ecKey},
+		{AlgorithmDirect, EncryptionA256GCM, []byte(strings.Repeat("k", 32)), []byte(strings.Repeat("k", 32))},
+		{AlgorithmDirect, EncryptionA192CBCHS384, []byte(strings.Repeat("k", 48)), []byte(strings.Repeat("k", 48))},
+	} {
+		t.Run(tc.alg+"_"+tc.enc, func(st *testing.T) {
+			token, err := Encrypt(plaintext, tc.alg, tc.enc, tc.encryptKey, map[string]interface{}{"cty": "JWT"})
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			if !IsJWE(token) {
+				st.Fatalf("expected compact serialization, got: %q", token)
+			}
+
+			decrypted, header, err := Decrypt(token, tc.decryptKey)
+			if err != nil {
+				st.Fatal(err)
+			}
+			if string(decrypted) != string(plaintext) {
+				st.Errorf("expected plaintext %q, got: %q", plaintext, decrypted)
+			}
+			if header["cty"] != "JWT" || header["alg"] != tc.alg || header["enc"] != tc.enc {
+				st.Errorf("unexpected header: %#v", header)
+			}
+
+			parts := strings.Split(token, ".")
+			parts[3] = base64.RawURLEncoding.EncodeToString([]byte("tampered ciphertext!"))
+			if _, _, err = Decrypt(strings.Join(parts, "."), tc.decryptKey); err == nil {
+				st.Error("expected error for tampered ciphertext")
+			}
+		})
+	}
+}
+
+func TestDecrypt_Errors(t *testing.T) {
+	secret := []byte(strings.Repeat("s", 32))
+	token, err := Encrypt([]byte("payload"), AlgorithmDirect, EncryptionA256GCM, secret, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		token  string
+		key    interface{}
+		expErr string
+	}{
+		{"wrong key", token, []byte(strings.Repeat("x", 32)), "jwe: decryption failed"},
+		{"wrong key size", token, []byte("short"), "jwe: dir requires a 32 byte key for A256GCM"},
+		{"wrong key type", token, rsaKey, "jwe: dir requires a symmetric key"},
+		{"signed token", "eyJhbGciOiJIUzI1NiJ9.e30.sig", secret, "jwe: invalid compact serialization"},
+		{"unsupported alg", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"A128KW","enc":"A128GCM"}`)) + "....", secret, `jwe: unsupported key management algorithm "A128KW"`},
+		{"unsupported enc", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"dir","enc":"A128CTR"}`)) + "....", secret, `jwe: unsupported content encryption algorithm "A128CTR"`},
+		{"compression", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"dir","enc":"A256GCM","zip":"DEF"}`)) + "....", secret, "jwe: compression is not supported"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, _, err := Decrypt(tc.token, tc.key)
+			if err == nil || err.Error() != tc.expErr {
+				st.Errorf("expected error %q, got: %v", tc.expErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package jwe
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"math/big"
+
+	acjwk "github.com/coupergateway/couper/accesscontrol/jwk"
+)
+
+// ParseDecryptionKey parses a PEM encoded RSA or EC private key. Other key
+// data is returned as symmetric key for the dir algorithm.
+func ParseDecryptionKey(keyBytes []byte) (interface{}, error) {
+	block, _ := pem.Decode(keyBytes)
+	if block == nil {
+		return keyBytes, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("jwe: invalid private key: %w", err)
+	}
+	switch key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("jwe: unsupported private key type %T", key)
+}
+
+// ParseEncryptionKey parses the PEM encoded RSA or EC public key (or certificate)
+// for the given algorithm, or returns the symmetric key for the dir algorithm.
+func ParseEncryptionKey(keyBytes []byte, alg string) (interface{}, error) {
+	if alg == AlgorithmDirect {
+		return keyBytes, nil
+	}
+
+	block, _ := pem.Decode(keyBytes)
+	if block == nil {
+		return nil, fmt.Errorf("jwe: public key must be PEM encoded")
+	}
+
+	var key interface{}
+	if pubKey, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		key = pubKey
+	} else if pubKey, perr := x509.ParsePKIXPublicKey(block.Bytes); perr == nil {
+		key = pubKey
+	} else if cert, cerr := x509.ParseCertificate(block.Bytes); cerr == nil {
+		key = cert.PublicKey
+	} else {
+		return nil, fmt.Errorf("jwe: invalid public key: %w", perr)
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if alg == AlgorithmRSAOAEP || alg == AlgorithmRSAOAEP256 {
+			return key, nil
+		}
+	case *ecdsa.PublicKey:
+		if alg == AlgorithmECDHES {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("jwe: inappropriate public key type %T for %s", key, alg)
+}
+
+func oaepHash(alg string) hash.Hash {
+	if alg == AlgorithmRSAOAEP256 {
+		return sha256.New()
+	}
+	return sha1.New()
+}
+
+// deriveECDHESKey derives the content encryption key with the ephemeral public key
+// of the "epk" header parameter.
+func deriveECDHESKey(privKey *ecdsa.PrivateKey, header Header, enc string, size int) ([]byte, error) {
+	epk, ok := header["epk"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("jwe: missing epk header parameter")
+	}
+
+	crv, _ := epk["crv"].(string)
+	curve, err := acjwk.GetCurve(crv)
+	if err != nil || curve != privKey.Curve {
+		return nil, fmt.Errorf("jwe: invalid epk curve")
+	}
+
+	x, xerr := decodeCoordinate(epk["x"])
+	y, yerr := decodeCoordinate(epk["y"])
+	if xerr != nil || yerr != nil {
+		return nil, fmt.Errorf("jwe: invalid epk coordinates")
+	}
+
+	ephemeral, err := (&ecdsa.PublicKey{Curve: curve, X: x, Y: y}).ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("jwe: invalid epk: %w", err)
+	}
+
+	ecdhKey, err := privKey.ECDH()
+	if err != nil {
+		return nil, err
+	}
+
+	z, err := ecdhKey.ECDH(ephemeral)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+
+	return concatKDF(z, enc, header, size)
+}
+
+// agreeECDHESKey creates an ephemeral key pair, adds its public key as "epk"
+// header parameter and derives the content encryption key.
+func agreeECDHESKey(pubKey *ecdsa.PublicKey, header Header, enc string, size int) ([]byte, error) {
+	recipient, err := pubKey.ECDH()
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeral, err := recipient.Curve().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	z, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	// uncompressed point: 0x04 || x || y
+	point := ephemeral.PublicKey().Bytes()
+	n := (len(point) - 1) / 2
+	header["epk"] = map[string]interface{}{
+		"kty": "EC",
+		"crv": curveName(pubKey.Curve),
+		"x":   base64.RawURLEncoding.EncodeToString(point[1 : 1+n]),
+		"y":   base64.RawURLEncoding.EncodeToString(point[1+n:]),
+	}
+
+	return concatKDF(z, enc, header, size)
+}
+
+// concatKDF implements the Concat KDF of RFC 7518, section 4.6.2 for the direct key agreement.
+func concatKDF(z []byte, enc string, header Header, size int) ([]byte, error) {
+	var apu, apv []byte
+	var err error
+	if v, ok := header["apu"].(string); ok {
+		if apu, err = base64.RawURLEncoding.DecodeString(v); err != nil {
+			return nil, fmt.Errorf("jwe: invalid apu header parameter")
+		}
+	}
+	if v, ok := header["apv"].(string); ok {
+		if apv, err = base64.RawURLEncoding.DecodeString(v); err != nil {
+			return nil, fmt.Errorf("jwe: invalid apv header parameter")
+		}
+	}
+
+	var otherInfo []byte
+	for _, field := range [][]byte{[]byte(enc), apu, apv} {
+		otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(len(field)))
+		otherInfo = append(otherInfo, field...)
+	}
+	otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(size*8))
+
+	var key []byte
+	for counter := uint32(1); len(key) < size; counter++ {
+		h := sha256.New()
+		_ = binary.Write(h, binary.BigEndian, counter)
+		h.Write(z)
+		h.Write(otherInfo)
+		key = h.Sum(key)
+	}
+	return key[:size], nil
+}
+
+func decodeCoordinate(v interface{}) (*big.Int, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("missing coordinate")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func curveName(curve elliptic.Curve) string {
+	switch curve {
+	case elliptic.P256():
+		return "P-256"
+	case elliptic.P384():
+		return "P-384"
+	default:
+		return "P-521"
+	}
+}
@@ -1,9 +1,12 @@
 package jwk
 
 import (
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
@@ -28,7 +31,7 @@ type rawJWK struct {
 	E   *base64URLEncodedField `json:"e"`
 	N   *base64URLEncodedField `json:"n"`
 	X5c []*base64EncodedField  `json:"x5c"`
-	// ECDSA public key
+	// ECDSA and EdDSA (OKP) public key
 	Crv string                 `json:"crv"`
 	X   *base64URLEncodedField `json:"x"`
 	Y   *base64URLEncodedField `json:"y"`
@@ -36,6 +39,98 @@ type rawJWK struct {
 	//X5tS256" *base64URLEncodedField `json:"x5t#S256"`
 }
 
+// publicJWK is the JSON representation of a public key published with a JWKS.
+type publicJWK struct {
+	Alg string                 `json:"alg,omitempty"`
+	Crv string                 `json:"crv,omitempty"`
+	E   *base64URLEncodedField `json:"e,omitempty"`
+	Kid string                 `json:"kid,omitempty"`
+	Kty string                 `json:"kty"`
+	N   *base64URLEncodedField `json:"n,omitempty"`
+	Use string                 `json:"use,omitempty"`
+	X   *base64URLEncodedField `json:"x,omitempty"`
+	Y   *base64URLEncodedField `json:"y,omitempty"`
+}
+
+// NewPublicJWK creates the signature JWK for the public part of the given (private or public) key.
+// Without a given kid, the key ID is the JWK thumbprint (RFC 7638).
+func NewPublicJWK(key interface{}, alg, kid string) (*JWK, error) {
+	if signer, ok := key.(crypto.Signer); ok {
+		key = signer.Public()
+	}
+
+	jwk := &JWK{Key: key, KeyID: kid, Algorithm: alg, Use: "sig"}
+	raw, err := jwk.public()
+	if err != nil {
+		return nil, err
+	}
+	jwk.KeyType = raw.Kty
+
+	if jwk.KeyID == "" {
+		if jwk.KeyID, err = raw.thumbprint(); err != nil {
+			return nil, err
+		}
+	}
+
+	return jwk, nil
+}
+
+// MarshalJSON implements the json.Marshaler interface for public keys.
+func (j *JWK) MarshalJSON() ([]byte, error) {
+	raw, err := j.public()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(raw)
+}
+
+func (j *JWK) public() (*publicJWK, error) {
+	raw := &publicJWK{Alg: j.Algorithm, Kid: j.KeyID, Use: j.Use}
+
+	switch key := j.Key.(type) {
+	case *rsa.PublicKey:
+		raw.Kty = "RSA"
+		raw.N = newBase64URLEncodedField(key.N.Bytes())
+		raw.E = newBase64URLEncodedField(big.NewInt(int64(key.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		raw.Kty = "EC"
+		raw.Crv = key.Curve.Params().Name
+		raw.X = newBase64URLEncodedField(key.X.FillBytes(make([]byte, size)))
+		raw.Y = newBase64URLEncodedField(key.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		raw.Kty = "OKP"
+		raw.Crv = "Ed25519"
+		raw.X = newBase64URLEncodedField(key)
+	default:
+		return nil, fmt.Errorf("unsupported public key type: %T", j.Key)
+	}
+
+	return raw, nil
+}
+
+// thumbprint returns the base64url encoded SHA-256 JWK thumbprint (RFC 7638).
+func (p *publicJWK) thumbprint() (string, error) {
+	// only the required members; encoding/json sorts the map keys lexicographically
+	members := map[string]interface{}{"kty": p.Kty}
+	switch p.Kty {
+	case "RSA":
+		members["e"], members["n"] = p.E, p.N
+	case "EC":
+		members["crv"], members["x"], members["y"] = p.Crv, p.X, p.Y
+	case "OKP":
+		members["crv"], members["x"] = p.Crv, p.X
+	}
+
+	b, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
 func (j *JWK) UnmarshalJSON(data []byte) error {
 	raw := &rawJWK{}
 	err := json.Unmarshal(data, raw)
@@ -92,6 +187,22 @@ func (j *JWK) UnmarshalJSON(data []byte) error {
 				return nil
 			}
 		}
+	case "OKP":
+		key, err = getPublicKeyFromX5c(raw.X5c)
+		if err != nil {
+			// TODO log warning properly
+			fmt.Printf("Invalid x5c: %v\n", err)
+			return nil
+		}
+
+		if key != nil {
+			jwk.Key = key
+		} else if raw.Crv == "Ed25519" && raw.X != nil && len(raw.X.data) == ed25519.PublicKeySize {
+			jwk.Key = ed25519.PublicKey(raw.X.data)
+		} else {
+			fmt.Printf("Ignoring invalid %s key: %q (invalid crv/x)\n", raw.Kty, raw.Kid)
+			return nil
+		}
 	default:
 		// TODO log warning properly
 		fmt.Printf("Found unsupported %s key: %q\n", raw.Kty, raw.Kid)
@@ -0,0 +1,85 @@
+package jwk_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/coupergateway/couper/accesscontrol/jwk"
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func Test_NewPublicJWK_Thumbprint(t *testing.T) {
+	helper := test.New(t)
+
+	// RFC 7638, section 3.1
+	rfcKey := &jwk.JWK{}
+	helper.Must(json.Unmarshal([]byte(`{
+		"kty": "RSA",
+		"n": "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		"e": "AQAB",
+		"alg": "RS256",
+		"kid": "2011-04-29"
+	}`), rfcKey))
+
+	pub, err := jwk.NewPublicJWK(rfcKey.Key, "RS256", "")
+	helper.Must(err)
+	if exp := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; pub.KeyID != exp {
+		t.Errorf("want kid %q, got: %q", exp, pub.KeyID)
+	}
+}
+
+func Test_NewPublicJWK_Marshal(t *testing.T) {
+	helper := test.New(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	helper.Must(err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	helper.Must(err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	helper.Must(err)
+
+	for _, tc := range []struct {
+		name string
+		alg  string
+		key  interface{}
+		kty  string
+	}{
+		{"RSA", "RS256", rsaKey, "RSA"},
+		{"ECDSA", "ES512", ecKey, "EC"},
+		{"Ed25519", "EdDSA", edKey, "OKP"},
+		{"RSA public key", "RS256", &rsaKey.PublicKey, "RSA"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			h := test.New(subT)
+
+			pub, perr := jwk.NewPublicJWK(tc.key, tc.alg, "")
+			h.Must(perr)
+
+			b, merr := json.Marshal(pub)
+			h.Must(merr)
+
+			var members map[string]interface{}
+			h.Must(json.Unmarshal(b, &members))
+			for _, private := range []string{"d", "p", "q", "dp", "dq", "qi"} {
+				if _, exists := members[private]; exists {
+					subT.Errorf("unexpected private member %q", private)
+				}
+			}
+			if members["kty"] != tc.kty || members["alg"] != tc.alg || members["use"] != "sig" || members["kid"] != pub.KeyID {
+				subT.Errorf("unexpected members: %v", members)
+			}
+
+			parsed := &jwk.JWK{}
+			h.Must(json.Unmarshal(b, parsed))
+			if !reflect.DeepEqual(parsed.Key, pub.Key) {
+				subT.Errorf("want key %#v, got: %#v", pub.Key, parsed.Key)
+			}
+		})
+	}
+}
@@ -141,6 +141,12 @@ func Test_JWKS_GetKey(t *testing.T) {
 		{"missing x", "testdata/jwks_ecdsa.json", "missing-x", "ES256", "sig", false},
 		{"missing y", "testdata/jwks_ecdsa.json", "missing-y", "ES256", "sig", false},
 		{"ok", "testdata/jwks_ecdsa.json", "ok", "ES256", "sig", true},
+		{"no_alg: key for PS256", "testdata/jwks_no_alg.json", "kid1", "PS256", "sig", true},
+		{"okp", "testdata/jwks_okp.json", "ok", "EdDSA", "sig", true},
+		{"okp: key for empty alg", "testdata/jwks_okp.json", "no-alg", "EdDSA", "sig", true},
+		{"okp: no key for ES256", "testdata/jwks_okp.json", "no-alg", "ES256", "sig", false},
+		{"okp: invalid crv", "testdata/jwks_okp.json", "invalid-crv", "EdDSA", "sig", false},
+		{"okp: missing x", "testdata/jwks_okp.json", "missing-x", "EdDSA", "sig", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(subT *testing.T) {
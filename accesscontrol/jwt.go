@@ -3,6 +3,7 @@ package accesscontrol
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -17,10 +18,12 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/sirupsen/logrus"
 
+	"github.com/coupergateway/couper/accesscontrol/jwe"
 	"github.com/coupergateway/couper/accesscontrol/jwk"
 	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
 	"github.com/coupergateway/couper/cache"
 	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/reader"
 	"github.com/coupergateway/couper/config/request"
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/eval"
@@ -36,6 +39,7 @@ type JWT struct {
 	algos                 []string
 	claims                hcl.Expression
 	claimsRequired        []string
+	decryptionKey         interface{}
 	disablePrivateCaching bool
 	source                TokenSource
 	hmacSecret            []byte
@@ -69,7 +73,7 @@ func NewJWT(jwtConf *config.JWT, introspector *Introspector, key []byte, memStor
 		return jwtAC, nil
 	}
 
-	pubKey, err := parsePublicPEMKey(key)
+	pubKey, err := ParsePublicPEMKey(key)
 	if err != nil {
 		return nil, err
 	}
@@ -78,9 +82,9 @@ func NewJWT(jwtConf *config.JWT, introspector *Introspector, key []byte, memStor
 	return jwtAC, nil
 }
 
-// parsePublicPEMKey tries to parse all supported publicKey variations which
+// ParsePublicPEMKey tries to parse all supported publicKey variations which
 // must be given in PEM encoded format.
-func parsePublicPEMKey(key []byte) (pub interface{}, err error) {
+func ParsePublicPEMKey(key []byte) (pub interface{}, err error) {
 	pemBlock, _ := pem.Decode(key)
 	if pemBlock == nil {
 		return nil, jwt.ErrKeyMustBePEMEncoded
@@ -99,8 +103,11 @@ func parsePublicPEMKey(key []byte) (pub interface{}, err error) {
 			if k, ok := cert.PublicKey.(*ecdsa.PublicKey); ok {
 				return k, nil
 			}
+			if k, ok := cert.PublicKey.(ed25519.PublicKey); ok {
+				return k, nil
+			}
 
-			return nil, fmt.Errorf("invalid RSA/ECDSA public key")
+			return nil, fmt.Errorf("invalid RSA/ECDSA/Ed25519 public key")
 		}
 
 		if k, ok := pkixKey.(*rsa.PublicKey); ok {
@@ -111,7 +118,11 @@ func parsePublicPEMKey(key []byte) (pub interface{}, err error) {
 			return k, nil
 		}
 
-		return nil, fmt.Errorf("invalid RSA/ECDSA public key")
+		if k, ok := pkixKey.(ed25519.PublicKey); ok {
+			return k, nil
+		}
+
+		return nil, fmt.Errorf("invalid RSA/ECDSA/Ed25519 public key")
 	}
 	return pubKey, nil
 }
@@ -126,9 +137,8 @@ func NewJWTFromJWKS(jwtConf *config.JWT, introspector *Introspector, jwks *jwk.J
 		return nil, err
 	}
 
-	algorithms := append(acjwt.RSAAlgorithms, acjwt.ECDSAlgorithms...)
 	var algos []string
-	for _, a := range algorithms {
+	for _, a := range acjwt.AsymmetricAlgorithms() {
 		algos = append(algos, a.String())
 	}
 
@@ -186,9 +196,21 @@ func newJWT(jwtConf *config.JWT, introspector *Introspector, memStore *cache.Mem
 		return nil, fmt.Errorf("missing roles_map")
 	}
 
+	var decryptionKey interface{}
+	if jwtConf.DecryptionKey != "" || jwtConf.DecryptionKeyFile != "" {
+		keyBytes, rerr := reader.ReadFromAttrFile("jwt decryption key", jwtConf.DecryptionKey, jwtConf.DecryptionKeyFile)
+		if rerr != nil {
+			return nil, rerr
+		}
+		if decryptionKey, err = jwe.ParseDecryptionKey(keyBytes); err != nil {
+			return nil, err
+		}
+	}
+
 	jwtAC := &JWT{
 		claims:                jwtConf.Claims,
 		claimsRequired:        jwtConf.ClaimsRequired,
+		decryptionKey:         decryptionKey,
 		disablePrivateCaching: jwtConf.DisablePrivateCaching,
 		introspector:          introspector,
 		memStore:              memStore,
@@ -251,8 +273,13 @@ func (j *JWT) Validate(req *http.Request) error {
 		j.jwks.Data()
 	}
 
+	signedToken, err := j.decrypt(tokenValue)
+	if err != nil {
+		return err
+	}
+
 	tokenClaims := jwt.MapClaims{}
-	if err = j.parse(parser, tokenValue, tokenClaims); err != nil {
+	if err = j.parse(parser, signedToken, tokenClaims); err != nil {
 		return err
 	}
 
@@ -269,7 +296,8 @@ func (j *JWT) Validate(req *http.Request) error {
 	if j.introspector != nil {
 		exp, _ := tokenClaims["exp"].(float64)
 		nbf, _ := tokenClaims["nbf"].(float64)
-		introspectionResponse, err := j.introspector.Introspect(ctx, tokenValue, int64(exp), int64(nbf))
+		iss, _ := tokenClaims["iss"].(string)
+		introspectionResponse, err := j.introspector.Introspect(ctx, tokenValue, iss, int64(exp), int64(nbf))
 		if err != nil {
 			return err
 		}
@@ -301,6 +329,20 @@ func (j *JWT) Validate(req *http.Request) error {
 	return nil
 }
 
+// decrypt returns the nested signed token of an encrypted token (JWE). Signed tokens are
+// returned unchanged, the signature must be validated in both cases.
+func (j *JWT) decrypt(tokenValue string) (string, error) {
+	if j.decryptionKey == nil || !jwe.IsJWE(tokenValue) {
+		return tokenValue, nil
+	}
+
+	payload, _, err := jwe.Decrypt(tokenValue, j.decryptionKey)
+	if err != nil {
+		return "", errors.JwtTokenInvalid.With(err)
+	}
+	return string(payload), nil
+}
+
 func (j *JWT) parse(parser *jwt.Parser, tokenValue string, tokenClaims jwt.MapClaims) error {
 	_, err := parser.ParseWithClaims(tokenValue, tokenClaims, j.getValidationKey)
 	if err != nil {
@@ -16,10 +16,25 @@ const (
 	AlgorithmECDSA256
 	AlgorithmECDSA384
 	AlgorithmECDSA512
+	AlgorithmRSAPSS256
+	AlgorithmRSAPSS384
+	AlgorithmRSAPSS512
+	AlgorithmEdDSA
 )
 
 var RSAAlgorithms = []Algorithm{AlgorithmRSA256, AlgorithmRSA384, AlgorithmRSA512}
 var ECDSAlgorithms = []Algorithm{AlgorithmECDSA256, AlgorithmECDSA384, AlgorithmECDSA512}
+var RSAPSSAlgorithms = []Algorithm{AlgorithmRSAPSS256, AlgorithmRSAPSS384, AlgorithmRSAPSS512}
+var EdDSAAlgorithms = []Algorithm{AlgorithmEdDSA}
+
+// AsymmetricAlgorithms returns all supported asymmetric signature algorithms.
+func AsymmetricAlgorithms() []Algorithm {
+	var algorithms []Algorithm
+	algorithms = append(algorithms, RSAAlgorithms...)
+	algorithms = append(algorithms, ECDSAlgorithms...)
+	algorithms = append(algorithms, RSAPSSAlgorithms...)
+	return append(algorithms, EdDSAAlgorithms...)
+}
 
 func NewAlgorithm(a string) Algorithm {
 	switch a {
@@ -41,6 +56,14 @@ func NewAlgorithm(a string) Algorithm {
 		return AlgorithmECDSA384
 	case "ES512":
 		return AlgorithmECDSA512
+	case "PS256":
+		return AlgorithmRSAPSS256
+	case "PS384":
+		return AlgorithmRSAPSS384
+	case "PS512":
+		return AlgorithmRSAPSS512
+	case "EdDSA":
+		return AlgorithmEdDSA
 	default:
 		return AlgorithmUnknown
 	}
@@ -55,6 +78,17 @@ func (a Algorithm) IsHMAC() bool {
 	}
 }
 
+// IsRSA returns true for the RS* and PS* algorithms.
+func (a Algorithm) IsRSA() bool {
+	switch a {
+	case AlgorithmRSA256, AlgorithmRSA384, AlgorithmRSA512,
+		AlgorithmRSAPSS256, AlgorithmRSAPSS384, AlgorithmRSAPSS512:
+		return true
+	default:
+		return false
+	}
+}
+
 func (a Algorithm) String() string {
 	switch a {
 	case AlgorithmRSA256:
@@ -75,6 +109,14 @@ func (a Algorithm) String() string {
 		return "ES384"
 	case AlgorithmECDSA512:
 		return "ES512"
+	case AlgorithmRSAPSS256:
+		return "PS256"
+	case AlgorithmRSAPSS384:
+		return "PS384"
+	case AlgorithmRSAPSS512:
+		return "PS512"
+	case AlgorithmEdDSA:
+		return "EdDSA"
 	default:
 		return "Unknown"
 	}
@@ -2,6 +2,7 @@ package accesscontrol_test
 
 import (
 	"context"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
@@ -25,6 +26,7 @@ import (
 	"github.com/zclconf/go-cty/cty"
 
 	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/accesscontrol/jwe"
 	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
 	"github.com/coupergateway/couper/cache"
 	"github.com/coupergateway/couper/config"
@@ -172,6 +174,8 @@ func Test_JWT_Validate(t *testing.T) {
 	for _, signingMethod := range []jwt.SigningMethod{
 		jwt.SigningMethodRS256, jwt.SigningMethodRS384, jwt.SigningMethodRS512,
 		jwt.SigningMethodHS256, jwt.SigningMethodHS384, jwt.SigningMethodHS512,
+		jwt.SigningMethodPS256, jwt.SigningMethodPS384, jwt.SigningMethodPS512,
+		jwt.SigningMethodEdDSA,
 	} {
 
 		pubKeyBytes, privKey := newRSAKeyPair()
@@ -188,6 +192,10 @@ func Test_JWT_Validate(t *testing.T) {
 		if algo.IsHMAC() {
 			pubKeyBytes = []byte("mySecretK3y")
 			token, tokenErr = tok.SignedString(pubKeyBytes)
+		} else if algo == acjwt.AlgorithmEdDSA {
+			var edPrivKey ed25519.PrivateKey
+			pubKeyBytes, edPrivKey = newEd25519KeyPair()
+			token, tokenErr = tok.SignedString(edPrivKey)
 		} else {
 			token, tokenErr = tok.SignedString(privKey)
 		}
@@ -370,6 +378,76 @@ func Test_JWT_Validate(t *testing.T) {
 	}
 }
 
+func Test_JWT_Validate_JWE(t *testing.T) {
+	log, _ := test.NewLogger()
+	tmpStoreCh := make(chan struct{})
+	defer close(tmpStoreCh)
+	memStore := cache.New(log.WithContext(context.Background()), tmpStoreCh)
+	helper := test.New(t)
+
+	pubKeyBytes, privKey := newRSAKeyPair()
+	encKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	helper.Must(err)
+	encKeyBytes := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(encKey),
+	})
+
+	j, err := ac.NewJWT(&config.JWT{
+		Bearer:             true,
+		DecryptionKey:      string(encKeyBytes),
+		Name:               "test_ac",
+		SignatureAlgorithm: "RS256",
+	}, nil, pubKeyBytes, memStore)
+	helper.Must(err)
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "partner"}).SignedString(privKey)
+	helper.Must(err)
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"sub": "partner"}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	helper.Must(err)
+
+	encrypt := func(token string, key *rsa.PublicKey) string {
+		encrypted, eerr := jwe.Encrypt([]byte(token), jwe.AlgorithmRSAOAEP256, jwe.EncryptionA256GCM, key, map[string]interface{}{"cty": "JWT"})
+		helper.Must(eerr)
+		return encrypted
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	helper.Must(err)
+
+	for _, tc := range []struct {
+		name        string
+		token       string
+		wantErrKind string
+	}{
+		{"nested JWE", encrypt(signed, &encKey.PublicKey), ""},
+		{"signed token", signed, ""},
+		{"nested unsigned token", encrypt(unsigned, &encKey.PublicKey), "jwt_token_invalid"},
+		{"foreign recipient", encrypt(signed, &otherKey.PublicKey), "jwt_token_invalid"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			req = req.WithContext(context.WithValue(context.Background(), request.LogEntry, log.WithContext(context.Background())))
+
+			errKind := ""
+			if verr := j.Validate(req); verr != nil {
+				errKind = verr.(*errors.Error).Kinds()[0]
+			}
+			if errKind != tc.wantErrKind {
+				subT.Errorf("Validate() error kind does not match; want: %q, got: %q", tc.wantErrKind, errKind)
+			}
+
+			if tc.wantErrKind == "" {
+				acMap := req.Context().Value(request.AccessControls).(map[string]interface{})
+				if sub := acMap["test_ac"].(map[string]interface{})["sub"]; sub != "partner" {
+					subT.Errorf("expected sub claim, got: %v", sub)
+				}
+			}
+		})
+	}
+}
+
 func Test_JWT_Validate_claims(t *testing.T) {
 	log, _ := test.NewLogger()
 	tmpStoreCh := make(chan struct{})
@@ -628,6 +706,82 @@ mwIDAQAB
 	}
 }
 
+func Test_JWT_DPoP_EdDSA(t *testing.T) {
+	log, _ := test.NewLogger()
+	tmpStoreCh := make(chan struct{})
+	defer close(tmpStoreCh)
+	memStore := cache.New(log.WithContext(context.Background()), tmpStoreCh)
+	h := test.New(t)
+
+	secret := []byte("mySecretK3y")
+	jwtAC, err := ac.NewJWT(&config.JWT{
+		Dpop:               true,
+		SignatureAlgorithm: "HS256",
+	}, nil, secret, memStore)
+	h.Must(err)
+
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	h.Must(err)
+	_, otherPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	h.Must(err)
+
+	newJWK := func() map[string]interface{} {
+		return map[string]interface{}{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"x":   base64.RawURLEncoding.EncodeToString(pubKey),
+		}
+	}
+	jkt := ac.JwkToJKT(newJWK())
+
+	for _, tc := range []struct {
+		name      string
+		signKey   ed25519.PrivateKey
+		expErrMsg string
+	}{
+		{"ok", privKey, ""},
+		{"foreign key", otherPrivKey, "access control error: DPoP proof parse error: token signature is invalid: ed25519: verification error"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			helper := test.New(subT)
+
+			req, err := http.NewRequest(http.MethodGet, "/foo", nil)
+			helper.Must(err)
+			req = req.WithContext(context.WithValue(context.Background(), request.LogEntry, log.WithContext(context.Background())))
+
+			at := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"cnf": map[string]interface{}{"jkt": jkt},
+			})
+			accessToken, err := at.SignedString(secret)
+			helper.Must(err)
+			hash := sha256.Sum256([]byte(accessToken))
+			req.Header.Set("Authorization", "DPoP "+accessToken)
+
+			p := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+				"ath": base64.RawURLEncoding.EncodeToString(hash[:]),
+				"htm": req.Method,
+				"htu": req.URL.String(),
+				"iat": time.Now().Unix(),
+				"jti": "some_id",
+			})
+			p.Header["jwk"] = newJWK()
+			p.Header["typ"] = ac.DpopTyp
+			proof, err := p.SignedString(tc.signKey)
+			helper.Must(err)
+			req.Header.Set("DPoP", proof)
+
+			err = jwtAC.Validate(req)
+			var msg string
+			if err != nil {
+				msg = err.(errors.GoError).LogError()
+			}
+			if msg != tc.expErrMsg {
+				subT.Errorf("expected error message: %q, got: %q", tc.expErrMsg, msg)
+			}
+		})
+	}
+}
+
 func Test_JWT_yields_permissions(t *testing.T) {
 	log, hook := test.NewLogger()
 	tmpStoreCh := make(chan struct{})
@@ -1367,6 +1521,24 @@ func newRSAKeyPair() (pubKeyBytes []byte, privKey *rsa.PrivateKey) {
 	return
 }
 
+func newEd25519KeyPair() (pubKeyBytes []byte, privKey ed25519.PrivateKey) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	pkix, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		panic(err)
+	}
+
+	pubKeyBytes = pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: pkix,
+	})
+	return
+}
+
 func setCookieAndHeader(req *http.Request, key, value string) *http.Request {
 	req.Header.Set(key, value)
 	req.Header.Set("Cookie", key+"="+value)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/coupergateway/couper/accesscontrol/session"
 	"github.com/coupergateway/couper/config/request"
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/oauth2"
@@ -11,6 +12,12 @@ import (
 
 var _ AccessControl = &OAuth2Callback{}
 
+// sessionTokenClient is implemented by clients which keep their tokens with a session.
+type sessionTokenClient interface {
+	SessionName() string
+	NewSessionToken(tokenResponseData map[string]interface{}) *session.Token
+}
+
 // OAuth2Callback represents the access control for the OAuth2 authorization code flow callback.
 type OAuth2Callback struct {
 	oauth2Client oauth2.AuthCodeFlowClient
@@ -43,6 +50,11 @@ func (oa *OAuth2Callback) Validate(req *http.Request) error {
 	}
 	acMap[oa.name] = tokenResponseData
 	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+
+	// stored by a session_write() call in the callback endpoint
+	if sc, ok := oa.oauth2Client.(sessionTokenClient); ok && sc.SessionName() != "" {
+		ctx = session.WithToken(ctx, sc.SessionName(), oa.name, sc.NewSessionToken(tokenResponseData))
+	}
 	*req = *req.WithContext(ctx)
 
 	return nil
@@ -2,23 +2,78 @@ package accesscontrol
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
 
 	"github.com/coupergateway/couper/config/request"
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/eval"
 	"github.com/coupergateway/couper/handler/middleware"
-	"github.com/coupergateway/couper/internal/seetie"
 )
 
+// ruleKeys are the attributes of a permission rule object. An object with
+// other keys is a permission map with request methods as keys.
+var ruleKeys = map[string]bool{
+	"all_of":    true,
+	"any_of":    true,
+	"condition": true,
+	"scopes":    true,
+}
+
+// permissionRule describes the permissions, scopes and condition required for a request.
+// A nil rule requires nothing.
+type permissionRule struct {
+	allOf     []string
+	anyOf     []string
+	condition *bool
+	scopes    []string
+}
+
+// newPermissionRule creates a rule requiring the given permission, or nil for an empty one.
+func newPermissionRule(permission string) *permissionRule {
+	if permission == "" {
+		return nil
+	}
+	return &permissionRule{allOf: []string{permission}}
+}
+
+// contextValue returns the rule as provided by request.context.required_permission: the permission
+// if exactly one is required, the list of permissions if all of them are required, otherwise an object
+// with the configured rule attributes.
+func (r *permissionRule) contextValue() interface{} {
+	if len(r.anyOf) == 0 && len(r.scopes) == 0 && r.condition == nil {
+		if len(r.allOf) == 1 {
+			return r.allOf[0]
+		}
+		return r.allOf
+	}
+
+	value := make(map[string]interface{})
+	if len(r.allOf) > 0 {
+		value["all_of"] = r.allOf
+	}
+	if len(r.anyOf) > 0 {
+		value["any_of"] = r.anyOf
+	}
+	if len(r.scopes) > 0 {
+		value["scopes"] = r.scopes
+	}
+	if r.condition != nil {
+		value["condition"] = *r.condition
+	}
+	return value
+}
+
 type requiredPermissions struct {
-	permission  string
-	permissions map[string]string // permission per method
+	permission  *permissionRule
+	permissions map[string]*permissionRule // permission per method
 }
 
-func newRequiredPermissions(permission string, permissionMap map[string]string) requiredPermissions {
+func newRequiredPermissions(permission *permissionRule, permissionMap map[string]*permissionRule) requiredPermissions {
 	rp := requiredPermissions{}
 	if permissionMap == nil {
 		rp.permission = permission
@@ -29,8 +84,8 @@ func newRequiredPermissions(permission string, permissionMap map[string]string)
 	return rp
 }
 
-func (r *requiredPermissions) setPermissionMap(permissionMap map[string]string) {
-	r.permissions = make(map[string]string)
+func (r *requiredPermissions) setPermissionMap(permissionMap map[string]*permissionRule) {
+	r.permissions = make(map[string]*permissionRule)
 	if otherPermission, otherMethodExists := permissionMap["*"]; otherMethodExists {
 		for _, method := range middleware.DefaultEndpointAllowedMethods {
 			r.permissions[method] = otherPermission
@@ -44,18 +99,131 @@ func (r *requiredPermissions) setPermissionMap(permissionMap map[string]string)
 	}
 }
 
-func (r *requiredPermissions) getPermission(method string) (string, error) {
+func (r *requiredPermissions) getPermission(method string) (*permissionRule, error) {
 	if r.permissions == nil {
 		return r.permission, nil
 	}
 
 	permission, exists := r.permissions[method]
 	if !exists {
-		return "", errors.MethodNotAllowed.Messagef("method %s not allowed by required_permission", method)
+		return nil, errors.MethodNotAllowed.Messagef("method %s not allowed by required_permission", method)
 	}
 	return permission, nil
 }
 
+// valueToRequiredPermissions converts the evaluated required_permission value: a string,
+// a list of strings, a rule object or an object with request methods as keys and one of
+// the former as values.
+func valueToRequiredPermissions(val cty.Value) (requiredPermissions, error) {
+	if val.Type() == cty.NilType {
+		return requiredPermissions{}, nil
+	}
+
+	if !val.Type().IsObjectType() && !val.Type().IsMapType() || isRuleObject(val) {
+		rule, err := valueToPermissionRule(val)
+		if err != nil {
+			return requiredPermissions{}, fmt.Errorf("unsupported value for required_permission: %w", err)
+		}
+		return newRequiredPermissions(rule, nil), nil
+	}
+
+	permissionMap := make(map[string]*permissionRule)
+	for k, v := range val.AsValueMap() {
+		if (v.Type().IsObjectType() || v.Type().IsMapType()) && !isRuleObject(v) {
+			return requiredPermissions{}, fmt.Errorf("unsupported value for method %q in required_permission", k)
+		}
+		rule, err := valueToPermissionRule(v)
+		if err != nil {
+			return requiredPermissions{}, fmt.Errorf("unsupported value for method %q in required_permission: %w", k, err)
+		}
+		permissionMap[strings.ToUpper(k)] = rule
+	}
+	return newRequiredPermissions(nil, permissionMap), nil
+}
+
+func isRuleObject(val cty.Value) bool {
+	if val.IsNull() || !val.Type().IsObjectType() && !val.Type().IsMapType() {
+		return false
+	}
+	for k := range val.AsValueMap() {
+		if ruleKeys[k] {
+			return true
+		}
+	}
+	return false
+}
+
+func valueToPermissionRule(val cty.Value) (*permissionRule, error) {
+	if val.IsNull() {
+		return nil, fmt.Errorf("null value")
+	}
+
+	switch t := val.Type(); {
+	case t == cty.String:
+		return newPermissionRule(val.AsString()), nil
+	case t.IsTupleType() || t.IsListType():
+		list, err := valueToStringList(val)
+		if err != nil {
+			return nil, err
+		}
+		if len(list) == 0 {
+			return nil, nil
+		}
+		return &permissionRule{allOf: list}, nil
+	case t.IsObjectType() || t.IsMapType():
+		rule := &permissionRule{}
+		for k, v := range val.AsValueMap() {
+			var err error
+			switch k {
+			case "all_of":
+				rule.allOf, err = valueToStringList(v)
+			case "any_of":
+				rule.anyOf, err = valueToStringList(v)
+			case "scopes":
+				rule.scopes, err = valueToStringList(v)
+			case "condition":
+				if v.IsNull() {
+					// e.g. a comparison with a missing claim
+					met := false
+					rule.condition = &met
+				} else if v.Type() != cty.Bool {
+					err = fmt.Errorf("condition must be a boolean")
+				} else {
+					met := v.True()
+					rule.condition = &met
+				}
+			default:
+				err = fmt.Errorf("unsupported rule attribute %q", k)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return rule, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", val.Type().FriendlyName())
+}
+
+func valueToStringList(val cty.Value) ([]string, error) {
+	if val.IsNull() {
+		return nil, nil
+	}
+	if !val.Type().IsTupleType() && !val.Type().IsListType() {
+		return nil, fmt.Errorf("expected a list of strings")
+	}
+
+	var list []string
+	for _, v := range val.AsValueSlice() {
+		if v.IsNull() || v.Type() != cty.String {
+			return nil, fmt.Errorf("expected a list of strings")
+		}
+		if s := v.AsString(); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list, nil
+}
+
 var _ AccessControl = &PermissionsControl{}
 
 type PermissionsControl struct {
@@ -77,34 +245,52 @@ func (p *PermissionsControl) Validate(req *http.Request) error {
 		return errors.Evaluation.With(err)
 	}
 
-	permission, permissionMap, err := seetie.ValueToPermission(permissionVal)
+	rp, err := valueToRequiredPermissions(permissionVal)
 	if err != nil {
 		return errors.Evaluation.With(err)
 	}
 
-	rp := newRequiredPermissions(permission, permissionMap)
-	requiredPermission, err := rp.getPermission(req.Method)
+	rule, err := rp.getPermission(req.Method)
 	if err != nil {
 		return err
 	}
 
-	if requiredPermission == "" {
+	if rule == nil {
 		return nil
 	}
 
-	ctx := req.Context()
-	ctx = context.WithValue(ctx, request.RequiredPermission, requiredPermission)
+	ctx := context.WithValue(req.Context(), request.RequiredPermission, rule.contextValue())
 	*req = *req.WithContext(ctx)
 
 	evalCtx := eval.ContextFromRequest(req)
 	*req = *req.WithContext(evalCtx.WithClientRequest(req))
 
-	grantedPermission, ok := ctx.Value(request.GrantedPermissions).([]string)
-	if !ok {
-		return errors.InsufficientPermissions.Messagef("no permissions granted")
+	if len(rule.allOf) > 0 || len(rule.anyOf) > 0 {
+		grantedPermission, ok := ctx.Value(request.GrantedPermissions).([]string)
+		if !ok {
+			return errors.InsufficientPermissions.Messagef("no permissions granted")
+		}
+		for _, requiredPermission := range rule.allOf {
+			if !hasGrantedPermission(grantedPermission, requiredPermission) {
+				return errors.InsufficientPermissions.Messagef("required permission %q not granted", requiredPermission)
+			}
+		}
+		if len(rule.anyOf) > 0 && !hasAnyGrantedPermission(grantedPermission, rule.anyOf) {
+			return errors.InsufficientPermissions.Messagef("none of the required permissions %q granted", rule.anyOf)
+		}
 	}
-	if !hasGrantedPermission(grantedPermission, requiredPermission) {
-		return errors.InsufficientPermissions.Messagef("required permission %q not granted", requiredPermission)
+
+	if len(rule.scopes) > 0 {
+		grantedScopes := getGrantedScopes(ctx)
+		for _, scope := range rule.scopes {
+			if !hasGrantedPermission(grantedScopes, scope) {
+				return errors.InsufficientPermissions.Messagef("required scope %q not granted", scope)
+			}
+		}
+	}
+
+	if rule.condition != nil && !*rule.condition {
+		return errors.InsufficientPermissions.Message("permission condition not met")
 	}
 	return nil
 }
@@ -118,3 +304,49 @@ func hasGrantedPermission(grantedPermissions []string, permission string) bool {
 	}
 	return false
 }
+
+// hasAnyGrantedPermission checks whether at least one of the given permissions is in the granted permissions
+func hasAnyGrantedPermission(grantedPermissions []string, permissions []string) bool {
+	for _, p := range permissions {
+		if hasGrantedPermission(grantedPermissions, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// getGrantedScopes collects the OAuth2 scopes from the scope (space-delimited string)
+// and scp (list) properties provided by access controls, e.g. JWT claims.
+func getGrantedScopes(ctx context.Context) []string {
+	acMap, _ := ctx.Value(request.AccessControls).(map[string]interface{})
+
+	var scopes []string
+	for _, data := range acMap {
+		dataMap, ok := data.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if scope, ok := dataMap["scope"].(string); ok {
+			for _, s := range strings.Fields(scope) {
+				scopes, _ = addPermission(scopes, s)
+			}
+		}
+		switch scp := dataMap["scp"].(type) {
+		case string:
+			for _, s := range strings.Fields(scp) {
+				scopes, _ = addPermission(scopes, s)
+			}
+		case []string:
+			for _, s := range scp {
+				scopes, _ = addPermission(scopes, s)
+			}
+		case []interface{}:
+			for _, v := range scp {
+				if s, ok := v.(string); ok {
+					scopes, _ = addPermission(scopes, s)
+				}
+			}
+		}
+	}
+	return scopes
+}
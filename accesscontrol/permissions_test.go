@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/hashicorp/hcl/v2"
@@ -55,7 +56,14 @@ func Test_requiredPermissions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(subT *testing.T) {
-			rp := newRequiredPermissions(tt.rp, tt.rpm)
+			var rpm map[string]*permissionRule
+			if tt.rpm != nil {
+				rpm = make(map[string]*permissionRule)
+				for method, permission := range tt.rpm {
+					rpm[method] = newPermissionRule(permission)
+				}
+			}
+			rp := newRequiredPermissions(newPermissionRule(tt.rp), rpm)
 			if tt.want == nil {
 				if rp.permissions != nil {
 					subT.Errorf("expected permissions to be nil: %#v", rp.permissions)
@@ -72,7 +80,7 @@ func Test_requiredPermissions(t *testing.T) {
 					subT.Errorf("no permission for method %q", method)
 					return
 				}
-				if permission != wantPermission {
+				if !reflect.DeepEqual(permission, newPermissionRule(wantPermission)) {
 					subT.Errorf("unexpected permission for %q: %#v, want: %#v", method, permission, wantPermission)
 					return
 				}
@@ -280,3 +288,83 @@ func Test_PermissionsControl(t *testing.T) {
 		})
 	}
 }
+
+func Test_PermissionsControl_Rules(t *testing.T) {
+	tests := []struct {
+		name               string
+		expr               string
+		method             string
+		grantedPermissions []string
+		acData             map[string]interface{}
+		wantErrorString    string
+	}{
+		{"all of, granted", `["read", "write"]`, http.MethodGet, []string{"write", "read"}, nil, ""},
+		{"all of, missing", `{ all_of = ["read", "write"] }`, http.MethodGet, []string{"read"}, nil,
+			`access control error: required permission "write" not granted`},
+		{"all of, no permissions granted", `{ all_of = ["read"] }`, http.MethodGet, nil, nil,
+			"access control error: no permissions granted"},
+		{"any of, granted", `{ any_of = ["admin", "write"] }`, http.MethodGet, []string{"read", "write"}, nil, ""},
+		{"any of, missing", `{ any_of = ["admin", "write"] }`, http.MethodGet, []string{"read"}, nil,
+			`access control error: none of the required permissions ["admin" "write"] granted`},
+		{"all of and any of", `{ all_of = ["read"], any_of = ["admin", "write"] }`, http.MethodGet, []string{"write"}, nil,
+			`access control error: required permission "read" not granted`},
+		{"scopes, granted by scope claim", `{ scopes = ["orders:read"] }`, http.MethodGet, nil,
+			map[string]interface{}{"token": map[string]interface{}{"scope": "profile orders:read"}}, ""},
+		{"scopes, granted by scp claim", `{ scopes = ["orders:read", "profile"] }`, http.MethodGet, nil,
+			map[string]interface{}{"token": map[string]interface{}{"scp": []interface{}{"orders:read", "profile"}}}, ""},
+		{"scopes, missing", `{ scopes = ["orders:write"] }`, http.MethodGet, nil,
+			map[string]interface{}{"token": map[string]interface{}{"scope": "orders:read"}},
+			`access control error: required scope "orders:write" not granted`},
+		{"scopes, no access control data", `{ scopes = ["orders:read"] }`, http.MethodGet, nil, nil,
+			`access control error: required scope "orders:read" not granted`},
+		{"condition met", `{ condition = "a" == "a" }`, http.MethodGet, nil, nil, ""},
+		{"condition not met", `{ condition = "a" == "b" }`, http.MethodGet, nil, nil,
+			"access control error: permission condition not met"},
+		{"condition not met, permission granted", `{ all_of = ["read"], condition = false }`, http.MethodGet, []string{"read"}, nil,
+			"access control error: permission condition not met"},
+		{"method map with rules", `{ get = "read", post = { any_of = ["write", "admin"] } }`, http.MethodPost, []string{"admin"}, nil, ""},
+		{"method map with rules, missing", `{ get = "read", post = ["write", "admin"] }`, http.MethodPost, []string{"admin"}, nil,
+			`access control error: required permission "write" not granted`},
+		{"method map with rules, method not allowed", `{ get = "read", post = ["write"] }`, http.MethodPut, nil, nil,
+			"method not allowed error: method PUT not allowed by required_permission"},
+		{"unsupported rule attribute", `{ all_of = ["read"], none_of = ["write"] }`, http.MethodGet, nil, nil,
+			`expression evaluation error: unsupported value for required_permission: unsupported rule attribute "none_of"`},
+		{"unsupported condition", `{ condition = "yes" }`, http.MethodGet, nil, nil,
+			"expression evaluation error: unsupported value for required_permission: condition must be a boolean"},
+		{"nested method map", `{ get = { post = "write" } }`, http.MethodGet, nil, nil,
+			`expression evaluation error: unsupported value for method "get" in required_permission`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(tt.expr), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				subT.Fatal(diags)
+			}
+
+			req := httptest.NewRequest(tt.method, "/", nil)
+			ctx := req.Context()
+			if tt.grantedPermissions != nil {
+				ctx = context.WithValue(ctx, request.GrantedPermissions, tt.grantedPermissions)
+			}
+			if tt.acData != nil {
+				ctx = context.WithValue(ctx, request.AccessControls, tt.acData)
+			}
+			*req = *req.WithContext(ctx)
+
+			err := NewPermissionsControl(expr).Validate(req)
+			if tt.wantErrorString == "" {
+				if err != nil {
+					subT.Errorf("no error expected, was: %#q", err.(errors.GoError).LogError())
+				}
+				return
+			}
+			if err == nil {
+				subT.Fatalf("no error thrown, expected: %q", tt.wantErrorString)
+			}
+			if logErr := err.(errors.GoError).LogError(); logErr != tt.wantErrorString {
+				subT.Errorf("unexpected error thrown, expected: %q, was: %q", tt.wantErrorString, logErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package accesscontrol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"github.com/sirupsen/logrus"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/eval/variables"
+	"github.com/coupergateway/couper/resource"
+)
+
+var _ AccessControl = &Policy{}
+
+// Policy evaluates a CEL expression loaded from a policy file against the request
+// variable, including request.context of preceding access controls and the path
+// parameters. The policy file is reloaded on modification.
+type Policy struct {
+	file *resource.WatchedFile
+	name string
+}
+
+// policyUnmarshaller compiles a policy file to a CEL program.
+type policyUnmarshaller struct {
+	env *cel.Env
+}
+
+// NewPolicy creates a new AC-Policy object.
+func NewPolicy(ctx context.Context, name, file string, log *logrus.Entry) (*Policy, error) {
+	if file == "" {
+		return nil, fmt.Errorf("file must not be empty")
+	}
+
+	env, err := cel.NewEnv(
+		cel.Variable(variables.ClientRequest, cel.DynType),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	watchedFile, err := resource.NewWatchedFile(ctx, "policy file", file, &policyUnmarshaller{env: env}, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Policy{file: watchedFile, name: name}, nil
+}
+
+// Validate implements the AccessControl interface.
+func (p *Policy) Validate(req *http.Request) error {
+	input, err := newPolicyInput(req)
+	if err != nil {
+		return errors.BetaPolicy.Label(p.name).With(err)
+	}
+
+	program := p.file.Data().(cel.Program)
+	result, _, err := program.Eval(map[string]interface{}{variables.ClientRequest: input})
+	if err != nil {
+		return errors.BetaPolicy.Label(p.name).With(err)
+	}
+
+	allowed, ok := result.Value().(bool)
+	if !ok {
+		return errors.BetaPolicy.Label(p.name).Messagef("policy must evaluate to bool, got: %s", result.Type())
+	}
+	if !allowed {
+		return errors.BetaPolicyDenied.Label(p.name).Message("policy denied")
+	}
+	return nil
+}
+
+func (u *policyUnmarshaller) Unmarshal(raw []byte) (interface{}, error) {
+	ast, issues := u.env.Compile(string(raw))
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("policy must evaluate to bool, got: %s", ast.OutputType())
+	}
+
+	return u.env.Program(ast)
+}
+
+// newPolicyInput converts the request variable of the client request to plain values.
+func newPolicyInput(req *http.Request) (interface{}, error) {
+	value, exists := eval.ContextFromRequest(req).HCLContextSync().Variables[variables.ClientRequest]
+	if !exists || value.IsNull() {
+		return map[string]interface{}{}, nil
+	}
+
+	raw, err := ctyjson.SimpleJSONValue{Value: value}.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var input interface{}
+	err = json.Unmarshal(raw, &input)
+	return input, err
+}
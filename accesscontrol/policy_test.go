@@ -0,0 +1,146 @@
+package accesscontrol_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/config/request"
+	couperErr "github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/internal/test"
+	"github.com/coupergateway/couper/resource"
+)
+
+func newPolicyRequest(method, role string) *http.Request {
+	req := httptest.NewRequest(method, "/orders", nil)
+	req.Header.Set("X-Tenant", "acme")
+	if role != "" {
+		ctx := context.WithValue(req.Context(), request.AccessControls, map[string]interface{}{
+			"token": map[string]interface{}{"role": role},
+		})
+		req = req.WithContext(ctx)
+	}
+	return req.WithContext(eval.NewDefaultContext().WithClientRequest(req))
+}
+
+func Test_Policy_Validate(t *testing.T) {
+	helper := test.New(t)
+
+	policyFile := filepath.Join(t.TempDir(), "orders.cel")
+	helper.Must(os.WriteFile(policyFile, []byte(`
+// admins may write, everyone else of the tenant may read
+request.headers["x-tenant"] == "acme" &&
+  (request.method == "GET" || request.context.token.role == "admin")
+`), 0600))
+
+	policy, err := ac.NewPolicy(context.Background(), "orders", policyFile, nil)
+	helper.Must(err)
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		role   string
+		expErr *couperErr.Error
+	}{
+		{"read", http.MethodGet, "", nil},
+		{"admin write", http.MethodPost, "admin", nil},
+		{"user write", http.MethodPost, "user", couperErr.BetaPolicyDenied},
+		{"missing context", http.MethodPost, "", couperErr.BetaPolicy},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			err := policy.Validate(newPolicyRequest(tc.method, tc.role))
+			if tc.expErr == nil {
+				if err != nil {
+					st.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+
+			if !couperErr.Equals(err, tc.expErr) {
+				st.Errorf("expected %v, got: %v", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func Test_NewPolicy(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, tc := range []struct {
+		name      string
+		policy    string
+		expErrMsg string
+	}{
+		{"syntax error", `request.method ==`, "Syntax error"},
+		{"no bool", `request.method`, ""},
+		{"bool literal", `true`, ""},
+		{"string literal", `"allow"`, "policy must evaluate to bool, got: string"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			policyFile := filepath.Join(dir, strings.ReplaceAll(tc.name, " ", "_")+".cel")
+			if err := os.WriteFile(policyFile, []byte(tc.policy), 0600); err != nil {
+				st.Fatal(err)
+			}
+
+			_, err := ac.NewPolicy(context.Background(), "test", policyFile, nil)
+			if tc.expErrMsg == "" {
+				if err != nil {
+					st.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expErrMsg) {
+				st.Errorf("expected error containing %q, got: %v", tc.expErrMsg, err)
+			}
+		})
+	}
+}
+
+func Test_Policy_FileReload(t *testing.T) {
+	helper := test.New(t)
+
+	interval := resource.WatchInterval
+	resource.WatchInterval = time.Millisecond * 50
+	defer func() { resource.WatchInterval = interval }()
+
+	policyFile := filepath.Join(t.TempDir(), "policy.cel")
+	helper.Must(os.WriteFile(policyFile, []byte(`request.method == "GET"`), 0600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	policy, err := ac.NewPolicy(ctx, "test", policyFile, nil)
+	helper.Must(err)
+
+	validate := func() error {
+		return policy.Validate(newPolicyRequest(http.MethodPost, ""))
+	}
+
+	if err = validate(); !couperErr.Equals(err, couperErr.BetaPolicyDenied) {
+		t.Fatalf("expected denied request, got: %v", err)
+	}
+
+	helper.Must(os.WriteFile(policyFile, []byte(`request.method in ["GET", "POST"]`), 0600))
+	helper.Must(os.Chtimes(policyFile, time.Now(), time.Now().Add(time.Second)))
+
+	deadline := time.Now().Add(time.Second * 2)
+	for validate() != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected reloaded policy file")
+		}
+		time.Sleep(time.Millisecond * 20)
+	}
+
+	// an invalid file keeps the previous policy
+	helper.Must(os.WriteFile(policyFile, []byte(`request.method in`), 0600))
+	helper.Must(os.Chtimes(policyFile, time.Now(), time.Now().Add(time.Second*2)))
+	time.Sleep(time.Millisecond * 200)
+	helper.Must(validate())
+}
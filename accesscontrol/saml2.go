@@ -1,15 +1,17 @@
 package accesscontrol
 
 import (
+	"bytes"
 	"context"
-	"crypto/x509"
+	"crypto/tls"
 	"encoding/base64"
+	"encoding/xml"
 	"fmt"
 	"net/http"
+	"net/url"
 	"sort"
 
 	saml2 "github.com/russellhaering/gosaml2"
-	dsig "github.com/russellhaering/goxmldsig"
 
 	samlpkg "github.com/coupergateway/couper/accesscontrol/saml"
 	"github.com/coupergateway/couper/config/request"
@@ -20,9 +22,11 @@ import (
 
 type Saml2 struct {
 	arrayAttributes  []string
+	keyPair          *tls.Certificate
 	metadataProvider samlpkg.MetadataProvider
 	name             string
 	acsURL           string
+	sloURL           string
 	spEntityID       string
 }
 
@@ -40,41 +44,33 @@ func NewSAML2ACS(provider samlpkg.MetadataProvider, name string, acsURL string,
 	return samlObj, nil
 }
 
-func (s *Saml2) buildServiceProvider() (*saml2.SAMLServiceProvider, error) {
+// WithSLO configures the Single Logout endpoint and the SP key for signing logout responses
+// and decrypting encrypted assertions.
+func (s *Saml2) WithSLO(sloURL string, keyPair *tls.Certificate) *Saml2 {
+	s.sloURL = sloURL
+	s.keyPair = keyPair
+	return s
+}
+
+func (s *Saml2) buildServiceProvider(origin *url.URL) (*saml2.SAMLServiceProvider, error) {
 	metadata, err := s.metadataProvider.Metadata()
 	if err != nil {
 		return nil, err
 	}
 
-	certStore := dsig.MemoryX509CertificateStore{
-		Roots: []*x509.Certificate{},
+	absAcsURL, err := lib.AbsoluteURL(s.acsURL, origin)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, kd := range metadata.IDPSSODescriptor.KeyDescriptors {
-		for idx, xcert := range kd.KeyInfo.X509Data.X509Certificates {
-			if xcert.Data == "" {
-				return nil, fmt.Errorf("metadata certificate(%d) must not be empty", idx)
-			}
-			certData, err := base64.StdEncoding.DecodeString(xcert.Data)
-			if err != nil {
-				return nil, err
-			}
-
-			idpCert, err := x509.ParseCertificate(certData)
-			if err != nil {
-				return nil, err
-			}
-
-			certStore.Roots = append(certStore.Roots, idpCert)
+	var absSloURL string
+	if s.sloURL != "" {
+		if absSloURL, err = lib.AbsoluteURL(s.sloURL, origin); err != nil {
+			return nil, err
 		}
 	}
 
-	return &saml2.SAMLServiceProvider{
-		AssertionConsumerServiceURL: s.acsURL,
-		AudienceURI:                 s.spEntityID,
-		IDPCertificateStore:         &certStore,
-		IdentityProviderIssuer:      metadata.EntityID,
-	}, nil
+	return lib.NewSAMLServiceProvider(metadata, s.spEntityID, absAcsURL, absSloURL, s.keyPair)
 }
 
 func contains(s []string, searchterm string) bool {
@@ -88,33 +84,42 @@ func (s *Saml2) Validate(req *http.Request) error {
 		return errors.Saml.With(err)
 	}
 
-	sp, err := s.buildServiceProvider()
-	if err != nil {
-		return errors.Saml.With(err)
-	}
-
-	origin := eval.NewRawOrigin(req.URL)
-	absAcsURL, err := lib.AbsoluteURL(sp.AssertionConsumerServiceURL, origin)
+	sp, err := s.buildServiceProvider(eval.NewRawOrigin(req.URL))
 	if err != nil {
 		return errors.Saml.With(err)
 	}
-	sp.AssertionConsumerServiceURL = absAcsURL
 
+	encodedRequest := req.FormValue("SAMLRequest")
 	encodedResponse := req.FormValue("SAMLResponse")
+	relayState := req.FormValue("RelayState")
 	req.ContentLength = 0
 
-	assertionInfo, err := sp.RetrieveAssertionInfo(encodedResponse)
-	if err != nil {
-		return errors.Saml.With(err)
-	}
+	// Logout messages are accepted at the SLO endpoint only and assertions at the ACS endpoint
+	// only, so neither can be replayed to the other or to any other protected endpoint.
+	isSLOPath := s.sloURL != "" && hasPath(sp.ServiceProviderSLOURL, req.URL.Path)
+	isACSPath := hasPath(sp.AssertionConsumerServiceURL, req.URL.Path)
 
-	err = s.ValidateAssertionInfo(assertionInfo)
+	var ass map[string]interface{}
+	if encodedRequest != "" {
+		if !isSLOPath {
+			return errors.Saml.With(fmt.Errorf("unexpected logout request at %q", req.URL.Path))
+		}
+		ass, err = s.validateLogoutRequest(req, sp, encodedRequest, relayState)
+	} else if isLogoutResponse(encodedResponse) {
+		if !isSLOPath {
+			return errors.Saml.With(fmt.Errorf("unexpected logout response at %q", req.URL.Path))
+		}
+		ass, err = s.validateLogoutResponse(req, sp, encodedResponse)
+	} else {
+		if !isACSPath {
+			return errors.Saml.With(fmt.Errorf("unexpected SAML response at %q", req.URL.Path))
+		}
+		ass, err = s.validateResponse(sp, encodedResponse)
+	}
 	if err != nil {
 		return errors.Saml.With(err)
 	}
 
-	ass := s.GetAssertionData(assertionInfo)
-
 	ctx := req.Context()
 	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
 	if !ok {
@@ -127,6 +132,94 @@ func (s *Saml2) Validate(req *http.Request) error {
 	return nil
 }
 
+func (s *Saml2) validateResponse(sp *saml2.SAMLServiceProvider, encodedResponse string) (map[string]interface{}, error) {
+	assertionInfo, err := sp.RetrieveAssertionInfo(encodedResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.ValidateAssertionInfo(assertionInfo); err != nil {
+		return nil, err
+	}
+
+	return s.GetAssertionData(assertionInfo), nil
+}
+
+// validateLogoutRequest validates an IdP-initiated LogoutRequest (HTTP-POST binding)
+// and provides the URL for the LogoutResponse.
+func (s *Saml2) validateLogoutRequest(req *http.Request, sp *saml2.SAMLServiceProvider, encodedRequest, relayState string) (map[string]interface{}, error) {
+	if req.Method != http.MethodPost {
+		return nil, fmt.Errorf("unexpected logout request")
+	}
+
+	logoutRequest, err := sp.ValidateEncodedLogoutRequestPOST(encodedRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	responseURL, err := lib.NewSAMLLogoutResponseURL(sp, logoutRequest.ID, relayState)
+	if err != nil {
+		return nil, err
+	}
+
+	var sub string
+	if logoutRequest.NameID != nil {
+		sub = logoutRequest.NameID.Value
+	}
+
+	return map[string]interface{}{
+		"logout": map[string]interface{}{
+			"type":         "request",
+			"sub":          sub,
+			"response_url": responseURL,
+		},
+	}, nil
+}
+
+// validateLogoutResponse validates the LogoutResponse (HTTP-POST binding) to an SP-initiated logout.
+func (s *Saml2) validateLogoutResponse(req *http.Request, sp *saml2.SAMLServiceProvider, encodedResponse string) (map[string]interface{}, error) {
+	if req.Method != http.MethodPost {
+		return nil, fmt.Errorf("unexpected logout response")
+	}
+
+	if _, err := sp.ValidateEncodedLogoutResponsePOST(encodedResponse); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"logout": map[string]interface{}{
+			"type":         "response",
+			"sub":          "",
+			"response_url": "",
+		},
+	}, nil
+}
+
+// hasPath reports whether the given absolute endpoint URL has the given path.
+func hasPath(endpointURL, path string) bool {
+	u, err := url.Parse(endpointURL)
+	return err == nil && u.Path == path
+}
+
+// isLogoutResponse checks the root element of the (not yet validated) SAML message.
+func isLogoutResponse(encodedResponse string) bool {
+	raw, err := base64.StdEncoding.DecodeString(encodedResponse)
+	if err != nil {
+		return false
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	for {
+		token, terr := decoder.Token()
+		if terr != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local == "LogoutResponse"
+		}
+	}
+}
+
 func (s *Saml2) ValidateAssertionInfo(assertionInfo *saml2.AssertionInfo) error {
 	if assertionInfo.WarningInfo.NotInAudience {
 		return fmt.Errorf("wrong audience")
@@ -159,6 +252,9 @@ func (s *Saml2) GetAssertionData(assertionInfo *saml2.AssertionInfo) map[string]
 	ass := make(map[string]interface{})
 	ass["attributes"] = attributes
 	ass["sub"] = assertionInfo.NameID
+	if assertionInfo.SessionIndex != "" {
+		ass["session_index"] = assertionInfo.SessionIndex
+	}
 	if assertionInfo.SessionNotOnOrAfter != nil {
 		ass["exp"] = assertionInfo.SessionNotOnOrAfter.Unix()
 	}
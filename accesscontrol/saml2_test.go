@@ -2,21 +2,28 @@ package accesscontrol_test
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/pem"
 	"encoding/xml"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/beevik/etree"
 	"github.com/google/go-cmp/cmp"
 	saml2 "github.com/russellhaering/gosaml2"
 	"github.com/russellhaering/gosaml2/types"
+	dsig "github.com/russellhaering/goxmldsig"
 
 	ac "github.com/coupergateway/couper/accesscontrol"
 	"github.com/coupergateway/couper/accesscontrol/saml"
 	"github.com/coupergateway/couper/config/reader"
+	"github.com/coupergateway/couper/config/request"
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/internal/test"
 )
@@ -99,7 +106,7 @@ func Test_SAML2ACS_Validate(t *testing.T) {
 		// TODO how to make test for valid SAMLResponse?
 	} {
 		t.Run(tc.name, func(subT *testing.T) {
-			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(tc.payload))
+			req := httptest.NewRequest(http.MethodPost, "/saml/acs", bytes.NewBufferString(tc.payload))
 			if err := sa.Validate(req); (err != nil) != tc.wantErr {
 				subT.Errorf("%s: Validate() error = %v, wantErr %v", tc.name, err, tc.wantErr)
 			}
@@ -333,3 +340,104 @@ func Test_SAML2ACS_GetAssertionData(t *testing.T) {
 		})
 	}
 }
+
+func Test_SAML2ACS_Logout(t *testing.T) {
+	helper := test.New(t)
+
+	metadataBytes, err := reader.ReadFromAttrFile("saml2", "", "testdata/idp-metadata-slo.xml")
+	helper.Must(err)
+	provider, err := saml.NewStaticMetadata(metadataBytes)
+	helper.Must(err)
+
+	spKeyPair, err := tls.LoadX509KeyPair("testdata/sp.crt", "testdata/sp.key")
+	helper.Must(err)
+
+	sa, err := ac.NewSAML2ACS(provider, "test", "http://www.example.org/saml/acs", "my-sp-entity-id", nil)
+	helper.Must(err)
+	sa = sa.WithSLO("/saml/slo", &spKeyPair)
+
+	// the IdP: builds and signs the logout messages with its key from the metadata
+	metadata := &types.EntityDescriptor{}
+	helper.Must(xml.Unmarshal(metadataBytes, metadata))
+	idpCert, err := base64.StdEncoding.DecodeString(strings.TrimSpace(metadata.IDPSSODescriptor.KeyDescriptors[0].KeyInfo.X509Data.X509Certificates[0].Data))
+	helper.Must(err)
+	idpKeyBytes, err := os.ReadFile("testdata/idp.key")
+	helper.Must(err)
+	block, _ := pem.Decode(idpKeyBytes)
+	idpKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	helper.Must(err)
+
+	idp := &saml2.SAMLServiceProvider{
+		ServiceProviderIssuer:  metadata.EntityID,
+		IdentityProviderSLOURL: "http://www.example.org/saml/slo",
+		SignAuthnRequests:      true,
+		SPKeyStore:             dsig.TLSCertKeyStore(tls.Certificate{Certificate: [][]byte{idpCert}, PrivateKey: idpKey}),
+	}
+
+	encode := func(doc *etree.Document) string {
+		b, werr := doc.WriteToBytes()
+		helper.Must(werr)
+		return base64.StdEncoding.EncodeToString(b)
+	}
+
+	logoutRequest, err := idp.BuildLogoutRequestDocument("abc12345", "_session-1")
+	helper.Must(err)
+	logoutResponse, err := idp.BuildLogoutResponseDocument(saml2.StatusCodeSuccess, "_request-1")
+	helper.Must(err)
+	unsignedRequest, err := idp.BuildLogoutRequestDocumentNoSig("abc12345", "_session-1")
+	helper.Must(err)
+
+	type testCase struct {
+		name        string
+		method      string
+		path        string
+		payload     url.Values
+		wantType    string
+		wantSub     string
+		wantRespURL string
+	}
+
+	for _, tc := range []testCase{
+		{"logout request", http.MethodPost, "/saml/slo", url.Values{"SAMLRequest": {encode(logoutRequest)}, "RelayState": {"state"}}, "request", "abc12345", "https://idp.example.org/saml/SLOService?SAMLResponse="},
+		{"logout response", http.MethodPost, "/saml/slo", url.Values{"SAMLResponse": {encode(logoutResponse)}}, "response", "", ""},
+		{"unsigned logout request", http.MethodPost, "/saml/slo", url.Values{"SAMLRequest": {encode(unsignedRequest)}}, "", "", ""},
+		{"logout request via GET", http.MethodGet, "/saml/slo", url.Values{"SAMLRequest": {encode(logoutRequest)}}, "", "", ""},
+		{"logout request at acs endpoint", http.MethodPost, "/saml/acs", url.Values{"SAMLRequest": {encode(logoutRequest)}}, "", "", ""},
+		{"logout response at acs endpoint", http.MethodPost, "/saml/acs", url.Values{"SAMLResponse": {encode(logoutResponse)}}, "", "", ""},
+		{"logout request at other endpoint", http.MethodPost, "/protected", url.Values{"SAMLRequest": {encode(logoutRequest)}}, "", "", ""},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			var req *http.Request
+			if tc.method == http.MethodPost {
+				req = httptest.NewRequest(tc.method, "http://www.example.org"+tc.path, strings.NewReader(tc.payload.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				req = httptest.NewRequest(tc.method, "http://www.example.org"+tc.path+"?"+tc.payload.Encode(), nil)
+			}
+
+			err := sa.Validate(req)
+			if tc.wantType == "" {
+				if err == nil {
+					subT.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+			data, _ := acMap["test"].(map[string]interface{})
+			logout, _ := data["logout"].(map[string]interface{})
+			if logout["type"] != tc.wantType {
+				subT.Errorf("want type %q, got: %v", tc.wantType, logout["type"])
+			}
+			if logout["sub"] != tc.wantSub {
+				subT.Errorf("want sub %q, got: %v", tc.wantSub, logout["sub"])
+			}
+			if respURL, _ := logout["response_url"].(string); !strings.HasPrefix(respURL, tc.wantRespURL) || (tc.wantRespURL == "") != (respURL == "") {
+				subT.Errorf("want response_url with prefix %q, got: %q", tc.wantRespURL, respURL)
+			}
+		})
+	}
+}
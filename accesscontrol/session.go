@@ -0,0 +1,77 @@
+package accesscontrol
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/coupergateway/couper/accesscontrol/session"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+var _ AccessControl = &Session{}
+
+// Session represents an AC-Session object
+type Session struct {
+	manager *session.Manager
+}
+
+// NewSession creates a new AC-Session object
+func NewSession(manager *session.Manager) *Session {
+	return &Session{manager: manager}
+}
+
+// Validate implements the AccessControl interface
+func (s *Session) Validate(req *http.Request) error {
+	if s == nil || s.manager == nil {
+		return errors.Configuration
+	}
+
+	id := s.manager.ID(req.Cookies())
+	if id == "" {
+		return errors.SessionMissing.Message("session cookie required")
+	}
+
+	data, err := s.manager.Load(id)
+	if err != nil {
+		return errors.Session.With(err)
+	}
+	if data == nil {
+		return errors.Session.Message("unknown or expired session")
+	}
+
+	tokens, err := s.manager.Tokens(req.Context(), id, data)
+	if err != nil {
+		return errors.Session.Message("token refresh failed").With(err)
+	}
+
+	values := data.Values
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	acMap[s.manager.Name()] = values
+	// the current access tokens, e.g. of an oidc block, are accessible via its label
+	for name, token := range tokens {
+		tokenData := map[string]interface{}{
+			"access_token": token.AccessToken,
+		}
+		if !token.Expiry.IsZero() {
+			tokenData["expires_at"] = token.Expiry.Unix()
+		}
+		if token.IDToken != "" {
+			tokenData["id_token"] = token.IDToken
+		}
+		acMap[name] = tokenData
+	}
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+
+	*req = *req.WithContext(ctx)
+
+	return nil
+}
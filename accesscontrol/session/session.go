@@ -0,0 +1,359 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+)
+
+const (
+	idLength     = 32
+	sessionLocks = 64
+)
+
+// Data represents the server-side state of a session.
+type Data struct {
+	Values   map[string]interface{}
+	Tokens   map[string]*Token
+	Created  time.Time
+	LastSeen time.Time
+}
+
+// Manager creates, loads and destroys the sessions of a session block.
+type Manager struct {
+	absoluteTimeout time.Duration
+	cookie          http.Cookie
+	idleTimeout     time.Duration
+	name            string
+	now             func() time.Time
+	refreshers      map[string]TokenRefresher
+	sessionMu       [sessionLocks]sync.Mutex
+	store           Store
+}
+
+// NewManager creates a Manager for the given session block configuration.
+func NewManager(conf *config.Session, memStore *cache.MemoryStore) (*Manager, error) {
+	idleTimeout, err := config.ParseDuration("idle_timeout", conf.IdleTimeout, 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	absoluteTimeout, err := config.ParseDuration("absolute_timeout", conf.AbsoluteTimeout, 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	if idleTimeout == 0 || absoluteTimeout == 0 {
+		return nil, fmt.Errorf("idle_timeout and absolute_timeout must be positive")
+	}
+
+	cookie := http.Cookie{
+		Name:     conf.CookieName,
+		Path:     conf.CookiePath,
+		Domain:   conf.CookieDomain,
+		HttpOnly: true,
+		Secure:   conf.CookieSecure == nil || *conf.CookieSecure,
+	}
+	if cookie.Name == "" {
+		cookie.Name = conf.Name
+	}
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+
+	switch strings.ToLower(conf.CookieSameSite) {
+	case "", "lax":
+		cookie.SameSite = http.SameSiteLaxMode
+	case "strict":
+		cookie.SameSite = http.SameSiteStrictMode
+	case "none":
+		if !cookie.Secure {
+			return nil, fmt.Errorf("cookie_same_site: \"None\" requires cookie_secure")
+		}
+		cookie.SameSite = http.SameSiteNoneMode
+	default:
+		return nil, fmt.Errorf("cookie_same_site: unsupported value %q", conf.CookieSameSite)
+	}
+
+	storeName := conf.Store
+	if storeName == "" {
+		storeName = "memory"
+	}
+	store, err := NewStore(storeName, conf.Name, memStore)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager{
+		absoluteTimeout: absoluteTimeout,
+		cookie:          cookie,
+		idleTimeout:     idleTimeout,
+		name:            conf.Name,
+		now:             time.Now,
+		refreshers:      make(map[string]TokenRefresher),
+		store:           store,
+	}, nil
+}
+
+// SetTokenRefresher registers the refresher for the tokens with the given name, e.g. an oidc block label.
+// Must not be called after startup.
+func (m *Manager) SetTokenRefresher(name string, refresher TokenRefresher) {
+	m.refreshers[name] = refresher
+}
+
+// Name returns the label of the session block.
+func (m *Manager) Name() string {
+	return m.name
+}
+
+// ID returns the session ID transported with the request or an empty string.
+func (m *Manager) ID(cookies []*http.Cookie) string {
+	for _, c := range cookies {
+		if c.Name == m.cookie.Name {
+			return c.Value
+		}
+	}
+	return ""
+}
+
+// Load returns the data of the given session and extends its idle timeout.
+// A nil value is returned for unknown or expired sessions.
+func (m *Manager) Load(id string) (*Data, error) {
+	if len(id) != base64.RawURLEncoding.EncodedLen(idLength) {
+		return nil, nil
+	}
+
+	data, err := m.store.Load(id)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	now := m.now()
+	if now.Sub(data.Created) >= m.absoluteTimeout || now.Sub(data.LastSeen) >= m.idleTimeout {
+		return nil, m.store.Delete(id)
+	}
+
+	// reload the data under the session lock to keep tokens refreshed in the meantime
+	mu := m.lock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if data, err = m.store.Load(id); err != nil || data == nil {
+		return nil, err
+	}
+
+	data.LastSeen = now
+	if err = m.store.Save(id, data, m.ttl(data)); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Create stores a new session with the given values and tokens and returns the session cookie.
+// A previous session is destroyed to prevent session fixation.
+func (m *Manager) Create(values map[string]interface{}, tokens map[string]*Token, previousID string) (*http.Cookie, error) {
+	if previousID != "" {
+		if err := m.store.Delete(previousID); err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+	data := &Data{
+		Values:   values,
+		Tokens:   tokens,
+		Created:  now,
+		LastSeen: now,
+	}
+	if err = m.store.Save(id, data, m.ttl(data)); err != nil {
+		return nil, err
+	}
+
+	cookie := m.cookie
+	cookie.Value = id
+	cookie.MaxAge = int(m.absoluteTimeout.Seconds())
+	return &cookie, nil
+}
+
+// Writes holds the cookies of the sessions created for a client request.
+type Writes struct {
+	cookies map[string]*http.Cookie
+	mu      sync.Mutex
+}
+
+// WithWrites returns a context holding the sessions created for the client request, see CreateOnce.
+func WithWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(request.SessionWrites).(*Writes); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, request.SessionWrites, &Writes{cookies: make(map[string]*http.Cookie)})
+}
+
+// CreateOnce creates a session like Create, but only once per client request of the given context.
+// Further calls return the cookie of the session created first, e.g. if session_write() is evaluated
+// more than once.
+func (m *Manager) CreateOnce(ctx context.Context, values map[string]interface{}, tokens map[string]*Token, previousID string) (*http.Cookie, error) {
+	writes, _ := ctx.Value(request.SessionWrites).(*Writes)
+	if writes == nil {
+		return m.Create(values, tokens, previousID)
+	}
+
+	writes.mu.Lock()
+	defer writes.mu.Unlock()
+
+	if cookie, exists := writes.cookies[m.name]; exists {
+		return cookie, nil
+	}
+
+	cookie, err := m.Create(values, tokens, previousID)
+	if err != nil {
+		return nil, err
+	}
+	writes.cookies[m.name] = cookie
+	return cookie, nil
+}
+
+// UpdateToken replaces the named token of the given session, e.g. after a refresh.
+func (m *Manager) UpdateToken(id, name string, token *Token) error {
+	mu := m.lock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	return m.updateToken(id, name, token)
+}
+
+// updateToken must be called with the lock of the given session held.
+func (m *Manager) updateToken(id, name string, token *Token) error {
+	data, err := m.store.Load(id)
+	if err != nil || data == nil {
+		return err
+	}
+
+	tokens := make(map[string]*Token, len(data.Tokens)+1)
+	for n, t := range data.Tokens {
+		tokens[n] = t
+	}
+	tokens[name] = token
+	data.Tokens = tokens
+
+	return m.store.Save(id, data, m.ttl(data))
+}
+
+// Tokens returns the tokens of the given session. (Almost) expired access tokens are refreshed
+// with their registered TokenRefresher first.
+func (m *Manager) Tokens(ctx context.Context, id string, data *Data) (map[string]*Token, error) {
+	tokens := make(map[string]*Token, len(data.Tokens))
+	for name, token := range data.Tokens {
+		refresher, exist := m.refreshers[name]
+		if exist && token.NeedsRefresh(m.now()) {
+			var err error
+			if token, err = m.refresh(ctx, id, name, refresher); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		tokens[name] = token
+	}
+	return tokens, nil
+}
+
+// lock returns the mutex serializing the updates of the given session.
+func (m *Manager) lock(id string) *sync.Mutex {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(id))
+	return &m.sessionMu[h.Sum32()%sessionLocks]
+}
+
+// refresh serializes the refreshes per session since a refresh token may only be used once.
+func (m *Manager) refresh(ctx context.Context, id, name string, refresher TokenRefresher) (*Token, error) {
+	mu := m.lock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	// a concurrent request may have refreshed the token in the meantime
+	data, err := m.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil || data.Tokens[name] == nil {
+		return nil, fmt.Errorf("unknown session token")
+	}
+
+	token := data.Tokens[name]
+	if !token.NeedsRefresh(m.now()) {
+		return token, nil
+	}
+
+	refreshed, err := refresher.RefreshToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.updateToken(id, name, refreshed); err != nil {
+		return nil, err
+	}
+	return refreshed, nil
+}
+
+// DestroyByToken deletes all sessions with a named token for the given subject and/or OpenID provider session ID.
+func (m *Manager) DestroyByToken(name, subject, sessionID string) error {
+	if subject == "" && sessionID == "" {
+		return nil
+	}
+
+	return m.store.DeleteMatching(func(data *Data) bool {
+		token := data.Tokens[name]
+		if token == nil {
+			return false
+		}
+		if sessionID != "" && token.SessionID != sessionID {
+			return false
+		}
+		return subject == "" || token.Subject == subject
+	})
+}
+
+// Destroy deletes the given session and returns a cookie removing the session ID from the client.
+func (m *Manager) Destroy(id string) (*http.Cookie, error) {
+	if id != "" {
+		if err := m.store.Delete(id); err != nil {
+			return nil, err
+		}
+	}
+
+	cookie := m.cookie
+	cookie.MaxAge = -1
+	return &cookie, nil
+}
+
+// ttl returns the time period until the session expires without further activity.
+func (m *Manager) ttl(data *Data) time.Duration {
+	ttl := m.idleTimeout
+	if remaining := m.absoluteTimeout - data.LastSeen.Sub(data.Created); remaining < ttl {
+		ttl = remaining
+	}
+	return ttl
+}
+
+func newID() (string, error) {
+	b := make([]byte, idLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
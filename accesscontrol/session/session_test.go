@@ -0,0 +1,240 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+)
+
+func newTestManager(t *testing.T, conf *config.Session) (*Manager, *time.Time) {
+	quitCh := make(chan struct{})
+	t.Cleanup(func() { close(quitCh) })
+
+	m, err := NewManager(conf, cache.New(logrus.NewEntry(logrus.New()), quitCh))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+func TestManager_Timeouts(t *testing.T) {
+	m, now := newTestManager(t, &config.Session{
+		Name:            "test",
+		IdleTimeout:     "10m",
+		AbsoluteTimeout: "25m",
+	})
+
+	cookie, err := m.Create(map[string]interface{}{"sub": "alice"}, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, step := range []struct {
+		advance time.Duration
+		valid   bool
+	}{
+		{9 * time.Minute, true},  // 9m, idle extended
+		{9 * time.Minute, true},  // 18m, idle extended
+		{6 * time.Minute, true},  // 24m
+		{2 * time.Minute, false}, // 26m, absolute timeout
+	} {
+		*now = now.Add(step.advance)
+		data, lerr := m.Load(cookie.Value)
+		if lerr != nil {
+			t.Fatal(lerr)
+		}
+		if valid := data != nil; valid != step.valid {
+			t.Fatalf("step %d: expected valid %t, got %t", i, step.valid, valid)
+		}
+		if data != nil && data.Values["sub"] != "alice" {
+			t.Errorf("step %d: unexpected values: %#v", i, data.Values)
+		}
+	}
+
+	cookie, err = m.Create(nil, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(10 * time.Minute)
+	if data, _ := m.Load(cookie.Value); data != nil {
+		t.Error("expected idle timeout")
+	}
+}
+
+func TestManager_Cookie(t *testing.T) {
+	m, _ := newTestManager(t, &config.Session{Name: "test", CookieDomain: "example.com"})
+
+	cookie, err := m.Create(nil, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cookie.Name != "test" || cookie.Path != "/" || cookie.Domain != "example.com" || !cookie.HttpOnly ||
+		!cookie.Secure || cookie.SameSite != http.SameSiteLaxMode || cookie.MaxAge != 86400 {
+		t.Errorf("unexpected cookie: %#v", cookie)
+	}
+
+	if id := m.ID([]*http.Cookie{{Name: "other", Value: "x"}, cookie}); id != cookie.Value {
+		t.Errorf("expected ID %q, got %q", cookie.Value, id)
+	}
+
+	removal, err := m.Destroy(cookie.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removal.Value != "" || removal.MaxAge != -1 {
+		t.Errorf("unexpected removal cookie: %#v", removal)
+	}
+	if data, _ := m.Load(cookie.Value); data != nil {
+		t.Error("expected destroyed session")
+	}
+}
+
+func TestNewManager_Errors(t *testing.T) {
+	f := false
+	for _, tc := range []struct {
+		name   string
+		conf   *config.Session
+		expErr string
+	}{
+		{"invalid idle_timeout", &config.Session{IdleTimeout: "1x"}, `idle_timeout: time: unknown unit "x" in duration "1x"`},
+		{"zero timeout", &config.Session{AbsoluteTimeout: "0s"}, "idle_timeout and absolute_timeout must be positive"},
+		{"unsupported same site", &config.Session{CookieSameSite: "foo"}, `cookie_same_site: unsupported value "foo"`},
+		{"insecure same site none", &config.Session{CookieSameSite: "None", CookieSecure: &f}, `cookie_same_site: "None" requires cookie_secure`},
+		{"unsupported store", &config.Session{Store: "redis"}, `store: unsupported value "redis", expected one of: "memory"`},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := NewManager(tc.conf, cache.New(logrus.NewEntry(logrus.New()), nil))
+			if err == nil || err.Error() != tc.expErr {
+				st.Errorf("expected error %q, got: %v", tc.expErr, err)
+			}
+		})
+	}
+}
+
+type testRefresher struct {
+	calls int
+	err   error
+}
+
+func (r *testRefresher) RefreshToken(_ context.Context, token *Token) (*Token, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &Token{AccessToken: token.AccessToken + "-refreshed", RefreshToken: token.RefreshToken, Expiry: time.Now().Add(time.Hour)}, nil
+}
+
+func TestManager_Tokens(t *testing.T) {
+	m, now := newTestManager(t, &config.Session{Name: "test", IdleTimeout: "4h"})
+
+	refresher := &testRefresher{}
+	m.SetTokenRefresher("oidc", refresher)
+
+	cookie, err := m.Create(nil, map[string]*Token{
+		"oidc":  {AccessToken: "at", RefreshToken: "rt", Expiry: now.Add(time.Hour)},
+		"other": {AccessToken: "other", RefreshToken: "rt", Expiry: now.Add(-time.Hour)},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	load := func() map[string]*Token {
+		data, lerr := m.Load(cookie.Value)
+		if lerr != nil || data == nil {
+			t.Fatalf("expected session, got error: %v", lerr)
+		}
+		tokens, terr := m.Tokens(context.Background(), cookie.Value, data)
+		if terr != nil {
+			t.Fatal(terr)
+		}
+		return tokens
+	}
+
+	if tokens := load(); tokens["oidc"].AccessToken != "at" || tokens["other"].AccessToken != "other" || refresher.calls != 0 {
+		t.Fatalf("expected unchanged tokens, got: %#v, refreshes: %d", tokens, refresher.calls)
+	}
+
+	// within the refresh leeway
+	*now = now.Add(time.Hour - refreshLeeway)
+	if tokens := load(); tokens["oidc"].AccessToken != "at-refreshed" || refresher.calls != 1 {
+		t.Fatalf("expected refreshed token, got: %#v, refreshes: %d", tokens["oidc"], refresher.calls)
+	}
+	if tokens := load(); tokens["oidc"].AccessToken != "at-refreshed" || refresher.calls != 1 {
+		t.Fatalf("expected stored refreshed token, got: %#v, refreshes: %d", tokens["oidc"], refresher.calls)
+	}
+
+	*now = now.Add(2 * time.Hour)
+	refresher.err = fmt.Errorf("invalid_grant")
+	data, _ := m.Load(cookie.Value)
+	if _, err = m.Tokens(context.Background(), cookie.Value, data); err == nil || err.Error() != "oidc: invalid_grant" {
+		t.Errorf("expected refresh error, got: %v", err)
+	}
+}
+
+type slowRefresher struct {
+	testRefresher
+	delay time.Duration
+}
+
+func (r *slowRefresher) RefreshToken(ctx context.Context, token *Token) (*Token, error) {
+	time.Sleep(r.delay)
+	return r.testRefresher.RefreshToken(ctx, token)
+}
+
+func TestManager_LoadDuringRefresh(t *testing.T) {
+	m, now := newTestManager(t, &config.Session{Name: "test", IdleTimeout: "4h"})
+
+	refresher := &slowRefresher{delay: 50 * time.Millisecond}
+	m.SetTokenRefresher("oidc", refresher)
+
+	cookie, err := m.Create(nil, map[string]*Token{
+		"oidc": {AccessToken: "at", RefreshToken: "rt", Expiry: now.Add(-time.Hour)},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	loadErrs := make(chan error, 1)
+	go func() {
+		defer close(loadErrs)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if _, lerr := m.Load(cookie.Value); lerr != nil {
+				loadErrs <- lerr
+				return
+			}
+		}
+	}()
+
+	data, err := m.Load(cookie.Value)
+	if err != nil || data == nil {
+		t.Fatalf("expected session, got error: %v", err)
+	}
+	if _, err = m.Tokens(context.Background(), cookie.Value, data); err != nil {
+		t.Fatal(err)
+	}
+	close(done)
+	if lerr := <-loadErrs; lerr != nil {
+		t.Fatal(lerr)
+	}
+
+	data, _ = m.Load(cookie.Value)
+	if token := data.Tokens["oidc"]; token.AccessToken != "at-refreshed" || refresher.calls != 1 {
+		t.Errorf("expected the refreshed token to be kept, got: %#v, refreshes: %d", token, refresher.calls)
+	}
+}
@@ -0,0 +1,116 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coupergateway/couper/cache"
+)
+
+// Store abstracts the server-side storage of session data.
+type Store interface {
+	// Load returns the stored data for the given session ID or nil if there is none.
+	Load(id string) (*Data, error)
+	// Save stores the data for the given session ID for at most ttl.
+	Save(id string, data *Data, ttl time.Duration) error
+	// Delete removes the data for the given session ID.
+	Delete(id string) error
+	// DeleteMatching removes the data of all sessions for which match returns true.
+	DeleteMatching(match func(*Data) bool) error
+}
+
+// StoreFactory creates a Store for the session block with the given label.
+type StoreFactory func(label string, memStore *cache.MemoryStore) (Store, error)
+
+var (
+	storesMu sync.RWMutex
+	stores   = map[string]StoreFactory{
+		"memory": newMemoryStore,
+	}
+)
+
+// RegisterStore makes a Store implementation available for the {store} attribute.
+func RegisterStore(name string, factory StoreFactory) {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+
+	stores[name] = factory
+}
+
+// NewStore creates the Store registered with the given name.
+func NewStore(name, label string, memStore *cache.MemoryStore) (Store, error) {
+	storesMu.RLock()
+	factory, exist := stores[name]
+	storesMu.RUnlock()
+
+	if !exist {
+		return nil, fmt.Errorf("store: unsupported value %q, expected one of: %s", name, storeNames())
+	}
+
+	return factory(label, memStore)
+}
+
+func storeNames() string {
+	storesMu.RLock()
+	defer storesMu.RUnlock()
+
+	names := make([]string, 0, len(stores))
+	for name := range stores {
+		names = append(names, fmt.Sprintf("%q", name))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+var _ Store = &memoryStore{}
+
+// memoryStore keeps the session data in the memory of the running Couper instance.
+type memoryStore struct {
+	memStore *cache.MemoryStore
+	prefix   string
+}
+
+func newMemoryStore(label string, memStore *cache.MemoryStore) (Store, error) {
+	if memStore == nil {
+		return nil, fmt.Errorf("store: missing memory store")
+	}
+
+	return &memoryStore{
+		memStore: memStore,
+		prefix:   "session_" + label + "_",
+	}, nil
+}
+
+func (m *memoryStore) Load(id string) (*Data, error) {
+	data, _ := m.memStore.Get(m.prefix + id).(*Data)
+	if data == nil {
+		return nil, nil
+	}
+
+	// hand out a copy; the stored one may be read concurrently
+	c := *data
+	return &c, nil
+}
+
+func (m *memoryStore) Save(id string, data *Data, ttl time.Duration) error {
+	c := *data
+	// round up, the memory store works with seconds
+	m.memStore.Set(m.prefix+id, &c, int64((ttl+time.Second-1)/time.Second))
+	return nil
+}
+
+func (m *memoryStore) Delete(id string) error {
+	m.memStore.Del(m.prefix + id)
+	return nil
+}
+
+func (m *memoryStore) DeleteMatching(match func(*Data) bool) error {
+	m.memStore.DelAllWithPrefix(m.prefix, func(v interface{}) bool {
+		data, ok := v.(*Data)
+		return ok && match(data)
+	})
+	return nil
+}
@@ -0,0 +1,62 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/coupergateway/couper/config/request"
+)
+
+// refreshLeeway is the time period before the expiry of an access token in which it is already refreshed.
+const refreshLeeway = 30 * time.Second
+
+// Token holds the OAuth2 tokens of a session, e.g. obtained by an oidc block.
+type Token struct {
+	AccessToken  string
+	Expiry       time.Time
+	IDToken      string
+	RefreshToken string
+	// SessionID is the session ID at the OpenID provider (sid claim).
+	SessionID string
+	Subject   string
+}
+
+// NeedsRefresh reports whether the access token is (almost) expired and can be refreshed.
+func (t *Token) NeedsRefresh(now time.Time) bool {
+	return t.RefreshToken != "" && !t.Expiry.IsZero() && !now.Add(refreshLeeway).Before(t.Expiry)
+}
+
+// TokenRefresher obtains a new access token with the refresh token of the given one.
+type TokenRefresher interface {
+	RefreshToken(ctx context.Context, token *Token) (*Token, error)
+}
+
+// WithToken returns a context with the token for the session block with the given label,
+// which is stored by a subsequent Manager.Create call.
+func WithToken(ctx context.Context, label, name string, token *Token) context.Context {
+	tokens, _ := ctx.Value(request.SessionTokens).(map[string]map[string]*Token)
+
+	// copy on write, the map may be shared with parent contexts
+	updated := make(map[string]map[string]*Token, len(tokens)+1)
+	for l, t := range tokens {
+		updated[l] = t
+	}
+
+	named := make(map[string]*Token, len(updated[label])+1)
+	for n, t := range updated[label] {
+		named[n] = t
+	}
+	named[name] = token
+	updated[label] = named
+
+	return context.WithValue(ctx, request.SessionTokens, updated)
+}
+
+// TokensFromContext returns the tokens for the session block with the given label.
+func TokensFromContext(ctx context.Context, label string) map[string]*Token {
+	if ctx == nil {
+		return nil
+	}
+	tokens, _ := ctx.Value(request.SessionTokens).(map[string]map[string]*Token)
+	return tokens[label]
+}
@@ -0,0 +1,231 @@
+package accesscontrol
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+const (
+	defaultSignatureHeader = "X-Signature"
+	headerComponentPrefix  = "header."
+)
+
+var _ AccessControl = &Signature{}
+
+// Signature verifies an HMAC over configured request components, e.g. of webhook requests.
+type Signature struct {
+	components      []string
+	decode          func(string) ([]byte, error)
+	hash            func() hash.Hash
+	header          string
+	name            string
+	prefix          string
+	secret          []byte
+	separator       string
+	timestampHeader string
+	tolerance       time.Duration
+}
+
+// NewSignature creates a new AC-Signature object
+func NewSignature(conf *config.Signature, secret []byte) (*Signature, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("secret or secret_file attribute required")
+	}
+
+	s := &Signature{
+		components:      conf.Components,
+		header:          conf.Header,
+		name:            conf.Name,
+		prefix:          conf.Prefix,
+		secret:          secret,
+		separator:       conf.Separator,
+		timestampHeader: conf.TimestampHeader,
+	}
+
+	switch conf.Algorithm {
+	case "sha1":
+		s.hash = sha1.New
+	case "", "sha256":
+		s.hash = sha256.New
+	case "sha512":
+		s.hash = sha512.New
+	default:
+		return nil, fmt.Errorf("algorithm %q is not supported", conf.Algorithm)
+	}
+
+	switch conf.Encoding {
+	case "", "hex":
+		s.decode = hex.DecodeString
+	case "base64":
+		s.decode = base64.StdEncoding.DecodeString
+	default:
+		return nil, fmt.Errorf("encoding %q is not supported", conf.Encoding)
+	}
+
+	if s.header == "" {
+		s.header = defaultSignatureHeader
+	}
+
+	if s.separator == "" {
+		s.separator = "."
+	}
+
+	if len(s.components) == 0 {
+		s.components = []string{"body"}
+	}
+
+	for _, c := range s.components {
+		switch {
+		case c == "body", c == "method", c == "path":
+		case c == "timestamp":
+			if s.timestampHeader == "" {
+				return nil, fmt.Errorf("timestamp component requires the timestamp_header attribute")
+			}
+		case strings.HasPrefix(c, headerComponentPrefix) && len(c) > len(headerComponentPrefix):
+		default:
+			return nil, fmt.Errorf("invalid component %q", c)
+		}
+	}
+
+	// an unsigned timestamp could be replaced by replayed requests
+	if s.timestampHeader != "" && !s.signsTimestamp() {
+		return nil, fmt.Errorf("timestamp_header requires the timestamp component")
+	}
+
+	tolerance, err := config.ParseDuration("timestamp_tolerance", conf.TimestampTolerance, time.Minute*5)
+	if err != nil {
+		return nil, err
+	}
+	s.tolerance = tolerance
+
+	return s, nil
+}
+
+// signsTimestamp reports whether the timestamp header is part of the signed components.
+func (s *Signature) signsTimestamp() bool {
+	for _, c := range s.components {
+		if c == "timestamp" {
+			return true
+		}
+		if name, ok := strings.CutPrefix(c, headerComponentPrefix); ok && strings.EqualFold(name, s.timestampHeader) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate implements the AccessControl interface
+func (s *Signature) Validate(req *http.Request) error {
+	value := req.Header.Get(s.header)
+	if value == "" {
+		return errors.SignatureMissing.Message("signature required")
+	}
+
+	if s.prefix != "" {
+		var found bool
+		if value, found = strings.CutPrefix(value, s.prefix); !found {
+			return errors.Signature.Messagef("signature prefix %q expected", s.prefix)
+		}
+	}
+
+	signature, err := s.decode(value)
+	if err != nil {
+		return errors.Signature.Message("invalid signature encoding").With(err)
+	}
+
+	sigCtx := make(map[string]interface{})
+
+	var timestamp string
+	if s.timestampHeader != "" {
+		timestamp = req.Header.Get(s.timestampHeader)
+		if timestamp == "" {
+			return errors.Signature.Message("timestamp required")
+		}
+
+		unix, perr := strconv.ParseInt(timestamp, 10, 64)
+		if perr != nil {
+			return errors.Signature.Messagef("invalid timestamp: %q", timestamp)
+		}
+
+		diff := time.Since(time.Unix(unix, 0))
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > s.tolerance {
+			return errors.Signature.Messagef("timestamp outside of tolerance: %s", s.tolerance)
+		}
+		sigCtx["timestamp"] = unix
+	}
+
+	mac := hmac.New(s.hash, s.secret)
+	for i, c := range s.components {
+		if i > 0 {
+			mac.Write([]byte(s.separator))
+		}
+
+		switch c {
+		case "body":
+			if err = writeBody(mac, req); err != nil {
+				return errors.Signature.With(err)
+			}
+		case "method":
+			mac.Write([]byte(req.Method))
+		case "path":
+			mac.Write([]byte(req.URL.EscapedPath()))
+		case "timestamp":
+			mac.Write([]byte(timestamp))
+		default:
+			mac.Write([]byte(req.Header.Get(strings.TrimPrefix(c, headerComponentPrefix))))
+		}
+	}
+
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return errors.Signature.Message("signature mismatch")
+	}
+
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	acMap[s.name] = sigCtx
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+	*req = *req.WithContext(ctx)
+
+	return nil
+}
+
+// writeBody writes the request body buffered by eval.SetGetBody.
+func writeBody(w io.Writer, req *http.Request) error {
+	if req.GetBody == nil {
+		if req.Body == nil || req.Body == http.NoBody {
+			return nil
+		}
+		return fmt.Errorf("request body is not buffered")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = io.Copy(w, body)
+	return err
+}
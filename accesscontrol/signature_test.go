@@ -0,0 +1,130 @@
+package accesscontrol_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/config"
+	couperErr "github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func Test_NewSignature(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		conf      *config.Signature
+		secret    string
+		expErrMsg string
+	}{
+		{"defaults", &config.Signature{}, "s", ""},
+		{"missing secret", &config.Signature{}, "", "secret or secret_file attribute required"},
+		{"unsupported algorithm", &config.Signature{Algorithm: "md5"}, "s", `algorithm "md5" is not supported`},
+		{"unsupported encoding", &config.Signature{Encoding: "base32"}, "s", `encoding "base32" is not supported`},
+		{"invalid component", &config.Signature{Components: []string{"query"}}, "s", `invalid component "query"`},
+		{"empty header component", &config.Signature{Components: []string{"header."}}, "s", `invalid component "header."`},
+		{"timestamp without header", &config.Signature{Components: []string{"timestamp", "body"}}, "s", "timestamp component requires the timestamp_header attribute"},
+		{"timestamp header without component", &config.Signature{TimestampHeader: "X-Ts"}, "s", "timestamp_header requires the timestamp component"},
+		{"timestamp header component", &config.Signature{Components: []string{"header.x-ts", "body"}, TimestampHeader: "X-Ts"}, "s", ""},
+		{"invalid tolerance", &config.Signature{Components: []string{"timestamp", "body"}, TimestampHeader: "X-Ts", TimestampTolerance: "-1s"}, "s", "timestamp_tolerance: cannot be negative: '-1s'"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := ac.NewSignature(tc.conf, []byte(tc.secret))
+			if tc.expErrMsg == "" && err != nil {
+				st.Errorf("unexpected error: %v", err)
+			} else if tc.expErrMsg != "" && (err == nil || err.Error() != tc.expErrMsg) {
+				st.Errorf("expected error %q, got: %v", tc.expErrMsg, err)
+			}
+		})
+	}
+}
+
+func Test_Signature_Validate(t *testing.T) {
+	sign := func(h func() hash.Hash, payload string) string {
+		mac := hmac.New(h, []byte("secret"))
+		mac.Write([]byte(payload))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	future := strconv.FormatInt(time.Now().Add(time.Minute*10).Unix(), 10)
+	past := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	tsComponents := []string{"timestamp", "body"}
+
+	for _, tc := range []struct {
+		name      string
+		conf      *config.Signature
+		body      string
+		header    http.Header
+		expErr    *couperErr.Error
+		expErrMsg string
+	}{
+		{"sha1 body", &config.Signature{Algorithm: "sha1"}, "payload", http.Header{
+			"X-Signature": []string{sign(sha1.New, "payload")},
+		}, nil, ""},
+		{"sha512 method path", &config.Signature{Algorithm: "sha512", Components: []string{"method", "path"}, Separator: "\n"}, "", http.Header{
+			"X-Signature": []string{sign(sha512.New, "POST\n/hook")},
+		}, nil, ""},
+		{"missing signature", &config.Signature{}, "payload", http.Header{}, couperErr.SignatureMissing, "signature required"},
+		{"invalid encoding", &config.Signature{}, "payload", http.Header{
+			"X-Signature": []string{"xyz"},
+		}, couperErr.Signature, "invalid signature encoding"},
+		{"mismatch", &config.Signature{Algorithm: "sha1"}, "other payload", http.Header{
+			"X-Signature": []string{sign(sha1.New, "payload")},
+		}, couperErr.Signature, "signature mismatch"},
+		{"invalid timestamp", &config.Signature{Components: tsComponents, TimestampHeader: "X-Ts", Algorithm: "sha1"}, "payload", http.Header{
+			"X-Signature": []string{sign(sha1.New, "yesterday.payload")},
+			"X-Ts":        []string{"yesterday"},
+		}, couperErr.Signature, `invalid timestamp: "yesterday"`},
+		{"future timestamp", &config.Signature{Components: tsComponents, TimestampHeader: "X-Ts", Algorithm: "sha1"}, "payload", http.Header{
+			"X-Signature": []string{sign(sha1.New, future+".payload")},
+			"X-Ts":        []string{future},
+		}, couperErr.Signature, "timestamp outside of tolerance: 5m0s"},
+		{"valid timestamp", &config.Signature{Components: tsComponents, TimestampHeader: "X-Ts", Algorithm: "sha1"}, "payload", http.Header{
+			"X-Signature": []string{sign(sha1.New, now+".payload")},
+			"X-Ts":        []string{now},
+		}, nil, ""},
+		{"replay with rewritten timestamp", &config.Signature{Components: tsComponents, TimestampHeader: "X-Ts", Algorithm: "sha1"}, "payload", http.Header{
+			"X-Signature": []string{sign(sha1.New, past+".payload")},
+			"X-Ts":        []string{now},
+		}, couperErr.Signature, "signature mismatch"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			tc.conf.Name = "webhook"
+			signature, err := ac.NewSignature(tc.conf, []byte("secret"))
+			h.Must(err)
+
+			req := httptest.NewRequest(http.MethodPost, "/hook", nil)
+			if tc.body != "" {
+				eval.SetBody(req, []byte(tc.body))
+			}
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+
+			err = signature.Validate(req)
+			if tc.expErr == nil {
+				h.Must(err)
+				return
+			}
+
+			if !couperErr.Equals(err, tc.expErr) {
+				st.Fatalf("expected error %v, got: %v", tc.expErr, err)
+			}
+			if msg := err.(*couperErr.Error).LogError(); !strings.Contains(msg, tc.expErrMsg) {
+				st.Errorf("expected error message %q, got: %q", tc.expErrMsg, msg)
+			}
+		})
+	}
+}
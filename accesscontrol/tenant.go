@@ -0,0 +1,199 @@
+package accesscontrol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/internal/seetie"
+	"github.com/coupergateway/couper/resource"
+)
+
+var _ AccessControl = &TenantControl{}
+
+// tenants maps the tenant ID to its record.
+type tenants map[string]map[string]interface{}
+
+// TenantControl resolves the tenant of a request and exposes its record as request.tenant.
+type TenantControl struct {
+	file        *resource.WatchedFile
+	header      string
+	hostSuffix  string
+	keyExpr     hcl.Expression
+	tenants     tenants
+	withHostKey bool
+}
+
+// NewTenantControl creates a new TenantControl from the given beta_tenants configuration.
+func NewTenantControl(ctx context.Context, conf *config.Tenants, log *logrus.Entry) (*TenantControl, error) {
+	t := &TenantControl{
+		header:  strings.TrimSpace(conf.Header),
+		keyExpr: conf.Key,
+		tenants: make(tenants),
+	}
+
+	if conf.HostPattern != "" {
+		pattern := strings.ToLower(conf.HostPattern)
+		if !strings.HasPrefix(pattern, "*.") || strings.Count(pattern, "*") > 1 {
+			return nil, fmt.Errorf("host_pattern: a single leading '*' label expected: %q", conf.HostPattern)
+		}
+		t.hostSuffix = pattern[1:]
+		t.withHostKey = true
+	}
+
+	if t.keyExpr != nil {
+		if v, _ := t.keyExpr.Value(nil); v.IsNull() {
+			t.keyExpr = nil
+		}
+	}
+
+	if t.header == "" && !t.withHostKey && t.keyExpr == nil {
+		return nil, fmt.Errorf("one of header, host_pattern or key attributes is required")
+	}
+
+	if !conf.Tenants.IsNull() {
+		ty := conf.Tenants.Type()
+		if !ty.IsObjectType() && !ty.IsMapType() {
+			return nil, fmt.Errorf("tenants must be an object")
+		}
+		for id, record := range conf.Tenants.AsValueMap() {
+			rt := record.Type()
+			if !record.IsNull() && !rt.IsObjectType() && !rt.IsMapType() {
+				return nil, fmt.Errorf("invalid record for tenant %q: object expected", id)
+			}
+			t.tenants[id] = seetie.ValueToMap(record)
+		}
+	}
+
+	if conf.TenantsFile != "" {
+		var err error
+		t.file, err = resource.NewWatchedFile(ctx, "beta_tenants tenants_file", conf.TenantsFile, &tenantsUnmarshaller{}, log)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(t.tenants) == 0 && t.file == nil {
+		return nil, fmt.Errorf("tenants or tenants_file attribute required")
+	}
+
+	return t, nil
+}
+
+// Validate resolves the tenant ID from all configured sources, which must agree,
+// and stores the tenant record in the request context.
+func (t *TenantControl) Validate(req *http.Request) error {
+	var ids []string
+
+	if t.withHostKey {
+		id := t.hostKey(req.Host)
+		if id == "" {
+			return errors.BetaTenant.Messagef("host %q does not match host_pattern", req.Host)
+		}
+		ids = append(ids, id)
+	}
+
+	if t.header != "" {
+		id := req.Header.Get(t.header)
+		if id == "" {
+			return errors.BetaTenant.Messagef("missing tenant header %q", t.header)
+		}
+		ids = append(ids, id)
+	}
+
+	if t.keyExpr != nil {
+		keyVal, err := eval.Value(eval.ContextFromRequest(req).HCLContext(), t.keyExpr)
+		if err != nil {
+			return errors.BetaTenant.Message("key").With(err)
+		}
+		if keyVal.IsNull() || keyVal.Type() != cty.String || keyVal.AsString() == "" {
+			return errors.BetaTenant.Message("empty or invalid key value")
+		}
+		ids = append(ids, keyVal.AsString())
+	}
+
+	id := ids[0]
+	for _, other := range ids[1:] {
+		if other != id {
+			return errors.BetaTenant.Messagef("ambiguous tenant: %q and %q", id, other)
+		}
+	}
+
+	record, ok := t.lookup(id)
+	if !ok {
+		return errors.BetaTenant.Messagef("unknown tenant %q", id)
+	}
+
+	tenant := make(map[string]interface{}, len(record)+1)
+	for k, v := range record {
+		tenant[k] = v
+	}
+	tenant["id"] = id
+
+	ctx := context.WithValue(req.Context(), request.Tenant, tenant)
+	*req = *req.WithContext(ctx)
+
+	evalCtx := eval.ContextFromRequest(req)
+	*req = *req.WithContext(evalCtx.WithClientRequest(req))
+
+	return nil
+}
+
+// hostKey returns the label matching the leading '*' of the host_pattern.
+func (t *TenantControl) hostKey(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+
+	if !strings.HasSuffix(host, t.hostSuffix) {
+		return ""
+	}
+	label := strings.TrimSuffix(host, t.hostSuffix)
+	if strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
+
+// lookup prefers inline tenants over the ones from the tenants file.
+func (t *TenantControl) lookup(id string) (map[string]interface{}, bool) {
+	if record, ok := t.tenants[id]; ok {
+		return record, true
+	}
+
+	if t.file == nil {
+		return nil, false
+	}
+
+	fileTenants, _ := t.file.Data().(tenants)
+	record, ok := fileTenants[id]
+	return record, ok
+}
+
+type tenantsUnmarshaller struct{}
+
+func (u *tenantsUnmarshaller) Unmarshal(raw []byte) (interface{}, error) {
+	result := make(tenants)
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("invalid tenants file content: %w", err)
+	}
+
+	for id, record := range result {
+		if record == nil {
+			result[id] = make(map[string]interface{})
+		}
+	}
+	return result, nil
+}
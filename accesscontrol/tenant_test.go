@@ -0,0 +1,110 @@
+package accesscontrol_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	couperErr "github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/internal/test"
+)
+
+var testTenants = cty.ObjectVal(map[string]cty.Value{
+	"acme": cty.ObjectVal(map[string]cty.Value{
+		"origin": cty.StringVal("https://acme.internal"),
+	}),
+})
+
+func Test_NewTenantControl(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		conf      *config.Tenants
+		expErrMsg string
+	}{
+		{"header", &config.Tenants{Header: "X-Tenant", Tenants: testTenants}, ""},
+		{"host pattern", &config.Tenants{HostPattern: "*.example.com", Tenants: testTenants}, ""},
+		{"missing source", &config.Tenants{Tenants: testTenants}, "one of header, host_pattern or key attributes is required"},
+		{"invalid host pattern", &config.Tenants{HostPattern: "api.*.example.com", Tenants: testTenants}, `host_pattern: a single leading '*' label expected: "api.*.example.com"`},
+		{"missing tenants", &config.Tenants{Header: "X-Tenant"}, "tenants or tenants_file attribute required"},
+		{"invalid record", &config.Tenants{Header: "X-Tenant", Tenants: cty.ObjectVal(map[string]cty.Value{
+			"acme": cty.StringVal("https://acme.internal"),
+		})}, `invalid record for tenant "acme": object expected`},
+		{"missing file", &config.Tenants{Header: "X-Tenant", TenantsFile: "testdata/missing.json"}, "configuration error"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := ac.NewTenantControl(context.Background(), tc.conf, nil)
+			if tc.expErrMsg == "" {
+				if err != nil {
+					st.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.expErrMsg {
+				st.Errorf("expected error %q, got: %v", tc.expErrMsg, err)
+			}
+		})
+	}
+}
+
+func Test_TenantControl_Validate(t *testing.T) {
+	helper := test.New(t)
+
+	tenantsFile := filepath.Join(t.TempDir(), "tenants.json")
+	helper.Must(os.WriteFile(tenantsFile, []byte(`{"globex": {"origin": "https://globex.internal"}, "acme": {"origin": "https://other.internal"}}`), 0600))
+
+	tc, err := ac.NewTenantControl(context.Background(), &config.Tenants{
+		Header:      "X-Tenant",
+		HostPattern: "*.example.com",
+		Tenants:     testTenants,
+		TenantsFile: tenantsFile,
+	}, nil)
+	helper.Must(err)
+
+	for _, c := range []struct {
+		name      string
+		host      string
+		header    string
+		expErr    *couperErr.Error
+		expTenant map[string]interface{}
+	}{
+		{"inline tenant", "acme.example.com", "acme", nil, map[string]interface{}{"id": "acme", "origin": "https://acme.internal"}},
+		{"tenant from file", "Globex.Example.com:8443", "globex", nil, map[string]interface{}{"id": "globex", "origin": "https://globex.internal"}},
+		{"missing header", "acme.example.com", "", couperErr.BetaTenant, nil},
+		{"ambiguous", "acme.example.com", "globex", couperErr.BetaTenant, nil},
+		{"host mismatch", "example.com", "acme", couperErr.BetaTenant, nil},
+		{"nested host", "eu.acme.example.com", "acme", couperErr.BetaTenant, nil},
+		{"unknown tenant", "initech.example.com", "initech", couperErr.BetaTenant, nil},
+	} {
+		t.Run(c.name, func(st *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://"+c.host+"/", nil)
+			if c.header != "" {
+				req.Header.Set("X-Tenant", c.header)
+			}
+
+			err := tc.Validate(req)
+			if c.expErr != nil {
+				if !couperErr.Equals(err, c.expErr) {
+					st.Errorf("expected error %v, got: %v", c.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				st.Fatalf("unexpected error: %v", err)
+			}
+
+			tenant, _ := req.Context().Value(request.Tenant).(map[string]interface{})
+			if !reflect.DeepEqual(tenant, c.expTenant) {
+				st.Errorf("expected tenant %v, got: %v", c.expTenant, tenant)
+			}
+		})
+	}
+}
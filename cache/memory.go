@@ -46,6 +46,18 @@ func (ms *MemoryStore) Del(k string) {
 	ms.mu.Unlock()
 }
 
+// DelAllWithPrefix deletes all values with the key prefix from the <MemoryStore> for which match returns true.
+func (ms *MemoryStore) DelAllWithPrefix(prefix string, match func(v interface{}) bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for k, v := range ms.db {
+		if strings.HasPrefix(k, prefix) && match(v.value) {
+			delete(ms.db, k)
+		}
+	}
+}
+
 // Get return the value by the key if the ttl is not expired from the <MemoryStore>.
 func (ms *MemoryStore) Get(k string) interface{} {
 	ms.mu.RLock()
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/config/meta"
+)
+
+var (
+	_ Body   = &APIKey{}
+	_ Inline = &APIKey{}
+)
+
+// APIKey represents the "api_key" config block
+type APIKey struct {
+	ErrorHandlerSetter
+	Cookie              string    `hcl:"cookie,optional" docs:"Read the key from the given cookie. Cannot be used together with {header} or {query_param}."`
+	Header              string    `hcl:"header,optional" docs:"Read the key from the given request header field. Cannot be used together with {cookie} or {query_param}." default:"X-API-Key"`
+	Keys                cty.Value `hcl:"keys,optional" docs:"Object with the lower-case hex encoded SHA-256 hashes of the valid keys as property names and objects with key metadata (e.g. {owner}) as values." type:"object"`
+	KeysFile            string    `hcl:"keys_file,optional" docs:"Reference to a JSON file containing an object like {keys}. The file is reloaded on change."`
+	Name                string    `hcl:"name,label"`
+	PermissionsProperty string    `hcl:"permissions_property,optional" docs:"Name of the key metadata property containing the granted permissions. The property value must either be a string containing a space-separated list of permissions or a list of string permissions." default:"permissions"`
+	QueryParam          string    `hcl:"query_param,optional" docs:"Read the key from the given URL query parameter. Cannot be used together with {cookie} or {header}."`
+	Remain              hcl.Body  `hcl:",remain"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (a *APIKey) HCLBody() *hclsyntax.Body {
+	return a.Remain.(*hclsyntax.Body)
+}
+
+func (a *APIKey) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (a *APIKey) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(a)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(a.Inline())
+	return schema
+}
@@ -20,12 +20,33 @@ var (
 // BasicAuth represents the "basic_auth" config block
 type BasicAuth struct {
 	ErrorHandlerSetter
-	File   string   `hcl:"htpasswd_file,optional" docs:"The htpasswd file."`
-	Name   string   `hcl:"name,label"`
-	User   string   `hcl:"user,optional" docs:"The user name."`
-	Pass   string   `hcl:"password,optional" docs:"The corresponding password."`
-	Realm  string   `hcl:"realm,optional" docs:"The realm to be sent in a WWW-Authenticate response HTTP header field."`
-	Remain hcl.Body `hcl:",remain"`
+	File   string         `hcl:"htpasswd_file,optional" docs:"The htpasswd file. The file is reloaded on modification."`
+	LDAP   *BasicAuthLDAP `hcl:"ldap,block" docs:"Configures an [LDAP authentication](/configuration/block/basic_auth_ldap) (zero or one)."`
+	Name   string         `hcl:"name,label"`
+	User   string         `hcl:"user,optional" docs:"The user name."`
+	Pass   string         `hcl:"password,optional" docs:"The corresponding password."`
+	Realm  string         `hcl:"realm,optional" docs:"The realm to be sent in a WWW-Authenticate response HTTP header field."`
+	Remain hcl.Body       `hcl:",remain"`
+}
+
+// BasicAuthLDAP represents the "ldap" block of a <BasicAuth>.
+type BasicAuthLDAP struct {
+	BindDN                string              `hcl:"bind_dn,optional" docs:"Template of the DN to bind with the client credentials, see [placeholders](#placeholders). Mutually exclusive with {user_filter}."`
+	CacheTTL              string              `hcl:"cache_ttl,optional" docs:"Time-to-live of cached successful authentications." type:"duration" default:"0s"`
+	DisableCertValidation bool                `hcl:"disable_certificate_validation,optional" docs:"Disables the peer certificate validation."`
+	GroupBaseDN           string              `hcl:"group_base_dn,optional" docs:"Base DN of the group search. Enables the group membership lookup."`
+	GroupFilter           string              `hcl:"group_filter,optional" docs:"Filter of the group search, see [placeholders](#placeholders)." default:"(member={dn})"`
+	GroupNameAttribute    string              `hcl:"group_name_attribute,optional" docs:"Attribute of the found groups containing the group name." default:"cn"`
+	GroupsMap             map[string][]string `hcl:"groups_map,optional" docs:"Mapping of group names to granted permissions. Non-mapped groups can be assigned with {*} to specific permissions."`
+	SearchBindDN          string              `hcl:"search_bind_dn,optional" docs:"DN to bind with for the user and group searches. Searches are anonymous if not set."`
+	SearchBindPassword    string              `hcl:"search_bind_password,optional" docs:"Password of the {search_bind_dn}."`
+	ServerCertificate     string              `hcl:"server_ca_certificate,optional" docs:"Public part of the certificate authority in DER or PEM format. Mutually exclusive with {server_ca_certificate_file}."`
+	ServerCertificateFile string              `hcl:"server_ca_certificate_file,optional" docs:"Reference to a file containing the public part of the certificate authority file in DER or PEM format. Mutually exclusive with {server_ca_certificate}."`
+	StartTLS              bool                `hcl:"start_tls,optional" docs:"Upgrades an {ldap://} connection with StartTLS."`
+	Timeout               string              `hcl:"timeout,optional" docs:"The timeout of the connection and each LDAP operation." type:"duration" default:"5s"`
+	URL                   string              `hcl:"url" docs:"URL of the LDAP server, {ldap://} or {ldaps://}."`
+	UserBaseDN            string              `hcl:"user_base_dn,optional" docs:"Base DN of the user search. Required with {user_filter}."`
+	UserFilter            string              `hcl:"user_filter,optional" docs:"Filter of the user search, see [placeholders](#placeholders). The found user is bound with the client password. Mutually exclusive with {bind_dn}."`
 }
 
 // HCLBody implements the <Body> interface. Internally used for 'error_handler'.
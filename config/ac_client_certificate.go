@@ -0,0 +1,52 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config/meta"
+)
+
+var (
+	_ Body   = &ClientCertificateAC{}
+	_ Inline = &ClientCertificateAC{}
+)
+
+// ClientCertificateAC represents the "client_certificate" access control block.
+type ClientCertificateAC struct {
+	ErrorHandlerSetter
+	DNSNames     []string            `hcl:"dns_names,optional" docs:"List of accepted DNS names from the subject alternative names."`
+	Fingerprints []string            `hcl:"fingerprints,optional" docs:"List of accepted hex encoded SHA-256 certificate fingerprints. Colons and letter case are ignored."`
+	Issuers      []string            `hcl:"issuers,optional" docs:"List of accepted issuer distinguished names in RFC 2253 format, e.g. {\"CN=Acme CA,O=Acme\"}."`
+	Name         string              `hcl:"name,label"`
+	Remain       hcl.Body            `hcl:",remain"`
+	RolesMap     map[string][]string `hcl:"roles_map,optional" docs:"Mapping of certificate identities (subject distinguished name, subject alternative DNS names, email addresses and URIs, or SHA-256 fingerprint) to granted permissions. Non-mapped certificates can be assigned with {*} to specific permissions. Mutually exclusive with {roles_map_file}."`
+	RolesMapFile string              `hcl:"roles_map_file,optional" docs:"Reference to JSON file containing identity mappings. Mutually exclusive with {roles_map}. See {roles_map} for more information."`
+	Subjects     []string            `hcl:"subjects,optional" docs:"List of accepted subject distinguished names in RFC 2253 format, e.g. {\"CN=svc-a,O=Acme\"}."`
+	URIs         []string            `hcl:"uris,optional" docs:"List of accepted URIs from the subject alternative names, e.g. SPIFFE IDs. A trailing {*} matches any URI with the given prefix, e.g. {\"spiffe://acme.org/ns/prod/*\"}."`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (c *ClientCertificateAC) HCLBody() *hclsyntax.Body {
+	return c.Remain.(*hclsyntax.Body)
+}
+
+func (c *ClientCertificateAC) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (c *ClientCertificateAC) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(c)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(c.Inline())
+	return schema
+}
@@ -23,9 +23,13 @@ var (
 type ExternalAuthZ struct {
 	ErrorHandlerSetter
 	BackendName         string   `hcl:"backend,optional" docs:"References a [backend](/configuration/block/backend) in [definitions](/configuration/block/definitions) for the authorization callout. Mutually exclusive with {backend} block."`
+	CacheTTL            string   `hcl:"cache_ttl,optional" docs:"Time-to-live of cached decisions if the authorization service response has no {Cache-Control} {max-age} directive. Requires {cache_key}." type:"duration" default:"0s"`
 	IncludeTLS          bool     `hcl:"include_tls,optional" docs:"Include TLS connection information of the client request in the authorization request." default:"false"`
 	Name                string   `hcl:"name,label"`
+	OnError             string   `hcl:"on_error,optional" docs:"Handling of failed authorization callouts, e.g. timeouts or unexpected response status codes: {\"deny\"}, {\"allow\"} (without permissions) or {\"allow_with_flag\"} (like {\"allow\"}, but exposes the error message as {request.context.<label>.error})." default:"deny"`
 	PermissionsProperty string   `hcl:"permissions_property,optional" docs:"Name of the response body property containing the granted permissions. The property value must either be a string containing a space-separated list of permissions or a list of string permissions."`
+	Protocol            string   `hcl:"protocol,optional" docs:"Protocol of the authorization callout: {\"http\"} posts a JSON description of the client request, {\"envoy_grpc\"} calls the Envoy {envoy.service.auth.v3.Authorization/Check} gRPC method and requires an HTTP/2 {backend}." default:"http"`
+	Timeout             string   `hcl:"timeout,optional" docs:"The total deadline duration of the authorization callout." type:"duration"`
 	URL                 string   `hcl:"url,optional" docs:"URL of the authorization service. Relative URL references are resolved against the origin of a referenced or nested {backend} block."`
 	Remain              hcl.Body `hcl:",remain"`
 
@@ -63,7 +67,8 @@ func (a *ExternalAuthZ) HCLBody() *hclsyntax.Body {
 func (a *ExternalAuthZ) Inline() interface{} {
 	type Inline struct {
 		meta.LogFieldsAttribute
-		Backend *Backend `hcl:"backend,block" docs:"Configures a [backend](/configuration/block/backend) for the authorization callout (zero or one). Mutually exclusive with {backend} attribute."`
+		Backend  *Backend `hcl:"backend,block" docs:"Configures a [backend](/configuration/block/backend) for the authorization callout (zero or one). Mutually exclusive with {backend} attribute."`
+		CacheKey string   `hcl:"cache_key,optional" docs:"Expression evaluated per client request whose value identifies a cacheable decision, e.g. {[request.headers.authorization, request.method, request.path]}. If set, allow, invalid credentials and insufficient permissions decisions are cached, see {cache_ttl}."`
 	}
 
 	return &Inline{}
@@ -72,15 +77,13 @@ func (a *ExternalAuthZ) Inline() interface{} {
 // DefaultErrorHandlers forwards the authorization service's WWW-Authenticate challenge
 // on denied credentials so clients can bootstrap authentication (e.g. OAuth protected
 // resource metadata discovery); a user-defined handler for the kind replaces it.
+// With the envoy_grpc protocol the denied_response of the check result is sent instead.
 func (a *ExternalAuthZ) DefaultErrorHandlers() []*ErrorHandler {
-	challenge := &hclsyntax.ScopeTraversalExpr{
-		Traversal: hcl.Traversal{
-			hcl.TraverseRoot{Name: "request"},
-			hcl.TraverseAttr{Name: "context"},
-			hcl.TraverseAttr{Name: a.Name},
-			hcl.TraverseAttr{Name: "www_authenticate"},
-		},
+	if a.Protocol == "envoy_grpc" {
+		return a.deniedResponseErrorHandlers()
 	}
+
+	challenge := a.contextTraversal("www_authenticate")
 	headers := &hclsyntax.ObjectConsExpr{
 		Items: []hclsyntax.ObjectConsItem{
 			{
@@ -107,6 +110,41 @@ func (a *ExternalAuthZ) DefaultErrorHandlers() []*ErrorHandler {
 	}
 }
 
+// deniedResponseErrorHandlers responds with the status, headers and body of the
+// Envoy denied_response, separately replaceable per error kind.
+func (a *ExternalAuthZ) deniedResponseErrorHandlers() []*ErrorHandler {
+	var handlers []*ErrorHandler
+	for _, kind := range []string{"external_authz_invalid_credentials", "external_authz_insufficient_permissions"} {
+		attributes := hclsyntax.Attributes{}
+		for _, name := range []string{"body", "headers", "status"} {
+			attributes[name] = &hclsyntax.Attribute{
+				Name:     name,
+				Expr:     a.contextTraversal("denied_response", name),
+				SrcRange: hcl.Range{Filename: "default_external_authz_error_handler"},
+			}
+		}
+		handlers = append(handlers, &ErrorHandler{
+			Kinds:    []string{kind},
+			Remain:   &hclsyntax.Body{},
+			Response: &Response{Remain: &hclsyntax.Body{Attributes: attributes}},
+		})
+	}
+	return handlers
+}
+
+// contextTraversal references the given request.context.<label> property.
+func (a *ExternalAuthZ) contextTraversal(names ...string) hclsyntax.Expression {
+	traversal := hcl.Traversal{
+		hcl.TraverseRoot{Name: "request"},
+		hcl.TraverseAttr{Name: "context"},
+		hcl.TraverseAttr{Name: a.Name},
+	}
+	for _, name := range names {
+		traversal = append(traversal, hcl.TraverseAttr{Name: name})
+	}
+	return &hclsyntax.ScopeTraversalExpr{Traversal: traversal}
+}
+
 // Schema implements the <Inline> interface.
 func (a *ExternalAuthZ) Schema(inline bool) *hcl.BodySchema {
 	if !inline {
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config/meta"
+)
+
+var (
+	_ Body   = &HTTPMessageSignatureAC{}
+	_ Inline = &HTTPMessageSignatureAC{}
+)
+
+// HTTPMessageSignatureAC represents the "http_message_signature" access control block.
+type HTTPMessageSignatureAC struct {
+	ErrorHandlerSetter
+	Key                string   `hcl:"key,optional" docs:"Public key (in PEM format) for {RS*}, {PS*}, {ES*} and {EdDSA} variants or the secret for {HS*} algorithms. Mutually exclusive with {key_file}."`
+	KeyFile            string   `hcl:"key_file,optional" docs:"Reference to file containing the verification key. Mutually exclusive with {key}. See {key} for more information."`
+	KeyID              string   `hcl:"key_id,optional" docs:"If set, the {keyid} signature parameter must have this value."`
+	Label              string   `hcl:"label,optional" docs:"The label of the signature to verify. Defaults to the first signature in the {Signature-Input} header field."`
+	MaxAge             string   `hcl:"max_age,optional" docs:"The maximum age of a signature according to its {created} signature parameter." type:"duration" default:"5m"`
+	Name               string   `hcl:"name,label"`
+	RequiredComponents []string `hcl:"required_components,optional" docs:"List of component identifiers which must be covered by the signature. If {\"content-digest\"} is covered, the digest is compared with the request body." default:"[\"@method\", \"@authority\", \"@path\"]"`
+	SignatureAlgorithm string   `hcl:"signature_algorithm" docs:"Valid values: {\"RS256\"}, {\"RS384\"}, {\"RS512\"}, {\"HS256\"}, {\"HS384\"}, {\"HS512\"}, {\"ES256\"}, {\"ES384\"}, {\"ES512\"}, {\"PS256\"}, {\"PS384\"}, {\"PS512\"}, {\"EdDSA\"}."`
+	Remain             hcl.Body `hcl:",remain"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (h *HTTPMessageSignatureAC) HCLBody() *hclsyntax.Body {
+	return h.Remain.(*hclsyntax.Body)
+}
+
+func (h *HTTPMessageSignatureAC) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (h *HTTPMessageSignatureAC) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(h)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(h.Inline())
+	return schema
+}
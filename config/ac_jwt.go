@@ -31,6 +31,8 @@ type JWT struct {
 	Claims                Claims              `hcl:"claims,optional" docs:"Object with claims that must be given for a valid token (equals comparison with JWT payload). The claim values are evaluated per request."`
 	ClaimsRequired        []string            `hcl:"required_claims,optional" docs:"List of claim names that must be given for a valid token."`
 	Cookie                string              `hcl:"cookie,optional" docs:"Read token value from a cookie. Cannot be used together with {bearer}, {beta_dpop}, {header} or {token_value}"`
+	DecryptionKey         string              `hcl:"decryption_key,optional" docs:"Private key (in PEM format) for the {RSA-OAEP}, {RSA-OAEP-256} and {ECDH-ES} key management algorithms or the symmetric key for {dir} to decrypt JWE tokens. Mutually exclusive with {decryption_key_file}."`
+	DecryptionKeyFile     string              `hcl:"decryption_key_file,optional" docs:"Reference to file containing the decryption key. Mutually exclusive with {decryption_key}. See {decryption_key} for more information."`
 	DisablePrivateCaching bool                `hcl:"disable_private_caching,optional" docs:"If set to {true}, Couper does not add the {private} directive to the {Cache-Control} HTTP header field value."`
 	Dpop                  bool                `hcl:"beta_dpop,optional" docs:"If set to {true} the token is obtained from an {Authorization: DPoP ...} request header. Cannot be used together with {bearer}, {cookie}, {header} or {token_value}."`
 	Header                string              `hcl:"header,optional" docs:"Read token value from the given request header field. Implies {Bearer} if {Authorization} (case-insensitive) is used (deprecated!), otherwise any other header name can be used. Cannot be used together with {bearer}, {cookie}, {beta_dpop} or {token_value}."`
@@ -38,7 +40,7 @@ type JWT struct {
 	JWKsURL               string              `hcl:"jwks_url,optional" docs:"URI pointing to a set of [JSON Web Keys (RFC 7517)](https://datatracker.ietf.org/doc/html/rfc7517)"`
 	JWKsTTL               string              `hcl:"jwks_ttl,optional" docs:"Time period the JWK set stays valid and may be cached." type:"duration" default:"1h"`
 	JWKsMaxStale          string              `hcl:"jwks_max_stale,optional" docs:"Time period the cached JWK set stays valid after its TTL has passed." type:"duration" default:"1h"`
-	Key                   string              `hcl:"key,optional" docs:"Public key (in PEM format) for {RS*} and {ES*} variants or the secret for {HS*} algorithm. Mutually exclusive with {key_file}."`
+	Key                   string              `hcl:"key,optional" docs:"Public key (in PEM format) for {RS*}, {PS*}, {ES*} and {EdDSA} variants or the secret for {HS*} algorithm. Mutually exclusive with {key_file}."`
 	KeyFile               string              `hcl:"key_file,optional" docs:"Reference to file containing verification key. Mutually exclusive with {key}. See {key} for more information."`
 	Name                  string              `hcl:"name,label"`
 	Remain                hcl.Body            `hcl:",remain"`
@@ -48,9 +50,10 @@ type JWT struct {
 	PermissionsClaim      string              `hcl:"permissions_claim,optional" docs:"Name of claim containing the granted permissions. The claim value must either be a string containing a space-separated list of permissions or a list of string permissions."`
 	PermissionsMap        map[string][]string `hcl:"permissions_map,optional" docs:"Mapping of granted permissions to additional granted permissions. Maps values from {permissions_claim} and those created from {roles_map}. The map is called recursively. Mutually exclusive with {permissions_map_file}."`
 	PermissionsMapFile    string              `hcl:"permissions_map_file,optional" docs:"Reference to JSON file containing permission mappings. Mutually exclusive with {permissions_map}. See {permissions_map} for more information."`
-	SignatureAlgorithm    string              `hcl:"signature_algorithm,optional" docs:"Valid values: {RS256}, {RS384}, {RS512}, {HS256}, {HS384}, {HS512}, {ES256}, {ES384}, {ES512}"`
-	SigningKey            string              `hcl:"signing_key,optional" docs:"Private key (in PEM format) for {RS*} and {ES*} variants. Mutually exclusive with {signing_key_file}."`
+	SignatureAlgorithm    string              `hcl:"signature_algorithm,optional" docs:"Valid values: {RS256}, {RS384}, {RS512}, {HS256}, {HS384}, {HS512}, {ES256}, {ES384}, {ES512}, {PS256}, {PS384}, {PS512}, {EdDSA}"`
+	SigningKey            string              `hcl:"signing_key,optional" docs:"Private key (in PEM format) for {RS*}, {PS*}, {ES*} and {EdDSA} variants. Mutually exclusive with {signing_key_file}."`
 	SigningKeyFile        string              `hcl:"signing_key_file,optional" docs:"Reference to file containing signing key. Mutually exclusive with {signing_key}. See {signing_key} for more information."`
+	SigningKeyID          string              `hcl:"signing_key_id,optional" docs:"The key ID, set as {kid} header of the signed tokens and used for the published [JWKS](/configuration/block/server) entry. Defaults to the JWK thumbprint of the public key for the JWKS."`
 	SigningTTL            string              `hcl:"signing_ttl,optional" docs:"The token's time-to-live (creates the {exp} claim)." type:"duration"`
 	TokenValue            hcl.Expression      `hcl:"token_value,optional" docs:"Expression to obtain the token. Cannot be used together with {bearer}, {cookie}, {beta_dpop} or {header}." type:"string"`
 
@@ -153,7 +156,7 @@ func (j *JWT) DefaultErrorHandlers() []*ErrorHandler {
 		return []*ErrorHandler{}
 	}
 	if j.Dpop {
-		wwwAuthenticateValue := `DPoP algs="RS256 RS384 RS512 ES256 ES384 ES512"`
+		wwwAuthenticateValue := `DPoP algs="RS256 RS384 RS512 ES256 ES384 ES512 PS256 PS384 PS512 EdDSA"`
 		return []*ErrorHandler{
 			{
 				Kinds: []string{"jwt_token_missing"},
@@ -23,6 +23,7 @@ var (
 type OIDC struct {
 	ErrorHandlerSetter
 	BackendName             string             `hcl:"backend,optional" docs:"References a default [backend](/configuration/block/backend) in [definitions](/configuration/block/definitions) for OpenID configuration, JWKS, token and userinfo requests. Mutually exclusive with {backend} block."`
+	BackchannelLogoutPath   string             `hcl:"backchannel_logout_path,optional" docs:"If set, Couper receives [back-channel logout](https://openid.net/specs/openid-connect-backchannel-1_0.html) requests of the OpenID provider at this path of every server. Sessions of the referenced {session} block and cached introspection responses matching the logout token are invalidated."`
 	ClientID                string             `hcl:"client_id" docs:"The client identifier."`
 	ClientSecret            string             `hcl:"client_secret,optional" docs:"The client password. Required unless {token_endpoint_auth_method} is {\"private_key_jwt\"}."`
 	ConfigurationURL        string             `hcl:"configuration_url" docs:"The OpenID configuration URL."`
@@ -31,7 +32,9 @@ type OIDC struct {
 	JWTSigningProfile       *JWTSigningProfile `hcl:"jwt_signing_profile,block" docs:"Configures a [JWT signing profile](/configuration/block/jwt_signing_profile) to create a client assertion if {token_endpoint_auth_method} is either {\"client_secret_jwt\"} or {\"private_key_jwt\"}."`
 	Name                    string             `hcl:"name,label"`
 	Remain                  hcl.Body           `hcl:",remain"`
+	PostLogoutRedirectURI   string             `hcl:"post_logout_redirect_uri,optional" docs:"The URL the OpenID provider redirects to after a logout initiated with [{oidc_logout_url()}](/configuration/functions). Relative URL references are resolved against the origin of the current request URL."`
 	RedirectURI             string             `hcl:"redirect_uri" docs:"The Couper endpoint for receiving the authorization code. Relative URL references are resolved against the origin of the current request URL. The origin can be changed with the [{accept_forwarded_url} attribute](settings) if Couper is running behind a proxy."`
+	Session                 string             `hcl:"session,optional" docs:"References a [session](/configuration/block/session) block. If set, the tokens of the token response are stored along with the session created by [{session_write()}](/configuration/functions) and the access token is refreshed with the refresh token when it expires."`
 	Scope                   string             `hcl:"scope,optional" docs:"A space separated list of requested scope values for the access token."`
 	TokenEndpointAuthMethod *string            `hcl:"token_endpoint_auth_method,optional" docs:"Defines the method to authenticate the client at the token endpoint. If set to {\"client_secret_post\"}, the client credentials are transported in the request body. If set to {\"client_secret_basic\"}, the client credentials are transported via Basic Authentication. If set to {\"client_secret_jwt\"}, the client is authenticated via a JWT signed with the {client_secret}. If set to {\"private_key_jwt\"}, the client is authenticated via a JWT signed with its private key (see {jwt_signing_profile} block)." default:"client_secret_basic"`
 	ConfigurationTTL        string             `hcl:"configuration_ttl,optional" docs:"The duration to cache the OpenID configuration located at {configuration_url}." type:"duration" default:"1h"`
@@ -117,6 +120,10 @@ func (o *OIDC) GetGrantType() string {
 	return "authorization_code"
 }
 
+func (o *OIDC) GetPostLogoutRedirectURI() string {
+	return o.PostLogoutRedirectURI
+}
+
 func (o *OIDC) GetRedirectURI() string {
 	return o.RedirectURI
 }
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config/meta"
+)
+
+var (
+	_ Body   = &Policy{}
+	_ Inline = &Policy{}
+)
+
+// Policy represents the "beta_policy" config block
+type Policy struct {
+	ErrorHandlerSetter
+	File   string   `hcl:"file" docs:"Location of the policy file containing a [CEL](https://cel.dev) expression which must evaluate to {true} to allow the request. The file is reloaded on modification."`
+	Name   string   `hcl:"name,label"`
+	Remain hcl.Body `hcl:",remain"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (p *Policy) HCLBody() *hclsyntax.Body {
+	return p.Remain.(*hclsyntax.Body)
+}
+
+// Inline implements the <Inline> interface.
+func (p *Policy) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (p *Policy) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(p)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(p.Inline())
+	return meta.MergeSchemas(schema, meta.LogFieldsAttributeSchema)
+}
@@ -21,16 +21,21 @@ var (
 // SAML represents the <SAML> object.
 type SAML struct {
 	ErrorHandlerSetter
-	ArrayAttributes  []string `hcl:"array_attributes,optional" docs:"A list of assertion attributes that may have several values. Results in at least an empty array in {request.context.<label>.attributes.<name>}"`
-	IdpMetadataFile  string   `hcl:"idp_metadata_file,optional" docs:"File reference to the Identity Provider metadata XML file. Mutually exclusive with {idp_metadata_url}."`
-	IdpMetadataURL   string   `hcl:"idp_metadata_url,optional" docs:"URL to fetch the Identity Provider metadata XML. Mutually exclusive with {idp_metadata_file}."`
-	MetadataTTL      string   `hcl:"metadata_ttl,optional" docs:"Time period the IdP metadata stays valid and may be cached." type:"duration" default:"1h"`
-	MetadataMaxStale string   `hcl:"metadata_max_stale,optional" docs:"Time period the cached IdP metadata stays valid after its TTL has passed." type:"duration" default:"1h"`
-	BackendName      string   `hcl:"backend,optional" docs:"References a [backend](/configuration/block/backend) in [definitions](/configuration/block/definitions) for IdP metadata requests. Mutually exclusive with {backend} block."`
-	Name             string   `hcl:"name,label"`
-	Remain           hcl.Body `hcl:",remain"`
-	SpAcsURL         string   `hcl:"sp_acs_url" docs:"The URL of the Service Provider's ACS endpoint. Relative URL references are resolved against the origin of the current request URL. The origin can be changed with the [{accept_forwarded_url} attribute](settings) if Couper is running behind a proxy."`
-	SpEntityID       string   `hcl:"sp_entity_id" docs:"The Service Provider's entity ID."`
+	ArrayAttributes   []string `hcl:"array_attributes,optional" docs:"A list of assertion attributes that may have several values. Results in at least an empty array in {request.context.<label>.attributes.<name>}"`
+	IdpMetadataFile   string   `hcl:"idp_metadata_file,optional" docs:"File reference to the Identity Provider metadata XML file. Mutually exclusive with {idp_metadata_url}."`
+	IdpMetadataURL    string   `hcl:"idp_metadata_url,optional" docs:"URL to fetch the Identity Provider metadata XML. Mutually exclusive with {idp_metadata_file}."`
+	MetadataTTL       string   `hcl:"metadata_ttl,optional" docs:"Time period the IdP metadata stays valid and may be cached." type:"duration" default:"1h"`
+	MetadataMaxStale  string   `hcl:"metadata_max_stale,optional" docs:"Time period the cached IdP metadata stays valid after its TTL has passed." type:"duration" default:"1h"`
+	BackendName       string   `hcl:"backend,optional" docs:"References a [backend](/configuration/block/backend) in [definitions](/configuration/block/definitions) for IdP metadata requests. Mutually exclusive with {backend} block."`
+	Name              string   `hcl:"name,label"`
+	Remain            hcl.Body `hcl:",remain"`
+	SpAcsURL          string   `hcl:"sp_acs_url" docs:"The URL of the Service Provider's ACS endpoint. Relative URL references are resolved against the origin of the current request URL. The origin can be changed with the [{accept_forwarded_url} attribute](settings) if Couper is running behind a proxy."`
+	SpCertificate     string   `hcl:"sp_certificate,optional" docs:"Certificate (in PEM format) of the Service Provider key. Published with the SP metadata. Mutually exclusive with {sp_certificate_file}."`
+	SpCertificateFile string   `hcl:"sp_certificate_file,optional" docs:"Reference to a file containing the Service Provider certificate. Mutually exclusive with {sp_certificate}."`
+	SpEntityID        string   `hcl:"sp_entity_id" docs:"The Service Provider's entity ID."`
+	SpPrivateKey      string   `hcl:"sp_private_key,optional" docs:"RSA private key (in PEM format) of the Service Provider for signing AuthnRequests and logout messages and for decrypting encrypted assertions. Mutually exclusive with {sp_private_key_file}."`
+	SpPrivateKeyFile  string   `hcl:"sp_private_key_file,optional" docs:"Reference to a file containing the Service Provider private key. Mutually exclusive with {sp_private_key}."`
+	SpSloURL          string   `hcl:"sp_slo_url,optional" docs:"The URL of the Service Provider's Single Logout endpoint. Relative URL references are resolved against the origin of the current request URL."`
 
 	// internally used
 	MetadataBytes []byte
@@ -80,6 +85,12 @@ func (s *SAML) check() error {
 		return fmt.Errorf("idp_metadata_file and idp_metadata_url are mutually exclusive")
 	}
 
+	hasCert := s.SpCertificate != "" || s.SpCertificateFile != ""
+	hasKey := s.SpPrivateKey != "" || s.SpPrivateKeyFile != ""
+	if hasCert != hasKey {
+		return fmt.Errorf("sp_certificate and sp_private_key must be configured together")
+	}
+
 	if s.IdpMetadataFile != "" {
 		if s.BackendName != "" || s.Backend != nil {
 			return fmt.Errorf("backend is only valid with idp_metadata_url")
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config/meta"
+)
+
+var (
+	_ Body   = &Session{}
+	_ Inline = &Session{}
+)
+
+// Session represents the "session" access control block.
+type Session struct {
+	ErrorHandlerSetter
+	AbsoluteTimeout string   `hcl:"absolute_timeout,optional" docs:"Maximum lifetime of a session regardless of its activity." type:"duration" default:"24h"`
+	CookieDomain    string   `hcl:"cookie_domain,optional" docs:"The {Domain} attribute of the session cookie."`
+	CookieName      string   `hcl:"cookie_name,optional" docs:"Name of the cookie transporting the session ID. Defaults to the block label."`
+	CookiePath      string   `hcl:"cookie_path,optional" docs:"The {Path} attribute of the session cookie." default:"/"`
+	CookieSameSite  string   `hcl:"cookie_same_site,optional" docs:"The {SameSite} attribute of the session cookie: {\"Strict\"}, {\"Lax\"} or {\"None\"}." default:"Lax"`
+	CookieSecure    *bool    `hcl:"cookie_secure,optional" docs:"Whether the {Secure} attribute is added to the session cookie." default:"true"`
+	IdleTimeout     string   `hcl:"idle_timeout,optional" docs:"A session expires if it has not been used for this time period." type:"duration" default:"30m"`
+	Name            string   `hcl:"name,label"`
+	Remain          hcl.Body `hcl:",remain"`
+	Store           string   `hcl:"store,optional" docs:"The server-side session storage." default:"memory"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (s *Session) HCLBody() *hclsyntax.Body {
+	return s.Remain.(*hclsyntax.Body)
+}
+
+func (s *Session) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (s *Session) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(s)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(s.Inline())
+	return schema
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config/meta"
+)
+
+var (
+	_ Body   = &Signature{}
+	_ Inline = &Signature{}
+)
+
+// Signature represents the "signature" config block
+type Signature struct {
+	ErrorHandlerSetter
+	Algorithm          string   `hcl:"algorithm,optional" docs:"The HMAC hash algorithm. Valid values: {\"sha1\"}, {\"sha256\"}, {\"sha512\"}." default:"sha256"`
+	Components         []string `hcl:"components,optional" docs:"List of request components the HMAC is computed over, in the given order. Valid values: {\"body\"}, {\"method\"}, {\"path\"}, {\"timestamp\"} and {\"header.<name>\"}." default:"[\"body\"]"`
+	Encoding           string   `hcl:"encoding,optional" docs:"The encoding of the signature value. Valid values: {\"hex\"}, {\"base64\"}." default:"hex"`
+	Header             string   `hcl:"header,optional" docs:"The request header field containing the signature." default:"X-Signature"`
+	Name               string   `hcl:"name,label"`
+	Prefix             string   `hcl:"prefix,optional" docs:"A prefix of the signature header value, e.g. {\"sha256=\"}, which is removed before the comparison."`
+	Remain             hcl.Body `hcl:",remain"`
+	Secret             string   `hcl:"secret,optional" docs:"The shared secret. Mutually exclusive with {secret_file}."`
+	SecretFile         string   `hcl:"secret_file,optional" docs:"Reference to a file containing the shared secret. Mutually exclusive with {secret}."`
+	Separator          string   `hcl:"separator,optional" docs:"The separator joining the {components}." default:"."`
+	TimestampHeader    string   `hcl:"timestamp_header,optional" docs:"The request header field containing the signing time as Unix timestamp. Required if {components} contains {\"timestamp\"}; if set, the timestamp must be one of the {components}. Requests with a timestamp outside of the {timestamp_tolerance} are rejected."`
+	TimestampTolerance string   `hcl:"timestamp_tolerance,optional" docs:"The maximum allowed difference between the timestamp and the current time." type:"duration" default:"5m"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (s *Signature) HCLBody() *hclsyntax.Body {
+	return s.Remain.(*hclsyntax.Body)
+}
+
+func (s *Signature) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (s *Signature) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(s)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(s.Inline())
+	return schema
+}
@@ -29,8 +29,10 @@ type Backend struct {
 	TLS                    *BackendTLS `hcl:"tls,block" docs:"Configures [backend TLS](/configuration/block/backend_tls) (zero or one)."`
 
 	// used for validation and documentation
-	OAuth2       *OAuth2ReqAuth  `hcl:"oauth2,block" docs:"Configures an [OAuth2 authorization](/configuration/block/oauth2) (zero or one)."`
-	TokenRequest []*TokenRequest `hcl:"beta_token_request,block" docs:"Configures a [token request authorization](/configuration/block/token_request) (zero or more)."`
+	HTTPMessageSignature *HTTPMessageSignature `hcl:"http_message_signature,block" docs:"Configures [HTTP message signing](/configuration/block/http_message_signature) (zero or one)."`
+	OAuth2               *OAuth2ReqAuth        `hcl:"oauth2,block" docs:"Configures an [OAuth2 authorization](/configuration/block/oauth2) (zero or one)."`
+	SigV4                *SigV4                `hcl:"sigv4,block" docs:"Configures [AWS Signature Version 4 signing](/configuration/block/sigv4) (zero or one)."`
+	TokenRequest         []*TokenRequest       `hcl:"beta_token_request,block" docs:"Configures a [token request authorization](/configuration/block/token_request) (zero or more)."`
 }
 
 // Reference implements the <BackendReference> interface.
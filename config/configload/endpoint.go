@@ -55,6 +55,17 @@ func refineEndpoints(helper *helper, endpoints config.Endpoints, checkPathPatter
 			}
 		}
 
+		if checkPathPattern && ep.Match != nil && len(ep.Match.Methods) > 0 {
+			for _, b := range endpointBody.Blocks {
+				if b.Type != endpointMatch {
+					continue
+				}
+				if err = validMethods(ep.Match.Methods, b.Body.Attributes["methods"]); err != nil {
+					return err
+				}
+			}
+		}
+
 		if checkPathPattern && len(ep.Proxies)+len(ep.Requests) == 0 && ep.Response == nil {
 			r := endpointBody.SrcRange
 			return newDiagErr(&r,
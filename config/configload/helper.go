@@ -477,9 +477,18 @@ func (h *helper) getDefinedACs() map[string]struct{} {
 		definedACs[ac.Name] = struct{}{}
 	}
 
+	for _, ac := range h.config.Definitions.APIKey {
+		definedACs[ac.Name] = struct{}{}
+	}
 	for _, ac := range h.config.Definitions.BasicAuth {
 		definedACs[ac.Name] = struct{}{}
 	}
+	for _, ac := range h.config.Definitions.ClientCertificate {
+		definedACs[ac.Name] = struct{}{}
+	}
+	for _, ac := range h.config.Definitions.HTTPMessageSignature {
+		definedACs[ac.Name] = struct{}{}
+	}
 	for _, ac := range h.config.Definitions.JWT {
 		definedACs[ac.Name] = struct{}{}
 	}
@@ -489,12 +498,21 @@ func (h *helper) getDefinedACs() map[string]struct{} {
 	for _, ac := range h.config.Definitions.OIDC {
 		definedACs[ac.Name] = struct{}{}
 	}
+	for _, ac := range h.config.Definitions.Policy {
+		definedACs[ac.Name] = struct{}{}
+	}
 	for _, ac := range h.config.Definitions.RateLimiter {
 		definedACs[ac.Name] = struct{}{}
 	}
 	for _, ac := range h.config.Definitions.SAML {
 		definedACs[ac.Name] = struct{}{}
 	}
+	for _, ac := range h.config.Definitions.Session {
+		definedACs[ac.Name] = struct{}{}
+	}
+	for _, ac := range h.config.Definitions.Signature {
+		definedACs[ac.Name] = struct{}{}
+	}
 
 	return definedACs
 }
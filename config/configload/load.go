@@ -24,6 +24,7 @@ const (
 	defaults                     = "defaults"
 	definitions                  = "definitions"
 	endpoint                     = "endpoint"
+	endpointMatch                = "match"
 	environment                  = "environment"
 	environmentVars              = "environment_variables"
 	errorHandler                 = "error_handler"
@@ -37,6 +38,7 @@ const (
 	settings                     = "settings"
 	spa                          = "spa"
 	tls                          = "tls"
+	tokenEndpoint                = "token_endpoint"
 	tokenRequest                 = "beta_token_request"
 	betaRateLimit                = "beta_rate_limit"
 	throttle                     = "throttle"
@@ -56,20 +58,27 @@ func init() {
 		"ca_file",
 		"client_certificate_file",
 		"client_private_key_file",
+		"decryption_key_file",
 		"document_root",
+		"encryption_key_file",
 		"error_file",
 		"file",
 		"htpasswd_file",
 		"idp_metadata_file",
 		"jwks_url",
 		"key_file",
+		"keys_file",
 		"leaf_certificate_file",
 		"permissions_map_file",
 		"private_key_file",
 		"public_key_file",
 		"roles_map_file",
 		"server_ca_certificate_file",
+		"secret_file",
 		"signing_key_file",
+		"sp_certificate_file",
+		"sp_private_key_file",
+		"tenants_file",
 	}
 
 	pathBearingAttributesMap = make(map[string]struct{})
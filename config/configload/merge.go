@@ -170,7 +170,7 @@ func mergeServers(bodies []*hclsyntax.Body, proxies map[string]*hclsyntax.Block)
 						return nil, err
 					}
 
-					results[serverKey].endpoints[block.Labels[0]] = block
+					results[serverKey].endpoints[newEndpointKey(block, results[serverKey].endpoints)] = block
 				} else if block.Type == api {
 					var apiKey string
 
@@ -221,7 +221,8 @@ func mergeServers(bodies []*hclsyntax.Body, proxies map[string]*hclsyntax.Block)
 								return nil, err
 							}
 
-							results[serverKey].apis[apiKey].endpoints[subBlock.Labels[0]] = subBlock
+							apiEndpoints := results[serverKey].apis[apiKey].endpoints
+							apiEndpoints[newEndpointKey(subBlock, apiEndpoints)] = subBlock
 						} else if subBlock.Type == errorHandler {
 							if err := checkForMultipleBackends(subBlock); err != nil {
 								return nil, err
@@ -339,6 +340,12 @@ func mergeServers(bodies []*hclsyntax.Body, proxies map[string]*hclsyntax.Block)
 
 					}
 
+				} else if block.Type == tokenEndpoint {
+					if len(block.Labels) == 0 {
+						return nil, newMergeError(errUniqueLabels, block)
+					}
+
+					results[serverKey].blocks[block.Type+"_"+block.Labels[0]] = block
 				} else {
 					results[serverKey].blocks[block.Type] = block
 				}
@@ -809,6 +816,25 @@ func newErrorHandlerKey(block *hclsyntax.Block) (key string) {
 	return strings.Join(sorted, errorHandlerLabelSep)
 }
 
+// newEndpointKey returns the key of the given endpoint block. Endpoints with a match
+// block may share their path pattern and keep their definition order.
+func newEndpointKey(block *hclsyntax.Block, endpoints namedBlocks) string {
+	key := block.Labels[0]
+	for _, b := range block.Body.Blocks {
+		if b.Type != endpointMatch {
+			continue
+		}
+
+		for i := 0; ; i++ {
+			matchKey := fmt.Sprintf("%s|%s|%04d", key, endpointMatch, i)
+			if _, exists := endpoints[matchKey]; !exists {
+				return matchKey
+			}
+		}
+	}
+	return key
+}
+
 func getSortedMapKeys[K string, V any](m map[K]V) []string {
 	var result []string
 	for k := range m {
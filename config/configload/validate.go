@@ -15,10 +15,7 @@ import (
 	"github.com/coupergateway/couper/utils"
 )
 
-var (
-	regexLabel     = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
-	reCleanPattern = regexp.MustCompile(`{([^}]+)}`)
-)
+var regexLabel = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
 
 // https://datatracker.ietf.org/doc/html/rfc7231#section-4
 // https://datatracker.ietf.org/doc/html/rfc7230#section-3.2.6
@@ -70,7 +67,7 @@ func validateBody(body *hclsyntax.Body, afterMerge bool) error {
 						return err
 					}
 
-				case "basic_auth", "beta_oauth2", "oidc", "saml":
+				case "api_key", "basic_auth", "beta_oauth2", "client_certificate", "http_message_signature", "oidc", "saml", "session", "signature":
 					err := checkAC(uniqueACs, label, labelRange, afterMerge)
 					if err != nil {
 						return err
@@ -153,6 +150,24 @@ func checkPathSegments(pathType, path string, r hcl.Range) error {
 	return nil
 }
 
+func checkPathParams(pattern string, r hcl.Range) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		param, ok := utils.ParsePathParam(segment)
+		if !ok || param.Pattern == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(param.Pattern)
+		if err != nil {
+			return newDiagErr(&r, fmt.Sprintf("invalid constraint for path parameter %q: %s", param.Name, err))
+		}
+		if re.NumSubexp() > 0 {
+			return newDiagErr(&r, fmt.Sprintf("constraint for path parameter %q must not contain capturing groups, use (?:...) instead", param.Name))
+		}
+	}
+	return nil
+}
+
 func getBasePath(bl *hclsyntax.Block, afterMerge bool) (string, error) {
 	basePath := ""
 	if bp, set := bl.Body.Attributes["base_path"]; set {
@@ -185,10 +200,29 @@ func registerEndpointPattern(endpointPatterns map[string]struct{}, basePath stri
 		if err := checkPathSegments("endpoint path pattern", pattern, bl.LabelRanges[0]); err != nil {
 			return err
 		}
+
+		if err := checkPathParams(pattern, bl.LabelRanges[0]); err != nil {
+			return err
+		}
+	}
+
+	// patterns only differing in their parameter names are duplicates
+	segments := strings.Split(utils.JoinOpenAPIPath(basePath, pattern), "/")
+	for i, segment := range segments {
+		if param, ok := utils.ParsePathParam(segment); ok {
+			param.Name = ""
+			segments[i] = param.String()
+		}
+	}
+	pattern = strings.Join(segments, "/")
+
+	// endpoints with a match block may share their path pattern
+	for _, b := range bl.Body.Blocks {
+		if b.Type == endpointMatch {
+			return nil
+		}
 	}
 
-	pattern = utils.JoinOpenAPIPath(basePath, pattern)
-	pattern = reCleanPattern.ReplaceAllString(pattern, "{}")
 	if _, set := endpointPatterns[pattern]; set {
 		return newDiagErr(&bl.LabelRanges[0], "duplicate endpoint")
 	}
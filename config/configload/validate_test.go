@@ -1016,6 +1016,106 @@ func Test_validateBody(t *testing.T) {
 			 }`,
 			"",
 		},
+		{
+			"duplicate endpoint pattern 7",
+			`server {
+			   endpoint "/a/{b:int}" {
+			     response {
+			       body = "1"
+			     }
+			   }
+			   endpoint "/a/{c:-?[0-9]+}" {
+			     response {
+			       body = "2"
+			     }
+			   }
+			 }`,
+			"couper.hcl:7,16-33: duplicate endpoint; ",
+		},
+		{
+			"distinct constrained endpoint patterns",
+			`server {
+			   endpoint "/a/{b}" {
+			     response {
+			       body = "1"
+			     }
+			   }
+			   endpoint "/a/{b:int}" {
+			     response {
+			       body = "2"
+			     }
+			   }
+			   endpoint "/a/{b:uuid}" {
+			     response {
+			       body = "3"
+			     }
+			   }
+			 }`,
+			"",
+		},
+		{
+			"shared endpoint pattern with match blocks",
+			`server {
+			   endpoint "/a" {
+			     match {
+			       methods = ["POST"]
+			     }
+			     response {
+			       body = "1"
+			     }
+			   }
+			   endpoint "/a" {
+			     match {
+			       headers = { accept = "text/plain" }
+			     }
+			     response {
+			       body = "2"
+			     }
+			   }
+			   endpoint "/a" {
+			     response {
+			       body = "3"
+			     }
+			   }
+			 }`,
+			"",
+		},
+		{
+			"invalid match method",
+			`server {
+			   endpoint "/a" {
+			     match {
+			       methods = ["in valid"]
+			     }
+			     response {
+			       body = "1"
+			     }
+			   }
+			 }`,
+			"couper.hcl:4,11-33: method contains invalid character(s); ",
+		},
+		{
+			"invalid path parameter constraint",
+			`server {
+			   endpoint "/a/{b:[0-9}" {
+			     response {
+			       body = "1"
+			     }
+			   }
+			 }`,
+			"couper.hcl:2,16-29: invalid constraint for path parameter \"b\": error parsing regexp: missing closing ]: `[0-9`; ",
+		},
+		{
+			"capturing group in path parameter constraint",
+			`server {
+			   endpoint "/a/{b:(x|y)}" {
+			     response {
+			       body = "1"
+			     }
+			   }
+			 }`,
+			"couper.hcl:2,16-30: constraint for path parameter \"b\" must not contain capturing groups, use (?:...) instead; ",
+		},
 		{
 			"saml missing metadata source",
 			`server {}
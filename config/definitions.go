@@ -2,16 +2,22 @@ package config
 
 // Definitions represents the <Definitions> object.
 type Definitions struct {
-	ExternalAuthZ     []*ExternalAuthZ     `hcl:"beta_external_authz,block" docs:"Configure an [external authorization access control](/configuration/block/beta_external_authz) (zero or more)."`
-	Backend           []*Backend           `hcl:"backend,block" docs:"Configure a [backend](/configuration/block/backend) (zero or more)."`
-	BasicAuth         []*BasicAuth         `hcl:"basic_auth,block" docs:"Configure a [BasicAuth access control](/configuration/block/basic_auth) (zero or more)."`
-	Job               []*Job               `hcl:"job,block" docs:"Configure a [job](/configuration/block/job) (zero or more)."`
-	JWT               []*JWT               `hcl:"jwt,block" docs:"Configure a [JWT access control](/configuration/block/jwt) (zero or more)."`
-	JWTSigningProfile []*JWTSigningProfile `hcl:"jwt_signing_profile,block" docs:"Configure a [JWT signing profile](/configuration/block/jwt_signing_profile) (zero or more)."`
-	RateLimiter       []*RateLimiter       `hcl:"beta_rate_limiter,block" docs:"Configure a [Rate limiter access control](/configuration/block/rate_limiter) (zero or more)."`
-	SAML              []*SAML              `hcl:"saml,block" docs:"Configure a [SAML access control](/configuration/block/saml) (zero or more)."`
-	OAuth2AC          []*OAuth2AC          `hcl:"beta_oauth2,block" docs:"Configure an [OAuth2 access control](/configuration/block/beta_oauth2) (zero or more)."`
-	OIDC              []*OIDC              `hcl:"oidc,block" docs:"Configure an [OIDC access control](/configuration/block/oidc) (zero or more)."`
+	ExternalAuthZ        []*ExternalAuthZ          `hcl:"beta_external_authz,block" docs:"Configure an [external authorization access control](/configuration/block/beta_external_authz) (zero or more)."`
+	APIKey               []*APIKey                 `hcl:"api_key,block" docs:"Configure an [API key access control](/configuration/block/api_key) (zero or more)."`
+	Backend              []*Backend                `hcl:"backend,block" docs:"Configure a [backend](/configuration/block/backend) (zero or more)."`
+	BasicAuth            []*BasicAuth              `hcl:"basic_auth,block" docs:"Configure a [BasicAuth access control](/configuration/block/basic_auth) (zero or more)."`
+	ClientCertificate    []*ClientCertificateAC    `hcl:"client_certificate,block" docs:"Configure a [client certificate access control](/configuration/block/client_certificate_ac) (zero or more)."`
+	HTTPMessageSignature []*HTTPMessageSignatureAC `hcl:"http_message_signature,block" docs:"Configure an [HTTP message signature access control](/configuration/block/http_message_signature_ac) (zero or more)."`
+	Job                  []*Job                    `hcl:"job,block" docs:"Configure a [job](/configuration/block/job) (zero or more)."`
+	JWT                  []*JWT                    `hcl:"jwt,block" docs:"Configure a [JWT access control](/configuration/block/jwt) (zero or more)."`
+	JWTSigningProfile    []*JWTSigningProfile      `hcl:"jwt_signing_profile,block" docs:"Configure a [JWT signing profile](/configuration/block/jwt_signing_profile) (zero or more)."`
+	RateLimiter          []*RateLimiter            `hcl:"beta_rate_limiter,block" docs:"Configure a [Rate limiter access control](/configuration/block/rate_limiter) (zero or more)."`
+	Session              []*Session                `hcl:"session,block" docs:"Configure a [session access control](/configuration/block/session) (zero or more)."`
+	Signature            []*Signature              `hcl:"signature,block" docs:"Configure a [signature access control](/configuration/block/signature) (zero or more)."`
+	SAML                 []*SAML                   `hcl:"saml,block" docs:"Configure a [SAML access control](/configuration/block/saml) (zero or more)."`
+	OAuth2AC             []*OAuth2AC               `hcl:"beta_oauth2,block" docs:"Configure an [OAuth2 access control](/configuration/block/beta_oauth2) (zero or more)."`
+	Policy               []*Policy                 `hcl:"beta_policy,block" docs:"Configure a [policy access control](/configuration/block/beta_policy) (zero or more)."`
+	OIDC                 []*OIDC                   `hcl:"oidc,block" docs:"Configure an [OIDC access control](/configuration/block/oidc) (zero or more)."`
 
 	// used for documentation
 	Proxy []*Proxy `hcl:"proxy,block" docs:"Configure a [proxy](/configuration/block/proxy) (zero or more)."`
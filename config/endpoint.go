@@ -17,17 +17,18 @@ var (
 // Endpoint represents the <Endpoint> object.
 type Endpoint struct {
 	ErrorHandlerSetter
-	AccessControl        []string  `hcl:"access_control,optional" docs:"Sets predefined access control for this block context."`
-	AllowedMethods       []string  `hcl:"allowed_methods,optional" docs:"Sets allowed methods overriding a default set in the containing {api} block. Requests with a method that is not allowed result in an error response with a {405 Method Not Allowed} status." default:"*"`
-	DisableAccessControl []string  `hcl:"disable_access_control,optional" docs:"Disables access controls by name."`
-	ErrorFile            string    `hcl:"error_file,optional" docs:"Location of the error file template."`
-	Pattern              string    `hcl:"pattern,label"`
-	Proxies              Proxies   `hcl:"proxy,block" docs:"Configures a [proxy](/configuration/block/proxy) (zero or more)."`
-	Proxy                string    `hcl:"proxy,optional" docs:"References a [{proxy} block](/configuration/block/proxy) in the [definitions](/configuration/block/definitions)."`
-	Remain               hcl.Body  `hcl:",remain"`
-	RequestBodyLimit     string    `hcl:"request_body_limit,optional" docs:"Configures the maximum buffer size while accessing {request.form_body} or {request.json_body} content. Valid units are: {KiB}, {MiB}, {GiB}." default:"64MiB"`
-	Requests             Requests  `hcl:"request,block" docs:"Configures a [request](/configuration/block/request) (zero or more)."`
-	Response             *Response `hcl:"response,block" docs:"Configures the [response](/configuration/block/response) (zero or one)."`
+	AccessControl        []string       `hcl:"access_control,optional" docs:"Sets predefined access control for this block context."`
+	AllowedMethods       []string       `hcl:"allowed_methods,optional" docs:"Sets allowed methods overriding a default set in the containing {api} block. Requests with a method that is not allowed result in an error response with a {405 Method Not Allowed} status." default:"*"`
+	DisableAccessControl []string       `hcl:"disable_access_control,optional" docs:"Disables access controls by name."`
+	ErrorFile            string         `hcl:"error_file,optional" docs:"Location of the error file template."`
+	Match                *EndpointMatch `hcl:"match,block" docs:"Configures additional [request matchers](/configuration/block/endpoint_match) (zero or one)."`
+	Pattern              string         `hcl:"pattern,label"`
+	Proxies              Proxies        `hcl:"proxy,block" docs:"Configures a [proxy](/configuration/block/proxy) (zero or more)."`
+	Proxy                string         `hcl:"proxy,optional" docs:"References a [{proxy} block](/configuration/block/proxy) in the [definitions](/configuration/block/definitions)."`
+	Remain               hcl.Body       `hcl:",remain"`
+	RequestBodyLimit     string         `hcl:"request_body_limit,optional" docs:"Configures the maximum buffer size while accessing {request.form_body} or {request.json_body} content. Valid units are: {KiB}, {MiB}, {GiB}." default:"64MiB"`
+	Requests             Requests       `hcl:"request,block" docs:"Configures a [request](/configuration/block/request) (zero or more)."`
+	Response             *Response      `hcl:"response,block" docs:"Configures the [response](/configuration/block/response) (zero or one)."`
 
 	// internally configured due to multi-label options
 	RequiredPermission hcl.Expression
@@ -51,7 +52,7 @@ func (e Endpoint) Inline() interface{} {
 		meta.QueryParamsAttributes
 		meta.LogFieldsAttribute
 		ResponseStatus     *uint8         `hcl:"set_response_status,optional" docs:"Modifies the response status code."`
-		RequiredPermission hcl.Expression `hcl:"required_permission,optional" docs:"Permission required to use this endpoint (see [error type](/configuration/error-handling#error-types) {insufficient_permissions})." type:"string or object (string)"`
+		RequiredPermission hcl.Expression `hcl:"required_permission,optional" docs:"Permission required to use this endpoint (see [error type](/configuration/error-handling#error-types) {insufficient_permissions})." type:"string, tuple (string) or object"`
 	}
 
 	return &Inline{}
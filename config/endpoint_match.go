@@ -0,0 +1,12 @@
+package config
+
+import "github.com/hashicorp/hcl/v2"
+
+// EndpointMatch represents the <Match> block of an endpoint.
+type EndpointMatch struct {
+	Condition   hcl.Expression    `hcl:"condition,optional" docs:"Expression which must evaluate to {true}, e.g. {request.query.beta[0] == \"1\"}." type:"bool" default:"true"`
+	ContentType []string          `hcl:"content_type,optional" docs:"Media types one of which the {Content-Type} request header must match, e.g. {[\"application/json\", \"text/*\"]}."`
+	Headers     map[string]string `hcl:"headers,optional" docs:"Request header fields with their required values. The value {\"*\"} only requires the header field to be present. A value also matches a member of a comma-separated list, e.g. of an {Accept} header field, and a media type matches regardless of further parameters like {q}."`
+	Methods     []string          `hcl:"methods,optional" docs:"Request methods one of which the request method must match."`
+	QueryParams map[string]string `hcl:"query_params,optional" docs:"Query parameters with their required values. The value {\"*\"} only requires the query parameter to be present."`
+}
@@ -11,25 +11,33 @@ import (
 // ConfigRegistry contains all config struct types that should be processed
 var ConfigRegistry = []interface{}{
 	&config.API{},
+	&config.APIKey{},
 	&config.ExternalAuthZ{},
 	&config.Backend{},
 	&config.BackendTLS{},
 	&config.BasicAuth{},
+	&config.BasicAuthLDAP{},
+	&config.ClientCertificateAC{},
 	&config.CORS{},
 	&config.Defaults{},
 	&config.Definitions{},
 	&config.Endpoint{},
+	&config.EndpointMatch{},
 	&config.ErrorHandler{},
 	&config.Files{},
 	&config.Health{},
+	&config.HTTPMessageSignature{},
+	&config.HTTPMessageSignatureAC{},
 	&config.Introspection{},
 	&config.JWTSigningProfile{},
+	&config.JWTSigningKey{},
 	&config.JWT{},
 	&config.Job{},
 	&config.OAuth2AC{},
 	&config.OAuth2ReqAuth{},
 	&config.OIDC{},
 	&config.OpenAPI{},
+	&config.Policy{},
 	&config.Proxy{},
 	&config.Throttle{},
 	&config.RateLimiter{},
@@ -37,11 +45,17 @@ var ConfigRegistry = []interface{}{
 	&config.Response{},
 	&config.SAML{},
 	&config.Server{},
+	&config.Session{},
 	&config.ClientCertificate{},
 	&config.ServerCertificate{},
 	&config.ServerTLS{},
 	&config.Settings{},
+	&config.Signature{},
+	&config.SigV4{},
 	&config.Spa{},
+	&config.Tenants{},
+	&config.TokenEndpoint{},
+	&config.TokenEndpointClient{},
 	&config.TokenRequest{},
 	&config.Websockets{},
 }
@@ -51,20 +65,37 @@ var filenameRegex = regexp.MustCompile(`(URL|JWT|OpenAPI|[a-z0-9]+)`)
 // BlockNamesMap provides mappings from internal type names to HCL block names
 // Used by docs generator to match documentation file names
 var BlockNamesMap = map[string]string{
-	"external_auth_z": "beta_external_authz",
-	"oauth2_ac":       "beta_oauth2",
-	"oauth2_req_auth": "oauth2",
+	"apikey":                   "api_key",
+	"external_auth_z":          "beta_external_authz",
+	"httpmessage_signature":    "http_message_signature",
+	"httpmessage_signature_ac": "http_message_signature_ac",
+	"oauth2_ac":                "beta_oauth2",
+	"oauth2_req_auth":          "oauth2",
+	"policy":                   "beta_policy",
+	"sig_v4":                   "sigv4",
+	"tenants":                  "beta_tenants",
 }
 
 // VSCodeBlockNamesMap provides mappings for VS Code schema (HCL block names).
 // Maps internal Go type names to their HCL block names when they differ.
 var VSCodeBlockNamesMap = map[string]string{
-	"external_auth_z": "beta_external_authz",
-	"introspection":   "beta_introspection",
-	"oauth2_ac":       "beta_oauth2",
-	"oauth2_req_auth": "oauth2",
-	"backend_tls":     "tls",
-	"server_tls":      "tls",
+	"apikey":                   "api_key",
+	"client_certificate_ac":    "client_certificate",
+	"external_auth_z":          "beta_external_authz",
+	"httpmessage_signature":    "http_message_signature",
+	"httpmessage_signature_ac": "http_message_signature",
+	"introspection":            "beta_introspection",
+	"oauth2_ac":                "beta_oauth2",
+	"oauth2_req_auth":          "oauth2",
+	"policy":                   "beta_policy",
+	"sig_v4":                   "sigv4",
+	"backend_tls":              "tls",
+	"basic_auth_ldap":          "ldap",
+	"endpoint_match":           "match",
+	"server_tls":               "tls",
+	"tenants":                  "beta_tenants",
+	"token_endpoint_client":    "client",
+	"jwt_signing_key":          "signing_key",
 }
 
 // GetBlockName returns the HCL block name for a config struct type
@@ -237,8 +237,13 @@ func extractFunctions(schema *Schema) {
 		"merge":                    "Deep-merges two or more of either objects or tuples. `null` arguments are ignored.",
 		"oauth2_authorization_url": "Creates an OAuth2 authorization URL from a referenced OAuth2 AC Block or OIDC Block.",
 		"oauth2_verifier":          "Creates a cryptographically random key as specified in RFC 7636.",
+		"oidc_logout_url":          "Creates an OpenID Connect RP-initiated logout URL from a referenced oidc block.",
 		"relative_url":             "Returns a relative URL by retaining path, query and fragment components.",
+		"saml_slo_url":             "Creates a signed SAML SingleLogout URL (including the SAMLRequest parameter) from a referenced saml block.",
+		"saml_sp_metadata":         "Creates the SAML Service Provider metadata XML from a referenced saml block.",
 		"saml_sso_url":             "Creates a SAML SingleSignOn URL (including the SAMLRequest parameter) from a referenced saml block.",
+		"session_destroy":          "Destroys the session of the client request and returns a Set-Cookie header value removing the session cookie.",
+		"session_write":            "Stores the given data in a new session and returns a Set-Cookie header value with the session ID.",
 		"set_intersection":         "Returns a new set containing the elements that exist in all of the given sets.",
 		"split":                    "Divides a given string by a given separator.",
 		"substr":                   "Extracts a sequence of characters from another string.",
@@ -267,8 +272,8 @@ func extractVariables(schema *Schema) {
 	commonProps := []string{"body", "context", "cookies", "headers", "json_body"}
 
 	requestProps := buildProps(commonProps,
-		"form_body", "host", "id", "method", "origin", "path",
-		"path_params", "port", "protocol", "query", "url",
+		"form_body", "host", "host_params", "id", "method", "origin", "path",
+		"path_params", "port", "protocol", "query", "tenant", "url",
 	)
 
 	backendRequestProps := buildProps(commonProps,
@@ -335,11 +340,17 @@ func extractVariables(schema *Schema) {
 
 // errorFamilyToParentBlocks maps error family prefixes to their HCL parent block names.
 var errorFamilyToParentBlocks = map[string][]string{
-	"basic_auth":        {"basic_auth"},
-	"jwt":               {"jwt"},
-	"oauth2":            {"beta_oauth2", "oidc"},
-	"saml2":             {"saml"},
-	"beta_rate_limiter":  {"rate_limiter"},
+	"api_key":                {"api_key"},
+	"basic_auth":             {"basic_auth"},
+	"client_certificate":     {"client_certificate"},
+	"http_message_signature": {"http_message_signature"},
+	"jwt":                    {"jwt"},
+	"oauth2":                 {"beta_oauth2", "oidc"},
+	"saml2":                  {"saml"},
+	"session":                {"session"},
+	"signature":              {"signature"},
+	"beta_policy":            {"beta_policy"},
+	"beta_rate_limiter":      {"rate_limiter"},
 }
 
 func extractErrorHandlerLabels(schema *Schema) {
@@ -519,7 +530,7 @@ func getDefiningBlocks(attrName string) []string {
 	case "proxy":
 		return []string{"proxy"}
 	case "access_control", "disable_access_control":
-		return []string{"basic_auth", "jwt", "oidc", "saml", "beta_oauth2", "beta_rate_limiter"}
+		return []string{"api_key", "basic_auth", "client_certificate", "http_message_signature", "jwt", "oidc", "saml", "session", "signature", "beta_oauth2", "beta_policy", "beta_rate_limiter"}
 	default:
 		return nil
 	}
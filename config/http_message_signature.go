@@ -0,0 +1,15 @@
+package config
+
+import "github.com/hashicorp/hcl/v2"
+
+// HTTPMessageSignature represents the http_message_signature block in a backend block.
+type HTTPMessageSignature struct {
+	Components         []string `hcl:"components,optional" docs:"List of covered component identifiers: derived components like {\"@method\"}, {\"@target-uri\"}, {\"@authority\"}, {\"@scheme\"}, {\"@request-target\"}, {\"@path\"} or {\"@query\"}, and lower-case header field names. A covered but missing {\"content-digest\"} header field is created from the request body." default:"[\"@method\", \"@authority\", \"@path\", \"@query\"]"`
+	Key                string   `hcl:"key,optional" docs:"Private key (in PEM format) for {RS*}, {PS*}, {ES*} and {EdDSA} variants or the secret for {HS*} algorithms. Mutually exclusive with {key_file}."`
+	KeyFile            string   `hcl:"key_file,optional" docs:"Reference to file containing the signing key. Mutually exclusive with {key}. See {key} for more information."`
+	KeyID              string   `hcl:"key_id" docs:"The {keyid} signature parameter identifying the key at the verifier."`
+	Label              string   `hcl:"label,optional" docs:"The signature label in the {Signature-Input} and {Signature} header fields." default:"sig1"`
+	SignatureAlgorithm string   `hcl:"signature_algorithm" docs:"Algorithm used for signing: {\"RS256\"}, {\"RS384\"}, {\"RS512\"}, {\"HS256\"}, {\"HS384\"}, {\"HS512\"}, {\"ES256\"}, {\"ES384\"}, {\"ES512\"}, {\"PS256\"}, {\"PS384\"}, {\"PS512\"}, {\"EdDSA\"}."`
+	TTL                string   `hcl:"ttl,optional" docs:"The signature's time-to-live, creates the {expires} signature parameter." type:"duration"`
+	Remain             hcl.Body `hcl:",remain"`
+}
@@ -3,11 +3,26 @@ package config
 import "github.com/hashicorp/hcl/v2"
 
 type JWTSigningProfile struct {
-	Claims             Claims         `hcl:"claims,optional" docs:"Claims for the JWT payload, claim values are evaluated per request."`
-	Headers            hcl.Expression `hcl:"headers,optional" docs:"Additional HTTP header fields for the JWT, {typ} has the default value {JWT}, {alg} cannot be set."`
-	Key                string         `hcl:"key,optional" docs:"Private key (in PEM format) for {RS*} and {ES*} variants or the secret for {HS*} algorithms. Mutually exclusive with {key_file}."`
-	KeyFile            string         `hcl:"key_file,optional" docs:"Reference to file containing signing key. Mutually exclusive with {key}. See {key} for more information."`
-	Name               string         `hcl:"name,label_optional"`
-	SignatureAlgorithm string         `hcl:"signature_algorithm" docs:"Algorithm used for signing: {\"RS256\"}, {\"RS384\"}, {\"RS512\"}, {\"HS256\"}, {\"HS384\"}, {\"HS512\"}, {\"ES256\"}, {\"ES384\"}, {\"ES512\"}."`
-	TTL                string         `hcl:"ttl" docs:"The token's time-to-live, creates the {exp} claim."`
+	Claims                     Claims           `hcl:"claims,optional" docs:"Claims for the JWT payload, claim values are evaluated per request."`
+	ContentEncryptionAlgorithm string           `hcl:"content_encryption_algorithm,optional" docs:"Content encryption algorithm of encrypted tokens: {\"A128GCM\"}, {\"A192GCM\"}, {\"A256GCM\"}, {\"A128CBC-HS256\"}, {\"A192CBC-HS384\"}, {\"A256CBC-HS512\"}." default:"A256GCM"`
+	EncryptionAlgorithm        string           `hcl:"encryption_algorithm,optional" docs:"If set, the signed token is encrypted as nested JWE with this key management algorithm: {\"RSA-OAEP\"}, {\"RSA-OAEP-256\"}, {\"ECDH-ES\"}, {\"dir\"}."`
+	EncryptionKey              string           `hcl:"encryption_key,optional" docs:"Public key (in PEM format) of the recipient for {RSA-OAEP*} and {ECDH-ES} or the symmetric key for {dir}. Mutually exclusive with {encryption_key_file}."`
+	EncryptionKeyFile          string           `hcl:"encryption_key_file,optional" docs:"Reference to file containing the encryption key. Mutually exclusive with {encryption_key}. See {encryption_key} for more information."`
+	Headers                    hcl.Expression   `hcl:"headers,optional" docs:"Additional HTTP header fields for the JWT, {typ} has the default value {JWT}, {alg} cannot be set."`
+	Key                        string           `hcl:"key,optional" docs:"Private key (in PEM format) for {RS*}, {PS*}, {ES*} and {EdDSA} variants or the secret for {HS*} algorithms. Mutually exclusive with {key_file}."`
+	KeyFile                    string           `hcl:"key_file,optional" docs:"Reference to file containing signing key. Mutually exclusive with {key}. See {key} for more information."`
+	KeyID                      string           `hcl:"key_id,optional" docs:"The key ID, set as {kid} header of the signed tokens and used for the published [JWKS](/configuration/block/server) entry. Defaults to the JWK thumbprint of the public key for the JWKS."`
+	Name                       string           `hcl:"name,label_optional"`
+	SignatureAlgorithm         string           `hcl:"signature_algorithm" docs:"Algorithm used for signing: {\"RS256\"}, {\"RS384\"}, {\"RS512\"}, {\"HS256\"}, {\"HS384\"}, {\"HS512\"}, {\"ES256\"}, {\"ES384\"}, {\"ES512\"}, {\"PS256\"}, {\"PS384\"}, {\"PS512\"}, {\"EdDSA\"}."`
+	SigningKeys                []*JWTSigningKey `hcl:"signing_key,block" docs:"Configures a [signing key](/configuration/block/jwt_signing_key) for key rotation (zero or more). Mutually exclusive with {key}, {key_file} and {key_id}."`
+	TTL                        string           `hcl:"ttl" docs:"The token's time-to-live, creates the {exp} claim."`
+}
+
+// JWTSigningKey represents a key of the <JWTSigningProfile> with a validity period.
+type JWTSigningKey struct {
+	ID        string `hcl:"id,label"`
+	Key       string `hcl:"key,optional" docs:"Private key (in PEM format) for {RS*}, {PS*}, {ES*} and {EdDSA} variants or the secret for {HS*} algorithms. Mutually exclusive with {key_file}."`
+	KeyFile   string `hcl:"key_file,optional" docs:"Reference to file containing the signing key. Mutually exclusive with {key}. See {key} for more information."`
+	NotAfter  string `hcl:"not_after,optional" docs:"Time (RFC 3339) from which the key is neither used for signing nor published."`
+	NotBefore string `hcl:"not_before,optional" docs:"Time (RFC 3339) from which the key is used for signing. Before, the key is already published."`
 }
@@ -20,6 +20,7 @@ const (
 	Error
 	GrantedPermissions
 	Handler
+	HostParams
 	LogCustomAccess
 	LogCustomUpstreamValue
 	LogCustomUpstreamError
@@ -34,7 +35,10 @@ const (
 	RoundTripProxy
 	ServerName
 	ServerTimings
+	SessionTokens
+	SessionWrites
 	StartTime
+	Tenant
 	TokenRequest
 	TokenRequestRetries
 	UID
@@ -0,0 +1,3 @@
+package request
+
+type HostParameter map[string]interface{}
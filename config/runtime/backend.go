@@ -16,8 +16,10 @@ import (
 	"github.com/coupergateway/couper/cache"
 	"github.com/coupergateway/couper/config"
 	hclbody "github.com/coupergateway/couper/config/body"
+	"github.com/coupergateway/couper/config/reader"
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/eval/lib"
 	"github.com/coupergateway/couper/handler/producer"
 	"github.com/coupergateway/couper/handler/throttle"
 	"github.com/coupergateway/couper/handler/transport"
@@ -138,6 +140,34 @@ func newBackend(evalCtx *hcl.EvalContext, backendCtx *hclsyntax.Body, log *logru
 		}
 	}
 
+	if sigConf := beConf.HTTPMessageSignature; sigConf != nil {
+		keyBytes, rerr := reader.ReadFromAttrFile("http_message_signature key", sigConf.Key, sigConf.KeyFile)
+		if rerr != nil {
+			return nil, rerr
+		}
+
+		key, kerr := lib.ParseSigningKey(keyBytes, sigConf.SignatureAlgorithm)
+		if kerr != nil {
+			return nil, kerr
+		}
+
+		var requestAuthorizer transport.RequestAuthorizer
+		requestAuthorizer, err = transport.NewHTTPMessageSignature(sigConf, key)
+		if err != nil {
+			return nil, err
+		}
+		options.RequestAuthz = append(options.RequestAuthz, requestAuthorizer)
+	}
+
+	if beConf.SigV4 != nil {
+		var requestAuthorizer transport.RequestAuthorizer
+		requestAuthorizer, err = transport.NewSigV4(beConf.SigV4)
+		if err != nil {
+			return nil, err
+		}
+		options.RequestAuthz = append(options.RequestAuthz, requestAuthorizer)
+	}
+
 	b := transport.NewBackend(backendCtx, tc, options, log)
 	return b, nil
 }
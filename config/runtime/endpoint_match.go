@@ -0,0 +1,197 @@
+package runtime
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/eval"
+)
+
+const matchAny = "*"
+
+// endpointMatcher reports whether a client request fulfills
+// all matchers configured by an endpoint match block.
+type endpointMatcher struct {
+	condition    hcl.Expression
+	contentTypes []string
+	evalCtx      *eval.Context
+	headers      map[string]string
+	log          *logrus.Entry
+	methods      []string
+	queryParams  map[string]string
+}
+
+func newEndpointMatcher(conf *config.EndpointMatch, evalCtx *eval.Context, log *logrus.Entry) (*endpointMatcher, error) {
+	m := &endpointMatcher{
+		condition:   conf.Condition,
+		evalCtx:     evalCtx,
+		headers:     make(map[string]string, len(conf.Headers)),
+		log:         log,
+		methods:     conf.Methods,
+		queryParams: conf.QueryParams,
+	}
+
+	if m.condition != nil {
+		if v, _ := m.condition.Value(nil); v.IsNull() {
+			m.condition = nil
+		}
+	}
+
+	for name, value := range conf.Headers {
+		m.headers[http.CanonicalHeaderKey(name)] = value
+	}
+
+	for _, ct := range conf.ContentType {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return nil, fmt.Errorf("content_type: %q: %w", ct, err)
+		}
+		m.contentTypes = append(m.contentTypes, mediaType)
+	}
+
+	if m.condition == nil && len(m.contentTypes)+len(m.headers)+len(m.methods)+len(m.queryParams) == 0 {
+		return nil, fmt.Errorf("at least one of condition, content_type, headers, methods or query_params is required")
+	}
+
+	return m, nil
+}
+
+// Matches implements the gorilla/mux MatcherFunc signature. A CORS preflight request is
+// matched with its requested method and without the headers and content_type matchers, as
+// it carries neither the header fields nor the body of the actual request.
+func (m *endpointMatcher) Matches(req *http.Request) bool {
+	preflight := isPreflightRequest(req)
+
+	method := req.Method
+	if preflight {
+		method = req.Header.Get("Access-Control-Request-Method")
+	}
+	if len(m.methods) > 0 && method != "" && !m.matchesMethod(method) {
+		return false
+	}
+
+	if !preflight {
+		for name, value := range m.headers {
+			if !matchesHeaderValue(req.Header.Values(name), value) {
+				return false
+			}
+		}
+
+		if len(m.contentTypes) > 0 && !m.matchesContentType(req.Header.Get("Content-Type")) {
+			return false
+		}
+	}
+
+	if len(m.queryParams) > 0 {
+		query := req.URL.Query()
+		for name, value := range m.queryParams {
+			if !matchesValue(query[name], value) {
+				return false
+			}
+		}
+	}
+
+	if m.condition != nil {
+		v, err := eval.Value(m.evalCtx.WithClientRequest(req).HCLContext(), m.condition)
+		if err != nil {
+			m.log.WithError(err).Error("endpoint match condition")
+			return false
+		}
+		return v.Type() == cty.Bool && v.IsKnown() && !v.IsNull() && v.True()
+	}
+
+	return true
+}
+
+// isPreflightRequest reports whether the request is a CORS preflight request, see middleware.CORS.
+func isPreflightRequest(req *http.Request) bool {
+	return req.Method == http.MethodOptions &&
+		(req.Header.Get("Access-Control-Request-Method") != "" ||
+			req.Header.Get("Access-Control-Request-Headers") != "")
+}
+
+func (m *endpointMatcher) matchesMethod(method string) bool {
+	for _, allowed := range m.methods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *endpointMatcher) matchesContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, ct := range m.contentTypes {
+		if ct == mediaType {
+			return true
+		}
+		if prefix, wildcard := strings.CutSuffix(ct, "/*"); wildcard && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesValue reports whether one of the given values equals the expected one. The
+// expected value "*" matches any present value.
+func matchesValue(values []string, expected string) bool {
+	for _, v := range values {
+		if expected == matchAny || v == expected {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesHeaderValue additionally matches the members of list header fields like Accept. A
+// media type matches regardless of further parameters, e.g. "application/vnd.example.v2+json"
+// matches "application/vnd.example.v2+json; q=0.9, */*;q=0.1" but not "*/*" itself.
+func matchesHeaderValue(values []string, expected string) bool {
+	if matchesValue(values, expected) {
+		return true
+	}
+
+	expMediaType, expParams, err := mime.ParseMediaType(expected)
+	isMediaType := err == nil && strings.Contains(expMediaType, "/")
+
+	for _, v := range values {
+		for _, member := range strings.Split(v, ",") {
+			member = strings.TrimSpace(member)
+			if member == expected {
+				return true
+			}
+			if !isMediaType {
+				continue
+			}
+
+			mediaType, params, perr := mime.ParseMediaType(member)
+			if perr != nil || mediaType != expMediaType {
+				continue
+			}
+			if containsParams(params, expParams) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsParams(params, expected map[string]string) bool {
+	for k, v := range expected {
+		if params[k] != v {
+			return false
+		}
+	}
+	return true
+}
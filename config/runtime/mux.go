@@ -9,14 +9,27 @@ import (
 type MuxOptions struct {
 	EndpointRoutes map[string]http.Handler
 	FileRoutes     map[string]http.Handler
-	SPARoutes      map[string]http.Handler
-	ServerOptions  *server.Options
+	// HostOrder is the position of the host in the configuration.
+	HostOrder int
+	// MatchedEndpointRoutes are tried before the EndpointRoutes entry of the same path pattern.
+	MatchedEndpointRoutes map[string][]*MatchedRoute
+	SPARoutes             map[string]http.Handler
+	ServerOptions         *server.Options
+}
+
+// MatchedRoute is an endpoint route which applies only if Matches returns true.
+type MatchedRoute struct {
+	Handler http.Handler
+	Matches func(*http.Request) bool
+	// Order is the position of the endpoint in the configuration.
+	Order int
 }
 
 func NewMuxOptions() *MuxOptions {
 	return &MuxOptions{
-		EndpointRoutes: make(map[string]http.Handler),
-		FileRoutes:     make(map[string]http.Handler),
-		SPARoutes:      make(map[string]http.Handler),
+		EndpointRoutes:        make(map[string]http.Handler),
+		FileRoutes:            make(map[string]http.Handler),
+		MatchedEndpointRoutes: make(map[string][]*MatchedRoute),
+		SPARoutes:             make(map[string]http.Handler),
 	}
 }
@@ -3,10 +3,13 @@
 package runtime
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"math"
 	"net"
 	"net/http"
+	"net/url"
 	"path"
 	"path/filepath"
 	"reflect"
@@ -20,7 +23,9 @@ import (
 	ac "github.com/coupergateway/couper/accesscontrol"
 	"github.com/coupergateway/couper/accesscontrol/authz"
 	"github.com/coupergateway/couper/accesscontrol/jwk"
+	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
 	"github.com/coupergateway/couper/accesscontrol/saml"
+	"github.com/coupergateway/couper/accesscontrol/session"
 	"github.com/coupergateway/couper/cache"
 	"github.com/coupergateway/couper/config"
 	"github.com/coupergateway/couper/config/configload/collect"
@@ -34,6 +39,7 @@ import (
 	"github.com/coupergateway/couper/eval/lib"
 	"github.com/coupergateway/couper/handler"
 	"github.com/coupergateway/couper/handler/middleware"
+	coupertls "github.com/coupergateway/couper/internal/tls"
 	"github.com/coupergateway/couper/oauth2"
 	"github.com/coupergateway/couper/oauth2/oidc"
 	"github.com/coupergateway/couper/utils"
@@ -99,16 +105,27 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 		return nil, spErr
 	}
 
+	sessions, sErr := configureSessions(conf, memStore)
+	if sErr != nil {
+		return nil, sErr
+	}
+
 	conf.Context = evalContext.
 		WithMemStore(memStore).
 		WithOidcConfig(oidcConfigs).
-		WithSAMLProviders(samlProviders)
+		WithSAMLProviders(samlProviders).
+		WithSessions(sessions)
 
-	accessControls, acErr := configureAccessControls(conf, confCtx, log, memStore, oidcConfigs, samlProviders)
+	accessControls, acErr := configureAccessControls(conf, confCtx, log, memStore, oidcConfigs, samlProviders, sessions)
 	if acErr != nil {
 		return nil, acErr
 	}
 
+	backchannelLogouts, blErr := configureBackchannelLogouts(conf, confCtx, memStore, oidcConfigs, sessions)
+	if blErr != nil {
+		return nil, blErr
+	}
+
 	var (
 		serverConfiguration = make(ServerConfiguration)
 		defaultPort         = conf.Settings.DefaultPort
@@ -158,6 +175,7 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 		}
 	}
 
+	var hostOrder int
 	for _, srvConf := range conf.Servers {
 		serverOptions, err := server.NewServerOptions(srvConf, log)
 		if err != nil {
@@ -183,10 +201,13 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 					return nil, fmt.Errorf("conflict: host %q already defined for port: %d", host, port)
 				}
 
+				// keep the definition order across servers for the host pattern precedence
+				muxOpts.HostOrder += hostOrder
 				serverConfiguration[port][host] = muxOpts
 				serverConfiguration[port][host].ServerOptions = serverOptions
 			}
 		}
+		hostOrder += len(srvConf.Hosts)
 
 		serverBodies := bodiesWithACBodies(conf.Definitions, srvConf.AccessControl, srvConf.DisableAccessControl)
 		serverBodies = append(serverBodies, srvConf.Remain)
@@ -311,6 +332,25 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 			return nil, err
 		}
 
+		var tenantControl *ac.TenantControl
+		if srvConf.Tenants != nil {
+			tenantControl, err = ac.NewTenantControl(conf.Context, srvConf.Tenants, log)
+			if err != nil {
+				return nil, errors.Configuration.Label(srvConf.Name).Message("beta_tenants").With(err)
+			}
+		}
+
+		// endpoints sharing their path pattern by match blocks are tried in definition order
+		endpointOrder := make(map[*config.Endpoint]int)
+		for _, apiConf := range srvConf.APIs {
+			for _, epConf := range apiConf.Endpoints {
+				endpointOrder[epConf] = len(endpointOrder)
+			}
+		}
+		for _, epConf := range srvConf.Endpoints {
+			endpointOrder[epConf] = len(endpointOrder)
+		}
+
 		for endpointConf, parentAPI := range endpointsMap {
 			if endpointConf.Pattern == "" { // could happen for internally registered endpoints
 				return nil, fmt.Errorf("endpoint path pattern required")
@@ -323,11 +363,12 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 			}
 
 			// Evaluate access-control related buffer options.
-			acBodies := bodiesWithACBodies(conf.Definitions,
-				newAC(srvConf, parentAPI).
-					Merge(config.
-						NewAccessControl(endpointConf.AccessControl, endpointConf.DisableAccessControl)).List(), nil)
+			acList := newAC(srvConf, parentAPI).
+				Merge(config.
+					NewAccessControl(endpointConf.AccessControl, endpointConf.DisableAccessControl)).List()
+			acBodies := bodiesWithACBodies(conf.Definitions, acList, nil)
 			epOpts.BufferOpts |= buffer.Must(acBodies...)
+			epOpts.BufferOpts |= acBufferOptions(conf.Definitions, acList)
 
 			errorHandlerDefinitions := ACDefinitions{ // misuse of definitions obj for now
 				"endpoint": &AccessControl{ErrorHandler: endpointConf.ErrorHandler},
@@ -383,6 +424,20 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 
 					protectedHandler = middleware.NewErrorHandler(permissionsControl.Validate, permissionsErrorHandler)(epHandler)
 				}
+
+				if tenantControl != nil {
+					// resolved after the access controls to be able to use their context, e.g. token claims
+					tenantErrorHandler, _, tenantErr := newErrorHandler(confCtx, conf, &protectedOptions{
+						epOpts:   epOpts,
+						memStore: memStore,
+						srvOpts:  serverOptions,
+					}, log, errorHandlerDefinitions, "api", "endpoint")
+					if tenantErr != nil {
+						return nil, tenantErr
+					}
+
+					protectedHandler = middleware.NewErrorHandler(tenantControl.Validate, tenantErrorHandler)(protectedHandler)
+				}
 			}
 
 			accessControl := newAC(srvConf, parentAPI)
@@ -433,16 +488,98 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 			pattern := utils.JoinOpenAPIPath(basePath, endpointConf.Pattern)
 
 			endpointHandlers[endpointConf] = epHandler
+			if endpointConf.Match != nil {
+				matcher, merr := newEndpointMatcher(endpointConf.Match, evalContext, log)
+				if merr != nil {
+					return nil, errors.Configuration.Label(endpointConf.Pattern).Message("match").With(merr)
+				}
+
+				setMatchedRoutesFromHosts(serverConfiguration, portsHosts, pattern, &MatchedRoute{
+					Handler: epHandler,
+					Matches: matcher.Matches,
+					Order:   endpointOrder[endpointConf],
+				})
+				continue
+			}
+
 			err = setRoutesFromHosts(serverConfiguration, portsHosts, pattern, endpointHandlers[endpointConf], kind)
 			if err != nil {
 				return nil, err
 			}
 		}
+
+		for _, teConf := range srvConf.TokenEndpoints {
+			if err = configureTokenEndpoint(serverConfiguration, portsHosts, teConf, serverOptions, conf, memStore); err != nil {
+				return nil, errors.Configuration.Label(teConf.Path).With(err)
+			}
+		}
+
+		if srvConf.JWKSPath != "" {
+			jwksHandler, jerr := handler.NewJWKS(evalContext.JWTSigningConfigs())
+			if jerr != nil {
+				return nil, errors.Configuration.Label(srvConf.Name).Message("jwks_path").With(jerr)
+			}
+
+			jwksPath := utils.JoinOpenAPIPath(serverOptions.SrvBasePath, srvConf.JWKSPath)
+			if err = setRoutesFromHosts(serverConfiguration, portsHosts, jwksPath, jwksHandler, endpoint); err != nil {
+				return nil, err
+			}
+		}
+
+		for logoutPath, logoutHandler := range backchannelLogouts {
+			logoutPath = utils.JoinOpenAPIPath(serverOptions.SrvBasePath, logoutPath)
+			if err = setRoutesFromHosts(serverConfiguration, portsHosts, logoutPath, logoutHandler, endpoint); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return serverConfiguration, nil
 }
 
+// configureTokenEndpoint registers the token endpoint, its JWKS and the authorization server metadata.
+func configureTokenEndpoint(serverConfiguration ServerConfiguration, portsHosts Ports, teConf *config.TokenEndpoint,
+	serverOptions *server.Options, conf *config.Couper, memStore *cache.MemoryStore) error {
+	evalContext := conf.Context.Value(request.ContextType).(*eval.Context)
+	signingConfig, exists := evalContext.JWTSigningConfigs()[teConf.JWTSigningProfile]
+	if !exists {
+		return fmt.Errorf("referenced jwt_signing_profile %q is not defined", teConf.JWTSigningProfile)
+	}
+	if acjwt.NewAlgorithm(signingConfig.SignatureAlgorithm).IsHMAC() {
+		return fmt.Errorf("jwt_signing_profile %q: an asymmetric signature_algorithm is required", teConf.JWTSigningProfile)
+	}
+
+	jwksPath := teConf.JWKSPath
+	if jwksPath == "" {
+		jwksPath = handler.DefaultJWKSPath
+	}
+	tokenPath := utils.JoinOpenAPIPath(serverOptions.SrvBasePath, teConf.Path)
+	jwksPath = utils.JoinOpenAPIPath(serverOptions.SrvBasePath, jwksPath)
+
+	tokenEndpoint, err := handler.NewTokenEndpoint(teConf, tokenPath, jwksPath, signingConfig, memStore)
+	if err != nil {
+		return err
+	}
+
+	issuerURL, err := url.Parse(teConf.Issuer)
+	if err != nil {
+		return err
+	}
+	metadataPath := handler.AuthorizationServerMetadataPath + strings.TrimSuffix(issuerURL.Path, "/")
+
+	for routePath, h := range map[string]http.Handler{
+		tokenPath:    tokenEndpoint,
+		jwksPath:     tokenEndpoint.JWKSHandler(),
+		metadataPath: tokenEndpoint.MetadataHandler(),
+	} {
+		if err = setRoutesFromHosts(serverConfiguration, portsHosts, routePath, h, endpoint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func bodiesWithACBodies(defs *config.Definitions, ac, dac []string) []hcl.Body {
 	var bodies []hcl.Body
 
@@ -476,6 +613,31 @@ func bodiesWithACBodies(defs *config.Definitions, ac, dac []string) []hcl.Body {
 	return bodies
 }
 
+// acBufferOptions returns the buffer options of access controls which
+// read the client request body themselves, e.g. to verify a signature.
+func acBufferOptions(defs *config.Definitions, acs []string) buffer.Option {
+	if defs == nil {
+		return buffer.None
+	}
+
+	var names []string
+	for _, sigConf := range defs.Signature {
+		names = append(names, sigConf.Name)
+	}
+	for _, hmsConf := range defs.HTTPMessageSignature {
+		names = append(names, hmsConf.Name)
+	}
+
+	for _, name := range acs {
+		for _, n := range names {
+			if name == n {
+				return buffer.Request
+			}
+		}
+	}
+	return buffer.None
+}
+
 func whichCORS(parent *config.Server, this interface{}) *config.CORS {
 	val := reflect.ValueOf(this)
 	if val.IsZero() {
@@ -521,8 +683,66 @@ func configureOidcConfigs(conf *config.Couper, confCtx *hcl.EvalContext, log *lo
 	return oidcConfigs, nil
 }
 
+// newExternalAuthZOptions reads the protocol, decision cache, timeout and on_error settings of a beta_external_authz block.
+func newExternalAuthZOptions(conf *config.ExternalAuthZ, memStore *cache.MemoryStore) (authz.ExternalOptions, error) {
+	opts := authz.ExternalOptions{MemStore: memStore}
+
+	var err error
+	if opts.OnError, err = authz.ParseOnError(conf.OnError); err != nil {
+		return opts, err
+	}
+	if opts.Protocol, err = authz.ParseProtocol(conf.Protocol); err != nil {
+		return opts, err
+	}
+	if opts.Timeout, err = config.ParseDuration("timeout", conf.Timeout, 0); err != nil {
+		return opts, err
+	}
+	if opts.CacheTTL, err = config.ParseDuration("cache_ttl", conf.CacheTTL, 0); err != nil {
+		return opts, err
+	}
+
+	if attr, exists := conf.HCLBody().Attributes["cache_key"]; exists {
+		opts.CacheKey = attr.Expr
+	} else if opts.CacheTTL > 0 {
+		return opts, fmt.Errorf("cache_ttl requires cache_key")
+	}
+
+	return opts, nil
+}
+
+// newLDAPTLSConfig adds the CA certificate of the ldap block and the related
+// Couper cli option to the system root CAs.
+func newLDAPTLSConfig(conf *config.BasicAuthLDAP, certificate []byte) (*tls.Config, error) {
+	tlsConf := coupertls.DefaultTLSConfig()
+	tlsConf.NextProtos = nil
+	if tlsConf.RootCAs == nil {
+		tlsConf.RootCAs = x509.NewCertPool()
+	}
+	if len(certificate) > 0 {
+		tlsConf.RootCAs.AppendCertsFromPEM(certificate)
+	}
+	tlsConf.InsecureSkipVerify = conf.DisableCertValidation
+
+	if conf.ServerCertificate == "" && conf.ServerCertificateFile == "" {
+		return tlsConf, nil
+	}
+
+	caCert, err := reader.ReadFromAttrFile("ldap", conf.ServerCertificate, conf.ServerCertificateFile)
+	if err != nil {
+		return nil, err
+	}
+	caCertificate, err := coupertls.ParseCertificate(caCert, nil)
+	if err != nil {
+		return nil, err
+	}
+	tlsConf.RootCAs.AddCert(caCertificate.Leaf)
+
+	return tlsConf, nil
+}
+
 func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log *logrus.Entry,
-	memStore *cache.MemoryStore, oidcConfigs oidc.Configs, samlProviders map[string]lib.SAMLConfigWithProvider) (ACDefinitions, error) {
+	memStore *cache.MemoryStore, oidcConfigs oidc.Configs, samlProviders map[string]lib.SAMLConfigWithProvider,
+	sessions map[string]*session.Manager) (ACDefinitions, error) {
 
 	accessControls := make(ACDefinitions)
 
@@ -534,21 +754,80 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 				return nil, confErr.With(err)
 			}
 
+			opts, err := newExternalAuthZOptions(authZExternal, memStore)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
 			authZExt := authz.NewExternal(authZExternal.Name, authZExternal.URL, authZExternal.IncludeTLS,
-				authZExternal.PermissionsProperty, backend)
+				authZExternal.PermissionsProperty, backend).WithOptions(opts)
 			accessControls.Add(authZExternal.Name, authZExt, authZExternal.ErrorHandler)
 		}
 
+		for _, akConf := range conf.Definitions.APIKey {
+			confErr := errors.Configuration.Label(akConf.Name)
+			apiKey, err := ac.NewAPIKey(conf.Context, akConf, log)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			accessControls.Add(akConf.Name, apiKey, akConf.ErrorHandler)
+		}
+
 		for _, baConf := range conf.Definitions.BasicAuth {
 			confErr := errors.Configuration.Label(baConf.Name)
-			basicAuth, err := ac.NewBasicAuth(baConf.Name, baConf.User, baConf.Pass, baConf.File)
+			basicAuth, err := ac.NewBasicAuth(conf.Context, baConf.Name, baConf.User, baConf.Pass, baConf.File, log)
 			if err != nil {
 				return nil, confErr.With(err)
 			}
 
+			if baConf.LDAP != nil {
+				tlsConf, tErr := newLDAPTLSConfig(baConf.LDAP, conf.Settings.Certificate)
+				if tErr != nil {
+					return nil, confErr.With(tErr)
+				}
+
+				ldap, lErr := ac.NewLDAP(baConf.Name, baConf.LDAP, tlsConf, memStore)
+				if lErr != nil {
+					return nil, confErr.With(lErr)
+				}
+				basicAuth.WithLDAP(ldap)
+			}
+
 			accessControls.Add(baConf.Name, basicAuth, baConf.ErrorHandler)
 		}
 
+		for _, ccConf := range conf.Definitions.ClientCertificate {
+			confErr := errors.Configuration.Label(ccConf.Name)
+			var err error
+			ccConf.RolesMap, err = reader.ReadFromAttrFileJSONObjectOptional("client_certificate roles map", ccConf.RolesMap, ccConf.RolesMapFile)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			clientCert, err := ac.NewClientCertificate(ccConf)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			accessControls.Add(ccConf.Name, clientCert, ccConf.ErrorHandler)
+		}
+
+		for _, hmsConf := range conf.Definitions.HTTPMessageSignature {
+			confErr := errors.Configuration.Label(hmsConf.Name)
+			key, err := reader.ReadFromAttrFile("http_message_signature key", hmsConf.Key, hmsConf.KeyFile)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			hms, err := ac.NewHTTPMessageSignature(hmsConf, key)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			accessControls.Add(hmsConf.Name, hms, hmsConf.ErrorHandler)
+		}
+
 		for _, jwtConf := range conf.Definitions.JWT {
 			confErr := errors.Configuration.Label(jwtConf.Name)
 
@@ -560,6 +839,16 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 			accessControls.Add(jwtConf.Name, jwt, jwtConf.ErrorHandler)
 		}
 
+		for _, policyConf := range conf.Definitions.Policy {
+			confErr := errors.Configuration.Label(policyConf.Name)
+			policy, err := ac.NewPolicy(conf.Context, policyConf.Name, policyConf.File, log)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			accessControls.Add(policyConf.Name, policy, policyConf.ErrorHandler)
+		}
+
 		for _, rlConf := range conf.Definitions.RateLimiter {
 			confErr := errors.Configuration.Label(rlConf.Name)
 			rateLimiter, err := ac.NewRateLimiter(conf.Context, rlConf.Name, rlConf)
@@ -579,7 +868,26 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 				return nil, confErr.With(err)
 			}
 
-			accessControls.Add(samlConf.Name, s, samlConf.ErrorHandler)
+			accessControls.Add(samlConf.Name, s.WithSLO(samlConf.SpSloURL, provider.KeyPair), samlConf.ErrorHandler)
+		}
+
+		for _, sessionConf := range conf.Definitions.Session {
+			accessControls.Add(sessionConf.Name, ac.NewSession(sessions[sessionConf.Name]), sessionConf.ErrorHandler)
+		}
+
+		for _, sigConf := range conf.Definitions.Signature {
+			confErr := errors.Configuration.Label(sigConf.Name)
+			secret, err := reader.ReadFromAttrFile("signature secret", sigConf.Secret, sigConf.SecretFile)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			signature, err := ac.NewSignature(sigConf, secret)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			accessControls.Add(sigConf.Name, signature, sigConf.ErrorHandler)
 		}
 
 		for _, oauth2Conf := range conf.Definitions.OAuth2AC {
@@ -607,6 +915,14 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 				return nil, confErr.With(err)
 			}
 
+			if oidcConf.Session != "" {
+				manager, exist := sessions[oidcConf.Session]
+				if !exist {
+					return nil, confErr.Messagef("session: referenced session block %q is not defined", oidcConf.Session)
+				}
+				manager.SetTokenRefresher(oidcConf.Name, oidcClient)
+			}
+
 			oa := ac.NewOAuth2Callback(oidcClient, oidcConf.Name)
 
 			accessControls.Add(oidcConf.Name, oa, oidcConf.ErrorHandler)
@@ -616,6 +932,38 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 	return accessControls, nil
 }
 
+// configureBackchannelLogouts creates the back-channel logout handlers of all oidc blocks
+// with a backchannel_logout_path, keyed by that path.
+func configureBackchannelLogouts(conf *config.Couper, confCtx *hcl.EvalContext, memStore *cache.MemoryStore,
+	oidcConfigs oidc.Configs, sessions map[string]*session.Manager) (map[string]http.Handler, error) {
+	handlers := make(map[string]http.Handler)
+
+	if conf.Definitions == nil {
+		return handlers, nil
+	}
+
+	for _, oidcConf := range conf.Definitions.OIDC {
+		if oidcConf.BackchannelLogoutPath == "" {
+			continue
+		}
+
+		confErr := errors.Configuration.Label(oidcConf.Name)
+		if _, exist := handlers[oidcConf.BackchannelLogoutPath]; exist {
+			return nil, confErr.Messagef("backchannel_logout_path: duplicate path %q", oidcConf.BackchannelLogoutPath)
+		}
+
+		oidcClient, err := oauth2.NewOidcClient(confCtx, oidcConfigs[oidcConf.Name])
+		if err != nil {
+			return nil, confErr.With(err)
+		}
+
+		handlers[oidcConf.BackchannelLogoutPath] = handler.NewBackchannelLogout(oidcConf.Name, oidcClient,
+			sessions[oidcConf.Session], memStore)
+	}
+
+	return handlers, nil
+}
+
 func configureSAMLProviders(conf *config.Couper, confCtx *hcl.EvalContext, log *logrus.Entry,
 	memStore *cache.MemoryStore) (map[string]lib.SAMLConfigWithProvider, error) {
 
@@ -644,8 +992,14 @@ func configureSAMLProviders(conf *config.Couper, confCtx *hcl.EvalContext, log *
 				return nil, confErr.With(err)
 			}
 
+			keyPair, err := lib.NewSAMLKeyPair(samlConf)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
 			providers[samlConf.Name] = lib.SAMLConfigWithProvider{
 				Config:   samlConf,
+				KeyPair:  keyPair,
 				Provider: provider,
 			}
 		}
@@ -654,6 +1008,23 @@ func configureSAMLProviders(conf *config.Couper, confCtx *hcl.EvalContext, log *
 	return providers, nil
 }
 
+func configureSessions(conf *config.Couper, memStore *cache.MemoryStore) (map[string]*session.Manager, error) {
+	managers := make(map[string]*session.Manager)
+
+	if conf.Definitions != nil {
+		for _, sessionConf := range conf.Definitions.Session {
+			manager, err := session.NewManager(sessionConf, memStore)
+			if err != nil {
+				return nil, errors.Configuration.Label(sessionConf.Name).With(err)
+			}
+
+			managers[sessionConf.Name] = manager
+		}
+	}
+
+	return managers, nil
+}
+
 func newJWT(jwtConf *config.JWT, conf *config.Couper, confCtx *hcl.EvalContext,
 	log *logrus.Entry, memStore *cache.MemoryStore) (*ac.JWT, error) {
 	var (
@@ -774,6 +1145,15 @@ func setRoutesFromHosts(
 	return nil
 }
 
+func setMatchedRoutesFromHosts(srvConf ServerConfiguration, portsHosts Ports, path string, route *MatchedRoute) {
+	for port, hosts := range portsHosts {
+		for host := range hosts {
+			routes := srvConf[port][host].MatchedEndpointRoutes
+			routes[path] = append(routes[path], route)
+		}
+	}
+}
+
 func getPortsHostsList(hosts []string, defaultPort int) (Ports, error) {
 	if len(hosts) == 0 {
 		hosts = append(hosts, fmt.Sprintf("*:%d", defaultPort))
@@ -781,23 +1161,37 @@ func getPortsHostsList(hosts []string, defaultPort int) (Ports, error) {
 
 	portsHosts := make(Ports)
 
-	for _, hp := range hosts {
-		if !strings.Contains(hp, ":") {
-			hp += fmt.Sprintf(":%d", defaultPort)
-		}
-
-		host, port, err := GetHostPort(hp)
-		if err != nil {
-			return nil, err
-		} else if port == -1 {
+	for i, hp := range hosts {
+		var host string
+		var port int
+		if strings.HasPrefix(hp, HostPatternPrefix) {
+			var p string
+			host, p = splitHostPattern(hp)
 			port = defaultPort
+			if p != "" && p != "*" {
+				port, _ = strconv.Atoi(p)
+			}
+		} else {
+			if !strings.Contains(hp, ":") {
+				hp += fmt.Sprintf(":%d", defaultPort)
+			}
+
+			var err error
+			host, port, err = GetHostPort(hp)
+			if err != nil {
+				return nil, err
+			} else if port == -1 {
+				port = defaultPort
+			}
 		}
 
 		if portsHosts[Port(port)] == nil {
 			portsHosts[Port(port)] = make(Hosts)
 		}
 
-		portsHosts[Port(port)][host] = NewMuxOptions()
+		muxOpts := NewMuxOptions()
+		muxOpts.HostOrder = i
+		portsHosts[Port(port)][host] = muxOpts
 	}
 
 	return portsHosts, nil
@@ -175,6 +175,52 @@ func TestServer_validatePortHosts(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"Wildcard and regular expression hosts",
+			args{
+				&config.Couper{
+					Servers: []*config.Server{
+						{Hosts: []string{"example.com", "*.example.com:9090"}},
+						{Hosts: []string{`~^(?P<sub>[a-z]+)\.example\.org$`, `~^(?:www\.)?example\.net$:9090`}},
+					},
+				}, 8080,
+			},
+			false,
+		},
+		{
+			"Same wildcard host in two servers",
+			args{
+				&config.Couper{
+					Servers: []*config.Server{
+						{Hosts: []string{"*.example.com"}},
+						{Hosts: []string{"*.example.com:8080"}},
+					},
+				}, 8080,
+			},
+			true,
+		},
+		{
+			"Invalid wildcard host format",
+			args{
+				&config.Couper{
+					Servers: []*config.Server{
+						{Hosts: []string{"api.*.example.com"}},
+					},
+				}, 8080,
+			},
+			true,
+		},
+		{
+			"Invalid regular expression host",
+			args{
+				&config.Couper{
+					Servers: []*config.Server{
+						{Hosts: []string{"~^(example\\.com$"}},
+					},
+				}, 8080,
+			},
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(subT *testing.T) {
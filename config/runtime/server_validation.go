@@ -3,10 +3,18 @@ package runtime
 import (
 	"fmt"
 	"regexp"
+	"strings"
 )
 
-// reValidFormat validates the format only, validating for a valid host or port is out of scope.
-var reValidFormat = regexp.MustCompile(`^([a-z0-9.-]+|\*)(:\*|:\d{1,5})?$`)
+var (
+	// reValidFormat validates the format only, validating for a valid host or port is out of scope.
+	reValidFormat = regexp.MustCompile(`^(\*\.[a-z0-9.-]+|[a-z0-9.-]+|\*)(:\*|:\d{1,5})?$`)
+	// reHostPatternPort matches the optional port of a regular expression host.
+	reHostPatternPort = regexp.MustCompile(`:(\*|\d{1,5})$`)
+)
+
+// HostPatternPrefix marks a host as regular expression.
+const HostPatternPrefix = "~"
 
 func validateHosts(serverName string, hosts []string, isHostsMandatory bool) error {
 	if isHostsMandatory && len(hosts) == 0 {
@@ -14,6 +22,14 @@ func validateHosts(serverName string, hosts []string, isHostsMandatory bool) err
 	}
 
 	for _, host := range hosts {
+		if strings.HasPrefix(host, HostPatternPrefix) {
+			pattern, _ := splitHostPattern(host)
+			if _, err := CompileHostPattern(pattern); err != nil {
+				return fmt.Errorf("the host pattern is invalid: %q: %v", host, err)
+			}
+			continue
+		}
+
 		if !reValidFormat.MatchString(host) {
 			return fmt.Errorf("the host format is invalid: %q", host)
 		}
@@ -21,3 +37,17 @@ func validateHosts(serverName string, hosts []string, isHostsMandatory bool) err
 
 	return nil
 }
+
+// CompileHostPattern compiles the regular expression of the given host pattern. The expression
+// must match the whole host, so it is anchored at both ends.
+func CompileHostPattern(host string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + strings.TrimPrefix(host, HostPatternPrefix) + ")$")
+}
+
+// splitHostPattern splits a regular expression host into the pattern and its optional port.
+func splitHostPattern(host string) (string, string) {
+	if loc := reHostPatternPort.FindStringIndex(host); loc != nil {
+		return host[:loc[0]], host[loc[0]+1:]
+	}
+	return host, ""
+}
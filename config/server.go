@@ -11,19 +11,22 @@ var _ Inline = &Server{}
 
 // Server represents the <Server> object.
 type Server struct {
-	AccessControl        []string    `hcl:"access_control,optional" docs:"The [access controls](../access-control) to protect the server. Inherited by nested blocks."`
-	APIs                 APIs        `hcl:"api,block" docs:"Configures an API (zero or more)."`
-	BasePath             string      `hcl:"base_path,optional" docs:"The path prefix for all requests."`
-	CORS                 *CORS       `hcl:"cors,block" docs:"Configures [CORS](/configuration/block/cors) settings (zero or one)."`
-	DisableAccessControl []string    `hcl:"disable_access_control,optional" docs:"Disables access controls by name."`
-	Endpoints            Endpoints   `hcl:"endpoint,block" docs:"Configures a free [endpoint](/configuration/block/endpoint) (zero or more)."`
-	ErrorFile            string      `hcl:"error_file,optional" docs:"Location of the error file template."`
-	Files                FilesBlocks `hcl:"files,block" docs:"Configures file serving (zero or more)."`
-	Hosts                []string    `hcl:"hosts,optional" docs:"Mandatory, if there is more than one {server} block."`
-	Name                 string      `hcl:"name,label_optional"`
-	Remain               hcl.Body    `hcl:",remain"`
-	SPAs                 SPAs        `hcl:"spa,block" docs:"Configures an SPA (zero or more)."`
-	TLS                  *ServerTLS  `hcl:"tls,block" docs:"Configures [server TLS](/configuration/block/server_tls) (zero or one)."`
+	AccessControl        []string       `hcl:"access_control,optional" docs:"The [access controls](../access-control) to protect the server. Inherited by nested blocks."`
+	APIs                 APIs           `hcl:"api,block" docs:"Configures an API (zero or more)."`
+	BasePath             string         `hcl:"base_path,optional" docs:"The path prefix for all requests."`
+	Tenants              *Tenants       `hcl:"beta_tenants,block" docs:"Configures the [tenant resolution](/configuration/block/beta_tenants) (zero or one)."`
+	CORS                 *CORS          `hcl:"cors,block" docs:"Configures [CORS](/configuration/block/cors) settings (zero or one)."`
+	DisableAccessControl []string       `hcl:"disable_access_control,optional" docs:"Disables access controls by name."`
+	Endpoints            Endpoints      `hcl:"endpoint,block" docs:"Configures a free [endpoint](/configuration/block/endpoint) (zero or more)."`
+	ErrorFile            string         `hcl:"error_file,optional" docs:"Location of the error file template."`
+	Files                FilesBlocks    `hcl:"files,block" docs:"Configures file serving (zero or more)."`
+	Hosts                []string       `hcl:"hosts,optional" docs:"Mandatory, if there is more than one {server} block."`
+	JWKSPath             string         `hcl:"jwks_path,optional" docs:"If set, the public keys of all [JWT signing profiles](/configuration/block/jwt_signing_profile) and [JWT](/configuration/block/jwt) blocks with an {RS*}, {PS*}, {ES*} or {EdDSA} signing key are published as JWK Set at this path."`
+	Name                 string         `hcl:"name,label_optional"`
+	Remain               hcl.Body       `hcl:",remain"`
+	SPAs                 SPAs           `hcl:"spa,block" docs:"Configures an SPA (zero or more)."`
+	TLS                  *ServerTLS     `hcl:"tls,block" docs:"Configures [server TLS](/configuration/block/server_tls) (zero or one)."`
+	TokenEndpoints       TokenEndpoints `hcl:"token_endpoint,block" docs:"Configures an OAuth2 [token endpoint](/configuration/block/token_endpoint) (zero or more)."`
 }
 
 // Servers represents a list of <Server> objects.
@@ -0,0 +1,14 @@
+package config
+
+import "github.com/hashicorp/hcl/v2"
+
+// SigV4 represents the sigv4 block in a backend block.
+type SigV4 struct {
+	AccessKeyID     string   `hcl:"access_key_id" docs:"The AWS access key ID."`
+	Region          string   `hcl:"region" docs:"The region of the service, e.g. {\"eu-central-1\"}."`
+	SecretAccessKey string   `hcl:"secret_access_key" docs:"The AWS secret access key."`
+	Service         string   `hcl:"service,optional" docs:"The signing name of the service." default:"s3"`
+	SessionToken    string   `hcl:"session_token,optional" docs:"The session token of temporary security credentials."`
+	UnsignedPayload bool     `hcl:"unsigned_payload,optional" docs:"Do not hash the request body, e.g. for large uploads. Sends {UNSIGNED-PAYLOAD} as {X-Amz-Content-Sha256} header field value." default:"false"`
+	Remain          hcl.Body `hcl:",remain"`
+}
@@ -0,0 +1,15 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Tenants represents the "beta_tenants" block of a server.
+type Tenants struct {
+	Header      string         `hcl:"header,optional" docs:"Name of the request header field containing the tenant ID."`
+	HostPattern string         `hcl:"host_pattern,optional" docs:"Host pattern with a leading {*} label matching the tenant ID, e.g. {\"*.example.com\"}."`
+	Key         hcl.Expression `hcl:"key,optional" docs:"Expression to obtain the tenant ID, e.g. from token claims: {request.context.my_jwt.tenant_id}." type:"string"`
+	Tenants     cty.Value      `hcl:"tenants,optional" docs:"Object with the tenant IDs as property names and objects with tenant records as values." type:"object"`
+	TenantsFile string         `hcl:"tenants_file,optional" docs:"Reference to a JSON file containing an object like {tenants}. The file is reloaded on change."`
+}
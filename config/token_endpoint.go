@@ -0,0 +1,25 @@
+package config
+
+// TokenEndpoint represents the <TokenEndpoint> object.
+type TokenEndpoint struct {
+	Clients           []*TokenEndpointClient `hcl:"client,block" docs:"Configures a [client](/configuration/block/token_endpoint_client) (one or more)."`
+	Issuer            string                 `hcl:"issuer" docs:"The issuer identifier URL, used as {iss} claim of the issued tokens and to publish the authorization server metadata at {/.well-known/oauth-authorization-server}."`
+	JWKSPath          string                 `hcl:"jwks_path,optional" docs:"The path where the public key of the signing profile is published as JWK Set." default:"/.well-known/jwks.json"`
+	JWTSigningProfile string                 `hcl:"jwt_signing_profile" docs:"References a [JWT signing profile](/configuration/block/jwt_signing_profile) in [definitions](/configuration/block/definitions) with an {RS*}, {PS*}, {ES*} or {EdDSA} {signature_algorithm} and a non-zero {ttl} to sign the access tokens."`
+	Path              string                 `hcl:"path,label"`
+	RefreshTokenTTL   string                 `hcl:"refresh_token_ttl,optional" docs:"If set, a refresh token with this time-to-live is issued along with the access token. Refresh tokens are rotated on use." type:"duration"`
+}
+
+// TokenEndpoints represents a list of <TokenEndpoint> objects.
+type TokenEndpoints []*TokenEndpoint
+
+// TokenEndpointClient represents a client of the <TokenEndpoint>.
+type TokenEndpointClient struct {
+	Audience           string   `hcl:"audience,optional" docs:"The {aud} claim of the access tokens issued for this client."`
+	ClientSecretHash   string   `hcl:"client_secret_hash,optional" docs:"The bcrypt hash of the client secret for the {client_secret_basic} or {client_secret_post} client authentication. Mutually exclusive with {key} and {key_file}."`
+	Key                string   `hcl:"key,optional" docs:"Public key (in PEM format) of the client for the {private_key_jwt} client authentication. Mutually exclusive with {key_file} and {client_secret_hash}."`
+	KeyFile            string   `hcl:"key_file,optional" docs:"Reference to file containing the public key of the client. Mutually exclusive with {key} and {client_secret_hash}."`
+	Name               string   `hcl:"name,label"`
+	Scope              []string `hcl:"scope,optional" docs:"The scope values the client may request. Without a requested scope, all values are granted."`
+	SignatureAlgorithm string   `hcl:"signature_algorithm,optional" docs:"Algorithm of the client assertion for the {private_key_jwt} client authentication: {\"RS256\"}, {\"RS384\"}, {\"RS512\"}, {\"ES256\"}, {\"ES384\"}, {\"ES512\"}, {\"PS256\"}, {\"PS384\"}, {\"PS512\"}, {\"EdDSA\"}. Required with {key} or {key_file}."`
+}
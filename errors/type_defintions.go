@@ -11,9 +11,18 @@ var Definitions = []*Error{
 	AccessControl.Kind("external_authz").Kind("external_authz_invalid_credentials").Status(http.StatusUnauthorized),
 	AccessControl.Kind("external_authz").Kind("external_authz_insufficient_permissions").Status(http.StatusForbidden),
 
+	AccessControl.Kind("api_key").Status(http.StatusUnauthorized),
+	AccessControl.Kind("api_key").Kind("api_key_missing").Status(http.StatusUnauthorized),
+
 	AccessControl.Kind("basic_auth").Status(http.StatusUnauthorized),
 	AccessControl.Kind("basic_auth").Kind("basic_auth_credentials_missing").Status(http.StatusUnauthorized),
 
+	AccessControl.Kind("client_certificate").Status(http.StatusForbidden),
+	AccessControl.Kind("client_certificate").Kind("client_certificate_missing").Status(http.StatusUnauthorized),
+
+	AccessControl.Kind("http_message_signature").Status(http.StatusUnauthorized),
+	AccessControl.Kind("http_message_signature").Kind("http_message_signature_missing").Status(http.StatusUnauthorized),
+
 	AccessControl.Kind("jwt").Status(http.StatusUnauthorized),
 	AccessControl.Kind("jwt").Kind("jwt_token_expired").Status(http.StatusUnauthorized),
 	AccessControl.Kind("jwt").Kind("jwt_token_inactive").Status(http.StatusUnauthorized),
@@ -22,14 +31,25 @@ var Definitions = []*Error{
 
 	AccessControl.Kind("oauth2"),
 
+	AccessControl.Kind("beta_policy").Status(http.StatusForbidden),
+	AccessControl.Kind("beta_policy").Kind("beta_policy_denied").Status(http.StatusForbidden),
+
 	AccessControl.Kind("beta_rate_limiter").Status(http.StatusTooManyRequests),
 	AccessControl.Kind("beta_rate_limiter").Kind("beta_rate_limiter_key").Status(http.StatusForbidden),
 
+	AccessControl.Kind("session").Status(http.StatusUnauthorized),
+	AccessControl.Kind("session").Kind("session_missing").Status(http.StatusUnauthorized),
+
+	AccessControl.Kind("signature").Status(http.StatusUnauthorized),
+	AccessControl.Kind("signature").Kind("signature_missing").Status(http.StatusUnauthorized),
+
 	AccessControl.Kind("saml2"),
 	AccessControl.Kind("saml2").Kind("saml"),
 
 	AccessControl.Kind("insufficient_permissions").Context("api").Context("endpoint"),
 
+	AccessControl.Kind("beta_tenant").Status(http.StatusForbidden).Context("api").Context("endpoint"),
+
 	Backend,
 	Backend.Kind("backend_openapi_validation").Status(http.StatusBadRequest),
 	Backend.Kind("backend_throttle_exceeded").Status(http.StatusTooManyRequests),
@@ -6,26 +6,39 @@ var (
 	ExternalAuthz                        = Definitions[1]
 	ExternalAuthzInvalidCredentials      = Definitions[2]
 	ExternalAuthzInsufficientPermissions = Definitions[3]
-	BasicAuth                            = Definitions[4]
-	BasicAuthCredentialsMissing          = Definitions[5]
-	Jwt                                  = Definitions[6]
-	JwtTokenExpired                      = Definitions[7]
-	JwtTokenInactive                     = Definitions[8]
-	JwtTokenInvalid                      = Definitions[9]
-	JwtTokenMissing                      = Definitions[10]
-	Oauth2                               = Definitions[11]
-	BetaRateLimiter                      = Definitions[12]
-	BetaRateLimiterKey                   = Definitions[13]
-	Saml2                                = Definitions[14]
-	Saml                                 = Definitions[15]
-	InsufficientPermissions              = Definitions[16]
-	BackendOpenapiValidation             = Definitions[18]
-	BackendThrottleExceeded              = Definitions[19]
-	BackendTimeout                       = Definitions[20]
-	BetaBackendTokenRequest              = Definitions[21]
-	BackendUnhealthy                     = Definitions[22]
-	Sequence                             = Definitions[24]
-	UnexpectedStatus                     = Definitions[25]
+	ApiKey                               = Definitions[4]
+	ApiKeyMissing                        = Definitions[5]
+	BasicAuth                            = Definitions[6]
+	BasicAuthCredentialsMissing          = Definitions[7]
+	ClientCertificate                    = Definitions[8]
+	ClientCertificateMissing             = Definitions[9]
+	HttpMessageSignature                 = Definitions[10]
+	HttpMessageSignatureMissing          = Definitions[11]
+	Jwt                                  = Definitions[12]
+	JwtTokenExpired                      = Definitions[13]
+	JwtTokenInactive                     = Definitions[14]
+	JwtTokenInvalid                      = Definitions[15]
+	JwtTokenMissing                      = Definitions[16]
+	Oauth2                               = Definitions[17]
+	BetaPolicy                           = Definitions[18]
+	BetaPolicyDenied                     = Definitions[19]
+	BetaRateLimiter                      = Definitions[20]
+	BetaRateLimiterKey                   = Definitions[21]
+	Session                              = Definitions[22]
+	SessionMissing                       = Definitions[23]
+	Signature                            = Definitions[24]
+	SignatureMissing                     = Definitions[25]
+	Saml2                                = Definitions[26]
+	Saml                                 = Definitions[27]
+	InsufficientPermissions              = Definitions[28]
+	BetaTenant                           = Definitions[29]
+	BackendOpenapiValidation             = Definitions[31]
+	BackendThrottleExceeded              = Definitions[32]
+	BackendTimeout                       = Definitions[33]
+	BetaBackendTokenRequest              = Definitions[34]
+	BackendUnhealthy                     = Definitions[35]
+	Sequence                             = Definitions[37]
+	UnexpectedStatus                     = Definitions[38]
 )
 
 // typeDefinitions holds all related error definitions which are
@@ -39,28 +52,41 @@ var types = typeDefinitions{
 	"external_authz":                          ExternalAuthz,
 	"external_authz_invalid_credentials":      ExternalAuthzInvalidCredentials,
 	"external_authz_insufficient_permissions": ExternalAuthzInsufficientPermissions,
-	"basic_auth":                              BasicAuth,
-	"basic_auth_credentials_missing":          BasicAuthCredentialsMissing,
-	"jwt":                                     Jwt,
-	"jwt_token_expired":                       JwtTokenExpired,
-	"jwt_token_inactive":                      JwtTokenInactive,
-	"jwt_token_invalid":                       JwtTokenInvalid,
-	"jwt_token_missing":                       JwtTokenMissing,
-	"oauth2":                                  Oauth2,
-	"beta_rate_limiter":                       BetaRateLimiter,
-	"beta_rate_limiter_key":                   BetaRateLimiterKey,
-	"saml2":                                   Saml2,
-	"saml":                                    Saml,
-	"insufficient_permissions":                InsufficientPermissions,
-	"backend":                                 Backend,
-	"backend_openapi_validation":              BackendOpenapiValidation,
-	"backend_throttle_exceeded":               BackendThrottleExceeded,
-	"backend_timeout":                         BackendTimeout,
-	"beta_backend_token_request":              BetaBackendTokenRequest,
-	"backend_unhealthy":                       BackendUnhealthy,
-	"endpoint":                                Endpoint,
-	"sequence":                                Sequence,
-	"unexpected_status":                       UnexpectedStatus,
+	"api_key":                        ApiKey,
+	"api_key_missing":                ApiKeyMissing,
+	"basic_auth":                     BasicAuth,
+	"basic_auth_credentials_missing": BasicAuthCredentialsMissing,
+	"client_certificate":             ClientCertificate,
+	"client_certificate_missing":     ClientCertificateMissing,
+	"http_message_signature":         HttpMessageSignature,
+	"http_message_signature_missing": HttpMessageSignatureMissing,
+	"jwt":                            Jwt,
+	"jwt_token_expired":              JwtTokenExpired,
+	"jwt_token_inactive":             JwtTokenInactive,
+	"jwt_token_invalid":              JwtTokenInvalid,
+	"jwt_token_missing":              JwtTokenMissing,
+	"oauth2":                         Oauth2,
+	"beta_policy":                    BetaPolicy,
+	"beta_policy_denied":             BetaPolicyDenied,
+	"beta_rate_limiter":              BetaRateLimiter,
+	"beta_rate_limiter_key":          BetaRateLimiterKey,
+	"session":                        Session,
+	"session_missing":                SessionMissing,
+	"signature":                      Signature,
+	"signature_missing":              SignatureMissing,
+	"saml2":                          Saml2,
+	"saml":                           Saml,
+	"insufficient_permissions":       InsufficientPermissions,
+	"beta_tenant":                    BetaTenant,
+	"backend":                        Backend,
+	"backend_openapi_validation":     BackendOpenapiValidation,
+	"backend_throttle_exceeded":      BackendThrottleExceeded,
+	"backend_timeout":                BackendTimeout,
+	"beta_backend_token_request":     BetaBackendTokenRequest,
+	"backend_unhealthy":              BackendUnhealthy,
+	"endpoint":                       Endpoint,
+	"sequence":                       Sequence,
+	"unexpected_status":              UnexpectedStatus,
 }
 
 // IsKnown tells the configuration callee if Couper
@@ -72,4 +98,4 @@ func IsKnown(errorType string) bool {
 
 // SuperTypesMapsByContext holds maps for error super-types to sub-types
 // by a given context block type (e.g. api or endpoint).
-var SuperTypesMapsByContext = map[string]map[string][]string{"api": map[string][]string{"*": []string{"insufficient_permissions", "backend_openapi_validation", "backend_throttle_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy"}, "access_control": []string{"insufficient_permissions"}, "backend": []string{"backend_openapi_validation", "backend_throttle_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy"}}, "endpoint": map[string][]string{"*": []string{"insufficient_permissions", "backend_openapi_validation", "backend_throttle_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy", "sequence", "unexpected_status"}, "access_control": []string{"insufficient_permissions"}, "backend": []string{"backend_openapi_validation", "backend_throttle_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy"}, "endpoint": []string{"sequence", "unexpected_status"}}}
+var SuperTypesMapsByContext = map[string]map[string][]string{"api": map[string][]string{"*": []string{"insufficient_permissions", "beta_tenant", "backend_openapi_validation", "backend_throttle_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy"}, "access_control": []string{"insufficient_permissions", "beta_tenant"}, "backend": []string{"backend_openapi_validation", "backend_throttle_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy"}}, "endpoint": map[string][]string{"*": []string{"insufficient_permissions", "beta_tenant", "backend_openapi_validation", "backend_throttle_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy", "sequence", "unexpected_status"}, "access_control": []string{"insufficient_permissions", "beta_tenant"}, "backend": []string{"backend_openapi_validation", "backend_throttle_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy"}, "endpoint": []string{"sequence", "unexpected_status"}}}
@@ -21,6 +21,7 @@ import (
 	"github.com/zclconf/go-cty/cty/function/stdlib"
 	ctyjson "github.com/zclconf/go-cty/cty/json"
 
+	"github.com/coupergateway/couper/accesscontrol/session"
 	"github.com/coupergateway/couper/cache"
 	"github.com/coupergateway/couper/config"
 	"github.com/coupergateway/couper/config/env"
@@ -54,6 +55,7 @@ type Context struct {
 	oauth2            map[string]config.OAuth2Authorization
 	jwtSigningConfigs map[string]*lib.JWTSigningConfig
 	samlProviders     []lib.SAMLConfigWithProvider
+	sessions          map[string]*session.Manager
 	syncedVariables   *SyncedVariables
 
 	cloneMu sync.RWMutex
@@ -143,6 +145,9 @@ func (c *Context) WithClientRequest(req *http.Request) *Context {
 	if endpoint, ok := ctx.inner.Value(request.Endpoint).(string); ok {
 		ctxMap[variables.Endpoint] = cty.StringVal(endpoint)
 	}
+	if tenant, ok := ctx.inner.Value(request.Tenant).(map[string]interface{}); ok {
+		ctxMap[variables.Tenant] = seetie.MapToValue(tenant)
+	}
 
 	var id string
 	if uid, ok := ctx.inner.Value(request.UID).(string); ok {
@@ -154,6 +159,11 @@ func (c *Context) WithClientRequest(req *http.Request) *Context {
 		pathParams = params
 	}
 
+	var hostParams request.HostParameter
+	if params, ok := ctx.inner.Value(request.HostParams).(request.HostParameter); ok {
+		hostParams = params
+	}
+
 	p := req.URL.Port()
 	if p == "" {
 		if req.URL.Scheme == "https" {
@@ -179,6 +189,7 @@ func (c *Context) WithClientRequest(req *http.Request) *Context {
 		variables.Origin:    cty.StringVal(origin.String()),
 		variables.Protocol:  cty.StringVal(req.URL.Scheme),
 		variables.Host:      cty.StringVal(req.URL.Hostname()),
+		variables.HostParam: seetie.MapToValue(hostParams),
 		variables.Port:      cty.NumberIntVal(port),
 		variables.Path:      cty.StringVal(req.URL.Path),
 		variables.Query:     seetie.ValuesMapToValue(req.URL.Query()),
@@ -192,7 +203,7 @@ func (c *Context) WithClientRequest(req *http.Request) *Context {
 	ctx.eval.Variables[variables.BackendResponses] = cty.ObjectVal(make(map[string]cty.Value))
 
 	mergeBackendVariables(ctx.eval, variables.Backends, ctx.syncBackendVariables())
-	ctx.updateRequestRelatedFunctions(origin)
+	ctx.updateRequestRelatedFunctions(origin, req.Cookies())
 	ctx.updateFunctions()
 
 	return ctx
@@ -243,6 +254,7 @@ func (c *Context) clone() *Context {
 		oauth2:            c.oauth2,
 		jwtSigningConfigs: c.jwtSigningConfigs,
 		samlProviders:     c.samlProviders[:],
+		sessions:          c.sessions,
 		syncedVariables:   NewSyncedVariables(),
 	}
 }
@@ -337,6 +349,11 @@ func (c *Context) WithJWTSigningConfigs(configs map[string]*lib.JWTSigningConfig
 	return c
 }
 
+// JWTSigningConfigs returns the signing configurations referenced by the lib.FnJWTSign function.
+func (c *Context) JWTSigningConfigs() map[string]*lib.JWTSigningConfig {
+	return c.jwtSigningConfigs
+}
+
 // WithOAuth2AC adds the OAuth2AC config structs.
 func (c *Context) WithOAuth2AC(os []*config.OAuth2AC) *Context {
 	c.cloneMu.Lock()
@@ -385,6 +402,15 @@ func (c *Context) WithSAMLProviders(providers map[string]lib.SAMLConfigWithProvi
 	return c
 }
 
+// WithSessions sets up the session managers for the session related functions.
+func (c *Context) WithSessions(managers map[string]*session.Manager) *Context {
+	c.cloneMu.Lock()
+	defer c.cloneMu.Unlock()
+
+	c.sessions = managers
+	return c
+}
+
 func (c *Context) HCLContext() *hcl.EvalContext {
 	return c.eval
 }
@@ -431,21 +457,45 @@ func (c *Context) updateFunctions() {
 }
 
 // updateRequestRelatedFunctions re-creates the listed functions for the client request context.
-func (c *Context) updateRequestRelatedFunctions(origin *url.URL) {
+func (c *Context) updateRequestRelatedFunctions(origin *url.URL, cookies []*http.Cookie) {
 	if len(c.oauth2) > 0 {
 		oauth2fn := lib.NewOAuthAuthorizationURLFunction(c.eval, c.oauth2, c.getCodeVerifier, origin, Value)
 		c.eval.Functions[lib.FnOAuthAuthorizationURL] = oauth2fn
 	} else {
 		c.eval.Functions[lib.FnOAuthAuthorizationURL] = lib.NoOpOAuthAuthorizationURLFunction
 	}
+	oidcLogoutConfigs := make(map[string]lib.OidcLogoutConfig)
+	for name, conf := range c.oauth2 {
+		if logoutConf, ok := conf.(lib.OidcLogoutConfig); ok {
+			oidcLogoutConfigs[name] = logoutConf
+		}
+	}
+	if len(oidcLogoutConfigs) > 0 {
+		c.eval.Functions[lib.FnOidcLogoutURL] = lib.NewOidcLogoutURLFunction(oidcLogoutConfigs, origin)
+	} else {
+		c.eval.Functions[lib.FnOidcLogoutURL] = lib.NoOpOidcLogoutURLFunction
+	}
+
 	c.eval.Functions[lib.FnOAuthVerifier] = lib.NewOAuthCodeVerifierFunction(c.getCodeVerifier)
 	c.eval.Functions[lib.InternalFnOAuthHashedVerifier] = lib.NewOAuthCodeChallengeFunction(c.getCodeVerifier)
 
 	if len(c.samlProviders) > 0 {
 		samlfn := lib.NewSamlSsoURLFunction(c.samlProviders, origin)
 		c.eval.Functions[lib.FnSamlSsoURL] = samlfn
+		c.eval.Functions[lib.FnSamlSloURL] = lib.NewSamlSloURLFunction(c.samlProviders, origin)
+		c.eval.Functions[lib.FnSamlSpMetadata] = lib.NewSamlSpMetadataFunction(c.samlProviders, origin)
 	} else {
 		c.eval.Functions[lib.FnSamlSsoURL] = lib.NoOpSamlSsoURLFunction
+		c.eval.Functions[lib.FnSamlSloURL] = lib.NoOpSamlSloURLFunction
+		c.eval.Functions[lib.FnSamlSpMetadata] = lib.NoOpSamlSpMetadataFunction
+	}
+
+	if len(c.sessions) > 0 {
+		c.eval.Functions[lib.FnSessionWrite] = lib.NewSessionWriteFunction(c.inner, c.sessions, cookies)
+		c.eval.Functions[lib.FnSessionDestroy] = lib.NewSessionDestroyFunction(c.sessions, cookies)
+	} else {
+		c.eval.Functions[lib.FnSessionWrite] = lib.NoOpSessionWriteFunction
+		c.eval.Functions[lib.FnSessionDestroy] = lib.NoOpSessionDestroyFunction
 	}
 }
 
@@ -608,7 +658,7 @@ func newVariable(ctx context.Context, cookies []*http.Cookie, headers http.Heade
 	if len(gp) > 0 {
 		ctxAcMap[grantedPermissions] = seetie.GoToValue(gp)
 	}
-	if rp, permissionSet := ctx.Value(request.RequiredPermission).(string); permissionSet {
+	if rp := ctx.Value(request.RequiredPermission); rp != nil {
 		ctxAcMap[requiredPermission] = seetie.GoToValue(rp)
 	}
 	var ctxAcMapValue cty.Value
@@ -12,6 +12,8 @@ import (
 	"github.com/zclconf/go-cty/cty/function"
 	"github.com/zclconf/go-cty/cty/function/stdlib"
 
+	"github.com/coupergateway/couper/accesscontrol/jwe"
+	"github.com/coupergateway/couper/accesscontrol/jwk"
 	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
 	"github.com/coupergateway/couper/config"
 	"github.com/coupergateway/couper/config/reader"
@@ -21,11 +23,61 @@ import (
 const FnJWTSign = "jwt_sign"
 
 type JWTSigningConfig struct {
-	Claims             config.Claims
-	Headers            hcl.Expression
-	Key                interface{}
-	SignatureAlgorithm string
-	TTL                int64
+	Claims                     config.Claims
+	ContentEncryptionAlgorithm string
+	EncryptionAlgorithm        string
+	EncryptionKey              interface{}
+	Headers                    hcl.Expression
+	SignatureAlgorithm         string
+	TTL                        int64
+	keys                       *signingKeys
+}
+
+// CreateToken creates the signed token and encrypts it as nested JWE if configured.
+// The kid header is set to the key ID of the active signing key, if configured.
+func (c *JWTSigningConfig) CreateToken(claims jwt.MapClaims, headers map[string]interface{}) (string, error) {
+	return c.createToken(claims, headers, false)
+}
+
+// CreateTokenWithKeyID is like CreateToken but also sets the kid header for signing
+// keys without a configured key ID, using the JWK thumbprint of the public key.
+func (c *JWTSigningConfig) CreateTokenWithKeyID(claims jwt.MapClaims, headers map[string]interface{}) (string, error) {
+	return c.createToken(claims, headers, true)
+}
+
+func (c *JWTSigningConfig) createToken(claims jwt.MapClaims, headers map[string]interface{}, thumbprintKeyID bool) (string, error) {
+	sk, err := c.keys.active(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	kid := sk.id
+	if kid == "" && thumbprintKeyID && sk.publicJWK != nil {
+		kid = sk.publicJWK.KeyID
+	}
+	if kid != "" {
+		if headers == nil {
+			headers = make(map[string]interface{})
+		}
+		if _, set := headers["kid"]; !set {
+			headers["kid"] = kid
+		}
+	}
+
+	token, err := CreateJWT(c.SignatureAlgorithm, sk.key, claims, headers)
+	if err != nil || c.EncryptionAlgorithm == "" {
+		return token, err
+	}
+
+	return jwe.Encrypt([]byte(token), c.EncryptionAlgorithm, c.ContentEncryptionAlgorithm, c.EncryptionKey,
+		map[string]interface{}{"cty": "JWT"})
+}
+
+// PublicJWKs returns the public parts of the asymmetric signing keys as JWKs,
+// including keys to be activated but not the expired ones. The key IDs default
+// to the JWK thumbprints.
+func (c *JWTSigningConfig) PublicJWKs() []*jwk.JWK {
+	return c.keys.published(time.Now())
 }
 
 func checkData(ttl, signatureAlgorithm string) (int64, acjwt.Algorithm, error) {
@@ -45,16 +97,20 @@ func checkData(ttl, signatureAlgorithm string) (int64, acjwt.Algorithm, error) {
 	return 0, alg, nil
 }
 
-func getKey(keyBytes []byte, signatureAlgorithm string) (interface{}, error) {
+// ParseSigningKey parses the PEM encoded private key for RS*, PS*, ES* and EdDSA algorithms
+// and returns the key bytes as secret for HS* algorithms.
+func ParseSigningKey(keyBytes []byte, signatureAlgorithm string) (interface{}, error) {
 	var (
 		key      interface{}
 		parseErr error
 	)
 	key = keyBytes
-	if strings.HasPrefix(signatureAlgorithm, "RS") {
+	if strings.HasPrefix(signatureAlgorithm, "RS") || strings.HasPrefix(signatureAlgorithm, "PS") {
 		key, parseErr = jwt.ParseRSAPrivateKeyFromPEM(keyBytes)
 	} else if strings.HasPrefix(signatureAlgorithm, "ES") {
 		key, parseErr = jwt.ParseECPrivateKeyFromPEM(keyBytes)
+	} else if signatureAlgorithm == "EdDSA" {
+		key, parseErr = jwt.ParseEdPrivateKeyFromPEM(keyBytes)
 	}
 
 	return key, parseErr
@@ -72,12 +128,15 @@ func NewJWTSigningConfigFromJWTSigningProfile(j *config.JWTSigningProfile, algCh
 		}
 	}
 
-	keyBytes, err := reader.ReadFromAttrFile("jwt_signing_profile key", j.Key, j.KeyFile)
-	if err != nil {
-		return nil, err
+	var keys *signingKeys
+	if len(j.SigningKeys) > 0 {
+		if j.Key != "" || j.KeyFile != "" || j.KeyID != "" {
+			return nil, fmt.Errorf("key, key_file and key_id must not be set with signing_key blocks")
+		}
+		keys, err = newSigningKeysFromConfig(j.SignatureAlgorithm, j.SigningKeys)
+	} else {
+		keys, err = newSingleSigningKey("jwt_signing_profile key", j.KeyID, j.Key, j.KeyFile, j.SignatureAlgorithm)
 	}
-
-	key, err := getKey(keyBytes, j.SignatureAlgorithm)
 	if err != nil {
 		return nil, err
 	}
@@ -85,13 +144,39 @@ func NewJWTSigningConfigFromJWTSigningProfile(j *config.JWTSigningProfile, algCh
 	c := &JWTSigningConfig{
 		Claims:             j.Claims,
 		Headers:            j.Headers,
-		Key:                key,
 		SignatureAlgorithm: j.SignatureAlgorithm,
 		TTL:                ttl,
+		keys:               keys,
+	}
+
+	if j.EncryptionAlgorithm != "" {
+		if err = configureEncryption(c, j); err != nil {
+			return nil, err
+		}
 	}
 	return c, nil
 }
 
+func configureEncryption(c *JWTSigningConfig, j *config.JWTSigningProfile) error {
+	c.EncryptionAlgorithm = j.EncryptionAlgorithm
+	c.ContentEncryptionAlgorithm = j.ContentEncryptionAlgorithm
+	if c.ContentEncryptionAlgorithm == "" {
+		c.ContentEncryptionAlgorithm = jwe.DefaultContentAlgorithm
+	}
+
+	if err := jwe.ValidateAlgorithms(c.EncryptionAlgorithm, c.ContentEncryptionAlgorithm); err != nil {
+		return err
+	}
+
+	keyBytes, err := reader.ReadFromAttrFile("jwt_signing_profile encryption_key", j.EncryptionKey, j.EncryptionKeyFile)
+	if err != nil {
+		return err
+	}
+
+	c.EncryptionKey, err = jwe.ParseEncryptionKey(keyBytes, c.EncryptionAlgorithm)
+	return err
+}
+
 func NewJWTSigningConfigFromJWT(j *config.JWT) (*JWTSigningConfig, error) {
 	if j.SigningTTL == "" {
 		return nil, nil
@@ -111,21 +196,16 @@ func NewJWTSigningConfigFromJWT(j *config.JWT) (*JWTSigningConfig, error) {
 		signingKey = j.SigningKey
 		signingKeyFile = j.SigningKeyFile
 	}
-	keyBytes, err := reader.ReadFromAttrFile("jwt signing key", signingKey, signingKeyFile)
-	if err != nil {
-		return nil, err
-	}
-
-	key, err := getKey(keyBytes, j.SignatureAlgorithm)
+	keys, err := newSingleSigningKey("jwt signing key", j.SigningKeyID, signingKey, signingKeyFile, j.SignatureAlgorithm)
 	if err != nil {
 		return nil, err
 	}
 
 	c := &JWTSigningConfig{
 		Claims:             j.Claims,
-		Key:                key,
 		SignatureAlgorithm: j.SignatureAlgorithm,
 		TTL:                ttl,
+		keys:               keys,
 	}
 	return c, nil
 }
@@ -211,7 +291,7 @@ func NewJwtSignFunction(ctx *hcl.EvalContext, jwtSigningConfigs map[string]*JWTS
 				claims[k] = v
 			}
 
-			tokenString, err := CreateJWT(signingConfig.SignatureAlgorithm, signingConfig.Key, claims, headers)
+			tokenString, err := signingConfig.CreateToken(claims, headers)
 			if err != nil {
 				return cty.StringVal(""), err
 			}
@@ -0,0 +1,206 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/coupergateway/couper/accesscontrol/jwk"
+	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/reader"
+)
+
+// keyFileCheckInterval is the minimum interval between two modification checks of the key files.
+var keyFileCheckInterval = 5 * time.Second
+
+type signingKey struct {
+	context   string
+	file      string
+	id        string
+	key       interface{}
+	modTime   time.Time
+	notAfter  time.Time
+	notBefore time.Time
+	publicJWK *jwk.JWK
+}
+
+// signingKeys holds the keys of a signing configuration ordered by their activation time.
+// Changed key files are reloaded on access, at most once per keyFileCheckInterval.
+type signingKeys struct {
+	algorithm string
+	checked   time.Time
+	keys      []*signingKey
+	mu        sync.RWMutex
+}
+
+func newSigningKey(context, id, keyValue, keyFile, algorithm string) (*signingKey, error) {
+	keyBytes, err := reader.ReadFromAttrFile(context, keyValue, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	sk := &signingKey{context: context, file: keyFile, id: id}
+	if keyFile != "" {
+		if sk.file, err = filepath.Abs(keyFile); err != nil {
+			return nil, err
+		}
+		if fileInfo, serr := os.Stat(sk.file); serr == nil {
+			sk.modTime = fileInfo.ModTime()
+		}
+	}
+
+	if err = sk.parse(keyBytes, algorithm); err != nil {
+		return nil, err
+	}
+	return sk, nil
+}
+
+func (sk *signingKey) parse(keyBytes []byte, algorithm string) error {
+	key, err := ParseSigningKey(keyBytes, algorithm)
+	if err != nil {
+		return err
+	}
+
+	var publicJWK *jwk.JWK
+	if !acjwt.NewAlgorithm(algorithm).IsHMAC() {
+		if publicJWK, err = jwk.NewPublicJWK(key, algorithm, sk.id); err != nil {
+			return err
+		}
+	}
+
+	sk.key = key
+	sk.publicJWK = publicJWK
+	return nil
+}
+
+func (sk *signingKey) isExpired(now time.Time) bool {
+	return !sk.notAfter.IsZero() && !now.Before(sk.notAfter)
+}
+
+func newSigningKeysFromConfig(algorithm string, confs []*config.JWTSigningKey) (*signingKeys, error) {
+	keys := make([]*signingKey, 0, len(confs))
+	ids := make(map[string]struct{})
+
+	for _, conf := range confs {
+		if _, exists := ids[conf.ID]; exists {
+			return nil, fmt.Errorf("signing_key %q: duplicate key ID", conf.ID)
+		}
+		ids[conf.ID] = struct{}{}
+
+		context := fmt.Sprintf("jwt_signing_profile signing_key %q", conf.ID)
+		sk, err := newSigningKey(context, conf.ID, conf.Key, conf.KeyFile, algorithm)
+		if err != nil {
+			return nil, err
+		}
+
+		if sk.notBefore, err = parseKeyTime(conf.NotBefore); err != nil {
+			return nil, fmt.Errorf("signing_key %q: not_before: %w", conf.ID, err)
+		}
+		if sk.notAfter, err = parseKeyTime(conf.NotAfter); err != nil {
+			return nil, fmt.Errorf("signing_key %q: not_after: %w", conf.ID, err)
+		}
+		if !sk.notAfter.IsZero() && !sk.notBefore.Before(sk.notAfter) {
+			return nil, fmt.Errorf("signing_key %q: not_after must be later than not_before", conf.ID)
+		}
+
+		keys = append(keys, sk)
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].notBefore.Before(keys[j].notBefore)
+	})
+
+	return &signingKeys{algorithm: algorithm, checked: time.Now(), keys: keys}, nil
+}
+
+func newSingleSigningKey(context, id, keyValue, keyFile, algorithm string) (*signingKeys, error) {
+	sk, err := newSigningKey(context, id, keyValue, keyFile, algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &signingKeys{algorithm: algorithm, checked: time.Now(), keys: []*signingKey{sk}}, nil
+}
+
+func parseKeyTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// active returns a copy of the key with the latest activation time which is not expired.
+func (s *signingKeys) active(now time.Time) (*signingKey, error) {
+	s.reload(now)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		sk := s.keys[i]
+		if !sk.notBefore.After(now) && !sk.isExpired(now) {
+			activeKey := *sk
+			return &activeKey, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no active signing key")
+}
+
+// published returns the public keys which are not expired, including keys to be activated.
+func (s *signingKeys) published(now time.Time) []*jwk.JWK {
+	s.reload(now)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var keys []*jwk.JWK
+	for _, sk := range s.keys {
+		if sk.publicJWK != nil && !sk.isExpired(now) {
+			keys = append(keys, sk.publicJWK)
+		}
+	}
+	return keys
+}
+
+// reload parses changed key files. A key file which cannot be read or parsed
+// is skipped and the previously loaded key remains in use.
+func (s *signingKeys) reload(now time.Time) {
+	s.mu.RLock()
+	due := now.Sub(s.checked) >= keyFileCheckInterval
+	s.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.checked) < keyFileCheckInterval {
+		return
+	}
+	s.checked = now
+
+	for _, sk := range s.keys {
+		if sk.file == "" {
+			continue
+		}
+
+		fileInfo, err := os.Stat(sk.file)
+		if err != nil || fileInfo.ModTime().Equal(sk.modTime) {
+			continue
+		}
+
+		keyBytes, err := reader.ReadFromFile(sk.context, sk.file)
+		if err != nil {
+			continue
+		}
+
+		if err = sk.parse(keyBytes, s.algorithm); err == nil {
+			sk.modTime = fileInfo.ModTime()
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package lib
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/coupergateway/couper/config"
+)
+
+func newECKeyPEM(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func TestJWTSigningConfig_SigningKeyRotation(t *testing.T) {
+	now := time.Now()
+	format := func(d time.Duration) string {
+		return now.Add(d).Format(time.RFC3339)
+	}
+
+	var keys = make(map[string]*ecdsa.PrivateKey)
+	var pems = make(map[string]string)
+	for _, id := range []string{"expired", "previous", "current", "next"} {
+		keys[id], pems[id] = newECKeyPEM(t)
+	}
+
+	signingConfig, err := NewJWTSigningConfigFromJWTSigningProfile(&config.JWTSigningProfile{
+		SignatureAlgorithm: "ES256",
+		TTL:                "1m",
+		SigningKeys: []*config.JWTSigningKey{
+			{ID: "next", Key: pems["next"], NotBefore: format(time.Hour)},
+			{ID: "current", Key: pems["current"], NotBefore: format(-time.Hour)},
+			{ID: "previous", Key: pems["previous"], NotBefore: format(-2 * time.Hour), NotAfter: format(time.Hour)},
+			{ID: "expired", Key: pems["expired"], NotBefore: format(-3 * time.Hour), NotAfter: format(-time.Minute)},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := signingConfig.CreateToken(jwt.MapClaims{"sub": "me"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		return keys["current"].Public(), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["kid"] != "current" {
+		t.Errorf("expected kid %q, got: %v", "current", parsed.Header["kid"])
+	}
+
+	var published []string
+	for _, publicKey := range signingConfig.PublicJWKs() {
+		published = append(published, publicKey.KeyID)
+	}
+	if expPublished := []string{"previous", "current", "next"}; len(published) != len(expPublished) ||
+		published[0] != expPublished[0] || published[1] != expPublished[1] || published[2] != expPublished[2] {
+		t.Errorf("expected published keys %v, got: %v", expPublished, published)
+	}
+
+	if sk, aerr := signingConfig.keys.active(now.Add(2 * time.Hour)); aerr != nil || sk.id != "next" {
+		t.Errorf("expected active key %q, got: %v, %v", "next", sk, aerr)
+	}
+	if _, err = signingConfig.keys.active(now.Add(-4 * time.Hour)); err == nil || err.Error() != "no active signing key" {
+		t.Errorf("expected no active signing key, got: %v", err)
+	}
+}
+
+func TestJWTSigningConfig_SigningKeyConfigError(t *testing.T) {
+	_, keyPEM := newECKeyPEM(t)
+
+	for _, tc := range []struct {
+		name    string
+		profile *config.JWTSigningProfile
+		expErr  string
+	}{
+		{
+			"key and signing_key",
+			&config.JWTSigningProfile{Key: keyPEM, SigningKeys: []*config.JWTSigningKey{{ID: "a", Key: keyPEM}}},
+			"key, key_file and key_id must not be set with signing_key blocks",
+		},
+		{
+			"duplicate key ID",
+			&config.JWTSigningProfile{SigningKeys: []*config.JWTSigningKey{{ID: "a", Key: keyPEM}, {ID: "a", Key: keyPEM}}},
+			`signing_key "a": duplicate key ID`,
+		},
+		{
+			"invalid not_before",
+			&config.JWTSigningProfile{SigningKeys: []*config.JWTSigningKey{{ID: "a", Key: keyPEM, NotBefore: "tomorrow"}}},
+			`signing_key "a": not_before: parsing time "tomorrow" as "2006-01-02T15:04:05Z07:00": cannot parse "tomorrow" as "2006"`,
+		},
+		{
+			"not_after before not_before",
+			&config.JWTSigningProfile{SigningKeys: []*config.JWTSigningKey{{ID: "a", Key: keyPEM, NotBefore: "2026-10-01T00:00:00Z", NotAfter: "2026-09-01T00:00:00Z"}}},
+			`signing_key "a": not_after must be later than not_before`,
+		},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			tc.profile.SignatureAlgorithm = "ES256"
+			tc.profile.TTL = "1m"
+
+			_, err := NewJWTSigningConfigFromJWTSigningProfile(tc.profile, nil)
+			if err == nil || err.Error() != tc.expErr {
+				subT.Errorf("expected error %q, got: %v", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func TestJWTSigningConfig_ReloadKeyFile(t *testing.T) {
+	interval := keyFileCheckInterval
+	keyFileCheckInterval = 0
+	defer func() { keyFileCheckInterval = interval }()
+
+	keyFile := filepath.Join(t.TempDir(), "signing.key")
+
+	firstKey, firstPEM := newECKeyPEM(t)
+	if err := os.WriteFile(keyFile, []byte(firstPEM), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	signingConfig, err := NewJWTSigningConfigFromJWTSigningProfile(&config.JWTSigningProfile{
+		KeyFile:            keyFile,
+		SignatureAlgorithm: "ES256",
+		TTL:                "1m",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verify := func(expKey *ecdsa.PrivateKey) {
+		t.Helper()
+
+		token, cerr := signingConfig.CreateToken(jwt.MapClaims{"sub": "me"}, nil)
+		if cerr != nil {
+			t.Fatal(cerr)
+		}
+		if _, cerr = jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+			return expKey.Public(), nil
+		}); cerr != nil {
+			t.Error(cerr)
+		}
+
+		publicKeys := signingConfig.PublicJWKs()
+		if len(publicKeys) != 1 || !publicKeys[0].Key.(*ecdsa.PublicKey).Equal(expKey.Public()) {
+			t.Errorf("expected the public key to be published")
+		}
+	}
+
+	verify(firstKey)
+
+	modTime := time.Now().Add(time.Minute)
+
+	secondKey, secondPEM := newECKeyPEM(t)
+	if err = os.WriteFile(keyFile, []byte(secondPEM), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chtimes(keyFile, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	verify(secondKey)
+
+	// an invalid file keeps the previously loaded key
+	if err = os.WriteFile(keyFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	modTime = modTime.Add(time.Minute)
+	if err = os.Chtimes(keyFile, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	verify(secondKey)
+}
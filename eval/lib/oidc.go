@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+const FnOidcLogoutURL = "oidc_logout_url"
+
+// OidcLogoutConfig is implemented by oidc configurations supporting the RP-initiated logout.
+type OidcLogoutConfig interface {
+	GetClientID() string
+	GetEndSessionEndpoint() (string, error)
+	GetPostLogoutRedirectURI() string
+}
+
+var NoOpOidcLogoutURLFunction = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "oidc_label",
+			Type: cty.String,
+		},
+	},
+	VarParam: &function.Parameter{
+		Name: "id_token_hint",
+		Type: cty.String,
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+		if len(args) > 0 {
+			return cty.StringVal(""), fmt.Errorf("missing oidc block with referenced label %q", args[0].AsString())
+		}
+		return cty.StringVal(""), fmt.Errorf("missing oidc definitions")
+	},
+})
+
+// NewOidcLogoutURLFunction creates a function returning the OpenID Connect RP-initiated logout URL
+// with the optional ID token hint.
+func NewOidcLogoutURLFunction(configs map[string]OidcLogoutConfig, origin *url.URL) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "oidc_label",
+				Type: cty.String,
+			},
+		},
+		VarParam: &function.Parameter{
+			Name: "id_token_hint",
+			Type: cty.String,
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			label := args[0].AsString()
+			conf, exist := configs[label]
+			if !exist {
+				return NoOpOidcLogoutURLFunction.Call(args)
+			}
+
+			if len(args) > 2 {
+				return cty.StringVal(""), fmt.Errorf("too many arguments, only an optional id_token_hint is allowed")
+			}
+
+			endSessionEndpoint, err := conf.GetEndSessionEndpoint()
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+			if endSessionEndpoint == "" {
+				return cty.StringVal(""), fmt.Errorf("missing end_session_endpoint in OpenID configuration for %q", label)
+			}
+
+			logoutURL, err := url.Parse(endSessionEndpoint)
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			query := logoutURL.Query()
+			query.Set("client_id", conf.GetClientID())
+			if len(args) > 1 && !args[1].IsNull() && args[1].AsString() != "" {
+				query.Set("id_token_hint", args[1].AsString())
+			}
+			if redirectURI := conf.GetPostLogoutRedirectURI(); redirectURI != "" {
+				absRedirectURI, aerr := AbsoluteURL(redirectURI, origin)
+				if aerr != nil {
+					return cty.StringVal(""), aerr
+				}
+				query.Set("post_logout_redirect_uri", absRedirectURI)
+			}
+			logoutURL.RawQuery = query.Encode()
+
+			return cty.StringVal(logoutURL.String()), nil
+		},
+	})
+}
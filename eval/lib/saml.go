@@ -1,18 +1,31 @@
 package lib
 
 import (
+	"bytes"
+	"compress/flate"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
 	"fmt"
 	"net/url"
+	"time"
 
 	saml2 "github.com/russellhaering/gosaml2"
 	"github.com/russellhaering/gosaml2/types"
+	dsig "github.com/russellhaering/goxmldsig"
+	dsigtypes "github.com/russellhaering/goxmldsig/types"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
 
 	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/reader"
 )
 
 const (
+	FnSamlSloURL            = "saml_slo_url"
+	FnSamlSpMetadata        = "saml_sp_metadata"
 	FnSamlSsoURL            = "saml_sso_url"
 	NameIDFormatUnspecified = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
 )
@@ -25,9 +38,238 @@ type SAMLMetadataProvider interface {
 // SAMLConfigWithProvider combines SAML config with its metadata provider.
 type SAMLConfigWithProvider struct {
 	Config   *config.SAML
+	KeyPair  *tls.Certificate
 	Provider SAMLMetadataProvider
 }
 
+// ServiceProvider creates the SAML service provider for the current IdP metadata.
+// Relative SP URLs are resolved against the given origin.
+func (s SAMLConfigWithProvider) ServiceProvider(origin *url.URL) (*saml2.SAMLServiceProvider, error) {
+	metadata, err := s.Provider.Metadata()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SAML metadata for %q: %w", s.Config.Name, err)
+	}
+
+	absAcsURL, err := AbsoluteURL(s.Config.SpAcsURL, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	var absSloURL string
+	if s.Config.SpSloURL != "" {
+		if absSloURL, err = AbsoluteURL(s.Config.SpSloURL, origin); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewSAMLServiceProvider(metadata, s.Config.SpEntityID, absAcsURL, absSloURL, s.KeyPair)
+}
+
+// NewSAMLKeyPair reads the SP certificate and private key of the given saml block.
+// Returns nil if no key is configured.
+func NewSAMLKeyPair(conf *config.SAML) (*tls.Certificate, error) {
+	if conf.SpPrivateKey == "" && conf.SpPrivateKeyFile == "" {
+		return nil, nil
+	}
+
+	certBytes, err := reader.ReadFromAttrFile("saml2 sp_certificate", conf.SpCertificate, conf.SpCertificateFile)
+	if err != nil {
+		return nil, err
+	}
+	keyBytes, err := reader.ReadFromAttrFile("saml2 sp_private_key", conf.SpPrivateKey, conf.SpPrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPair, err := tls.X509KeyPair(certBytes, keyBytes)
+	if err != nil {
+		return nil, err
+	}
+	// goxmldsig signs and decrypts with RSA keys only
+	if _, ok := keyPair.PrivateKey.(*rsa.PrivateKey); !ok {
+		return nil, fmt.Errorf("sp_private_key: RSA key required")
+	}
+	return &keyPair, nil
+}
+
+// NewSAMLServiceProvider creates a SAML service provider trusting the signing certificates of the given
+// IdP metadata. The SP URLs must be absolute. With keyPair, requests are signed and encrypted assertions decrypted.
+func NewSAMLServiceProvider(metadata *types.EntityDescriptor, spEntityID, acsURL, sloURL string, keyPair *tls.Certificate) (*saml2.SAMLServiceProvider, error) {
+	if metadata.IDPSSODescriptor == nil {
+		return nil, fmt.Errorf("missing IDPSSODescriptor in metadata")
+	}
+
+	certStore := dsig.MemoryX509CertificateStore{
+		Roots: []*x509.Certificate{},
+	}
+
+	for _, kd := range metadata.IDPSSODescriptor.KeyDescriptors {
+		for idx, xcert := range kd.KeyInfo.X509Data.X509Certificates {
+			if xcert.Data == "" {
+				return nil, fmt.Errorf("metadata certificate(%d) must not be empty", idx)
+			}
+			certData, err := base64.StdEncoding.DecodeString(xcert.Data)
+			if err != nil {
+				return nil, err
+			}
+
+			idpCert, err := x509.ParseCertificate(certData)
+			if err != nil {
+				return nil, err
+			}
+
+			certStore.Roots = append(certStore.Roots, idpCert)
+		}
+	}
+
+	sp := &saml2.SAMLServiceProvider{
+		AssertionConsumerServiceURL: acsURL,
+		AudienceURI:                 spEntityID,
+		IDPCertificateStore:         &certStore,
+		IdentityProviderIssuer:      metadata.EntityID,
+		ServiceProviderIssuer:       spEntityID,
+		ServiceProviderSLOURL:       sloURL,
+	}
+
+	for _, ssoService := range metadata.IDPSSODescriptor.SingleSignOnServices {
+		if ssoService.Binding == saml2.BindingHttpRedirect {
+			sp.IdentityProviderSSOURL = ssoService.Location
+		}
+	}
+	for _, sloService := range metadata.IDPSSODescriptor.SingleLogoutServices {
+		if sloService.Binding == saml2.BindingHttpRedirect {
+			sp.IdentityProviderSLOURL = sloService.Location
+		}
+	}
+
+	if nameIDFormat := getNameIDFormat(metadata.IDPSSODescriptor.NameIDFormats); nameIDFormat != "" {
+		sp.NameIdFormat = nameIDFormat
+	}
+
+	if keyPair != nil {
+		sp.SPKeyStore = dsig.TLSCertKeyStore(*keyPair)
+		sp.SignAuthnRequests = true
+	}
+
+	return sp, nil
+}
+
+// NewSAMLLogoutResponseURL creates the URL sending a LogoutResponse for the given LogoutRequest ID to the IdP
+// with the HTTP-Redirect binding. The URL is signed if the service provider has a key.
+func NewSAMLLogoutResponseURL(sp *saml2.SAMLServiceProvider, requestID, relayState string) (string, error) {
+	if sp.IdentityProviderSLOURL == "" {
+		return "", fmt.Errorf("missing SingleLogoutService with HTTP-Redirect binding in IdP metadata")
+	}
+
+	doc, err := sp.BuildLogoutResponseDocumentNoSig(saml2.StatusCodeSuccess, requestID)
+	if err != nil {
+		return "", err
+	}
+
+	message, err := doc.WriteToBytes()
+	if err != nil {
+		return "", err
+	}
+
+	return samlRedirectURL(sp, sp.IdentityProviderSLOURL, "SAMLResponse", message, relayState)
+}
+
+// samlRedirectURL encodes the message according to the SAML HTTP-Redirect binding,
+// see section 3.4.4 of saml-bindings-2.0-os.
+func samlRedirectURL(sp *saml2.SAMLServiceProvider, location, param string, message []byte, relayState string) (string, error) {
+	redirectURL, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	fw, err := flate.NewWriter(buf, flate.DefaultCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err = fw.Write(message); err != nil {
+		return "", err
+	}
+	if err = fw.Close(); err != nil {
+		return "", err
+	}
+
+	// the signed parameters have to be in this order
+	signed := url.QueryEscape(param) + "=" + url.QueryEscape(base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if relayState != "" {
+		signed += "&RelayState=" + url.QueryEscape(relayState)
+	}
+
+	if sp.SPKeyStore != nil {
+		ctx := sp.SigningContext()
+		signed += "&SigAlg=" + url.QueryEscape(ctx.GetSignatureMethodIdentifier())
+		signature, serr := ctx.SignString(signed)
+		if serr != nil {
+			return "", serr
+		}
+		signed += "&Signature=" + url.QueryEscape(base64.StdEncoding.EncodeToString(signature))
+	}
+
+	if redirectURL.RawQuery != "" {
+		redirectURL.RawQuery += "&" + signed
+	} else {
+		redirectURL.RawQuery = signed
+	}
+	return redirectURL.String(), nil
+}
+
+// NewSAMLSPMetadata returns the metadata XML document of the service provider.
+func NewSAMLSPMetadata(sp *saml2.SAMLServiceProvider, keyPair *tls.Certificate) ([]byte, error) {
+	descriptor := &types.SPSSODescriptor{
+		AuthnRequestsSigned:        sp.SignAuthnRequests,
+		WantAssertionsSigned:       true,
+		ProtocolSupportEnumeration: saml2.SAMLProtocolNamespace,
+		AssertionConsumerServices: []types.IndexedEndpoint{{
+			Binding:  saml2.BindingHttpPost,
+			Location: sp.AssertionConsumerServiceURL,
+			Index:    1,
+		}},
+	}
+	if sp.NameIdFormat != "" {
+		descriptor.NameIDFormats = []string{sp.NameIdFormat}
+	}
+	if sp.ServiceProviderSLOURL != "" {
+		descriptor.SingleLogoutServices = []types.Endpoint{{
+			Binding:  saml2.BindingHttpPost,
+			Location: sp.ServiceProviderSLOURL,
+		}}
+	}
+
+	if keyPair != nil && len(keyPair.Certificate) > 0 {
+		keyInfo := dsigtypes.KeyInfo{
+			X509Data: dsigtypes.X509Data{
+				X509Certificates: []dsigtypes.X509Certificate{{
+					Data: base64.StdEncoding.EncodeToString(keyPair.Certificate[0]),
+				}},
+			},
+		}
+		descriptor.KeyDescriptors = []types.KeyDescriptor{
+			{Use: "signing", KeyInfo: keyInfo},
+			{Use: "encryption", KeyInfo: keyInfo, EncryptionMethods: []types.EncryptionMethod{
+				{Algorithm: types.MethodAES128GCM},
+				{Algorithm: types.MethodAES256GCM},
+				{Algorithm: types.MethodAES128CBC},
+				{Algorithm: types.MethodAES256CBC},
+			}},
+		}
+	}
+
+	metadata, err := xml.MarshalIndent(&types.EntityDescriptor{
+		ValidUntil:      time.Now().UTC().Add(time.Hour * 24 * 7),
+		EntityID:        sp.ServiceProviderIssuer,
+		SPSSODescriptor: descriptor,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), metadata...), nil
+}
+
 var NoOpSamlSsoURLFunction = function.New(&function.Spec{
 	Params: []function.Parameter{
 		{
@@ -37,18 +279,61 @@ var NoOpSamlSsoURLFunction = function.New(&function.Spec{
 	},
 	Type: function.StaticReturnType(cty.String),
 	Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
-		if len(args) > 0 {
-			return cty.StringVal(""), fmt.Errorf("missing saml block with referenced label %q", args[0].AsString())
-		}
-		return cty.StringVal(""), fmt.Errorf("missing saml definitions")
+		return noOpSamlImpl(args)
 	},
 })
 
-func NewSamlSsoURLFunction(configs []SAMLConfigWithProvider, origin *url.URL) function.Function {
+var NoOpSamlSloURLFunction = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "saml_label",
+			Type: cty.String,
+		},
+		{
+			Name: "name_id",
+			Type: cty.String,
+		},
+		{
+			Name: "session_index",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+		return noOpSamlImpl(args)
+	},
+})
+
+var NoOpSamlSpMetadataFunction = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "saml_label",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+		return noOpSamlImpl(args)
+	},
+})
+
+func noOpSamlImpl(args []cty.Value) (cty.Value, error) {
+	if len(args) > 0 {
+		return cty.StringVal(""), fmt.Errorf("missing saml block with referenced label %q", args[0].AsString())
+	}
+	return cty.StringVal(""), fmt.Errorf("missing saml definitions")
+}
+
+func samlEntitiesByLabel(configs []SAMLConfigWithProvider) map[string]SAMLConfigWithProvider {
 	samlEntities := make(map[string]SAMLConfigWithProvider)
 	for _, conf := range configs {
 		samlEntities[conf.Config.Name] = conf
 	}
+	return samlEntities
+}
+
+func NewSamlSsoURLFunction(configs []SAMLConfigWithProvider, origin *url.URL) function.Function {
+	samlEntities := samlEntitiesByLabel(configs)
 
 	return function.New(&function.Spec{
 		Params: []function.Parameter{
@@ -65,42 +350,120 @@ func NewSamlSsoURLFunction(configs []SAMLConfigWithProvider, origin *url.URL) fu
 				return NoOpSamlSsoURLFunction.Call(args)
 			}
 
-			metadata, err := ent.Provider.Metadata()
+			sp, err := ent.ServiceProvider(origin)
 			if err != nil {
-				return cty.StringVal(""), fmt.Errorf("failed to get SAML metadata for %q: %w", label, err)
+				return cty.StringVal(""), err
 			}
 
-			var ssoURL string
-			for _, ssoService := range metadata.IDPSSODescriptor.SingleSignOnServices {
-				if ssoService.Binding == "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" {
-					ssoURL = ssoService.Location
-					continue
+			var samlSsoURL string
+			if sp.SignAuthnRequests {
+				doc, derr := sp.BuildAuthRequestDocumentNoSig()
+				if derr != nil {
+					return cty.StringVal(""), derr
 				}
+				samlSsoURL, err = sp.BuildAuthURLRedirect("", doc)
+			} else {
+				samlSsoURL, err = sp.BuildAuthURL("")
+			}
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			return cty.StringVal(samlSsoURL), nil
+		},
+	})
+}
+
+// NewSamlSloURLFunction creates a function returning the URL of a signed LogoutRequest
+// (HTTP-Redirect binding) for the SP-initiated Single Logout.
+func NewSamlSloURLFunction(configs []SAMLConfigWithProvider, origin *url.URL) function.Function {
+	samlEntities := samlEntitiesByLabel(configs)
+
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "saml_label",
+				Type: cty.String,
+			},
+			{
+				Name: "name_id",
+				Type: cty.String,
+			},
+			{
+				Name: "session_index",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			label := args[0].AsString()
+			ent, exist := samlEntities[label]
+			if !exist {
+				return NoOpSamlSloURLFunction.Call(args)
 			}
 
-			nameIDFormat := getNameIDFormat(metadata.IDPSSODescriptor.NameIDFormats)
+			if ent.KeyPair == nil {
+				return cty.StringVal(""), fmt.Errorf("saml block %q: sp_private_key required for logout requests", label)
+			}
 
-			absAcsURL, err := AbsoluteURL(ent.Config.SpAcsURL, origin)
+			sp, err := ent.ServiceProvider(origin)
 			if err != nil {
 				return cty.StringVal(""), err
 			}
+			if sp.IdentityProviderSLOURL == "" {
+				return cty.StringVal(""), fmt.Errorf("missing SingleLogoutService with HTTP-Redirect binding in IdP metadata for %q", label)
+			}
 
-			sp := &saml2.SAMLServiceProvider{
-				AssertionConsumerServiceURL: absAcsURL,
-				IdentityProviderSSOURL:      ssoURL,
-				ServiceProviderIssuer:       ent.Config.SpEntityID,
-				SignAuthnRequests:           false,
+			doc, err := sp.BuildLogoutRequestDocumentNoSig(args[1].AsString(), args[2].AsString())
+			if err != nil {
+				return cty.StringVal(""), err
 			}
-			if nameIDFormat != "" {
-				sp.NameIdFormat = nameIDFormat
+
+			message, err := doc.WriteToBytes()
+			if err != nil {
+				return cty.StringVal(""), err
 			}
 
-			samlSsoURL, err := sp.BuildAuthURL("")
+			samlSloURL, err := samlRedirectURL(sp, sp.IdentityProviderSLOURL, "SAMLRequest", message, "")
 			if err != nil {
 				return cty.StringVal(""), err
 			}
 
-			return cty.StringVal(samlSsoURL), nil
+			return cty.StringVal(samlSloURL), nil
+		},
+	})
+}
+
+// NewSamlSpMetadataFunction creates a function returning the SP metadata XML document.
+func NewSamlSpMetadataFunction(configs []SAMLConfigWithProvider, origin *url.URL) function.Function {
+	samlEntities := samlEntitiesByLabel(configs)
+
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "saml_label",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			label := args[0].AsString()
+			ent, exist := samlEntities[label]
+			if !exist {
+				return NoOpSamlSpMetadataFunction.Call(args)
+			}
+
+			sp, err := ent.ServiceProvider(origin)
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			metadata, err := NewSAMLSPMetadata(sp, ent.KeyPair)
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			return cty.StringVal(string(metadata)), nil
 		},
 	})
 }
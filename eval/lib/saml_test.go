@@ -4,14 +4,21 @@ import (
 	"bytes"
 	"compress/flate"
 	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/pem"
 	"encoding/xml"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/russellhaering/gosaml2/types"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/coupergateway/couper/accesscontrol/saml"
@@ -32,8 +39,11 @@ func createSAMLProviders(t *testing.T, samlConfigs []*config.SAML) map[string]li
 	for _, samlConf := range samlConfigs {
 		provider, err := saml.NewStaticMetadata(samlConf.MetadataBytes)
 		h.Must(err)
+		keyPair, err := lib.NewSAMLKeyPair(samlConf)
+		h.Must(err)
 		providers[samlConf.Name] = lib.SAMLConfigWithProvider{
 			Config:   samlConf,
+			KeyPair:  keyPair,
 			Provider: provider,
 		}
 	}
@@ -216,3 +226,197 @@ func TestSamlSsoURLError(t *testing.T) {
 		})
 	}
 }
+
+// verifyRedirectSignature verifies the signature of a SAML HTTP-Redirect binding URL with the given certificate.
+func verifyRedirectSignature(t *testing.T, rawURL, param, certFile string) {
+	t.Helper()
+	h := test.New(t)
+
+	u, err := url.Parse(rawURL)
+	h.Must(err)
+
+	var signed []string
+	for _, part := range strings.Split(u.RawQuery, "&") {
+		if strings.HasPrefix(part, param+"=") || strings.HasPrefix(part, "RelayState=") || strings.HasPrefix(part, "SigAlg=") {
+			signed = append(signed, part)
+		}
+	}
+
+	q := u.Query()
+	if q.Get("SigAlg") != "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256" {
+		t.Fatalf("unexpected SigAlg: %q", q.Get("SigAlg"))
+	}
+	signature, err := base64.StdEncoding.DecodeString(q.Get("Signature"))
+	h.Must(err)
+
+	certBytes, err := os.ReadFile(certFile)
+	h.Must(err)
+	block, _ := pem.Decode(certBytes)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	h.Must(err)
+
+	digest := sha256.Sum256([]byte(strings.Join(signed, "&")))
+	if err = rsa.VerifyPKCS1v15(cert.PublicKey.(*rsa.PublicKey), crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("invalid signature: %v", err)
+	}
+}
+
+func inflateSAMLMessage(t *testing.T, encoded string) []byte {
+	t.Helper()
+	h := test.New(t)
+
+	b64Decoded, err := base64.StdEncoding.DecodeString(encoded)
+	h.Must(err)
+
+	inflated, err := io.ReadAll(flate.NewReader(bytes.NewReader(b64Decoded)))
+	h.Must(err)
+	return inflated
+}
+
+const samlSPKeyConfig = `
+			server "test" {
+			}
+			definitions {
+				saml "MySAML" {
+					idp_metadata_file = "testdata/idp-metadata-slo.xml"
+					sp_entity_id = "the-sp"
+					sp_acs_url = "/saml/acs"
+					sp_slo_url = "/saml/slo"
+					sp_certificate_file = "testdata/sp.crt"
+					sp_private_key_file = "testdata/sp.key"
+				}
+			}
+			`
+
+func Test_SamlSsoURL_Signed(t *testing.T) {
+	h := test.New(t)
+	cf, err := configload.LoadBytes([]byte(samlSPKeyConfig), "couper.hcl")
+	h.Must(err)
+
+	evalContext := setupSAMLTestContext(t, cf)
+
+	ssoURL, err := evalContext.HCLContext().Functions[lib.FnSamlSsoURL].Call([]cty.Value{cty.StringVal("MySAML")})
+	h.Must(err)
+
+	verifyRedirectSignature(t, ssoURL.AsString(), "SAMLRequest", "testdata/sp.crt")
+
+	u, err := url.Parse(ssoURL.AsString())
+	h.Must(err)
+	authnRequest := string(inflateSAMLMessage(t, u.Query().Get("SAMLRequest")))
+	if !strings.Contains(authnRequest, `AssertionConsumerServiceURL="https://www.example.com/saml/acs"`) {
+		t.Errorf("expected absolute ACS URL in AuthnRequest, got: %s", authnRequest)
+	}
+}
+
+func Test_SamlSloURL(t *testing.T) {
+	h := test.New(t)
+	cf, err := configload.LoadBytes([]byte(samlSPKeyConfig), "couper.hcl")
+	h.Must(err)
+
+	evalContext := setupSAMLTestContext(t, cf)
+
+	sloURL, err := evalContext.HCLContext().Functions[lib.FnSamlSloURL].Call([]cty.Value{
+		cty.StringVal("MySAML"), cty.StringVal("abc12345"), cty.StringVal("_session-1"),
+	})
+	h.Must(err)
+
+	if !strings.HasPrefix(sloURL.AsString(), "https://idp.example.org/saml/SLOService?") {
+		t.Errorf("expected IdP SLO URL, got: %q", sloURL.AsString())
+	}
+	verifyRedirectSignature(t, sloURL.AsString(), "SAMLRequest", "testdata/sp.crt")
+
+	u, err := url.Parse(sloURL.AsString())
+	h.Must(err)
+	logoutRequest := string(inflateSAMLMessage(t, u.Query().Get("SAMLRequest")))
+	for _, want := range []string{"<samlp:LogoutRequest", ">abc12345</saml:NameID>", ">_session-1</samlp:SessionIndex>", ">the-sp</saml:Issuer>"} {
+		if !strings.Contains(logoutRequest, want) {
+			t.Errorf("expected %q in LogoutRequest, got: %s", want, logoutRequest)
+		}
+	}
+}
+
+func Test_SamlSloURL_Errors(t *testing.T) {
+	h := test.New(t)
+	cf, err := configload.LoadBytes([]byte(`
+			server "test" {
+			}
+			definitions {
+				saml "NoKey" {
+					idp_metadata_file = "testdata/idp-metadata-slo.xml"
+					sp_entity_id = "the-sp"
+					sp_acs_url = "/saml/acs"
+				}
+				saml "NoSLO" {
+					idp_metadata_file = "testdata/idp-metadata.xml"
+					sp_entity_id = "the-sp"
+					sp_acs_url = "/saml/acs"
+					sp_certificate_file = "testdata/sp.crt"
+					sp_private_key_file = "testdata/sp.key"
+				}
+			}
+			`), "couper.hcl")
+	h.Must(err)
+
+	evalContext := setupSAMLTestContext(t, cf)
+
+	for label, wantErr := range map[string]string{
+		"NoKey":   `saml block "NoKey": sp_private_key required for logout requests`,
+		"NoSLO":   `missing SingleLogoutService with HTTP-Redirect binding in IdP metadata for "NoSLO"`,
+		"MyLabel": `missing saml block with referenced label "MyLabel"`,
+	} {
+		_, err = evalContext.HCLContext().Functions[lib.FnSamlSloURL].Call([]cty.Value{
+			cty.StringVal(label), cty.StringVal("abc12345"), cty.StringVal(""),
+		})
+		if err == nil || err.Error() != wantErr {
+			t.Errorf("%s: want error %q, got: %v", label, wantErr, err)
+		}
+	}
+}
+
+func Test_SamlSpMetadata(t *testing.T) {
+	h := test.New(t)
+	cf, err := configload.LoadBytes([]byte(samlSPKeyConfig), "couper.hcl")
+	h.Must(err)
+
+	evalContext := setupSAMLTestContext(t, cf)
+
+	metadata, err := evalContext.HCLContext().Functions[lib.FnSamlSpMetadata].Call([]cty.Value{cty.StringVal("MySAML")})
+	h.Must(err)
+
+	descriptor := &types.EntityDescriptor{}
+	h.Must(xml.Unmarshal([]byte(metadata.AsString()), descriptor))
+
+	if descriptor.EntityID != "the-sp" {
+		t.Errorf("expected entityID %q, got: %q", "the-sp", descriptor.EntityID)
+	}
+
+	sp := descriptor.SPSSODescriptor
+	if sp == nil {
+		t.Fatal("expected SPSSODescriptor")
+	}
+	if !sp.AuthnRequestsSigned {
+		t.Error("expected AuthnRequestsSigned")
+	}
+	if len(sp.AssertionConsumerServices) != 1 || sp.AssertionConsumerServices[0].Location != "https://www.example.com/saml/acs" {
+		t.Errorf("unexpected AssertionConsumerService: %#v", sp.AssertionConsumerServices)
+	}
+	if len(sp.SingleLogoutServices) != 1 || sp.SingleLogoutServices[0].Location != "https://www.example.com/saml/slo" {
+		t.Errorf("unexpected SingleLogoutService: %#v", sp.SingleLogoutServices)
+	}
+
+	certBytes, err := os.ReadFile("testdata/sp.crt")
+	h.Must(err)
+	block, _ := pem.Decode(certBytes)
+	wantCert := base64.StdEncoding.EncodeToString(block.Bytes)
+
+	uses := make(map[string]bool)
+	for _, kd := range sp.KeyDescriptors {
+		if len(kd.KeyInfo.X509Data.X509Certificates) != 1 || kd.KeyInfo.X509Data.X509Certificates[0].Data != wantCert {
+			t.Errorf("unexpected %s certificate", kd.Use)
+		}
+		uses[kd.Use] = true
+	}
+	if !uses["signing"] || !uses["encryption"] {
+		t.Errorf("expected signing and encryption key descriptors, got: %v", uses)
+	}
+}
@@ -0,0 +1,122 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/coupergateway/couper/accesscontrol/session"
+	"github.com/coupergateway/couper/internal/seetie"
+)
+
+const (
+	FnSessionDestroy = "session_destroy"
+	FnSessionWrite   = "session_write"
+)
+
+var NoOpSessionWriteFunction = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "session_label",
+			Type: cty.String,
+		},
+		{
+			Name: "data",
+			Type: cty.DynamicPseudoType,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+		return noOpSessionImpl(args)
+	},
+})
+
+var NoOpSessionDestroyFunction = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "session_label",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+		return noOpSessionImpl(args)
+	},
+})
+
+func noOpSessionImpl(args []cty.Value) (cty.Value, error) {
+	if len(args) > 0 {
+		return cty.StringVal(""), fmt.Errorf("missing session block with referenced label %q", args[0].AsString())
+	}
+	return cty.StringVal(""), fmt.Errorf("missing session definitions")
+}
+
+// NewSessionWriteFunction creates a function storing the given data in a new session. Its return value is
+// the Set-Cookie header field value with the new session ID. A session found in the client request is destroyed.
+// The session is created once per client request, further calls return the same Set-Cookie header field value.
+// Tokens obtained by access controls in the given context, e.g. by an oidc block, are stored along with the data.
+func NewSessionWriteFunction(ctx context.Context, managers map[string]*session.Manager, cookies []*http.Cookie) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "session_label",
+				Type: cty.String,
+			},
+			{
+				Name: "data",
+				Type: cty.DynamicPseudoType,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			label := args[0].AsString()
+			manager, exist := managers[label]
+			if !exist {
+				return NoOpSessionWriteFunction.Call(args)
+			}
+
+			data := args[1]
+			if !data.IsNull() && !data.Type().IsObjectType() && !data.Type().IsMapType() {
+				return cty.StringVal(""), fmt.Errorf("session data must be an object")
+			}
+
+			cookie, err := manager.CreateOnce(ctx, seetie.ValueToMap(data), session.TokensFromContext(ctx, label), manager.ID(cookies))
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			return cty.StringVal(cookie.String()), nil
+		},
+	})
+}
+
+// NewSessionDestroyFunction creates a function destroying the session found in the client request. Its return
+// value is the Set-Cookie header field value removing the session cookie.
+func NewSessionDestroyFunction(managers map[string]*session.Manager, cookies []*http.Cookie) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "session_label",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			label := args[0].AsString()
+			manager, exist := managers[label]
+			if !exist {
+				return NoOpSessionDestroyFunction.Call(args)
+			}
+
+			cookie, err := manager.Destroy(manager.ID(cookies))
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			return cty.StringVal(cookie.String()), nil
+		},
+	})
+}
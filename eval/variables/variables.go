@@ -23,11 +23,13 @@ const (
 	PathParam        = "path_params"
 	Query            = "query"
 	RemoteIp         = "remote_ip"
+	Tenant           = "tenant"
 	TokenResponse    = "beta_token_response"
 	URL              = "url"
 	Origin           = "origin"
 	Protocol         = "protocol"
 	Host             = "host"
+	HostParam        = "host_params"
 	Port             = "port"
 	Couper           = "couper"
 )
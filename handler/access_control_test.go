@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -25,7 +26,7 @@ func TestAccessControl_ServeHTTP(t *testing.T) {
 	}
 
 	newBasicAuth := func(user, pass string) *ac.BasicAuth {
-		ba, err := ac.NewBasicAuth("ba-test", user, pass, "")
+		ba, err := ac.NewBasicAuth(context.Background(), "ba-test", user, pass, "", nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/accesscontrol/session"
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/oauth2"
+)
+
+const maxLogoutRequestBytes = 64 << 10
+
+var _ http.Handler = &BackchannelLogout{}
+
+// LogoutTokenValidator is implemented by clients supporting the OpenID Connect back-channel logout.
+type LogoutTokenValidator interface {
+	ValidateLogoutToken(logoutToken string) (*oauth2.LogoutToken, error)
+}
+
+// BackchannelLogout receives the logout tokens sent by an OpenID provider. Matching sessions and
+// cached introspection responses for the subject of the provider are invalidated.
+// See https://openid.net/specs/openid-connect-backchannel-1_0.html
+type BackchannelLogout struct {
+	manager       *session.Manager
+	memStore      *cache.MemoryStore
+	mu            sync.Mutex
+	name          string
+	storagePrefix string
+	validator     LogoutTokenValidator
+}
+
+// NewBackchannelLogout creates the back-channel logout handler for the named oidc block.
+// The session manager may be nil.
+func NewBackchannelLogout(name string, validator LogoutTokenValidator, manager *session.Manager,
+	memStore *cache.MemoryStore) *BackchannelLogout {
+	return &BackchannelLogout{
+		manager:       manager,
+		memStore:      memStore,
+		name:          name,
+		storagePrefix: "backchannel_logout_" + name + "_",
+		validator:     validator,
+	}
+}
+
+func (b *BackchannelLogout) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		writeTokenError(rw, newTokenError(http.StatusMethodNotAllowed, "invalid_request", "method not allowed"))
+		return
+	}
+
+	req.Body = http.MaxBytesReader(rw, req.Body, maxLogoutRequestBytes)
+	if err := req.ParseForm(); err != nil {
+		writeTokenError(rw, newTokenError(http.StatusBadRequest, "invalid_request", "%v", err))
+		return
+	}
+
+	logoutToken := req.PostForm.Get("logout_token")
+	if logoutToken == "" {
+		writeTokenError(rw, newTokenError(http.StatusBadRequest, "invalid_request", "missing logout_token"))
+		return
+	}
+
+	token, err := b.validator.ValidateLogoutToken(logoutToken)
+	if err != nil {
+		writeTokenError(rw, newTokenError(http.StatusBadRequest, "invalid_request", "invalid logout_token: %v", err))
+		return
+	}
+
+	if !b.markUsed(token) {
+		writeTokenError(rw, newTokenError(http.StatusBadRequest, "invalid_request", "logout_token already used"))
+		return
+	}
+
+	if b.manager != nil {
+		if err = b.manager.DestroyByToken(b.name, token.Subject, token.SessionID); err != nil {
+			writeTokenError(rw, newTokenError(http.StatusInternalServerError, "server_error", "%v", err))
+			return
+		}
+	}
+	ac.InvalidateIntrospections(b.memStore, token.Issuer, token.Subject)
+
+	setNoStoreHeaders(rw)
+	rw.WriteHeader(http.StatusOK)
+}
+
+// markUsed records the jti of the given logout token until it expires and reports
+// whether it has not been seen before.
+func (b *BackchannelLogout) markUsed(token *oauth2.LogoutToken) bool {
+	key := b.storagePrefix + token.ID
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.memStore.Get(key) != nil {
+		return false
+	}
+
+	// without an exp claim the jti is kept for the maximum memory store ttl
+	ttl := int64(86400)
+	if !token.Expiry.IsZero() {
+		ttl = int64(time.Until(token.Expiry).Seconds()) + 1
+	}
+	b.memStore.Set(key, true, ttl)
+
+	return true
+}
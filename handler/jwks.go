@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/coupergateway/couper/accesscontrol/jwk"
+	"github.com/coupergateway/couper/eval/lib"
+)
+
+var _ http.Handler = &JWKS{}
+
+// JWKS publishes the public parts of asymmetric signing keys as JWK Set.
+// The document is created per request since the published keys depend on
+// their validity period and on reloaded key files.
+type JWKS struct {
+	names          []string
+	signingConfigs map[string]*lib.JWTSigningConfig
+}
+
+// NewJWKS creates a handler publishing the public parts of all asymmetric
+// signing keys. Symmetric (HS*) keys are skipped.
+func NewJWKS(signingConfigs map[string]*lib.JWTSigningConfig) (*JWKS, error) {
+	names := make([]string, 0, len(signingConfigs))
+	for name := range signingConfigs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	j := &JWKS{names: names, signingConfigs: signingConfigs}
+	if _, err := j.document(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *JWKS) document() ([]byte, error) {
+	keys := make([]*jwk.JWK, 0, len(j.names))
+	published := make(map[string][]byte)
+
+	for _, name := range j.names {
+		for _, publicKey := range j.signingConfigs[name].PublicJWKs() {
+			raw, err := json.Marshal(publicKey)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", name, err)
+			}
+
+			// The same key may be referenced by several profiles.
+			if other, exists := published[publicKey.KeyID]; exists {
+				if !bytes.Equal(other, raw) {
+					return nil, fmt.Errorf("%q: key ID %q is already used for a different key", name, publicKey.KeyID)
+				}
+				continue
+			}
+
+			published[publicKey.KeyID] = raw
+			keys = append(keys, publicKey)
+		}
+	}
+
+	return json.Marshal(jwk.JWKSData{Keys: keys})
+}
+
+func (j *JWKS) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	document, err := j.document()
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	jsonDocument(document).ServeHTTP(rw, req)
+}
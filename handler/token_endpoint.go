@@ -0,0 +1,557 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/reader"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/eval/lib"
+	"github.com/coupergateway/couper/internal/seetie"
+)
+
+const (
+	// AuthorizationServerMetadataPath is the well-known path of the OAuth2 authorization server metadata (RFC 8414).
+	AuthorizationServerMetadataPath = "/.well-known/oauth-authorization-server"
+	// DefaultJWKSPath is the default path of the JWK Set published by a token endpoint.
+	DefaultJWKSPath = "/.well-known/jwks.json"
+
+	clientAssertionType    = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	maxTokenRequestBytes   = 64 << 10
+	refreshTokenBytes      = 32
+	grantClientCredentials = "client_credentials"
+	grantRefreshToken      = "refresh_token"
+)
+
+var _ http.Handler = &TokenEndpoint{}
+
+// TokenEndpoint is a minimal OAuth2 authorization server issuing signed JWT access tokens
+// for the client_credentials and refresh_token grants.
+type TokenEndpoint struct {
+	clients         map[string]*tokenEndpointClient
+	issuer          string
+	jwks            http.Handler
+	memStore        *cache.MemoryStore
+	metadata        []byte
+	mu              sync.Mutex
+	refreshTokenTTL time.Duration
+	signingConfig   *lib.JWTSigningConfig
+	storagePrefix   string
+	tokenURL        string
+}
+
+type tokenEndpointClient struct {
+	audience           string
+	key                interface{}
+	scope              []string
+	secretHash         []byte
+	signatureAlgorithm string
+}
+
+// refreshGrant is the server-side state of an issued refresh token.
+type refreshGrant struct {
+	clientID string
+	scope    []string
+}
+
+// tokenError represents an OAuth2 error response (RFC 6749, section 5.2).
+type tokenError struct {
+	code        string
+	description string
+	status      int
+}
+
+func newTokenError(status int, code, format string, args ...interface{}) *tokenError {
+	return &tokenError{code: code, description: fmt.Sprintf(format, args...), status: status}
+}
+
+// NewTokenEndpoint creates the token endpoint handler served at the given (server) path.
+func NewTokenEndpoint(conf *config.TokenEndpoint, path, jwksPath string, signingConfig *lib.JWTSigningConfig,
+	memStore *cache.MemoryStore) (*TokenEndpoint, error) {
+	if signingConfig.TTL <= 0 {
+		return nil, fmt.Errorf("jwt_signing_profile %q: ttl must be positive", conf.JWTSigningProfile)
+	}
+
+	issuerURL, err := url.Parse(conf.Issuer)
+	if err != nil || issuerURL.Scheme == "" || issuerURL.Host == "" {
+		return nil, fmt.Errorf("issuer must be an absolute URL: %q", conf.Issuer)
+	}
+
+	refreshTokenTTL, err := config.ParseDuration("refresh_token_ttl", conf.RefreshTokenTTL, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	jwks, err := NewJWKS(map[string]*lib.JWTSigningConfig{conf.JWTSigningProfile: signingConfig})
+	if err != nil {
+		return nil, err
+	}
+
+	te := &TokenEndpoint{
+		clients:         make(map[string]*tokenEndpointClient),
+		issuer:          conf.Issuer,
+		jwks:            jwks,
+		memStore:        memStore,
+		refreshTokenTTL: refreshTokenTTL,
+		signingConfig:   signingConfig,
+		storagePrefix:   "token_endpoint_" + conf.Issuer + "_",
+		tokenURL:        issuerURL.Scheme + "://" + issuerURL.Host + path,
+	}
+
+	if len(conf.Clients) == 0 {
+		return nil, fmt.Errorf("missing client block")
+	}
+
+	var (
+		authMethods   = make(map[string]struct{})
+		signingAlgs   = make(map[string]struct{})
+		scopeValues   = make(map[string]struct{})
+		grantTypes    = []string{grantClientCredentials}
+		sortedMembers = func(m map[string]struct{}) []string {
+			list := make([]string, 0, len(m))
+			for k := range m {
+				list = append(list, k)
+			}
+			sort.Strings(list)
+			return list
+		}
+	)
+
+	for _, clientConf := range conf.Clients {
+		if _, exists := te.clients[clientConf.Name]; exists {
+			return nil, fmt.Errorf("client %q: duplicate client", clientConf.Name)
+		}
+
+		client, cerr := newTokenEndpointClient(clientConf)
+		if cerr != nil {
+			return nil, fmt.Errorf("client %q: %w", clientConf.Name, cerr)
+		}
+		te.clients[clientConf.Name] = client
+
+		if client.key != nil {
+			authMethods["private_key_jwt"] = struct{}{}
+			signingAlgs[client.signatureAlgorithm] = struct{}{}
+		} else {
+			authMethods["client_secret_basic"] = struct{}{}
+			authMethods["client_secret_post"] = struct{}{}
+		}
+		for _, s := range client.scope {
+			scopeValues[s] = struct{}{}
+		}
+	}
+
+	if te.refreshTokenTTL > 0 {
+		grantTypes = append(grantTypes, grantRefreshToken)
+	}
+
+	metadata := map[string]interface{}{
+		"issuer":                                conf.Issuer,
+		"token_endpoint":                        te.tokenURL,
+		"jwks_uri":                              issuerURL.Scheme + "://" + issuerURL.Host + jwksPath,
+		"grant_types_supported":                 grantTypes,
+		"token_endpoint_auth_methods_supported": sortedMembers(authMethods),
+	}
+	if len(signingAlgs) > 0 {
+		metadata["token_endpoint_auth_signing_alg_values_supported"] = sortedMembers(signingAlgs)
+	}
+	if len(scopeValues) > 0 {
+		metadata["scopes_supported"] = sortedMembers(scopeValues)
+	}
+
+	if te.metadata, err = json.Marshal(metadata); err != nil {
+		return nil, err
+	}
+
+	return te, nil
+}
+
+func newTokenEndpointClient(conf *config.TokenEndpointClient) (*tokenEndpointClient, error) {
+	client := &tokenEndpointClient{
+		audience: conf.Audience,
+		scope:    conf.Scope,
+	}
+
+	hasKey := conf.Key != "" || conf.KeyFile != ""
+	switch {
+	case hasKey && conf.ClientSecretHash != "":
+		return nil, fmt.Errorf("client_secret_hash and key/key_file are mutually exclusive")
+	case conf.ClientSecretHash != "":
+		if _, err := bcrypt.Cost([]byte(conf.ClientSecretHash)); err != nil {
+			return nil, fmt.Errorf("client_secret_hash: %w", err)
+		}
+		client.secretHash = []byte(conf.ClientSecretHash)
+	case hasKey:
+		alg := acjwt.NewAlgorithm(conf.SignatureAlgorithm)
+		if alg == acjwt.AlgorithmUnknown || alg.IsHMAC() {
+			return nil, fmt.Errorf("signature_algorithm: unsupported value %q", conf.SignatureAlgorithm)
+		}
+		client.signatureAlgorithm = conf.SignatureAlgorithm
+
+		keyBytes, err := reader.ReadFromAttrFile("client key", conf.Key, conf.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if client.key, err = ac.ParsePublicPEMKey(keyBytes); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("missing client_secret_hash or key/key_file")
+	}
+
+	return client, nil
+}
+
+// JWKSHandler returns the handler publishing the public signing keys.
+func (t *TokenEndpoint) JWKSHandler() http.Handler {
+	return t.jwks
+}
+
+// MetadataHandler returns the handler publishing the authorization server metadata.
+func (t *TokenEndpoint) MetadataHandler() http.Handler {
+	return jsonDocument(t.metadata)
+}
+
+func (t *TokenEndpoint) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		writeTokenError(rw, newTokenError(http.StatusMethodNotAllowed, "invalid_request", "method not allowed"))
+		return
+	}
+
+	req.Body = http.MaxBytesReader(rw, req.Body, maxTokenRequestBytes)
+	if err := req.ParseForm(); err != nil {
+		writeTokenError(rw, newTokenError(http.StatusBadRequest, "invalid_request", "%v", err))
+		return
+	}
+
+	clientID, client, terr := t.authenticateClient(req)
+	if terr != nil {
+		writeTokenError(rw, terr)
+		return
+	}
+
+	var scope []string
+	switch grantType := req.PostForm.Get("grant_type"); grantType {
+	case grantClientCredentials:
+		scope, terr = grantedScope(req.PostForm.Get("scope"), client.scope)
+	case grantRefreshToken:
+		if t.refreshTokenTTL <= 0 {
+			terr = newTokenError(http.StatusBadRequest, "unsupported_grant_type", "grant_type %q not supported", grantType)
+			break
+		}
+		scope, terr = t.redeemRefreshToken(req.PostForm.Get("refresh_token"), req.PostForm.Get("scope"), clientID)
+	case "":
+		terr = newTokenError(http.StatusBadRequest, "invalid_request", "missing grant_type")
+	default:
+		terr = newTokenError(http.StatusBadRequest, "unsupported_grant_type", "grant_type %q not supported", grantType)
+	}
+	if terr != nil {
+		writeTokenError(rw, terr)
+		return
+	}
+
+	response, err := t.newTokenResponse(req, clientID, client, scope)
+	if err != nil {
+		writeTokenError(rw, newTokenError(http.StatusInternalServerError, "server_error", "%v", err))
+		return
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		writeTokenError(rw, newTokenError(http.StatusInternalServerError, "server_error", "%v", err))
+		return
+	}
+
+	setNoStoreHeaders(rw)
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(body)
+}
+
+// authenticateClient authenticates the client with client_secret_basic, client_secret_post or private_key_jwt.
+func (t *TokenEndpoint) authenticateClient(req *http.Request) (string, *tokenEndpointClient, *tokenError) {
+	invalidClient := newTokenError(http.StatusUnauthorized, "invalid_client", "client authentication failed")
+
+	if assertion := req.PostForm.Get("client_assertion"); assertion != "" {
+		if req.PostForm.Get("client_assertion_type") != clientAssertionType {
+			return "", nil, newTokenError(http.StatusBadRequest, "invalid_request", "unsupported client_assertion_type")
+		}
+		clientID, client, err := t.validateClientAssertion(assertion)
+		if err != nil {
+			return "", nil, invalidClient
+		}
+		if id := req.PostForm.Get("client_id"); id != "" && id != clientID {
+			return "", nil, invalidClient
+		}
+		return clientID, client, nil
+	}
+
+	clientID, secret, basic := req.BasicAuth()
+	if basic {
+		// client credentials are form-urlencoded before basic encoding (RFC 6749, section 2.3.1)
+		var err error
+		if clientID, err = url.QueryUnescape(clientID); err != nil {
+			return "", nil, invalidClient
+		}
+		if secret, err = url.QueryUnescape(secret); err != nil {
+			return "", nil, invalidClient
+		}
+	} else {
+		clientID, secret = req.PostForm.Get("client_id"), req.PostForm.Get("client_secret")
+		if clientID == "" {
+			return "", nil, invalidClient
+		}
+	}
+
+	client, exists := t.clients[clientID]
+	if !exists || client.secretHash == nil {
+		return "", nil, invalidClient
+	}
+	if bcrypt.CompareHashAndPassword(client.secretHash, []byte(secret)) != nil {
+		return "", nil, invalidClient
+	}
+
+	return clientID, client, nil
+}
+
+// validateClientAssertion validates a private_key_jwt client assertion (RFC 7523, section 3).
+func (t *TokenEndpoint) validateClientAssertion(assertion string) (string, *tokenEndpointClient, error) {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(assertion, claims); err != nil {
+		return "", nil, err
+	}
+
+	clientID, _ := claims["sub"].(string)
+	client, exists := t.clients[clientID]
+	if !exists || client.key == nil {
+		return "", nil, fmt.Errorf("unknown client")
+	}
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{client.signatureAlgorithm}), jwt.WithExpirationRequired())
+	claims = jwt.MapClaims{}
+	if _, err := parser.ParseWithClaims(assertion, claims, func(_ *jwt.Token) (interface{}, error) {
+		return client.key, nil
+	}); err != nil {
+		return "", nil, err
+	}
+
+	if iss, _ := claims["iss"].(string); iss != clientID {
+		return "", nil, fmt.Errorf("iss must equal sub")
+	}
+
+	audiences, err := claims.GetAudience()
+	if err != nil {
+		return "", nil, err
+	}
+	var audienceMatch bool
+	for _, aud := range audiences {
+		if aud == t.tokenURL || aud == t.issuer {
+			audienceMatch = true
+			break
+		}
+	}
+	if !audienceMatch {
+		return "", nil, fmt.Errorf("invalid aud")
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return "", nil, fmt.Errorf("missing jti")
+	}
+	exp, _ := claims.GetExpirationTime()
+
+	// a client assertion must be used only once
+	jtiKey := t.storagePrefix + "jti_" + clientID + "_" + jti
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.memStore.Get(jtiKey) != nil {
+		return "", nil, fmt.Errorf("client assertion already used")
+	}
+	t.memStore.Set(jtiKey, true, int64(time.Until(exp.Time).Seconds())+1)
+
+	return clientID, client, nil
+}
+
+// redeemRefreshToken invalidates the given refresh token and returns its (narrowed) scope.
+func (t *TokenEndpoint) redeemRefreshToken(refreshToken, requestedScope, clientID string) ([]string, *tokenError) {
+	if refreshToken == "" {
+		return nil, newTokenError(http.StatusBadRequest, "invalid_request", "missing refresh_token")
+	}
+
+	// get and delete at once, so a refresh token is redeemed only once by concurrent requests
+	key := t.refreshTokenKey(refreshToken)
+	t.mu.Lock()
+	grant, _ := t.memStore.Get(key).(*refreshGrant)
+	if grant == nil || grant.clientID != clientID {
+		t.mu.Unlock()
+		return nil, newTokenError(http.StatusBadRequest, "invalid_grant", "invalid refresh_token")
+	}
+	t.memStore.Del(key)
+	t.mu.Unlock()
+
+	return grantedScope(requestedScope, grant.scope)
+}
+
+func (t *TokenEndpoint) newTokenResponse(req *http.Request, clientID string, client *tokenEndpointClient, scope []string) (map[string]interface{}, error) {
+	var claims, headers map[string]interface{}
+
+	hclCtx := eval.ContextFromRequest(req).HCLContext()
+	if t.signingConfig.Claims != nil {
+		v, err := eval.Value(hclCtx, t.signingConfig.Claims)
+		if err != nil {
+			return nil, err
+		}
+		claims = seetie.ValueToMap(v)
+	}
+	if claims == nil {
+		claims = make(map[string]interface{})
+	}
+
+	if t.signingConfig.Headers != nil {
+		v, err := eval.Value(hclCtx, t.signingConfig.Headers)
+		if err != nil {
+			return nil, err
+		}
+		headers = seetie.ValueToMap(v)
+	}
+
+	jti, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	claims["iss"] = t.issuer
+	claims["sub"] = clientID
+	claims["client_id"] = clientID
+	claims["iat"] = now
+	claims["exp"] = now + t.signingConfig.TTL
+	claims["jti"] = jti
+	if client.audience != "" {
+		claims["aud"] = client.audience
+	}
+	if len(scope) > 0 {
+		claims["scope"] = strings.Join(scope, " ")
+	}
+
+	accessToken, err := t.signingConfig.CreateTokenWithKeyID(claims, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	response := map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   t.signingConfig.TTL,
+	}
+	if len(scope) > 0 {
+		response["scope"] = strings.Join(scope, " ")
+	}
+
+	if t.refreshTokenTTL > 0 {
+		refreshToken, rerr := randomString()
+		if rerr != nil {
+			return nil, rerr
+		}
+		t.memStore.Set(t.refreshTokenKey(refreshToken), &refreshGrant{clientID: clientID, scope: scope},
+			int64(t.refreshTokenTTL.Seconds()))
+		response["refresh_token"] = refreshToken
+	}
+
+	return response, nil
+}
+
+func (t *TokenEndpoint) refreshTokenKey(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return t.storagePrefix + "rt_" + hex.EncodeToString(sum[:])
+}
+
+func (t *TokenEndpoint) String() string {
+	return "token_endpoint"
+}
+
+// grantedScope returns the requested scope values if they are a subset of the allowed ones,
+// or all allowed values if no scope is requested.
+func grantedScope(requested string, allowed []string) ([]string, *tokenError) {
+	values := strings.Fields(requested)
+	if len(values) == 0 {
+		return allowed, nil
+	}
+
+	for _, v := range values {
+		var found bool
+		for _, a := range allowed {
+			if a == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, newTokenError(http.StatusBadRequest, "invalid_scope", "scope value %q not allowed", v)
+		}
+	}
+	return values, nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func setNoStoreHeaders(rw http.ResponseWriter) {
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Pragma", "no-cache")
+}
+
+func writeTokenError(rw http.ResponseWriter, terr *tokenError) {
+	body, _ := json.Marshal(map[string]string{
+		"error":             terr.code,
+		"error_description": terr.description,
+	})
+
+	if terr.status == http.StatusUnauthorized {
+		rw.Header().Set("WWW-Authenticate", `Basic realm="token_endpoint"`)
+	}
+	setNoStoreHeaders(rw)
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(terr.status)
+	_, _ = rw.Write(body)
+}
+
+// jsonDocument serves a static JSON document.
+type jsonDocument []byte
+
+func (d jsonDocument) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		rw.Header().Set("Allow", "GET, HEAD")
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Cache-Control", "max-age=300")
+	rw.WriteHeader(http.StatusOK)
+	if req.Method == http.MethodGet {
+		_, _ = rw.Write(d)
+	}
+}
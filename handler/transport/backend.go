@@ -201,6 +201,10 @@ func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
 		outreq.Header.Del("Upgrade")
 	}
 
+	if err = b.withSignature(outreq); err != nil {
+		return nil, err
+	}
+
 	var beresp *http.Response
 	if b.openAPIValidator != nil {
 		beresp, err = b.openAPIValidate(outreq, &tconf, deadlineErr)
@@ -362,6 +366,18 @@ func (b *Backend) withRetryTokenRequest(req *http.Request, res *http.Response) (
 	return retry, nil
 }
 
+// withSignature signs the final outgoing request.
+func (b *Backend) withSignature(req *http.Request) error {
+	for _, ra := range b.requestAuthorizer {
+		if signer, ok := ra.(RequestSigner); ok {
+			if err := signer.SignRequest(req); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (b *Backend) withPathPrefix(req *http.Request, evalCtx *hcl.EvalContext, hclContext *hclsyntax.Body) error {
 	if pathPrefix := b.getAttribute(evalCtx, "path_prefix", hclContext); pathPrefix != "" {
 		if i := strings.Index(pathPrefix, "#"); i >= 0 {
@@ -20,3 +20,9 @@ type RequestAuthorizer interface {
 
 	value() (string, string)
 }
+
+// RequestSigner is implemented by request authorizers which sign the outgoing
+// request after all modifications, e.g. of the header fields or the path prefix.
+type RequestSigner interface {
+	SignRequest(req *http.Request) error
+}
@@ -0,0 +1,55 @@
+package transport
+
+import (
+	"net/http"
+
+	"github.com/coupergateway/couper/accesscontrol/httpsig"
+	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/errors"
+)
+
+var (
+	_ RequestAuthorizer = &HTTPMessageSignature{}
+	_ RequestSigner     = &HTTPMessageSignature{}
+)
+
+// HTTPMessageSignature signs backend requests according to RFC 9421.
+type HTTPMessageSignature struct {
+	signer *httpsig.Signer
+}
+
+func NewHTTPMessageSignature(conf *config.HTTPMessageSignature, key interface{}) (RequestAuthorizer, error) {
+	ttl, err := config.ParseDuration("ttl", conf.TTL, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := httpsig.NewSigner(acjwt.NewAlgorithm(conf.SignatureAlgorithm), key,
+		conf.KeyID, conf.Label, conf.Components, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HTTPMessageSignature{signer: signer}, nil
+}
+
+func (h *HTTPMessageSignature) GetToken(_ *http.Request) error {
+	return nil
+}
+
+func (h *HTTPMessageSignature) RetryWithToken(_ *http.Request, _ *http.Response) (bool, error) {
+	return false, nil
+}
+
+// SignRequest implements the <RequestSigner> interface.
+func (h *HTTPMessageSignature) SignRequest(req *http.Request) error {
+	if err := h.signer.Sign(req); err != nil {
+		return errors.Backend.Message("http_message_signature").With(err)
+	}
+	return nil
+}
+
+func (h *HTTPMessageSignature) value() (string, string) {
+	return "", ""
+}
@@ -1,6 +1,8 @@
 package transport
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/coupergateway/couper/cache"
 	"github.com/coupergateway/couper/config"
@@ -24,6 +27,7 @@ var supportedGrantTypes = map[string]struct{}{
 	config.ClientCredentials: {},
 	config.JwtBearer:         {},
 	config.Password:          {},
+	config.TokenExchange:     {},
 }
 
 var (
@@ -50,19 +54,32 @@ func newAssertionCreatorFromExpr(expr hcl.Expression) assertionCreator {
 }
 
 func (ac *assertionCreatorFromExpr) createAssertion(ctx *hcl.EvalContext) (string, error) {
-	assertionValue, err := eval.Value(ctx, ac.expr)
+	return evalStringExpr(ctx, ac.expr, "assertion")
+}
+
+func evalStringExpr(ctx *hcl.EvalContext, expr hcl.Expression, name string) (string, error) {
+	value, err := eval.Value(ctx, expr)
 	if err != nil {
 		return "", err
 	}
 
-	if assertionValue.IsNull() {
-		return "", fmt.Errorf("assertion expression evaluates to null")
+	if value.IsNull() {
+		return "", fmt.Errorf("%s expression evaluates to null", name)
 	}
-	if assertionValue.Type() != cty.String {
-		return "", fmt.Errorf("assertion expression must evaluate to a string")
+	if value.Type() != cty.String {
+		return "", fmt.Errorf("%s expression must evaluate to a string", name)
 	}
 
-	return assertionValue.AsString(), nil
+	return value.AsString(), nil
+}
+
+// isSet reports whether the optional attribute of the given expression is configured.
+func isSet(expr hcl.Expression) bool {
+	if expr == nil {
+		return false
+	}
+	r := expr.Range()
+	return r.Start != r.End
 }
 
 type assertionCreatorFromJSP struct {
@@ -110,12 +127,13 @@ func (ac *assertionCreatorFromJSP) createAssertion(_ *hcl.EvalContext) (string,
 	now := time.Now().Unix()
 	claims["exp"] = now + ac.TTL
 
-	return lib.CreateJWT(ac.SignatureAlgorithm, ac.Key, claims, ac.headers)
+	return ac.CreateToken(claims, ac.headers)
 }
 
 // OAuth2ReqAuth represents the transport <OAuth2ReqAuth> object.
 type OAuth2ReqAuth struct {
 	config           *config.OAuth2ReqAuth
+	exchangeGroup    singleflight.Group
 	mu               sync.Mutex
 	memStore         *cache.MemoryStore
 	oauth2Client     *oauth2.Client
@@ -149,8 +167,7 @@ func NewOAuth2ReqAuth(evalCtx *hcl.EvalContext, conf *config.OAuth2ReqAuth, memS
 	}
 
 	var assertionCreator assertionCreator
-	assertionRange := conf.AssertionExpr.Range()
-	assertionSet := assertionRange.Start != assertionRange.End
+	assertionSet := isSet(conf.AssertionExpr)
 	if conf.GrantType == config.JwtBearer {
 		if !assertionSet && conf.JWTSigningProfile == nil {
 			return nil, fmt.Errorf("missing assertion attribute or jwt_signing_profile block with grant_type=%s", conf.GrantType)
@@ -170,6 +187,35 @@ func NewOAuth2ReqAuth(evalCtx *hcl.EvalContext, conf *config.OAuth2ReqAuth, memS
 		}
 	}
 
+	if conf.GrantType == config.TokenExchange {
+		if !isSet(conf.SubjectTokenExpr) {
+			return nil, fmt.Errorf("missing subject_token attribute with grant_type=%s", conf.GrantType)
+		}
+		if !isSet(conf.ActorTokenExpr) && conf.ActorTokenType != "" {
+			return nil, fmt.Errorf("actor_token_type attribute must not be set without actor_token attribute")
+		}
+		if conf.SubjectTokenType == "" {
+			conf.SubjectTokenType = config.AccessTokenType
+		}
+		if conf.ActorTokenType == "" && isSet(conf.ActorTokenExpr) {
+			conf.ActorTokenType = config.AccessTokenType
+		}
+	} else {
+		for name, set := range map[string]bool{
+			"actor_token":          isSet(conf.ActorTokenExpr),
+			"actor_token_type":     conf.ActorTokenType != "",
+			"audience":             conf.Audience != "",
+			"requested_token_type": conf.RequestedTokenType != "",
+			"resource":             conf.Resource != "",
+			"subject_token":        isSet(conf.SubjectTokenExpr),
+			"subject_token_type":   conf.SubjectTokenType != "",
+		} {
+			if set {
+				return nil, fmt.Errorf("%s attribute must not be set with grant_type=%s", name, conf.GrantType)
+			}
+		}
+	}
+
 	oauth2Client, err := oauth2.NewClient(evalCtx, conf.GrantType, conf, conf, asBackend, "")
 	if err != nil {
 		return nil, err
@@ -186,29 +232,54 @@ func NewOAuth2ReqAuth(evalCtx *hcl.EvalContext, conf *config.OAuth2ReqAuth, memS
 }
 
 func (oa *OAuth2ReqAuth) GetToken(req *http.Request) error {
-	token := oa.readAccessToken()
-	if token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
-		return nil
-	}
+	requestError := errors.Request.Label("oauth2")
 
-	oa.mu.Lock()
-	defer oa.mu.Unlock()
+	formParams, storageKey, err := oa.newTokenParams(req)
+	if err != nil {
+		return requestError.With(err)
+	}
 
-	token = oa.readAccessToken()
+	token := oa.readAccessToken(storageKey)
 	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
 		return nil
 	}
 
+	// exchanged tokens are requested per storage key, so that requests
+	// with different subject tokens do not wait for each other
+	if oa.config.GrantType == config.TokenExchange {
+		var v interface{}
+		v, err, _ = oa.exchangeGroup.Do(storageKey, func() (interface{}, error) {
+			return oa.requestToken(req, formParams, storageKey)
+		})
+		token, _ = v.(string)
+	} else {
+		oa.mu.Lock()
+		token, err = oa.requestToken(req, formParams, storageKey)
+		oa.mu.Unlock()
+	}
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// requestToken returns the stored token or requests a new one. Callers must prevent concurrent
+// token requests for the same storage key.
+func (oa *OAuth2ReqAuth) requestToken(req *http.Request, formParams url.Values, storageKey string) (string, error) {
 	requestError := errors.Request.Label("oauth2")
-	formParams := url.Values{}
+
+	if token := oa.readAccessToken(storageKey); token != "" {
+		return token, nil
+	}
 
 	if oa.config.GrantType == config.JwtBearer {
 		requestContext := eval.ContextFromRequest(req).HCLContext()
 		assertion, err := oa.assertionCreator.createAssertion(requestContext)
 		if err != nil {
-			return requestError.With(err)
+			return "", requestError.With(err)
 		}
 
 		formParams.Set("assertion", assertion)
@@ -223,13 +294,12 @@ func (oa *OAuth2ReqAuth) GetToken(req *http.Request) error {
 
 	tokenResponseData, token, err := oa.oauth2Client.GetTokenResponse(req.Context(), formParams)
 	if err != nil {
-		return requestError.Message("token request failed").With(err)
+		return "", requestError.Message("token request failed").With(err)
 	}
 
-	oa.updateAccessToken(token, tokenResponseData)
+	oa.updateAccessToken(storageKey, token, tokenResponseData)
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	return nil
+	return token, nil
 }
 
 func (oa *OAuth2ReqAuth) RetryWithToken(req *http.Request, res *http.Response) (bool, error) {
@@ -237,7 +307,9 @@ func (oa *OAuth2ReqAuth) RetryWithToken(req *http.Request, res *http.Response) (
 		return false, nil
 	}
 
-	oa.memStore.Del(oa.storageKey)
+	if _, storageKey, err := oa.newTokenParams(req); err == nil {
+		oa.memStore.Del(storageKey)
+	}
 
 	ctx := req.Context()
 	if retries, ok := ctx.Value(request.TokenRequestRetries).(*uint8); !ok || *retries < *oa.config.Retries {
@@ -249,26 +321,80 @@ func (oa *OAuth2ReqAuth) RetryWithToken(req *http.Request, res *http.Response) (
 	return false, nil
 }
 
-func (oa *OAuth2ReqAuth) readAccessToken() string {
-	if data := oa.memStore.Get(oa.storageKey); data != nil {
+// newTokenParams returns the request specific token request parameters and the storage key of the token.
+// Exchanged tokens are stored per subject (and actor) token, all others per oauth2 block.
+func (oa *OAuth2ReqAuth) newTokenParams(req *http.Request) (url.Values, string, error) {
+	formParams := url.Values{}
+	if oa.config.GrantType != config.TokenExchange {
+		return formParams, oa.storageKey, nil
+	}
+
+	requestContext := eval.ContextFromRequest(req).HCLContext()
+	subjectToken, err := evalStringExpr(requestContext, oa.config.SubjectTokenExpr, "subject_token")
+	if err != nil {
+		return nil, "", err
+	}
+	if subjectToken == "" {
+		return nil, "", fmt.Errorf("subject_token expression evaluates to an empty string")
+	}
+
+	formParams.Set("subject_token", subjectToken)
+	formParams.Set("subject_token_type", oa.config.SubjectTokenType)
+
+	hash := sha256.New()
+	hash.Write([]byte(subjectToken))
+
+	if isSet(oa.config.ActorTokenExpr) {
+		actorToken, aerr := evalStringExpr(requestContext, oa.config.ActorTokenExpr, "actor_token")
+		if aerr != nil {
+			return nil, "", aerr
+		}
+		if actorToken != "" {
+			formParams.Set("actor_token", actorToken)
+			formParams.Set("actor_token_type", oa.config.ActorTokenType)
+			hash.Write([]byte{0})
+			hash.Write([]byte(actorToken))
+		}
+	}
+
+	if oa.config.Audience != "" {
+		formParams.Set("audience", oa.config.Audience)
+	}
+	if oa.config.Resource != "" {
+		formParams.Set("resource", oa.config.Resource)
+	}
+	if oa.config.RequestedTokenType != "" {
+		formParams.Set("requested_token_type", oa.config.RequestedTokenType)
+	}
+
+	return formParams, oa.storageKey + "-" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (oa *OAuth2ReqAuth) readAccessToken(storageKey string) string {
+	if data := oa.memStore.Get(storageKey); data != nil {
 		return data.(string)
 	}
 
 	return ""
 }
 
-func (oa *OAuth2ReqAuth) updateAccessToken(token string, jData map[string]interface{}) {
+func (oa *OAuth2ReqAuth) updateAccessToken(storageKey, token string, jData map[string]interface{}) {
 	if oa.memStore != nil {
 		var ttl int64
 		if t, ok := jData["expires_in"].(float64); ok {
 			ttl = (int64)(t * 0.9)
 		}
 
-		oa.memStore.Set(oa.storageKey, token, ttl)
+		oa.memStore.Set(storageKey, token, ttl)
 	}
 }
 
 func (oa *OAuth2ReqAuth) value() (string, string) {
-	token := oa.readAccessToken()
+	// exchanged tokens belong to the client request
+	if oa.config.GrantType == config.TokenExchange {
+		return "oauth2", ""
+	}
+
+	token := oa.readAccessToken(oa.storageKey)
 	return "oauth2", token
 }
@@ -0,0 +1,253 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/errors"
+)
+
+const (
+	sigV4Algorithm       = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat      = "20060102T150405Z"
+	sigV4UnsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+var (
+	_ RequestAuthorizer = &SigV4{}
+	_ RequestSigner     = &SigV4{}
+)
+
+// SigV4 signs backend requests with the AWS Signature Version 4.
+type SigV4 struct {
+	conf *config.SigV4
+}
+
+func NewSigV4(conf *config.SigV4) (RequestAuthorizer, error) {
+	if conf.AccessKeyID == "" || conf.SecretAccessKey == "" {
+		return nil, errors.Configuration.Message("sigv4: access_key_id and secret_access_key must not be empty")
+	}
+	if conf.Region == "" {
+		return nil, errors.Configuration.Message("sigv4: region must not be empty")
+	}
+	if conf.Service == "" {
+		conf.Service = "s3"
+	}
+	return &SigV4{conf: conf}, nil
+}
+
+func (s *SigV4) GetToken(_ *http.Request) error {
+	return nil
+}
+
+func (s *SigV4) RetryWithToken(_ *http.Request, _ *http.Response) (bool, error) {
+	return false, nil
+}
+
+// SignRequest implements the <RequestSigner> interface.
+func (s *SigV4) SignRequest(req *http.Request) error {
+	if err := s.sign(req, time.Now()); err != nil {
+		return errors.Backend.Message("sigv4").With(err)
+	}
+	return nil
+}
+
+func (s *SigV4) value() (string, string) {
+	return "", ""
+}
+
+func (s *SigV4) sign(req *http.Request, t time.Time) error {
+	t = t.UTC()
+	amzDate := t.Format(sigV4TimeFormat)
+	scope := strings.Join([]string{t.Format("20060102"), s.conf.Region, s.conf.Service, "aws4_request"}, "/")
+
+	// remove a possibly forwarded client signature
+	req.Header.Del("Authorization")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.conf.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.conf.SessionToken)
+	} else {
+		req.Header.Del("X-Amz-Security-Token")
+	}
+
+	payloadHash := sigV4UnsignedPayload
+	if !s.conf.UnsignedPayload {
+		body, err := readBody(req)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	if s.conf.Service == "s3" || s.conf.UnsignedPayload {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	signedHeaders, canonicalHeaders := sigV4Headers(req)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		sigV4CanonicalURI(req.URL, s.conf.Service != "s3"),
+		sigV4CanonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.conf.SecretAccessKey), t.Format("20060102"))
+	key = hmacSHA256(key, s.conf.Region)
+	key = hmacSHA256(key, s.conf.Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", sigV4Algorithm+
+		" Credential="+s.conf.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+
+		", Signature="+signature)
+	return nil
+}
+
+// sigV4Headers returns the host, content-type, content-md5 and all x-amz-* header fields
+// in canonical form. Other header fields are not signed since they may be changed on the way.
+func sigV4Headers(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	values := map[string]string{"host": host}
+	for name, v := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "content-md5" || strings.HasPrefix(lower, "x-amz-") {
+			trimmed := make([]string, len(v))
+			for i, value := range v {
+				trimmed[i] = strings.Join(strings.Fields(value), " ")
+			}
+			values[lower] = strings.Join(trimmed, ",")
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + values[name] + "\n")
+	}
+	return strings.Join(names, ";"), canonical.String()
+}
+
+// sigV4CanonicalURI encodes the segments of the escaped path as sent, so that an escaped
+// reserved character like "%2F" stays part of its segment.
+func sigV4CanonicalURI(u *url.URL, encodeTwice bool) string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segments[i] = sigV4Encode(segment, true)
+	}
+
+	encoded := strings.Join(segments, "/")
+	if encodeTwice {
+		encoded = sigV4Encode(encoded, false)
+	}
+	return encoded
+}
+
+// sigV4CanonicalQuery sorts the encoded parameters by their key and then by their value.
+func sigV4CanonicalQuery(query url.Values) string {
+	var params [][2]string
+	for key, values := range query {
+		for _, v := range values {
+			params = append(params, [2]string{sigV4Encode(key, true), sigV4Encode(v, true)})
+		}
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i][0] != params[j][0] {
+			return params[i][0] < params[j][0]
+		}
+		return params[i][1] < params[j][1]
+	})
+
+	pairs := make([]string, len(params))
+	for i, p := range params {
+		pairs[i] = p[0] + "=" + p[1]
+	}
+	return strings.Join(pairs, "&")
+}
+
+// sigV4Encode percent-encodes all bytes except the unreserved characters and optionally the slash.
+func sigV4Encode(s string, encodeSlash bool) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '.' || c == '_' || c == '~' || (c == '/' && !encodeSlash) {
+			buf.WriteByte(c)
+			continue
+		}
+		buf.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+	}
+	return buf.String()
+}
+
+// readBody returns the request body and ensures it can be read again.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = req.Body.Close()
+
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Body, _ = req.GetBody()
+	req.ContentLength = int64(len(body))
+	return body, nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
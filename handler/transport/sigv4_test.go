@@ -0,0 +1,97 @@
+package transport
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coupergateway/couper/config"
+)
+
+func TestSigV4_sign(t *testing.T) {
+	// https://github.com/awslabs/aws-c-auth/tree/main/tests/aws-signing-test-suite/v4
+	signer, err := NewSigV4(&config.SigV4{
+		AccessKeyID:     "AKIDEXAMPLE",
+		Region:          "us-east-1",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Service:         "service",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{"get-vanilla", "https://example.amazonaws.com/", "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"get-vanilla-query-order-key-case", "https://example.amazonaws.com/?Param2=value2&Param1=value1", "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tc.url, nil)
+
+			if err = signer.(*SigV4).sign(req, now); err != nil {
+				st.Fatal(err)
+			}
+
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				st.Errorf("expected X-Amz-Date, got: %q", got)
+			}
+
+			authorization := req.Header.Get("Authorization")
+			if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, ") {
+				st.Errorf("unexpected Authorization: %q", authorization)
+			}
+			if !strings.HasSuffix(authorization, "Signature="+tc.expected) {
+				st.Errorf("expected signature %q, got: %q", tc.expected, authorization)
+			}
+		})
+	}
+}
+
+func TestSigV4_sigV4CanonicalURI(t *testing.T) {
+	for _, tc := range []struct {
+		path, exp, expTwice string
+	}{
+		{"", "/", "/"},
+		{"/bucket/my file.txt", "/bucket/my%20file.txt", "/bucket/my%2520file.txt"},
+		{"/a~b/c-d_e.f", "/a~b/c-d_e.f", "/a~b/c-d_e.f"},
+		{"/bucket/a%2Fb", "/bucket/a%2Fb", "/bucket/a%252Fb"},
+		{"/bucket/a%3Fb%20c$", "/bucket/a%3Fb%20c%24", "/bucket/a%253Fb%2520c%2524"},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost", nil)
+		u, err := url.Parse(tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.URL.Path, req.URL.RawPath = u.Path, u.RawPath
+
+		if got := sigV4CanonicalURI(req.URL, false); got != tc.exp {
+			t.Errorf("expected %q, got: %q", tc.exp, got)
+		}
+		if got := sigV4CanonicalURI(req.URL, true); got != tc.expTwice {
+			t.Errorf("expected %q, got: %q", tc.expTwice, got)
+		}
+	}
+}
+
+func TestSigV4_sigV4CanonicalQuery(t *testing.T) {
+	for _, tc := range []struct {
+		query url.Values
+		exp   string
+	}{
+		{url.Values{}, ""},
+		{url.Values{"a-b": {"1"}, "a": {"1"}}, "a=1&a-b=1"},
+		{url.Values{"a": {"b", "a-b", "a"}}, "a=a&a=a-b&a=b"},
+		{url.Values{"my key": {"x/y"}, "a.b": {"2"}}, "a.b=2&my%20key=x%2Fy"},
+	} {
+		if got := sigV4CanonicalQuery(tc.query); got != tc.exp {
+			t.Errorf("expected %q, got: %q", tc.exp, got)
+		}
+	}
+}
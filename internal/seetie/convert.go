@@ -71,27 +71,6 @@ func ValueToMap(val cty.Value) map[string]interface{} {
 	return result
 }
 
-func ValueToPermission(val cty.Value) (string, map[string]string, error) {
-	switch val.Type() {
-	case cty.NilType:
-		return "", nil, nil
-	case cty.String:
-		return val.AsString(), nil, nil
-	default:
-		if val.Type().IsObjectType() {
-			permissionMap := make(map[string]string)
-			for k, v := range val.AsValueMap() {
-				if v.Type() != cty.String {
-					return "", nil, fmt.Errorf("unsupported value for method %q in required_permission", k)
-				}
-				permissionMap[strings.ToUpper(k)] = v.AsString()
-			}
-			return "", permissionMap, nil
-		}
-	}
-	return "", nil, fmt.Errorf("unsupported value for required_permission")
-}
-
 func ValuesMapToValue(m url.Values) cty.Value {
 	result := make(map[string]interface{})
 	for k, v := range m {
@@ -0,0 +1,272 @@
+package test
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+// LDAP protocol operations and result codes used by the LDAPServer.
+const (
+	ldapBindRequest      = 0
+	ldapBindResponse     = 1
+	ldapUnbindRequest    = 2
+	ldapSearchRequest    = 3
+	ldapSearchEntry      = 4
+	ldapSearchDone       = 5
+	ldapExtendedRequest  = 23
+	ldapExtendedResponse = 24
+
+	ldapSuccess                 = 0
+	ldapProtocolError           = 2
+	ldapNoSuchObject            = 32
+	ldapInvalidCredentials      = 49
+	ldapInsufficientAccessRight = 50
+	ldapUnwillingToPerform      = 53
+
+	ldapStartTLSOID = "1.3.6.1.4.1.1466.20037"
+)
+
+// LDAPEntry is a directory entry of the LDAPServer. Entries with a password can be bound.
+type LDAPEntry struct {
+	DN         string
+	Attributes map[string][]string
+	Password   string
+}
+
+// LDAPServer is an in-process LDAP stand-in supporting simple binds, subtree searches with
+// and, or, equality and presence filters and StartTLS. Searches require a non-anonymous bind.
+type LDAPServer struct {
+	Addr string
+	URL  string
+
+	binds     int64
+	entries   []LDAPEntry
+	listener  net.Listener
+	tlsConfig *tls.Config
+	wg        sync.WaitGroup
+}
+
+// NewLDAPServer starts an LDAPServer with the given entries. StartTLS is supported
+// if a TLS config is given.
+func NewLDAPServer(entries []LDAPEntry, tlsConfig *tls.Config) *LDAPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+
+	s := &LDAPServer{
+		Addr:      listener.Addr().String(),
+		URL:       "ldap://" + listener.Addr().String(),
+		entries:   entries,
+		listener:  listener,
+		tlsConfig: tlsConfig,
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// Binds returns the number of received bind requests.
+func (s *LDAPServer) Binds() int {
+	return int(atomic.LoadInt64(&s.binds))
+}
+
+// Close stops the server.
+func (s *LDAPServer) Close() {
+	_ = s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *LDAPServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *LDAPServer) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	var boundDN string
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		if len(packet.Children) < 2 {
+			return
+		}
+
+		id := packet.Children[0].Value
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldapBindRequest:
+			atomic.AddInt64(&s.binds, 1)
+			dn, password := decodeString(op.Children[1]), decodeString(op.Children[2])
+			code := ldapInvalidCredentials
+			if dn == "" && password == "" {
+				code = ldapSuccess
+			} else if entry := s.entry(dn); entry != nil && password != "" && entry.Password == password {
+				code = ldapSuccess
+			}
+			if code == ldapSuccess {
+				boundDN = dn
+			}
+			writeLDAPResult(conn, id, ldapBindResponse, code)
+		case ldapUnbindRequest:
+			return
+		case ldapSearchRequest:
+			code := s.search(conn, id, op, boundDN)
+			writeLDAPResult(conn, id, ldapSearchDone, code)
+		case ldapExtendedRequest:
+			if decodeString(op.Children[0]) != ldapStartTLSOID || s.tlsConfig == nil {
+				writeLDAPResult(conn, id, ldapExtendedResponse, ldapUnwillingToPerform)
+				continue
+			}
+			writeLDAPResult(conn, id, ldapExtendedResponse, ldapSuccess)
+
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err = tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+		default:
+			writeLDAPResult(conn, id, ldapExtendedResponse, ldapProtocolError)
+		}
+	}
+}
+
+func (s *LDAPServer) search(w io.Writer, id interface{}, op *ber.Packet, boundDN string) int {
+	if boundDN == "" {
+		return ldapInsufficientAccessRight
+	}
+
+	baseDN := decodeString(op.Children[0])
+	filter := op.Children[6]
+
+	var attributes []string
+	for _, attribute := range op.Children[7].Children {
+		attributes = append(attributes, decodeString(attribute))
+	}
+
+	found := false
+	for _, entry := range s.entries {
+		if !strings.HasSuffix(strings.ToLower(entry.DN), strings.ToLower(baseDN)) {
+			continue
+		}
+		found = true
+
+		if !matchLDAPFilter(filter, entry) {
+			continue
+		}
+
+		result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldapSearchEntry, nil, "Search Result Entry")
+		result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "DN"))
+		list := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+		for _, name := range attributes {
+			values, exists := entryAttribute(entry, name)
+			if !exists {
+				continue
+			}
+			attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+			attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+			set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+			for _, value := range values {
+				set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+			}
+			attribute.AppendChild(set)
+			list.AppendChild(attribute)
+		}
+		result.AppendChild(list)
+
+		writeLDAPMessage(w, id, result)
+	}
+
+	if !found {
+		return ldapNoSuchObject
+	}
+	return ldapSuccess
+}
+
+func (s *LDAPServer) entry(dn string) *LDAPEntry {
+	for i := range s.entries {
+		if strings.EqualFold(s.entries[i].DN, dn) {
+			return &s.entries[i]
+		}
+	}
+	return nil
+}
+
+func matchLDAPFilter(filter *ber.Packet, entry LDAPEntry) bool {
+	switch filter.Tag {
+	case 0: // and
+		for _, child := range filter.Children {
+			if !matchLDAPFilter(child, entry) {
+				return false
+			}
+		}
+		return true
+	case 1: // or
+		for _, child := range filter.Children {
+			if matchLDAPFilter(child, entry) {
+				return true
+			}
+		}
+		return false
+	case 3: // equality match
+		values, _ := entryAttribute(entry, decodeString(filter.Children[0]))
+		for _, value := range values {
+			if strings.EqualFold(value, decodeString(filter.Children[1])) {
+				return true
+			}
+		}
+		return false
+	case 7: // present
+		_, exists := entryAttribute(entry, ber.DecodeString(filter.Data.Bytes()))
+		return exists
+	}
+	return false
+}
+
+func entryAttribute(entry LDAPEntry, name string) ([]string, bool) {
+	for attribute, values := range entry.Attributes {
+		if strings.EqualFold(attribute, name) {
+			return values, true
+		}
+	}
+	return nil, false
+}
+
+func decodeString(p *ber.Packet) string {
+	if s, ok := p.Value.(string); ok {
+		return s
+	}
+	return ber.DecodeString(p.Data.Bytes())
+}
+
+func writeLDAPResult(w io.Writer, id interface{}, tag ber.Tag, code int) {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	writeLDAPMessage(w, id, result)
+}
+
+func writeLDAPMessage(w io.Writer, id interface{}, op *ber.Packet) {
+	message := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Message")
+	message.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "Message ID"))
+	message.AppendChild(op)
+	_, _ = w.Write(message.Bytes())
+}
@@ -164,6 +164,9 @@ func validateSigningProfileCSJ(jwtSigningProfile *config.JWTSigningProfile) erro
 	if jwtSigningProfile.KeyFile != "" {
 		return fmt.Errorf("key_file must not be set with %s", clientSecretJwt)
 	}
+	if len(jwtSigningProfile.SigningKeys) > 0 {
+		return fmt.Errorf("signing_key must not be set with %s", clientSecretJwt)
+	}
 	return nil
 }
 
@@ -199,7 +202,7 @@ func validateSigningProfilePKJ(jwtSigningProfile *config.JWTSigningProfile) erro
 	if jwtSigningProfile == nil {
 		return fmt.Errorf("jwt_signing_profile block must be set with %s", privateKeyJwt)
 	}
-	if jwtSigningProfile.Key == "" && jwtSigningProfile.KeyFile == "" {
+	if jwtSigningProfile.Key == "" && jwtSigningProfile.KeyFile == "" && len(jwtSigningProfile.SigningKeys) == 0 {
 		return fmt.Errorf("key and key_file must not both be empty with %s", privateKeyJwt)
 	}
 	return nil
@@ -275,7 +278,7 @@ func (ca *JwtClientAuthenticator) Authenticate(formParams *url.Values, req *http
 	// there is a negligible probability that the same value will be
 	// accidentally assigned to a different data object
 	claims["jti"] = "client_assertion-" + xid.New().String()
-	clientAssertion, err := lib.CreateJWT(ca.jsc.SignatureAlgorithm, ca.jsc.Key, claims, ca.headers)
+	clientAssertion, err := ca.jsc.CreateToken(claims, ca.headers)
 	if err != nil {
 		return err
 	}
@@ -88,7 +88,9 @@ func (c *Client) newTokenRequest(ctx context.Context, formParams url.Values) (*h
 	outreq.Header.Set("Accept", "application/json")
 	outreq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	formParams.Set("grant_type", c.grantType)
+	if formParams.Get("grant_type") == "" {
+		formParams.Set("grant_type", c.grantType)
+	}
 
 	if c.authenticator != nil {
 		err = c.authenticator.Authenticate(&formParams, outreq)
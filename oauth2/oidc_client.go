@@ -7,20 +7,25 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/hashicorp/hcl/v2"
 
 	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
+	"github.com/coupergateway/couper/accesscontrol/session"
 	"github.com/coupergateway/couper/config/request"
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/eval/buffer"
 	"github.com/coupergateway/couper/oauth2/oidc"
 )
 
+const backchannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
 var (
-	_ AuthCodeFlowClient = &OidcClient{}
+	_ AuthCodeFlowClient     = &OidcClient{}
+	_ session.TokenRefresher = &OidcClient{}
 )
 
 // OidcClient represents an OpenID Connect client using the authorization code flow.
@@ -40,7 +45,7 @@ func NewOidcClient(evalCtx *hcl.EvalContext, oidcConfig *oidc.Config) (*OidcClie
 	}
 
 	var algorithms []string
-	for _, a := range append(acjwt.RSAAlgorithms, acjwt.ECDSAlgorithms...) {
+	for _, a := range acjwt.AsymmetricAlgorithms() {
 		algorithms = append(algorithms, a.String())
 	}
 	issuer, err := oidcConfig.GetIssuer()
@@ -106,6 +111,128 @@ func (o *OidcClient) validateTokenResponseData(ctx context.Context, tokenRespons
 	return nil
 }
 
+// SessionName returns the label of the session block keeping the tokens, if configured.
+func (o *OidcClient) SessionName() string {
+	return o.config.Session
+}
+
+// NewSessionToken creates the session token from the validated token response data.
+func (o *OidcClient) NewSessionToken(tokenResponseData map[string]interface{}) *session.Token {
+	token := newSessionToken(tokenResponseData, time.Now())
+	token.IDToken, _ = tokenResponseData["id_token"].(string)
+	if claims, ok := tokenResponseData["id_token_claims"].(map[string]interface{}); ok {
+		token.Subject, _ = claims["sub"].(string)
+		token.SessionID, _ = claims["sid"].(string)
+	}
+	return token
+}
+
+// RefreshToken implements the session.TokenRefresher interface.
+func (o *OidcClient) RefreshToken(ctx context.Context, token *session.Token) (*session.Token, error) {
+	formParams := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {token.RefreshToken},
+	}
+
+	tokenResponseData, _, err := o.GetTokenResponse(ctx, formParams)
+	if err != nil {
+		return nil, errors.Oauth2.Message("refresh token request error").With(err)
+	}
+
+	refreshed := newSessionToken(tokenResponseData, time.Now())
+	if refreshed.AccessToken == "" {
+		return nil, errors.Oauth2.Message("missing access_token in refresh token response")
+	}
+	// the authorization server may keep the refresh token
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = token.RefreshToken
+	}
+	refreshed.IDToken = token.IDToken
+	refreshed.SessionID = token.SessionID
+	refreshed.Subject = token.Subject
+
+	// OpenID Connect Core 1.0, 12.2. Successful Refresh Response
+	if idTokenString, ok := tokenResponseData["id_token"].(string); ok {
+		idTokenClaims := jwt.MapClaims{}
+		if _, err = o.jwtParser.ParseWithClaims(idTokenString, idTokenClaims, o.keyfunc); err != nil {
+			return nil, errors.Oauth2.Message("refresh token response validation error").With(err)
+		}
+		if sub, _ := idTokenClaims["sub"].(string); sub != token.Subject {
+			return nil, errors.Oauth2.Messagef("subject mismatch, in ID token %q, in session %q", sub, token.Subject)
+		}
+		refreshed.IDToken = idTokenString
+		if sid, ok := idTokenClaims["sid"].(string); ok {
+			refreshed.SessionID = sid
+		}
+	}
+
+	return refreshed, nil
+}
+
+// LogoutToken represents the claims of a validated logout token relevant for the back-channel logout.
+type LogoutToken struct {
+	Expiry    time.Time
+	ID        string
+	Issuer    string
+	SessionID string
+	Subject   string
+}
+
+// ValidateLogoutToken validates a logout token sent to the back-channel logout endpoint.
+// See https://openid.net/specs/openid-connect-backchannel-1_0.html#Validation
+func (o *OidcClient) ValidateLogoutToken(logoutToken string) (*LogoutToken, error) {
+	claims := jwt.MapClaims{}
+	token, err := o.jwtParser.ParseWithClaims(logoutToken, claims, o.keyfunc)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ, ok := token.Header["typ"].(string); ok && !strings.EqualFold(typ, "logout+jwt") && !strings.EqualFold(typ, "JWT") {
+		return nil, fmt.Errorf("invalid typ header %q in logout token", typ)
+	}
+
+	if _, ok := claims["iat"].(float64); !ok {
+		return nil, fmt.Errorf("missing iat claim in logout token")
+	}
+
+	lt := &LogoutToken{}
+	if lt.ID, _ = claims["jti"].(string); lt.ID == "" {
+		return nil, fmt.Errorf("missing jti claim in logout token")
+	}
+
+	events, _ := claims["events"].(map[string]interface{})
+	if _, ok := events[backchannelLogoutEvent].(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("missing %s event in logout token", backchannelLogoutEvent)
+	}
+
+	if _, exists := claims["nonce"]; exists {
+		return nil, fmt.Errorf("nonce claim not allowed in logout token")
+	}
+
+	lt.Issuer, _ = claims["iss"].(string)
+	lt.Subject, _ = claims["sub"].(string)
+	lt.SessionID, _ = claims["sid"].(string)
+	if lt.Subject == "" && lt.SessionID == "" {
+		return nil, fmt.Errorf("missing sub or sid claim in logout token")
+	}
+
+	if exp, _ := claims.GetExpirationTime(); exp != nil {
+		lt.Expiry = exp.Time
+	}
+
+	return lt, nil
+}
+
+func newSessionToken(tokenResponseData map[string]interface{}, now time.Time) *session.Token {
+	token := &session.Token{}
+	token.AccessToken, _ = tokenResponseData["access_token"].(string)
+	token.RefreshToken, _ = tokenResponseData["refresh_token"].(string)
+	if expiresIn, ok := tokenResponseData["expires_in"].(float64); ok && expiresIn > 0 {
+		token.Expiry = now.Add(time.Duration(expiresIn) * time.Second)
+	}
+	return token
+}
+
 func (o *OidcClient) keyfunc(token *jwt.Token) (interface{}, error) {
 	return o.config.JWKS().
 		GetSigKeyForToken(token)
@@ -0,0 +1,132 @@
+package resource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/coupergateway/couper/config/reader"
+	"github.com/coupergateway/couper/config/request"
+)
+
+// WatchInterval is the period in which watched files are checked for modifications.
+var WatchInterval = time.Second
+
+// WatchedFile provides the unmarshalled content of a local file and reloads it
+// whenever its modification time changes, so e.g. credential stores can be updated
+// without a configuration reload. A failed reload keeps the previous data.
+// The watch goroutine exits when the provided context is cancelled.
+type WatchedFile struct {
+	fileContext  string
+	log          *logrus.Entry
+	path         string
+	unmarshaller ResourceUnmarshaller
+	// used internally
+	data    interface{}
+	modTime time.Time
+	mu      sync.RWMutex
+}
+
+func NewWatchedFile(ctx context.Context, fileContext, path string, unmarshaller ResourceUnmarshaller, log *logrus.Entry) (*WatchedFile, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	wf := &WatchedFile{
+		fileContext:  fileContext,
+		log:          log,
+		path:         absPath,
+		unmarshaller: unmarshaller,
+	}
+
+	if err = wf.read(); err != nil {
+		return nil, err
+	}
+
+	// do not start go-routine on config check (-watch)
+	if _, exist := ctx.Value(request.ConfigDryRun).(bool); !exist {
+		go wf.watch(ctx)
+	}
+
+	return wf, nil
+}
+
+// Data returns the most recently loaded file content.
+func (w *WatchedFile) Data() interface{} {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.data
+}
+
+func (w *WatchedFile) watch(ctx context.Context) {
+	ticker := time.NewTicker(WatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(w.path)
+			if err != nil {
+				w.warn(err)
+				continue
+			}
+
+			w.mu.RLock()
+			changed := !info.ModTime().Equal(w.modTime)
+			w.mu.RUnlock()
+			if !changed {
+				continue
+			}
+
+			if err = w.read(); err != nil {
+				w.warn(err)
+				// retry with the next modification only
+				w.mu.Lock()
+				w.modTime = info.ModTime()
+				w.mu.Unlock()
+			}
+		}
+	}
+}
+
+// read stats the file before reading it: if the file is replaced in between, the content is
+// newer than the stored modification time and the next tick reads it again.
+func (w *WatchedFile) read() error {
+	info, statErr := os.Stat(w.path)
+
+	// report a missing file with the error of the reader
+	raw, err := reader.ReadFromFile(w.fileContext, w.path)
+	if err != nil {
+		return err
+	}
+	if statErr != nil {
+		return statErr
+	}
+
+	data, err := w.unmarshaller.Unmarshal(raw)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	w.data = data
+	w.modTime = info.ModTime()
+	w.mu.Unlock()
+
+	return nil
+}
+
+func (w *WatchedFile) warn(err error) {
+	if w.log == nil {
+		return
+	}
+	w.log.WithField("file", w.path).WithField("type", w.fileContext).
+		Warnf("watched file reload failed, keeping previous content: %v", err)
+}
@@ -6,11 +6,14 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/coupergateway/couper/accesscontrol/session"
 	"github.com/coupergateway/couper/config"
 	"github.com/coupergateway/couper/config/env"
 	"github.com/coupergateway/couper/config/request"
@@ -28,6 +31,14 @@ import (
 
 type muxers map[string]*Mux
 
+// hostMuxer is the Mux of a wildcard host or a regular expression host.
+type hostMuxer struct {
+	mux     *Mux
+	order   int
+	pattern *regexp.Regexp
+	suffix  string
+}
+
 // HTTPServer represents a configured HTTP server.
 type HTTPServer struct {
 	commandCtx context.Context
@@ -35,11 +46,13 @@ type HTTPServer struct {
 	listeners  []net.Listener
 	log        logrus.FieldLogger
 	muxers     muxers
+	patterns   []hostMuxer
 	port       string
 	settings   *config.Settings
 	shutdownCh chan struct{}
 	srv        *http.Server
 	timings    *runtime.HTTPTimings
+	wildcards  []hostMuxer
 }
 
 // NewServers returns a list of the created and configured HTTP(s) servers.
@@ -75,12 +88,25 @@ func New(cmdCtx, evalCtx context.Context, log logrus.FieldLogger, settings *conf
 	shutdownCh := make(chan struct{})
 
 	muxersList := make(muxers)
+	var patterns, wildcards []hostMuxer
 	var serverTLS *config.ServerTLS
 	for host, muxOpts := range hosts {
 		mux := NewMux(muxOpts)
 		registerHandler(mux.endpointRoot, []string{http.MethodGet}, settings.HealthPath, handler.NewHealthCheck(settings.HealthPath, shutdownCh))
 		mux.RegisterConfigured()
-		muxersList[host] = mux
+
+		switch {
+		case strings.HasPrefix(host, runtime.HostPatternPrefix):
+			pattern, err := runtime.CompileHostPattern(host)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, hostMuxer{mux: mux, order: muxOpts.HostOrder, pattern: pattern})
+		case strings.HasPrefix(host, "*."):
+			wildcards = append(wildcards, hostMuxer{mux: mux, suffix: host[1:]})
+		default:
+			muxersList[host] = mux
+		}
 
 		// TODO: refactor (hosts,muxOpts, etc) format type and usage
 		// serverOpts are all the same, pick first
@@ -89,15 +115,25 @@ func New(cmdCtx, evalCtx context.Context, log logrus.FieldLogger, settings *conf
 		}
 	}
 
+	// regular expressions in definition order, the most specific wildcard first
+	sort.Slice(patterns, func(i, j int) bool {
+		return patterns[i].order < patterns[j].order
+	})
+	sort.Slice(wildcards, func(i, j int) bool {
+		return len(wildcards[i].suffix) > len(wildcards[j].suffix)
+	})
+
 	httpSrv := &HTTPServer{
 		evalCtx:    evalCtx.Value(request.ContextType).(*eval.Context),
 		commandCtx: cmdCtx,
 		log:        log,
 		muxers:     muxersList,
+		patterns:   patterns,
 		port:       p.String(),
 		settings:   settings,
 		shutdownCh: shutdownCh,
 		timings:    timings,
+		wildcards:  wildcards,
 	}
 
 	accessLog := logging.NewAccessLog(&logConf, log)
@@ -253,12 +289,9 @@ func (s *HTTPServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		h = errors.DefaultHTML.WithError(errors.ClientRequest)
 	}
 
-	mux, ok := s.muxers[host]
-	if !ok {
-		mux, ok = s.muxers["*"]
-		if !ok && h == nil {
-			h = errors.DefaultHTML.WithError(errors.Configuration)
-		}
+	mux, hostParams, ok := s.findMux(host)
+	if !ok && h == nil {
+		h = errors.DefaultHTML.WithError(errors.Configuration)
 	}
 
 	if h == nil {
@@ -268,6 +301,9 @@ func (s *HTTPServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 	ctx := context.WithValue(req.Context(), request.LogEntry, s.log)
 	ctx = context.WithValue(ctx, request.XFF, req.Header.Get("X-Forwarded-For"))
+	if hostParams != nil {
+		ctx = context.WithValue(ctx, request.HostParams, hostParams)
+	}
 
 	// set innermost handler name for logging purposes
 	if hs, stringer := getChildHandler(h).(fmt.Stringer); stringer {
@@ -307,6 +343,7 @@ func (s *HTTPServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 
 	ctx = context.WithValue(ctx, request.BufferOptions, bufferOption)
+	ctx = session.WithWrites(ctx)
 	// due to the middleware callee stack we have to update the 'req' value.
 	*req = *req.WithContext(s.evalCtx.WithClientRequest(req.WithContext(ctx)))
 
@@ -327,6 +364,41 @@ func (s *HTTPServer) setGetBody(h http.Handler, req *http.Request) (opt buffer.O
 	return opt, err
 }
 
+// findMux returns the Mux for the given host. Exact hosts take precedence over wildcard
+// hosts, the most specific first, over regular expression hosts in definition order and
+// the catch-all host "*". The named groups of a matching regular expression are returned
+// as host parameters.
+func (s *HTTPServer) findMux(host string) (*Mux, request.HostParameter, bool) {
+	if mux, ok := s.muxers[host]; ok {
+		return mux, nil, true
+	}
+
+	for _, w := range s.wildcards {
+		// a wildcard matches exactly one label
+		if label := strings.TrimSuffix(host, w.suffix); label != host && label != "" && !strings.Contains(label, ".") {
+			return w.mux, nil, true
+		}
+	}
+
+	for _, p := range s.patterns {
+		match := p.pattern.FindStringSubmatch(host)
+		if match == nil {
+			continue
+		}
+
+		params := make(request.HostParameter)
+		for i, name := range p.pattern.SubexpNames() {
+			if name != "" && i < len(match) {
+				params[name] = match[i]
+			}
+		}
+		return p.mux, params, true
+	}
+
+	mux, ok := s.muxers["*"]
+	return mux, nil, ok
+}
+
 // getHost configures the host from the incoming request host based on
 // the xfh setting and listener port to be prepared for the http multiplexer.
 func (s *HTTPServer) getHost(req *http.Request) string {
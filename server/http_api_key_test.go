@@ -0,0 +1,66 @@
+package server_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestAPIKey_AccessControl(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	shutdown, hook := newCouper("testdata/api_key/01_couper.hcl", helper)
+	defer shutdown()
+
+	for _, tc := range []struct {
+		name         string
+		path         string
+		key          string
+		expStatus    int
+		expOwner     string
+		expErrorType string
+	}{
+		{"missing key", "/orders", "", http.StatusUnauthorized, "", "api_key_missing"},
+		{"unknown key", "/orders", "unknown", http.StatusUnauthorized, "", "api_key"},
+		{"granted", "/orders", "acme-secret", http.StatusOK, "acme", ""},
+		{"insufficient permissions", "/admin", "acme-secret", http.StatusForbidden, "", "insufficient_permissions"},
+		{"rate limited by owner", "/orders", "acme-secret", http.StatusTooManyRequests, "", "beta_rate_limiter"},
+		{"other owner", "/admin", "globex-secret", http.StatusNoContent, "", ""},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			hook.Reset()
+
+			req, err := http.NewRequest(http.MethodGet, "http://localhost:8080"+tc.path, nil)
+			helper.Must(err)
+			if tc.key != "" {
+				req.Header.Set("X-API-Key", tc.key)
+			}
+
+			res, err := client.Do(req)
+			helper.Must(err)
+			_, _ = io.Copy(io.Discard, res.Body)
+			_ = res.Body.Close()
+
+			if res.StatusCode != tc.expStatus {
+				st.Errorf("expected status %d, got: %d", tc.expStatus, res.StatusCode)
+			}
+
+			if owner := res.Header.Get("X-Owner"); owner != tc.expOwner {
+				st.Errorf("expected owner %q, got: %q", tc.expOwner, owner)
+			}
+
+			var loggedType string
+			for _, entry := range hook.AllEntries() {
+				if errorType, ok := entry.Data["error_type"].(string); ok {
+					loggedType = errorType
+				}
+			}
+			if loggedType != tc.expErrorType {
+				st.Errorf("expected logged error_type %q, got: %q", tc.expErrorType, loggedType)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package server_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestBasicAuth_LDAP(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	ldap := test.NewLDAPServer([]test.LDAPEntry{
+		{DN: "cn=couper,ou=services,dc=example,dc=com", Password: "service-secret"},
+		{DN: "uid=clark,ou=people,dc=example,dc=com", Password: "kryptonite", Attributes: map[string][]string{"uid": {"clark"}}},
+		{DN: "uid=lois,ou=people,dc=example,dc=com", Password: "daily-planet", Attributes: map[string][]string{"uid": {"lois"}}},
+		{DN: "cn=editors,ou=groups,dc=example,dc=com", Attributes: map[string][]string{"cn": {"editors"}, "member": {
+			"uid=clark,ou=people,dc=example,dc=com",
+			"uid=lois,ou=people,dc=example,dc=com",
+		}}},
+		{DN: "cn=admins,ou=groups,dc=example,dc=com", Attributes: map[string][]string{"cn": {"admins"}, "member": {
+			"uid=lois,ou=people,dc=example,dc=com",
+		}}},
+	}, nil)
+	defer ldap.Close()
+
+	shutdown, hook, err := newCouperWithTemplate("testdata/basic_auth/01_couper.hcl", helper, map[string]interface{}{"url": ldap.URL})
+	helper.Must(err)
+	defer shutdown()
+
+	for _, tc := range []struct {
+		name         string
+		path         string
+		user, pass   string
+		expStatus    int
+		expGroups    string
+		expErrorType string
+	}{
+		{"missing credentials", "/orders", "", "", http.StatusUnauthorized, "", "basic_auth_credentials_missing"},
+		{"wrong password", "/orders", "clark", "lex", http.StatusUnauthorized, "", "basic_auth"},
+		{"unknown user", "/orders", "bruce", "kryptonite", http.StatusUnauthorized, "", "basic_auth"},
+		{"editor", "/orders", "clark", "kryptonite", http.StatusOK, "editors", ""},
+		{"editor write", "/admin", "clark", "kryptonite", http.StatusForbidden, "", "insufficient_permissions"},
+		{"admin", "/orders", "lois", "daily-planet", http.StatusOK, "editors,admins", ""},
+		{"admin write", "/admin", "lois", "daily-planet", http.StatusNoContent, "", ""},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			hook.Reset()
+
+			req, err := http.NewRequest(http.MethodGet, "http://localhost:8080"+tc.path, nil)
+			helper.Must(err)
+			if tc.user != "" {
+				req.SetBasicAuth(tc.user, tc.pass)
+			}
+
+			res, err := client.Do(req)
+			helper.Must(err)
+			_, _ = io.Copy(io.Discard, res.Body)
+			_ = res.Body.Close()
+
+			if res.StatusCode != tc.expStatus {
+				st.Errorf("expected status %d, got: %d", tc.expStatus, res.StatusCode)
+			}
+
+			if groups := res.Header.Get("X-Groups"); groups != tc.expGroups {
+				st.Errorf("expected groups %q, got: %q", tc.expGroups, groups)
+			}
+
+			if res.StatusCode == http.StatusUnauthorized {
+				if challenge := res.Header.Get("Www-Authenticate"); challenge != `Basic realm="Corporate"` {
+					st.Errorf("expected challenge, got: %q", challenge)
+				}
+			}
+
+			var loggedType string
+			for _, entry := range hook.AllEntries() {
+				if errorType, ok := entry.Data["error_type"].(string); ok {
+					loggedType = errorType
+				}
+			}
+			if loggedType != tc.expErrorType {
+				st.Errorf("expected logged error_type %q, got: %q", tc.expErrorType, loggedType)
+			}
+		})
+	}
+
+	// search, user and group search binds for the first authentication of each user,
+	// subsequent ones are cached: 2 (wrong password) + 1 (unknown user) + 3 (clark) + 3 (lois)
+	if binds := ldap.Binds(); binds != 9 {
+		t.Errorf("expected 9 binds, got: %d", binds)
+	}
+}
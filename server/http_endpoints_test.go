@@ -1246,6 +1246,112 @@ func TestEndpointWildcardProxyPathWildcard(t *testing.T) {
 	}
 }
 
+func TestEndpoint_PathParamConstraints(t *testing.T) {
+	client := newClient()
+
+	shutdown, _ := newCouper("testdata/endpoints/23_couper.hcl", test.New(t))
+	defer shutdown()
+
+	for _, testcase := range []struct {
+		path       string
+		statusCode int
+		expBody    string
+	}{
+		{"/v1/users/me", http.StatusOK, `{"route":"me"}`},
+		{"/v1/users/41", http.StatusOK, `{"next":42,"route":"int"}`},
+		{"/v1/users/0b6bb4c4-cb1c-4ba8-9f3b-b4d9b2b1a2c8", http.StatusOK, `{"id":"0b6bb4c4-cb1c-4ba8-9f3b-b4d9b2b1a2c8","route":"uuid"}`},
+		{"/v1/users/john", http.StatusNotFound, ""},
+		{"/v1/codes/ABC", http.StatusOK, `{"code":"ABC","route":"code"}`},
+		{"/v1/codes/ABCD", http.StatusNotFound, ""},
+	} {
+		t.Run(testcase.path[1:], func(st *testing.T) {
+			helper := test.New(st)
+			req, err := http.NewRequest(http.MethodGet, "http://localhost:8080"+testcase.path, nil)
+			helper.Must(err)
+
+			res, err := client.Do(req)
+			helper.Must(err)
+
+			if res.StatusCode != testcase.statusCode {
+				st.Errorf("expected status %d, got %d", testcase.statusCode, res.StatusCode)
+			}
+
+			b, err := io.ReadAll(res.Body)
+			helper.Must(res.Body.Close())
+			helper.Must(err)
+
+			if testcase.expBody != "" && string(b) != testcase.expBody {
+				st.Errorf("expected body %s, got %s", testcase.expBody, string(b))
+			}
+		})
+	}
+}
+
+func TestEndpoint_Match(t *testing.T) {
+	client := newClient()
+
+	shutdown, _ := newCouper("testdata/endpoints/24_couper.hcl", test.New(t))
+	defer shutdown()
+
+	for _, testcase := range []struct {
+		name       string
+		method     string
+		path       string
+		header     http.Header
+		body       string
+		statusCode int
+		expBody    string
+	}{
+		{"fallback", http.MethodGet, "/v1/items", nil, "", http.StatusOK, `{"version":1}`},
+		{"header", http.MethodGet, "/v1/items", http.Header{"Accept": {"application/vnd.example.v2+json"}}, "", http.StatusOK, `{"version":2}`},
+		{"other header value", http.MethodGet, "/v1/items", http.Header{"Accept": {"application/json"}}, "", http.StatusOK, `{"version":1}`},
+		{"header list member", http.MethodGet, "/v1/items", http.Header{"Accept": {"application/vnd.example.v2+json, */*;q=0.1"}}, "", http.StatusOK, `{"version":2}`},
+		{"header media type parameters", http.MethodGet, "/v1/items", http.Header{"Accept": {"text/html", "Application/Vnd.Example.v2+JSON;q=0.9"}}, "", http.StatusOK, `{"version":2}`},
+		{"header wildcard member", http.MethodGet, "/v1/items", http.Header{"Accept": {"text/html, */*;q=0.8"}}, "", http.StatusOK, `{"version":1}`},
+		{"query", http.MethodGet, "/v1/items?version=3", nil, "", http.StatusOK, `{"version":3}`},
+		{"definition order", http.MethodGet, "/v1/items?version=3", http.Header{"Accept": {"application/vnd.example.v2+json"}}, "", http.StatusOK, `{"version":2}`},
+		{"method and content type", http.MethodPost, "/v1/items", http.Header{"Content-Type": {"application/json; charset=utf-8"}}, `{"name":"foo"}`, http.StatusOK, `{"created":"foo"}`},
+		{"method w/o content type", http.MethodPost, "/v1/items", http.Header{"Content-Type": {"text/plain"}}, "foo", http.StatusOK, `{"version":1}`},
+		{"condition", http.MethodGet, "/v1/beta", http.Header{"X-Beta": {"1"}}, "", http.StatusOK, `{"beta":true}`},
+		{"condition not met", http.MethodGet, "/v1/beta", nil, "", http.StatusNotFound, ""},
+		{"condition with path param", http.MethodGet, "/v1/tenants/1234", nil, "", http.StatusOK, `{"tenant":"large"}`},
+		{"condition with path param not met", http.MethodGet, "/v1/tenants/12", nil, "", http.StatusOK, `{"tenant":"small"}`},
+		{"cors preflight", http.MethodOptions, "/v2/orders", http.Header{
+			"Origin":                         {"https://www.example.com"},
+			"Access-Control-Request-Method":  {"POST"},
+			"Access-Control-Request-Headers": {"content-type"},
+		}, "", http.StatusNoContent, ""},
+		{"cors preflight other method", http.MethodOptions, "/v2/orders", http.Header{
+			"Origin":                        {"https://www.example.com"},
+			"Access-Control-Request-Method": {"PUT"},
+		}, "", http.StatusNotFound, ""},
+	} {
+		t.Run(testcase.name, func(st *testing.T) {
+			helper := test.New(st)
+			req, err := http.NewRequest(testcase.method, "http://localhost:8080"+testcase.path, strings.NewReader(testcase.body))
+			helper.Must(err)
+			for k, v := range testcase.header {
+				req.Header[k] = v
+			}
+
+			res, err := client.Do(req)
+			helper.Must(err)
+
+			if res.StatusCode != testcase.statusCode {
+				st.Errorf("expected status %d, got %d", testcase.statusCode, res.StatusCode)
+			}
+
+			b, err := io.ReadAll(res.Body)
+			helper.Must(res.Body.Close())
+			helper.Must(err)
+
+			if testcase.expBody != "" && string(b) != testcase.expBody {
+				st.Errorf("expected body %s, got %s", testcase.expBody, string(b))
+			}
+		})
+	}
+}
+
 func Test_toSlice1(t *testing.T) {
 	shutdown, _ := newCouper("testdata/endpoints/22_couper.hcl", test.New(t))
 	defer shutdown()
@@ -1,6 +1,7 @@
 package server_test
 
 import (
+	"context"
 	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
@@ -9,13 +10,25 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
-
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/runtime"
+	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/internal/test"
 	"github.com/coupergateway/couper/server"
 )
@@ -441,3 +454,186 @@ func TestExternalAuthz_ErrorHandler(t *testing.T) {
 		t.Errorf("expected challenge %q, got: %q", expChallenge, challenge)
 	}
 }
+
+func TestExternalAuthz_DecisionCache(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	shutdown, hook := newCouper("testdata/external_authz/10_couper.hcl", helper)
+	defer shutdown()
+
+	countCallouts := func() int {
+		var callouts int
+		for _, entry := range hook.AllEntries() {
+			if entry.Data["type"] == "couper_access" && entry.Data["port"] == "8081" {
+				callouts++
+			}
+		}
+		return callouts
+	}
+
+	hook.Reset()
+	for _, authorization := range []string{"Bearer a", "Bearer a", "Bearer b", "Bearer a"} {
+		req, err := http.NewRequest(http.MethodGet, "http://protected.local:8080/cached", nil)
+		helper.Must(err)
+		req.Header.Set("Authorization", authorization)
+
+		res, err := client.Do(req)
+		helper.Must(err)
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+
+		if res.StatusCode != http.StatusNoContent {
+			t.Errorf("expected status %d, got: %d", http.StatusNoContent, res.StatusCode)
+		}
+	}
+
+	if callouts := countCallouts(); callouts != 2 {
+		t.Errorf("expected 2 callouts, got: %d", callouts)
+	}
+}
+
+func TestExternalAuthz_OnError(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	shutdown, _ := newCouper("testdata/external_authz/10_couper.hcl", helper)
+	defer shutdown()
+
+	req, err := http.NewRequest(http.MethodGet, "http://protected.local:8080/unavailable", nil)
+	helper.Must(err)
+
+	res, err := client.Do(req)
+	helper.Must(err)
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("expected status %d, got: %d", http.StatusNoContent, res.StatusCode)
+	}
+	if res.Header.Get("X-Authz-Error") == "" {
+		t.Error("expected the callout error to be exposed")
+	}
+}
+
+func TestExternalAuthz_CacheTTLWithoutCacheKey(t *testing.T) {
+	helper := test.New(t)
+
+	conf, err := configload.LoadFile(filepath.Join(testWorkingDir, "testdata/external_authz/11_couper.hcl"), "test")
+	helper.Must(err)
+
+	logger, _ := test.NewLogger()
+	log := logger.WithContext(context.TODO())
+
+	tmpStoreCh := make(chan struct{})
+	defer close(tmpStoreCh)
+
+	ctx, cancel := context.WithCancel(conf.Context)
+	conf.Context = ctx
+	defer cancel()
+
+	_, err = runtime.NewServerConfiguration(conf, log, cache.New(log, tmpStoreCh))
+	if err == nil {
+		t.Fatal("expected a configuration error")
+	}
+
+	expMsg := "cache_ttl requires cache_key"
+	if errMsg := err.(errors.GoError).LogError(); !strings.Contains(errMsg, expMsg) {
+		t.Errorf("expected error message containing %q, got: %q", expMsg, errMsg)
+	}
+}
+
+func TestExternalAuthz_EnvoyGRPC(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	grpcServer := grpc.NewServer()
+	authv3.RegisterAuthorizationServer(grpcServer, envoyCheckFunc(func(req *authv3.CheckRequest) *authv3.CheckResponse {
+		if req.GetAttributes().GetRequest().GetHttp().GetHeaders()["authorization"] != "Bearer valid" {
+			return &authv3.CheckResponse{
+				Status: &status.Status{Code: int32(codes.Unauthenticated)},
+				HttpResponse: &authv3.CheckResponse_DeniedResponse{DeniedResponse: &authv3.DeniedHttpResponse{
+					Status: &typev3.HttpStatus{Code: typev3.StatusCode_Unauthorized},
+					Headers: []*corev3.HeaderValueOption{
+						{Header: &corev3.HeaderValue{Key: "content-type", Value: "application/json"}},
+						{Header: &corev3.HeaderValue{Key: "www-authenticate", Value: "Bearer"}},
+					},
+					Body: `{"error":"invalid token"}`,
+				}},
+			}
+		}
+
+		return &authv3.CheckResponse{
+			Status: &status.Status{Code: int32(codes.OK)},
+			HttpResponse: &authv3.CheckResponse_OkResponse{OkResponse: &authv3.OkHttpResponse{
+				Headers:         []*corev3.HeaderValueOption{{Header: &corev3.HeaderValue{Key: "x-user", Value: "clark.kent"}}},
+				HeadersToRemove: []string{"authorization"},
+			}},
+		}
+	}))
+
+	authzService := httptest.NewServer(h2c.NewHandler(grpcServer, &http2.Server{}))
+	defer authzService.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Upstream-User", req.Header.Get("X-User"))
+		rw.Header().Set("X-Upstream-Authorization", req.Header.Get("Authorization"))
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer upstream.Close()
+
+	shutdown, _, err := newCouperWithTemplate("testdata/external_authz/12_couper.hcl", helper,
+		map[string]interface{}{"origin": authzService.URL, "upstream": upstream.URL})
+	helper.Must(err)
+	defer shutdown()
+
+	t.Run("allowed with mutated upstream request", func(st *testing.T) {
+		req, rerr := http.NewRequest(http.MethodGet, "http://protected.local:8080/protected", nil)
+		helper.Must(rerr)
+		req.Header.Set("Authorization", "Bearer valid")
+		req.Header.Set("X-User", "lex.luthor")
+
+		res, derr := client.Do(req)
+		helper.Must(derr)
+		_ = res.Body.Close()
+
+		if res.StatusCode != http.StatusNoContent {
+			st.Fatalf("expected status %d, got: %d", http.StatusNoContent, res.StatusCode)
+		}
+		if user := res.Header.Get("X-Upstream-User"); user != "clark.kent" {
+			st.Errorf("expected upstream x-user %q, got: %q", "clark.kent", user)
+		}
+		if authorization := res.Header.Get("X-Upstream-Authorization"); authorization != "" {
+			st.Errorf("expected removed upstream authorization, got: %q", authorization)
+		}
+	})
+
+	t.Run("denied response", func(st *testing.T) {
+		req, rerr := http.NewRequest(http.MethodGet, "http://protected.local:8080/protected", nil)
+		helper.Must(rerr)
+
+		res, derr := client.Do(req)
+		helper.Must(derr)
+		body, _ := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+
+		if res.StatusCode != http.StatusUnauthorized {
+			st.Errorf("expected status %d, got: %d", http.StatusUnauthorized, res.StatusCode)
+		}
+		if ct := res.Header.Get("Content-Type"); ct != "application/json" {
+			st.Errorf("expected denied content-type, got: %q", ct)
+		}
+		if challenge := res.Header.Get("Www-Authenticate"); challenge != "Bearer" {
+			st.Errorf("expected denied challenge, got: %q", challenge)
+		}
+		if string(body) != `{"error":"invalid token"}` {
+			st.Errorf("expected denied body, got: %q", string(body))
+		}
+	})
+}
+
+type envoyCheckFunc func(*authv3.CheckRequest) *authv3.CheckResponse
+
+func (f envoyCheckFunc) Check(_ context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	return f(req), nil
+}
@@ -0,0 +1,59 @@
+package server_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestHTTPServer_HostPatterns(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	shutdown, _ := newCouper("testdata/integration/vhosts/02_couper.hcl", helper)
+	defer shutdown()
+
+	for _, tc := range []struct {
+		host string
+		exp  map[string]interface{}
+	}{
+		{"api.example.com", map[string]interface{}{"server": "exact"}},
+		{"API.Example.com", map[string]interface{}{"server": "exact"}},
+		{"shop.example.com", map[string]interface{}{"server": "wildcard"}},
+		{"shop.eu.example.com", map[string]interface{}{"server": "specific-wildcard"}},
+		{"shop.us.example.com", map[string]interface{}{"server": "fallback"}},
+		{"example.com", map[string]interface{}{"server": "fallback"}},
+		{"acme-eu.example.org", map[string]interface{}{"server": "pattern", "tenant": "acme", "region": "eu"}},
+		{"acme-asia.example.org", map[string]interface{}{"server": "pattern-b"}},
+		{"acme-asia.example.org.attacker.net", map[string]interface{}{"server": "fallback"}},
+		{"acme-eu.example.org.attacker.net", map[string]interface{}{"server": "fallback"}},
+		{"example.org", map[string]interface{}{"server": "fallback"}},
+	} {
+		t.Run(tc.host, func(st *testing.T) {
+			h := test.New(st)
+
+			req, err := http.NewRequest(http.MethodGet, "http://"+tc.host+":8080/", nil)
+			h.Must(err)
+
+			res, err := client.Do(req)
+			h.Must(err)
+			body, err := io.ReadAll(res.Body)
+			h.Must(err)
+			_ = res.Body.Close()
+
+			if res.StatusCode != http.StatusOK {
+				st.Fatalf("expected status 200, got: %d", res.StatusCode)
+			}
+
+			var result map[string]interface{}
+			h.Must(json.Unmarshal(body, &result))
+			if !reflect.DeepEqual(result, tc.exp) {
+				st.Errorf("expected %v, got: %v", tc.exp, result)
+			}
+		})
+	}
+}
@@ -3625,9 +3625,9 @@ dn/RsYEONbwQSjIfMPkvxF+8HQ==
 		{"invalid Bearer token", "/jwt", http.Header{"Authorization": []string{"Bearer " + invalidToken}}, http.StatusUnauthorized, "jwt_token_invalid", `Bearer error="invalid_token"`},
 
 		{"valid DPoP token", "/jwt/dpop", createDPoPHeaders(http.MethodGet, "/jwt/dpop", privKey, jwk, h, false, false), http.StatusOK, "", ""},
-		{"no DPoP token", "/jwt/dpop", http.Header{}, http.StatusUnauthorized, "jwt_token_missing", `DPoP algs="RS256 RS384 RS512 ES256 ES384 ES512"`},
-		{"expired DPoP token", "/jwt/dpop", createDPoPHeaders(http.MethodGet, "/jwt/dpop", privKey, jwk, h, true, false), http.StatusUnauthorized, "jwt_token_expired", `DPoP algs="RS256 RS384 RS512 ES256 ES384 ES512", error="invalid_token", error_description="The access token expired"`},
-		{"DPoP token, missing DPoP proof", "/jwt/dpop", createDPoPHeaders(http.MethodGet, "/jwt/dpop", privKey, jwk, h, false, true), http.StatusUnauthorized, "jwt_token_invalid", `DPoP algs="RS256 RS384 RS512 ES256 ES384 ES512", error="invalid_token"`},
+		{"no DPoP token", "/jwt/dpop", http.Header{}, http.StatusUnauthorized, "jwt_token_missing", `DPoP algs="RS256 RS384 RS512 ES256 ES384 ES512 PS256 PS384 PS512 EdDSA"`},
+		{"expired DPoP token", "/jwt/dpop", createDPoPHeaders(http.MethodGet, "/jwt/dpop", privKey, jwk, h, true, false), http.StatusUnauthorized, "jwt_token_expired", `DPoP algs="RS256 RS384 RS512 ES256 ES384 ES512 PS256 PS384 PS512 EdDSA", error="invalid_token", error_description="The access token expired"`},
+		{"DPoP token, missing DPoP proof", "/jwt/dpop", createDPoPHeaders(http.MethodGet, "/jwt/dpop", privKey, jwk, h, false, true), http.StatusUnauthorized, "jwt_token_invalid", `DPoP algs="RS256 RS384 RS512 ES256 ES384 ES512 PS256 PS384 PS512 EdDSA", error="invalid_token"`},
 
 		{"valid token in header", "/jwt/header", http.Header{"X-Token": []string{validToken}}, http.StatusOK, "", ""},
 		{"no token in header", "/jwt/header", http.Header{}, http.StatusUnauthorized, "jwt_token_missing", ""},
@@ -4140,8 +4140,9 @@ func Test_Permissions(t *testing.T) {
 	defer shutdown()
 
 	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"scp": "a",
-		"rl":  "r1",
+		"scp":    "a",
+		"rl":     "r1",
+		"tenant": "t1",
 	})
 	token, tokenErr := tok.SignedString([]byte("asdf"))
 	h.Must(tokenErr)
@@ -4173,6 +4174,13 @@ func Test_Permissions(t *testing.T) {
 		{"by scope: required permission bad type boolean", http.MethodGet, "/scope/bad/type/boolean", true, http.StatusInternalServerError, ``, ``, "expression evaluation error", "evaluation"},
 		{"by scope: required permission bad type tuple", http.MethodGet, "/scope/bad/type/tuple", true, http.StatusInternalServerError, ``, ``, "expression evaluation error", "evaluation"},
 		{"by scope: required permission bad type null", http.MethodGet, "/scope/bad/type/null", true, http.StatusInternalServerError, ``, ``, "expression evaluation error", "evaluation"},
+		{"by scope: any of required permissions", http.MethodGet, "/scope/rules/any-of", true, http.StatusNoContent, `["a"]`, ``, "", ""},
+		{"by scope: all of required permissions: insufficient permissions", http.MethodGet, "/scope/rules/all-of", true, http.StatusForbidden, ``, `["a","p1"]`, `access control error: required permission "p1" not granted`, "insufficient_permissions"},
+		{"by scope: any of required permissions: insufficient permissions", http.MethodGet, "/scope/rules/any-of/missing", true, http.StatusForbidden, ``, `{"any_of":["y","z"]}`, `access control error: none of the required permissions ["y" "z"] granted`, "insufficient_permissions"},
+		{"by scope: required scope", http.MethodGet, "/scope/rules/scopes/a", true, http.StatusNoContent, `["a"]`, ``, "", ""},
+		{"by scope: required scope: insufficient permissions", http.MethodGet, "/scope/rules/scopes/b", true, http.StatusForbidden, ``, ``, `access control error: required scope "b" not granted`, "insufficient_permissions"},
+		{"by scope: condition met", http.MethodGet, "/scope/rules/tenant/t1", true, http.StatusNoContent, `["a"]`, ``, "", ""},
+		{"by scope: condition not met", http.MethodGet, "/scope/rules/tenant/t2", true, http.StatusForbidden, ``, ``, "access control error: permission condition not met", "insufficient_permissions"},
 		{"by scope: required permission by api only: insufficient permissions", http.MethodGet, "/scope/permission-from-api", true, http.StatusForbidden, ``, ``, `access control error: required permission "z" not granted`, "insufficient_permissions"},
 		{"by role: unauthorized", http.MethodGet, "/role/foo", false, http.StatusUnauthorized, ``, ``, "access control error: roled_jwt: missing authorization header", "jwt_token_missing"},
 		{"by role: sufficient permission", http.MethodGet, "/role/foo", true, http.StatusNoContent, `["a","b"]`, ``, "", ""},
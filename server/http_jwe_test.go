@@ -0,0 +1,59 @@
+package server_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestJWE_SessionCookie(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	shutdown, _ := newCouper("testdata/jwe/01_couper.hcl", helper)
+	defer shutdown()
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost:8080/login", nil)
+	helper.Must(err)
+
+	res, err := client.Do(req)
+	helper.Must(err)
+
+	cookies := res.Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "session" {
+		t.Fatalf("expected session cookie, got: %v", cookies)
+	}
+	if parts := strings.Split(cookies[0].Value, "."); len(parts) != 5 {
+		t.Fatalf("expected encrypted token, got: %q", cookies[0].Value)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		cookie    string
+		expStatus int
+		expSub    string
+	}{
+		{"encrypted session", cookies[0].Value, http.StatusNoContent, "alice"},
+		{"tampered session", cookies[0].Value[:len(cookies[0].Value)-4] + "AAAA", http.StatusUnauthorized, ""},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+
+			req, err = http.NewRequest(http.MethodGet, "http://localhost:8080/profile", nil)
+			h.Must(err)
+			req.AddCookie(&http.Cookie{Name: "session", Value: tc.cookie})
+
+			res, err = client.Do(req)
+			h.Must(err)
+
+			if res.StatusCode != tc.expStatus {
+				st.Errorf("expected status %d, got: %d", tc.expStatus, res.StatusCode)
+			}
+			if sub := res.Header.Get("X-Sub"); sub != tc.expSub {
+				st.Errorf("expected sub %q, got: %q", tc.expSub, sub)
+			}
+		})
+	}
+}
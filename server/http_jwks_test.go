@@ -0,0 +1,115 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/coupergateway/couper/accesscontrol/jwk"
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/runtime"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestJWKS_SigningKeys(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+
+	shutdown, _ := newCouper("testdata/jwks/01_couper.hcl", helper)
+	defer shutdown()
+
+	res, err := client.Get("http://couper.example:8080/.well-known/jwks.json")
+	helper.Must(err)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got: %d", res.StatusCode)
+	}
+	jwksData := &jwk.JWKSData{}
+	helper.Must(json.NewDecoder(res.Body).Decode(jwksData))
+	helper.Must(res.Body.Close())
+
+	keys := make(map[string]*jwk.JWK)
+	for _, key := range jwksData.Keys {
+		keys[key.KeyID] = key
+	}
+	if len(jwksData.Keys) != 2 || len(keys) != 2 {
+		t.Fatalf("expected two distinct keys, got: %#v", jwksData.Keys)
+	}
+	if key, exists := keys["rsa-2026"]; !exists || key.Algorithm != "RS256" || key.KeyType != "RSA" {
+		t.Errorf("expected RSA key with configured key_id, got: %#v", key)
+	}
+
+	for _, tc := range []struct {
+		profile string
+		expKid  interface{}
+	}{
+		{"rsa", "rsa-2026"},
+		{"rsa_other_claims", "rsa-2026"},
+		{"self_signed", nil},
+	} {
+		t.Run(tc.profile, func(subT *testing.T) {
+			h := test.New(subT)
+
+			tokenRes, terr := client.Get("http://couper.example:8080/token/" + tc.profile)
+			h.Must(terr)
+			tokenBytes, terr := io.ReadAll(tokenRes.Body)
+			h.Must(terr)
+			h.Must(tokenRes.Body.Close())
+
+			token, _, terr := jwt.NewParser().ParseUnverified(string(tokenBytes), jwt.MapClaims{})
+			h.Must(terr)
+			if token.Header["kid"] != tc.expKid {
+				subT.Errorf("want kid %v, got: %v", tc.expKid, token.Header["kid"])
+			}
+
+			if tc.expKid == nil {
+				return
+			}
+
+			req, terr := http.NewRequest(http.MethodGet, "http://couper.example:8080/protected", nil)
+			h.Must(terr)
+			req.Header.Set("Authorization", "Bearer "+string(tokenBytes))
+
+			protectedRes, terr := client.Do(req)
+			h.Must(terr)
+			h.Must(protectedRes.Body.Close())
+			if protectedRes.StatusCode != http.StatusOK {
+				subT.Errorf("expected the token to be verified with the published JWKS, got status: %d", protectedRes.StatusCode)
+			}
+		})
+	}
+}
+
+func TestJWKS_ConfigError(t *testing.T) {
+	helper := test.New(t)
+
+	conf, err := configload.LoadFile(filepath.Join(testWorkingDir, "testdata/jwks/02_couper.hcl"), "test")
+	helper.Must(err)
+
+	logger, _ := test.NewLogger()
+	log := logger.WithContext(context.TODO())
+
+	tmpStoreCh := make(chan struct{})
+	defer close(tmpStoreCh)
+
+	ctx, cancel := context.WithCancel(conf.Context)
+	conf.Context = ctx
+	defer cancel()
+
+	_, err = runtime.NewServerConfiguration(conf, log, cache.New(log, tmpStoreCh))
+	if err == nil {
+		t.Fatal("expected a configuration error")
+	}
+
+	expMsg := `configuration error: jwks_path: "two": key ID "shared" is already used for a different key`
+	if errMsg := err.(errors.GoError).LogError(); !strings.Contains(errMsg, expMsg) {
+		t.Errorf("expected error message containing %q, got: %q", expMsg, errMsg)
+	}
+}
@@ -0,0 +1,78 @@
+package server_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestHTTPMessageSignature_Backend(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	shutdown, hook := newCouper("testdata/http_message_signature/01_couper.hcl", helper)
+	defer shutdown()
+
+	for _, tc := range []struct {
+		name         string
+		path         string
+		header       http.Header
+		expStatus    int
+		expErrorType string
+	}{
+		{"signed backend request", "/sign/orders?id=1", nil, http.StatusOK, ""},
+		{"unsigned client request", "/verify/orders", nil, http.StatusUnauthorized, "http_message_signature_missing"},
+		{"foreign signature", "/verify/orders", http.Header{
+			"Signature-Input": []string{`sig1=("@method" "@path" "x-partner" "content-digest");created=1618884473;keyid="couper-key"`},
+			"Signature":       []string{`sig1=:dGVzdA==:`},
+			"X-Partner":       []string{"couper"},
+		}, http.StatusUnauthorized, "http_message_signature"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			hook.Reset()
+
+			req, err := http.NewRequest(http.MethodPost, "http://localhost:8080"+tc.path, strings.NewReader(`{"item":42}`))
+			helper.Must(err)
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+
+			res, err := client.Do(req)
+			helper.Must(err)
+			body, err := io.ReadAll(res.Body)
+			helper.Must(err)
+			_ = res.Body.Close()
+
+			if res.StatusCode != tc.expStatus {
+				st.Fatalf("expected status %d, got: %d", tc.expStatus, res.StatusCode)
+			}
+
+			var loggedType string
+			for _, entry := range hook.AllEntries() {
+				if errorType, ok := entry.Data["error_type"].(string); ok {
+					loggedType = errorType
+				}
+			}
+			if loggedType != tc.expErrorType {
+				st.Errorf("expected logged error_type %q, got: %q", tc.expErrorType, loggedType)
+			}
+
+			if tc.expStatus != http.StatusOK {
+				return
+			}
+
+			if keyID := res.Header.Get("X-Keyid"); keyID != "couper-key" {
+				st.Errorf("expected keyid, got: %q", keyID)
+			}
+			if components := res.Header.Get("X-Components"); components != "@method @path @query x-partner content-digest" {
+				st.Errorf("unexpected components: %q", components)
+			}
+			if string(body) != `{"item":42}` {
+				st.Errorf("unexpected body: %q", string(body))
+			}
+		})
+	}
+}
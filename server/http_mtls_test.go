@@ -1,8 +1,10 @@
 package server_test
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"strings"
@@ -311,3 +313,57 @@ func TestHTTPSServer_TLS_ServerBackendClient(t *testing.T) {
 		t.Errorf("Expected statusOK, got: %d", res.StatusCode)
 	}
 }
+
+func TestHTTPSServer_TLS_ClientCertificateAccessControl(t *testing.T) {
+	helper := test.New(t)
+
+	selfSigned, err := server.NewCertificate(time.Minute, nil, nil)
+	helper.Must(err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(selfSigned.CA.Leaf)
+	client := test.NewHTTPSClient(&tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{*selfSigned.Client},
+	})
+
+	sum := sha256.Sum256(selfSigned.Client.Leaf.Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	shutdown, _, err := newCouperWithTemplate("testdata/mtls/08_couper.hcl", helper, map[string]interface{}{
+		"publicKey":   string(selfSigned.ServerCertificate.Certificate),             // PEM
+		"privateKey":  string(selfSigned.ServerCertificate.PrivateKey),              // PEM
+		"clientCA":    string(selfSigned.ClientIntermediateCertificate.Certificate), // PEM
+		"fingerprint": fingerprint,
+	})
+	helper.Must(err)
+	defer shutdown()
+
+	outreq, err := http.NewRequest(http.MethodGet, "https://localhost:4443/", nil)
+	helper.Must(err)
+
+	res, err := client.Do(outreq)
+	helper.Must(err)
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected statusOK, got: %d", res.StatusCode)
+	}
+
+	if subject := res.Header.Get("X-Subject"); subject != "OU=Development,O=Couper,C=DE" {
+		t.Errorf("Expected certificate subject, got: %q", subject)
+	}
+
+	if fp := res.Header.Get("X-Fingerprint"); fp != fingerprint {
+		t.Errorf("Expected certificate fingerprint %q, got: %q", fingerprint, fp)
+	}
+
+	outreq, err = http.NewRequest(http.MethodGet, "https://localhost:4443/denied", nil)
+	helper.Must(err)
+
+	res, err = client.Do(outreq)
+	helper.Must(err)
+
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status forbidden, got: %d", res.StatusCode)
+	}
+}
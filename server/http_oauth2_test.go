@@ -399,6 +399,163 @@ func TestEndpoints_OAuth2_JWTBearer(t *testing.T) {
 	}
 }
 
+func TestEndpoints_OAuth2_TokenExchange(t *testing.T) {
+	helper := test.New(t)
+
+	var tokenRequests int32
+	oauthOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/token" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		atomic.AddInt32(&tokenRequests, 1)
+		helper.Must(req.ParseForm())
+
+		for k, v := range map[string]string{
+			"grant_type":           "urn:ietf:params:oauth:grant-type:token-exchange",
+			"subject_token_type":   "urn:ietf:params:oauth:token-type:access_token",
+			"audience":             "orders",
+			"requested_token_type": "urn:ietf:params:oauth:token-type:access_token",
+			"actor_token":          "",
+		} {
+			if got := req.PostForm.Get(k); got != v {
+				t.Errorf("%s: want %q, got: %q", k, v, got)
+			}
+		}
+		if user, pass, _ := req.BasicAuth(); user != "gateway" || pass != "secret" {
+			t.Errorf("unexpected client credentials: %q, %q", user, pass)
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		_, werr := fmt.Fprintf(rw, `{"access_token":"exchanged-%s","issued_token_type":"urn:ietf:params:oauth:token-type:access_token","token_type":"Bearer","expires_in":100}`, req.PostForm.Get("subject_token"))
+		helper.Must(werr)
+	}))
+	defer oauthOrigin.Close()
+
+	resourceOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Authorization", req.Header.Get("Authorization"))
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer resourceOrigin.Close()
+
+	shutdown, _, err := newCouperWithTemplate("testdata/oauth2/27_couper.hcl", helper, map[string]interface{}{"asOrigin": oauthOrigin.URL, "rsOrigin": resourceOrigin.URL})
+	helper.Must(err)
+	defer shutdown()
+
+	type testCase struct {
+		subjectToken string
+		expStatus    int
+		expAuth      string
+		expRequests  int32
+	}
+
+	for _, tc := range []testCase{
+		{"alice", http.StatusNoContent, "Bearer exchanged-alice", 1},
+		{"bob", http.StatusNoContent, "Bearer exchanged-bob", 2},
+		{"alice", http.StatusNoContent, "Bearer exchanged-alice", 2}, // cached
+		{"", http.StatusBadGateway, "", 2},
+	} {
+		req, rerr := http.NewRequest(http.MethodGet, "http://anyserver:8080/", nil)
+		helper.Must(rerr)
+		if tc.subjectToken != "" {
+			req.Header.Set("X-Token", tc.subjectToken)
+		}
+
+		res, rerr := newClient().Do(req)
+		helper.Must(rerr)
+
+		if res.StatusCode != tc.expStatus {
+			t.Errorf("%q: expected status %d, got: %d", tc.subjectToken, tc.expStatus, res.StatusCode)
+		}
+		if auth := res.Header.Get("X-Authorization"); auth != tc.expAuth {
+			t.Errorf("%q: expected Authorization %q, got: %q", tc.subjectToken, tc.expAuth, auth)
+		}
+		if n := atomic.LoadInt32(&tokenRequests); n != tc.expRequests {
+			t.Errorf("%q: expected %d token requests, got: %d", tc.subjectToken, tc.expRequests, n)
+		}
+	}
+}
+
+func TestEndpoints_OAuth2_TokenExchange_Concurrent(t *testing.T) {
+	helper := test.New(t)
+
+	var slowRequests int32
+	slowReceived := make(chan struct{})
+	releaseSlow := make(chan struct{})
+	oauthOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		helper.Must(req.ParseForm())
+
+		subjectToken := req.PostForm.Get("subject_token")
+		if subjectToken == "slow" {
+			if atomic.AddInt32(&slowRequests, 1) == 1 {
+				close(slowReceived)
+			}
+			<-releaseSlow
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		_, werr := fmt.Fprintf(rw, `{"access_token":"exchanged-%s","token_type":"Bearer","expires_in":100}`, subjectToken)
+		helper.Must(werr)
+	}))
+	defer oauthOrigin.Close()
+
+	resourceOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Authorization", req.Header.Get("Authorization"))
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer resourceOrigin.Close()
+
+	shutdown, _, err := newCouperWithTemplate("testdata/oauth2/27_couper.hcl", helper, map[string]interface{}{"asOrigin": oauthOrigin.URL, "rsOrigin": resourceOrigin.URL})
+	helper.Must(err)
+	defer shutdown()
+
+	do := func(subjectToken string) string {
+		req, rerr := http.NewRequest(http.MethodGet, "http://anyserver:8080/", nil)
+		helper.Must(rerr)
+		req.Header.Set("X-Token", subjectToken)
+
+		client := newClient()
+		client.Timeout = 5 * time.Second
+		res, rerr := client.Do(req)
+		if rerr != nil {
+			t.Errorf("%q: %v", subjectToken, rerr)
+			return ""
+		}
+		return res.Header.Get("X-Authorization")
+	}
+
+	var wg sync.WaitGroup
+	slowAuth := make([]string, 3)
+	for i := range slowAuth {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			slowAuth[i] = do("slow")
+		}(i)
+		if i == 0 {
+			<-slowReceived
+		}
+	}
+
+	// a pending exchange of another subject token must not block this one
+	if auth := do("fast"); auth != "Bearer exchanged-fast" {
+		t.Errorf("expected Authorization %q, got: %q", "Bearer exchanged-fast", auth)
+	}
+
+	close(releaseSlow)
+	wg.Wait()
+
+	for i, auth := range slowAuth {
+		if auth != "Bearer exchanged-slow" {
+			t.Errorf("slow request %d: expected Authorization %q, got: %q", i, "Bearer exchanged-slow", auth)
+		}
+	}
+	if n := atomic.LoadInt32(&slowRequests); n != 1 {
+		t.Errorf("expected 1 token request for the slow subject token, got: %d", n)
+	}
+}
+
 func TestOAuth2_Config_Errors(t *testing.T) {
 	log, _ := test.NewLogger()
 
@@ -588,6 +745,57 @@ definitions {
 `,
 			"configuration error: be: missing assertion attribute or jwt_signing_profile block with grant_type=urn:ietf:params:oauth:grant-type:jwt-bearer",
 		},
+		{
+			"missing subject_token with grant_type token-exchange",
+			`server {}
+definitions {
+  backend "be" {
+    oauth2 {
+      token_endpoint = "https://authorization.server/token"
+      client_id      = "my_client"
+      client_secret  = "my_client_secret"
+      grant_type     = "urn:ietf:params:oauth:grant-type:token-exchange"
+    }
+  }
+}
+`,
+			"configuration error: be: missing subject_token attribute with grant_type=urn:ietf:params:oauth:grant-type:token-exchange",
+		},
+		{
+			"actor_token_type without actor_token",
+			`server {}
+definitions {
+  backend "be" {
+    oauth2 {
+      token_endpoint   = "https://authorization.server/token"
+      client_id        = "my_client"
+      client_secret    = "my_client_secret"
+      grant_type       = "urn:ietf:params:oauth:grant-type:token-exchange"
+      subject_token    = request.headers.x-token
+      actor_token_type = "urn:ietf:params:oauth:token-type:jwt"
+    }
+  }
+}
+`,
+			"configuration error: be: actor_token_type attribute must not be set without actor_token attribute",
+		},
+		{
+			"audience with grant_type client_credentials",
+			`server {}
+definitions {
+  backend "be" {
+    oauth2 {
+      token_endpoint = "https://authorization.server/token"
+      client_id      = "my_client"
+      client_secret  = "my_client_secret"
+      grant_type     = "client_credentials"
+      audience       = "api"
+    }
+  }
+}
+`,
+			"configuration error: be: audience attribute must not be set with grant_type=client_credentials",
+		},
 
 		{
 			"unsupported token_endpoint_auth_method",
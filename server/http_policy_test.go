@@ -0,0 +1,58 @@
+package server_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestPolicy_AccessControl(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	shutdown, hook := newCouper("testdata/policy/01_couper.hcl", helper)
+	defer shutdown()
+
+	for _, tc := range []struct {
+		name         string
+		method       string
+		path         string
+		key          string
+		expStatus    int
+		expErrorType string
+	}{
+		{"member read", http.MethodGet, "/tenants/acme/orders", "acme-secret", http.StatusNoContent, ""},
+		{"member write", http.MethodPost, "/tenants/acme/orders", "acme-secret", http.StatusForbidden, "beta_policy_denied"},
+		{"other tenant", http.MethodGet, "/tenants/globex/orders", "acme-secret", http.StatusForbidden, "beta_policy_denied"},
+		{"admin write", http.MethodPost, "/tenants/globex/orders", "globex-secret", http.StatusNoContent, ""},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			hook.Reset()
+
+			req, err := http.NewRequest(tc.method, "http://localhost:8080"+tc.path, nil)
+			helper.Must(err)
+			req.Header.Set("X-API-Key", tc.key)
+
+			res, err := client.Do(req)
+			helper.Must(err)
+			_, _ = io.Copy(io.Discard, res.Body)
+			_ = res.Body.Close()
+
+			if res.StatusCode != tc.expStatus {
+				st.Errorf("expected status %d, got: %d", tc.expStatus, res.StatusCode)
+			}
+
+			var loggedType string
+			for _, entry := range hook.AllEntries() {
+				if errorType, ok := entry.Data["error_type"].(string); ok {
+					loggedType = errorType
+				}
+			}
+			if loggedType != tc.expErrorType {
+				st.Errorf("expected logged error_type %q, got: %q", tc.expErrorType, loggedType)
+			}
+		})
+	}
+}
@@ -0,0 +1,425 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/coupergateway/couper/eval/lib"
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestSession_AccessControl(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	shutdown, hook := newCouper("testdata/session/01_couper.hcl", helper)
+	defer shutdown()
+
+	do := func(path, sid string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, "http://localhost:8080"+path, nil)
+		helper.Must(err)
+		if sid != "" {
+			req.AddCookie(&http.Cookie{Name: "sid", Value: sid})
+		}
+
+		res, err := client.Do(req)
+		helper.Must(err)
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		return res
+	}
+
+	sessionID := func(res *http.Response) string {
+		for _, c := range res.Cookies() {
+			if c.Name == "sid" {
+				return c.Value
+			}
+		}
+		return ""
+	}
+
+	loggedErrorType := func() string {
+		var loggedType string
+		for _, entry := range hook.AllEntries() {
+			if errorType, ok := entry.Data["error_type"].(string); ok {
+				loggedType = errorType
+			}
+		}
+		return loggedType
+	}
+
+	res := do("/app", "")
+	if res.StatusCode != http.StatusUnauthorized || loggedErrorType() != "session_missing" {
+		t.Fatalf("expected status 401 with session_missing, got: %d %q", res.StatusCode, loggedErrorType())
+	}
+
+	hook.Reset()
+	res = do("/app", strings.Repeat("a", 43))
+	if res.StatusCode != http.StatusUnauthorized || loggedErrorType() != "session" {
+		t.Fatalf("expected status 401 with session error, got: %d %q", res.StatusCode, loggedErrorType())
+	}
+
+	res = do("/login?sub=alice", "")
+	first := sessionID(res)
+	if first == "" {
+		t.Fatal("expected session cookie")
+	}
+	if setCookie := res.Header.Get("Set-Cookie"); !strings.Contains(setCookie, "HttpOnly") || !strings.Contains(setCookie, "SameSite=Lax") {
+		t.Errorf("unexpected Set-Cookie: %q", setCookie)
+	}
+
+	res = do("/app", first)
+	if res.StatusCode != http.StatusOK || res.Header.Get("X-Sub") != "alice" {
+		t.Fatalf("expected status 200 for alice, got: %d %q", res.StatusCode, res.Header.Get("X-Sub"))
+	}
+
+	// login with an existing session rotates the session ID
+	res = do("/login?sub=bob", first)
+	second := sessionID(res)
+	if second == "" || second == first {
+		t.Fatalf("expected new session ID, got: %q", second)
+	}
+
+	if res = do("/app", first); res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected rotated session to be rejected, got: %d", res.StatusCode)
+	}
+
+	res = do("/app", second)
+	if res.StatusCode != http.StatusOK || res.Header.Get("X-Sub") != "bob" {
+		t.Fatalf("expected status 200 for bob, got: %d %q", res.StatusCode, res.Header.Get("X-Sub"))
+	}
+
+	res = do("/logout", second)
+	if setCookie := res.Header.Get("Set-Cookie"); !strings.Contains(setCookie, "Max-Age=0") {
+		t.Errorf("expected expiring cookie, got: %q", setCookie)
+	}
+
+	if res = do("/app", second); res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected destroyed session to be rejected, got: %d", res.StatusCode)
+	}
+
+	// a session_write() evaluated twice creates one session
+	res = do("/login-twice?sub=carol", "")
+	third := sessionID(res)
+	if third == "" || res.Header.Get("X-Set-Cookie") != res.Header.Get("Set-Cookie") {
+		t.Fatalf("expected the same session cookie, got: %q and %q", res.Header.Get("Set-Cookie"), res.Header.Get("X-Set-Cookie"))
+	}
+
+	res = do("/app", third)
+	if res.StatusCode != http.StatusOK || res.Header.Get("X-Sub") != "carol" {
+		t.Fatalf("expected status 200 for carol, got: %d %q", res.StatusCode, res.Header.Get("X-Sub"))
+	}
+}
+
+func TestSession_OIDCTokenRefresh(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	keyBytes, err := os.ReadFile("testdata/integration/files/pkcs8.key")
+	helper.Must(err)
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(keyBytes)
+	helper.Must(err)
+
+	var refreshes int32
+	asOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+
+		switch req.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(rw).Encode(map[string]string{
+				"issuer":                 "https://authorization.server",
+				"authorization_endpoint": "https://authorization.server/oauth2/authorize",
+				"token_endpoint":         "http://" + req.Host + "/token",
+				"jwks_uri":               "http://" + req.Host + "/jwks",
+			})
+		case "/jwks":
+			jsonBytes, rerr := os.ReadFile("testdata/integration/files/jwks.json")
+			helper.Must(rerr)
+			_, _ = bytes.NewBuffer(jsonBytes).WriteTo(rw)
+		case "/token":
+			helper.Must(req.ParseForm())
+			idToken, cerr := lib.CreateJWT("RS256", key, jwt.MapClaims{
+				"iss": "https://authorization.server",
+				"aud": "foo",
+				"sub": "alice",
+				"exp": 4000000000,
+				"iat": 1000,
+			}, map[string]interface{}{"kid": "rs256"})
+			helper.Must(cerr)
+
+			var tokenResponse map[string]interface{}
+			switch req.PostForm.Get("grant_type") {
+			case "authorization_code":
+				// expires within the refresh leeway
+				tokenResponse = map[string]interface{}{
+					"access_token":  "at-1",
+					"refresh_token": "rt-1",
+					"expires_in":    1,
+					"id_token":      idToken,
+				}
+			case "refresh_token":
+				if req.PostForm.Get("refresh_token") != "rt-1" {
+					rw.WriteHeader(http.StatusBadRequest)
+					_, _ = rw.Write([]byte(`{"error":"invalid_grant"}`))
+					return
+				}
+				atomic.AddInt32(&refreshes, 1)
+				tokenResponse = map[string]interface{}{
+					"access_token": "at-2",
+					"expires_in":   3600,
+					"id_token":     idToken,
+				}
+			}
+			_ = json.NewEncoder(rw).Encode(tokenResponse)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer asOrigin.Close()
+
+	shutdown, _, err := newCouperWithTemplate("testdata/session/02_couper.hcl", helper, map[string]interface{}{"asOrigin": asOrigin.URL})
+	helper.Must(err)
+	defer shutdown()
+
+	time.Sleep(time.Second) // wait for oidc/jwks inits
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost:8080/callback?code=qeuboub", nil)
+	helper.Must(err)
+	req.AddCookie(&http.Cookie{Name: "pkcecv", Value: "qerbnr"})
+
+	res, err := client.Do(req)
+	helper.Must(err)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected status 204, got: %d", res.StatusCode)
+	}
+
+	var sid string
+	for _, c := range res.Cookies() {
+		if c.Name == "sid" {
+			sid = c.Value
+		}
+	}
+	if sid == "" {
+		t.Fatal("expected session cookie")
+	}
+
+	for i := 0; i < 2; i++ {
+		req, err = http.NewRequest(http.MethodGet, "http://localhost:8080/api", nil)
+		helper.Must(err)
+		req.AddCookie(&http.Cookie{Name: "sid", Value: sid})
+
+		res, err = client.Do(req)
+		helper.Must(err)
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: expected status 200, got: %d", i, res.StatusCode)
+		}
+		if sub := res.Header.Get("X-Sub"); sub != "alice" {
+			t.Errorf("request %d: expected sub %q, got: %q", i, "alice", sub)
+		}
+		if token := res.Header.Get("X-Token"); token != "at-2" {
+			t.Errorf("request %d: expected refreshed access token, got: %q", i, token)
+		}
+	}
+
+	if n := atomic.LoadInt32(&refreshes); n != 1 {
+		t.Errorf("expected one refresh, got: %d", n)
+	}
+}
+
+func TestSession_OIDCLogout(t *testing.T) {
+	client := newClient()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	helper := test.New(t)
+
+	keyBytes, err := os.ReadFile("testdata/integration/files/pkcs8.key")
+	helper.Must(err)
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(keyBytes)
+	helper.Must(err)
+
+	newToken := func(claims jwt.MapClaims) string {
+		claims["iss"] = "https://authorization.server"
+		claims["aud"] = "foo"
+		claims["iat"] = 1000
+		token, cerr := lib.CreateJWT("RS256", key, claims, map[string]interface{}{"kid": "rs256"})
+		helper.Must(cerr)
+		return token
+	}
+
+	idToken := newToken(jwt.MapClaims{"sub": "alice", "sid": "op-sid-1", "exp": 4000000000})
+
+	asOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+
+		switch req.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(rw).Encode(map[string]string{
+				"issuer":                 "https://authorization.server",
+				"authorization_endpoint": "https://authorization.server/oauth2/authorize",
+				"end_session_endpoint":   "https://authorization.server/logout",
+				"token_endpoint":         "http://" + req.Host + "/token",
+				"jwks_uri":               "http://" + req.Host + "/jwks",
+			})
+		case "/jwks":
+			jsonBytes, rerr := os.ReadFile("testdata/integration/files/jwks.json")
+			helper.Must(rerr)
+			_, _ = bytes.NewBuffer(jsonBytes).WriteTo(rw)
+		case "/token":
+			_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+				"access_token": "at-1",
+				"expires_in":   3600,
+				"id_token":     idToken,
+			})
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer asOrigin.Close()
+
+	shutdown, _, err := newCouperWithTemplate("testdata/session/03_couper.hcl", helper, map[string]interface{}{"asOrigin": asOrigin.URL})
+	helper.Must(err)
+	defer shutdown()
+
+	time.Sleep(time.Second) // wait for oidc/jwks inits
+
+	login := func() string {
+		req, rerr := http.NewRequest(http.MethodGet, "http://localhost:8080/callback?code=qeuboub", nil)
+		helper.Must(rerr)
+		req.AddCookie(&http.Cookie{Name: "pkcecv", Value: "qerbnr"})
+
+		res, rerr := client.Do(req)
+		helper.Must(rerr)
+		if res.StatusCode != http.StatusNoContent {
+			t.Fatalf("expected status 204, got: %d", res.StatusCode)
+		}
+		for _, c := range res.Cookies() {
+			if c.Name == "sid" {
+				return c.Value
+			}
+		}
+		t.Fatal("expected session cookie")
+		return ""
+	}
+
+	get := func(path, sid string) *http.Response {
+		req, rerr := http.NewRequest(http.MethodGet, "http://localhost:8080"+path, nil)
+		helper.Must(rerr)
+		req.AddCookie(&http.Cookie{Name: "sid", Value: sid})
+		res, rerr := client.Do(req)
+		helper.Must(rerr)
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		return res
+	}
+
+	backchannelLogout := func(logoutToken string) *http.Response {
+		body := url.Values{"logout_token": {logoutToken}}.Encode()
+		req, rerr := http.NewRequest(http.MethodPost, "http://localhost:8080/backchannel-logout", strings.NewReader(body))
+		helper.Must(rerr)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		res, rerr := client.Do(req)
+		helper.Must(rerr)
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		return res
+	}
+
+	t.Run("RP-initiated logout", func(st *testing.T) {
+		sid := login()
+
+		res := get("/logout", sid)
+		if res.StatusCode != http.StatusSeeOther {
+			st.Fatalf("expected status 303, got: %d", res.StatusCode)
+		}
+
+		location, perr := url.Parse(res.Header.Get("Location"))
+		helper.Must(perr)
+		if location.Host != "authorization.server" || location.Path != "/logout" {
+			st.Errorf("expected end_session_endpoint, got: %q", location.String())
+		}
+		query := location.Query()
+		if v := query.Get("client_id"); v != "foo" {
+			st.Errorf("expected client_id %q, got: %q", "foo", v)
+		}
+		if v := query.Get("id_token_hint"); v != idToken {
+			st.Errorf("expected id_token_hint %q, got: %q", idToken, v)
+		}
+		if v := query.Get("post_logout_redirect_uri"); v != "http://localhost:8080/logged-out" {
+			st.Errorf("expected absolute post_logout_redirect_uri, got: %q", v)
+		}
+
+		if res = get("/api", sid); res.StatusCode != http.StatusUnauthorized {
+			st.Errorf("expected destroyed session, got status: %d", res.StatusCode)
+		}
+	})
+
+	t.Run("back-channel logout", func(st *testing.T) {
+		sid := login()
+
+		events := map[string]interface{}{"http://schemas.openid.net/event/backchannel-logout": map[string]interface{}{}}
+
+		invalid := []jwt.MapClaims{
+			{"sid": "op-sid-1", "jti": "1"},                                      // missing events
+			{"sid": "op-sid-1", "events": events},                                // missing jti
+			{"jti": "2", "events": events},                                       // missing sub and sid
+			{"sid": "op-sid-1", "jti": "3", "events": events, "nonce": "qerbnr"}, // nonce not allowed
+		}
+		for i, claims := range invalid {
+			if res := backchannelLogout(newToken(claims)); res.StatusCode != http.StatusBadRequest {
+				st.Errorf("logout token %d: expected status 400, got: %d", i, res.StatusCode)
+			}
+		}
+
+		if res := get("/api", sid); res.StatusCode != http.StatusNoContent {
+			st.Fatalf("expected valid session, got status: %d", res.StatusCode)
+		}
+
+		// other OP session
+		if res := backchannelLogout(newToken(jwt.MapClaims{"sid": "op-sid-2", "jti": "4", "events": events})); res.StatusCode != http.StatusOK {
+			st.Fatalf("expected status 200, got: %d", res.StatusCode)
+		}
+		if res := get("/api", sid); res.StatusCode != http.StatusNoContent {
+			st.Fatalf("expected valid session, got status: %d", res.StatusCode)
+		}
+
+		res := backchannelLogout(newToken(jwt.MapClaims{"sub": "alice", "sid": "op-sid-1", "jti": "5", "events": events}))
+		if res.StatusCode != http.StatusOK {
+			st.Fatalf("expected status 200, got: %d", res.StatusCode)
+		}
+		if cc := res.Header.Get("Cache-Control"); cc != "no-store" {
+			st.Errorf("expected cache-control no-store, got: %q", cc)
+		}
+
+		if res = get("/api", sid); res.StatusCode != http.StatusUnauthorized {
+			st.Errorf("expected invalidated session, got status: %d", res.StatusCode)
+		}
+
+		// replayed jti
+		sid = login()
+		if res = backchannelLogout(newToken(jwt.MapClaims{"sub": "alice", "sid": "op-sid-1", "jti": "5", "events": events})); res.StatusCode != http.StatusBadRequest {
+			st.Errorf("replayed logout token: expected status 400, got: %d", res.StatusCode)
+		}
+		if res = get("/api", sid); res.StatusCode != http.StatusNoContent {
+			st.Errorf("expected valid session, got status: %d", res.StatusCode)
+		}
+
+		if res = get("/backchannel-logout", sid); res.StatusCode != http.StatusMethodNotAllowed {
+			st.Errorf("expected status 405, got: %d", res.StatusCode)
+		}
+	})
+}
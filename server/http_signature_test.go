@@ -0,0 +1,118 @@
+package server_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestSignature_AccessControl(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	shutdown, hook := newCouper("testdata/signature/01_couper.hcl", helper)
+	defer shutdown()
+
+	sign := func(secret, payload string) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(payload))
+		return mac.Sum(nil)
+	}
+
+	body := `{"action":"opened"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	past := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	for _, tc := range []struct {
+		name         string
+		path         string
+		header       http.Header
+		expStatus    int
+		expErrorType string
+	}{
+		{"missing signature", "/github", http.Header{}, http.StatusUnauthorized, "signature_missing"},
+		{"missing prefix", "/github", http.Header{
+			"X-Hub-Signature-256": []string{hex.EncodeToString(sign("gh-secret", body))},
+		}, http.StatusUnauthorized, "signature"},
+		{"wrong secret", "/github", http.Header{
+			"X-Hub-Signature-256": []string{"sha256=" + hex.EncodeToString(sign("other", body))},
+		}, http.StatusUnauthorized, "signature"},
+		{"valid body signature", "/github", http.Header{
+			"X-Hub-Signature-256": []string{"sha256=" + hex.EncodeToString(sign("gh-secret", body))},
+		}, http.StatusOK, ""},
+		{"missing timestamp", "/payments", http.Header{
+			"X-Event-Id":  []string{"evt-1"},
+			"X-Signature": []string{base64.StdEncoding.EncodeToString(sign("pay-secret", now+".evt-1."+body))},
+		}, http.StatusUnauthorized, "signature"},
+		{"expired timestamp", "/payments", http.Header{
+			"X-Event-Id":  []string{"evt-1"},
+			"X-Signature": []string{base64.StdEncoding.EncodeToString(sign("pay-secret", past+".evt-1."+body))},
+			"X-Timestamp": []string{past},
+		}, http.StatusUnauthorized, "signature"},
+		{"tampered header", "/payments", http.Header{
+			"X-Event-Id":  []string{"evt-2"},
+			"X-Signature": []string{base64.StdEncoding.EncodeToString(sign("pay-secret", now+".evt-1."+body))},
+			"X-Timestamp": []string{now},
+		}, http.StatusUnauthorized, "signature"},
+		{"valid components signature", "/payments", http.Header{
+			"X-Event-Id":  []string{"evt-1"},
+			"X-Signature": []string{base64.StdEncoding.EncodeToString(sign("pay-secret", now+".evt-1."+body))},
+			"X-Timestamp": []string{now},
+		}, http.StatusOK, ""},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			hook.Reset()
+
+			req, err := http.NewRequest(http.MethodPost, "http://localhost:8080"+tc.path, strings.NewReader(body))
+			helper.Must(err)
+			req.Header = tc.header
+
+			res, err := client.Do(req)
+			helper.Must(err)
+			resBytes, err := io.ReadAll(res.Body)
+			helper.Must(err)
+			_ = res.Body.Close()
+
+			if res.StatusCode != tc.expStatus {
+				st.Errorf("expected status %d, got: %d", tc.expStatus, res.StatusCode)
+			}
+
+			var loggedType string
+			for _, entry := range hook.AllEntries() {
+				if errorType, ok := entry.Data["error_type"].(string); ok {
+					loggedType = errorType
+				}
+			}
+			if loggedType != tc.expErrorType {
+				st.Errorf("expected logged error_type %q, got: %q", tc.expErrorType, loggedType)
+			}
+
+			if res.StatusCode != http.StatusOK {
+				return
+			}
+
+			switch tc.path {
+			case "/github": // the verified body must still be proxied
+				var anything struct{ Body string }
+				helper.Must(json.Unmarshal(resBytes, &anything))
+				if anything.Body != body {
+					st.Errorf("expected proxied body %q, got: %q", body, anything.Body)
+				}
+			case "/payments":
+				if ts := res.Header.Get("X-Timestamp"); ts != now {
+					st.Errorf("expected timestamp %q in context, got: %q", now, ts)
+				}
+			}
+		})
+	}
+}
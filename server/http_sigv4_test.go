@@ -0,0 +1,154 @@
+package server_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestBackend_SigV4(t *testing.T) {
+	helper := test.New(t)
+
+	// minio acts as a minimal S3 compatible origin which verifies the signature independently
+	minio := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if err := verifySigV4(r, "couper", "minio-secret", "eu-central-1", "s3"); err != "" {
+			rw.Header().Set("X-Error", err)
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+		rw.Header().Set("X-Path", r.URL.Path)
+		rw.Header().Set("X-Owner", r.Header.Get("X-Amz-Meta-Owner"))
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer minio.Close()
+
+	shutdown, _, err := newCouperWithTemplate("testdata/sigv4/01_couper.hcl", helper, map[string]interface{}{
+		"origin": minio.URL,
+	})
+	helper.Must(err)
+	defer shutdown()
+
+	client := newClient()
+
+	for _, tc := range []struct {
+		name      string
+		method    string
+		path      string
+		body      string
+		expStatus int
+		expPath   string
+	}{
+		{"get object", http.MethodGet, "/objects/reports/2024 q1.csv?versionId=1&acl", "", http.StatusOK, "/bucket/reports/2024 q1.csv"},
+		{"put object", http.MethodPut, "/objects/data.json", `{"id":1}`, http.StatusOK, "/bucket/data.json"},
+		{"wrong secret", http.MethodGet, "/invalid/data.json", "", http.StatusForbidden, ""},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+
+			req, err := http.NewRequest(tc.method, "http://localhost:8080"+tc.path, strings.NewReader(tc.body))
+			h.Must(err)
+			req.Header.Set("Authorization", "Basic Zm9vOmJhcg==")
+
+			res, err := client.Do(req)
+			h.Must(err)
+			_, _ = io.Copy(io.Discard, res.Body)
+			_ = res.Body.Close()
+
+			if res.StatusCode != tc.expStatus {
+				st.Fatalf("expected status %d, got: %d (%s)", tc.expStatus, res.StatusCode, res.Header.Get("X-Error"))
+			}
+
+			if tc.expStatus != http.StatusOK {
+				return
+			}
+
+			if p := res.Header.Get("X-Path"); p != tc.expPath {
+				st.Errorf("expected path %q, got: %q", tc.expPath, p)
+			}
+			if o := res.Header.Get("X-Owner"); o != "couper" {
+				st.Errorf("expected signed x-amz-meta-owner header, got: %q", o)
+			}
+		})
+	}
+}
+
+func verifySigV4(r *http.Request, accessKeyID, secret, region, service string) string {
+	const prefix = "AWS4-HMAC-SHA256 "
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, prefix) {
+		return "missing signature"
+	}
+
+	params := map[string]string{}
+	for _, p := range strings.Split(strings.TrimPrefix(authorization, prefix), ", ") {
+		k, v, _ := strings.Cut(p, "=")
+		params[k] = v
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	if len(amzDate) != 16 {
+		return "invalid x-amz-date"
+	}
+	scope := amzDate[:8] + "/" + region + "/" + service + "/aws4_request"
+	if params["Credential"] != accessKeyID+"/"+scope {
+		return "invalid credential"
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	if r.Header.Get("X-Amz-Content-Sha256") != payloadHash {
+		return "payload hash mismatch"
+	}
+
+	signedHeaders := strings.Split(params["SignedHeaders"], ";")
+	var canonicalHeaders string
+	for _, name := range signedHeaders {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders += name + ":" + strings.TrimSpace(value) + "\n"
+	}
+
+	query := r.URL.Query()
+	var queryParams []string
+	for k, values := range query {
+		for _, v := range values {
+			queryParams = append(queryParams, url.QueryEscape(k)+"="+strings.ReplaceAll(url.QueryEscape(v), "+", "%20"))
+		}
+	}
+	sort.Strings(queryParams)
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		strings.ReplaceAll((&url.URL{Path: r.URL.Path}).EscapedPath(), "+", "%2B"),
+		strings.Join(queryParams, "&"),
+		canonicalHeaders,
+		params["SignedHeaders"],
+		payloadHash,
+	}, "\n")
+
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	mac := func(key []byte, data string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+	key := mac(mac(mac(mac([]byte("AWS4"+secret), amzDate[:8]), region), service), "aws4_request")
+	if hex.EncodeToString(mac(key, stringToSign)) != params["Signature"] {
+		return "signature mismatch"
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package server_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestTenants_Routing(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	shutdown, hook := newCouper("testdata/tenants/01_couper.hcl", helper)
+	defer shutdown()
+
+	newToken := func(tenant string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"tenant": tenant}).SignedString([]byte("asdf"))
+		helper.Must(err)
+		return token
+	}
+
+	for _, tc := range []struct {
+		name          string
+		host          string
+		path          string
+		tenant        string
+		expStatus     int
+		expAuthHeader string
+		expTenant     map[string]interface{}
+		expErrorType  string
+	}{
+		{"acme", "acme.example.com", "/orders", "acme", http.StatusOK, "Bearer acme-secret", nil, ""},
+		{"globex", "globex.example.com", "/orders", "globex", http.StatusOK, "Bearer globex-secret", nil, ""},
+		{"tenant from file", "initech.example.com", "/tenant", "initech", http.StatusOK, "",
+			map[string]interface{}{"id": "initech", "name": "Initech", "region": "eu"}, ""},
+		{"claim of other tenant", "acme.example.com", "/orders", "globex", http.StatusNotFound, "", nil, "beta_tenant"},
+		{"unknown tenant", "umbrella.example.com", "/orders", "umbrella", http.StatusNotFound, "", nil, "beta_tenant"},
+		{"host mismatch", "acme.example.org", "/orders", "acme", http.StatusNotFound, "", nil, "beta_tenant"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			hook.Reset()
+
+			req, err := http.NewRequest(http.MethodGet, "http://"+tc.host+":8080"+tc.path, nil)
+			h.Must(err)
+			req.Header.Set("Authorization", "Bearer "+newToken(tc.tenant))
+
+			res, err := client.Do(req)
+			h.Must(err)
+			body, err := io.ReadAll(res.Body)
+			h.Must(err)
+			_ = res.Body.Close()
+
+			if res.StatusCode != tc.expStatus {
+				st.Fatalf("expected status %d, got: %d", tc.expStatus, res.StatusCode)
+			}
+
+			if tc.expAuthHeader != "" {
+				var anything struct{ Headers http.Header }
+				h.Must(json.Unmarshal(body, &anything))
+				if got := anything.Headers.Get("Authorization"); got != tc.expAuthHeader {
+					st.Errorf("expected backend authorization %q, got: %q", tc.expAuthHeader, got)
+				}
+			}
+
+			if tc.expTenant != nil {
+				var tenant map[string]interface{}
+				h.Must(json.Unmarshal(body, &tenant))
+				if len(tenant) != len(tc.expTenant) {
+					st.Errorf("expected tenant %v, got: %v", tc.expTenant, tenant)
+				}
+				for k, v := range tc.expTenant {
+					if tenant[k] != v {
+						st.Errorf("expected tenant %v, got: %v", tc.expTenant, tenant)
+					}
+				}
+			}
+
+			var loggedType string
+			for _, entry := range hook.AllEntries() {
+				if errorType, ok := entry.Data["error_type"].(string); ok {
+					loggedType = errorType
+				}
+			}
+			if loggedType != tc.expErrorType {
+				st.Errorf("expected logged error_type %q, got: %q", tc.expErrorType, loggedType)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package server_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/coupergateway/couper/accesscontrol/jwk"
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestTokenEndpoint(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+
+	shutdown, _ := newCouper("testdata/token_endpoint/01_couper.hcl", helper)
+	defer shutdown()
+
+	const tokenURL = "http://couper.example:8080/oauth2/token"
+
+	getJSON := func(path string) map[string]interface{} {
+		res, err := client.Get("http://couper.example:8080" + path)
+		helper.Must(err)
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got: %d", path, res.StatusCode)
+		}
+
+		var data map[string]interface{}
+		helper.Must(json.NewDecoder(res.Body).Decode(&data))
+		return data
+	}
+
+	metadata := getJSON("/.well-known/oauth-authorization-server")
+	for k, v := range map[string]string{
+		"issuer":         "https://auth.example.com",
+		"token_endpoint": "https://auth.example.com/oauth2/token",
+		"jwks_uri":       "https://auth.example.com/.well-known/jwks.json",
+	} {
+		if metadata[k] != v {
+			t.Errorf("metadata %s: want %q, got: %v", k, v, metadata[k])
+		}
+	}
+
+	res, err := client.Get("http://couper.example:8080/.well-known/jwks.json")
+	helper.Must(err)
+	jwksData := &jwk.JWKSData{}
+	helper.Must(json.NewDecoder(res.Body).Decode(jwksData))
+	helper.Must(res.Body.Close())
+	if len(jwksData.Keys) != 1 || jwksData.Keys[0].Key == nil {
+		t.Fatalf("expected one public key, got: %#v", jwksData.Keys)
+	}
+	signingKey := jwksData.Keys[0]
+
+	type tokenResponse struct {
+		status int
+		data   map[string]interface{}
+	}
+
+	requestToken := func(params url.Values, basicUser, basicPass string) tokenResponse {
+		req, rerr := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(params.Encode()))
+		helper.Must(rerr)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if basicUser != "" {
+			req.SetBasicAuth(basicUser, basicPass)
+		}
+
+		tokenRes, rerr := client.Do(req)
+		helper.Must(rerr)
+		defer tokenRes.Body.Close()
+
+		if cc := tokenRes.Header.Get("Cache-Control"); cc != "no-store" {
+			t.Errorf("expected Cache-Control no-store, got: %q", cc)
+		}
+
+		b, rerr := io.ReadAll(tokenRes.Body)
+		helper.Must(rerr)
+		data := make(map[string]interface{})
+		helper.Must(json.Unmarshal(b, &data))
+		return tokenResponse{tokenRes.StatusCode, data}
+	}
+
+	verifyAccessToken := func(tr tokenResponse, expClaims map[string]interface{}) {
+		t.Helper()
+		if tr.status != http.StatusOK {
+			t.Fatalf("expected status 200, got: %d, %v", tr.status, tr.data)
+		}
+
+		accessToken, _ := tr.data["access_token"].(string)
+		claims := jwt.MapClaims{}
+		token, perr := jwt.NewParser(jwt.WithValidMethods([]string{"RS256"})).ParseWithClaims(accessToken, claims, func(_ *jwt.Token) (interface{}, error) {
+			return signingKey.Key, nil
+		})
+		helper.Must(perr)
+
+		if token.Header["kid"] != signingKey.KeyID {
+			t.Errorf("want kid %q, got: %v", signingKey.KeyID, token.Header["kid"])
+		}
+		for k, v := range expClaims {
+			if claims[k] != v {
+				t.Errorf("claim %s: want %v, got: %v", k, v, claims[k])
+			}
+		}
+		if exp, _ := claims.GetExpirationTime(); exp == nil || time.Until(exp.Time) > 10*time.Minute {
+			t.Errorf("unexpected exp: %v", exp)
+		}
+	}
+
+	// client_secret_basic
+	tr := requestToken(url.Values{"grant_type": {"client_credentials"}, "scope": {"read"}}, "svc", "s3cret")
+	verifyAccessToken(tr, map[string]interface{}{
+		"iss": "https://auth.example.com", "sub": "svc", "client_id": "svc", "aud": "orders", "scope": "read", "env": "test",
+	})
+	if tr.data["token_type"] != "Bearer" || tr.data["expires_in"] != float64(600) || tr.data["scope"] != "read" {
+		t.Errorf("unexpected token response: %v", tr.data)
+	}
+	refreshToken, _ := tr.data["refresh_token"].(string)
+	if refreshToken == "" {
+		t.Fatal("expected refresh_token")
+	}
+
+	// client_secret_post, all allowed scope values
+	tr = requestToken(url.Values{"grant_type": {"client_credentials"}, "client_id": {"svc"}, "client_secret": {"s3cret"}}, "", "")
+	verifyAccessToken(tr, map[string]interface{}{"sub": "svc", "scope": "read write"})
+
+	// refresh_token grant with rotation
+	tr = requestToken(url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}}, "svc", "s3cret")
+	verifyAccessToken(tr, map[string]interface{}{"sub": "svc", "scope": "read"})
+	if tr.data["refresh_token"] == refreshToken {
+		t.Error("expected a new refresh_token")
+	}
+
+	// private_key_jwt
+	clientKeyBytes, err := os.ReadFile("testdata/token_endpoint/client.key")
+	helper.Must(err)
+	clientKey, err := jwt.ParseECPrivateKeyFromPEM(clientKeyBytes)
+	helper.Must(err)
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": "batch", "sub": "batch", "aud": "https://auth.example.com/oauth2/token",
+		"jti": "assertion-1", "iat": now.Unix(), "exp": now.Add(time.Minute).Unix(),
+	}).SignedString(clientKey)
+	helper.Must(err)
+	assertionParams := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {assertion},
+	}
+	tr = requestToken(assertionParams, "", "")
+	verifyAccessToken(tr, map[string]interface{}{"sub": "batch", "client_id": "batch"})
+	if _, exists := tr.data["scope"]; exists {
+		t.Errorf("unexpected scope: %v", tr.data["scope"])
+	}
+
+	for _, tc := range []struct {
+		name      string
+		params    url.Values
+		user      string
+		pass      string
+		expStatus int
+		expError  string
+	}{
+		{"wrong secret", url.Values{"grant_type": {"client_credentials"}}, "svc", "wrong", http.StatusUnauthorized, "invalid_client"},
+		{"unknown client", url.Values{"grant_type": {"client_credentials"}}, "unknown", "s3cret", http.StatusUnauthorized, "invalid_client"},
+		{"missing client authentication", url.Values{"grant_type": {"client_credentials"}}, "", "", http.StatusUnauthorized, "invalid_client"},
+		{"replayed client assertion", assertionParams, "", "", http.StatusUnauthorized, "invalid_client"},
+		{"scope not allowed", url.Values{"grant_type": {"client_credentials"}, "scope": {"read admin"}}, "svc", "s3cret", http.StatusBadRequest, "invalid_scope"},
+		{"used refresh_token", url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}}, "svc", "s3cret", http.StatusBadRequest, "invalid_grant"},
+		{"unsupported grant_type", url.Values{"grant_type": {"password"}}, "svc", "s3cret", http.StatusBadRequest, "unsupported_grant_type"},
+		{"missing grant_type", url.Values{}, "svc", "s3cret", http.StatusBadRequest, "invalid_request"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			errRes := requestToken(tc.params, tc.user, tc.pass)
+			if errRes.status != tc.expStatus || errRes.data["error"] != tc.expError {
+				subT.Errorf("want %d %q, got: %d %v", tc.expStatus, tc.expError, errRes.status, errRes.data)
+			}
+		})
+	}
+
+	res, err = client.Get(tokenURL)
+	helper.Must(err)
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got: %d", res.StatusCode)
+	}
+}
+
+func TestTokenEndpoint_ConcurrentRedeem(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+
+	shutdown, _ := newCouper("testdata/token_endpoint/01_couper.hcl", helper)
+	defer shutdown()
+
+	const tokenURL = "http://couper.example:8080/oauth2/token"
+
+	requestToken := func(params url.Values, basicUser, basicPass string) (int, map[string]interface{}) {
+		req, rerr := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(params.Encode()))
+		helper.Must(rerr)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if basicUser != "" {
+			req.SetBasicAuth(basicUser, basicPass)
+		}
+
+		res, rerr := client.Do(req)
+		helper.Must(rerr)
+		defer res.Body.Close()
+
+		data := make(map[string]interface{})
+		helper.Must(json.NewDecoder(res.Body).Decode(&data))
+		return res.StatusCode, data
+	}
+
+	// redeemOnce sends the same token request concurrently and expects exactly one success
+	redeemOnce := func(name string, params url.Values, basicUser, basicPass string) {
+		const concurrency = 10
+
+		var wg sync.WaitGroup
+		statusCodes := make(chan int, concurrency)
+		start := make(chan struct{})
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				status, _ := requestToken(params, basicUser, basicPass)
+				statusCodes <- status
+			}()
+		}
+		close(start)
+		wg.Wait()
+		close(statusCodes)
+
+		var succeeded int
+		for status := range statusCodes {
+			if status == http.StatusOK {
+				succeeded++
+			}
+		}
+		if succeeded != 1 {
+			t.Errorf("%s: expected exactly one successful token request, got: %d", name, succeeded)
+		}
+	}
+
+	status, data := requestToken(url.Values{"grant_type": {"client_credentials"}, "scope": {"read"}}, "svc", "s3cret")
+	refreshToken, _ := data["refresh_token"].(string)
+	if status != http.StatusOK || refreshToken == "" {
+		t.Fatalf("expected a refresh_token, got: %d %v", status, data)
+	}
+	redeemOnce("refresh_token", url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}}, "svc", "s3cret")
+
+	clientKeyBytes, err := os.ReadFile("testdata/token_endpoint/client.key")
+	helper.Must(err)
+	clientKey, err := jwt.ParseECPrivateKeyFromPEM(clientKeyBytes)
+	helper.Must(err)
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": "batch", "sub": "batch", "aud": "https://auth.example.com/oauth2/token",
+		"jti": "assertion-concurrent", "iat": now.Unix(), "exp": now.Add(time.Minute).Unix(),
+	}).SignedString(clientKey)
+	helper.Must(err)
+	redeemOnce("client_assertion", url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {assertion},
+	}, "", "")
+}
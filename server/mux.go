@@ -3,7 +3,9 @@ package server
 import (
 	"context"
 	"net/http"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	gmux "github.com/gorilla/mux"
@@ -14,6 +16,7 @@ import (
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/handler"
 	"github.com/coupergateway/couper/handler/middleware"
+	"github.com/coupergateway/couper/utils"
 )
 
 // Mux is a http request router and dispatches requests
@@ -22,6 +25,7 @@ type Mux struct {
 	endpointRoot *gmux.Router
 	fileRoot     *gmux.Router
 	opts         *runtime.MuxOptions
+	paramTypes   map[*gmux.Route]map[string]string
 	spaRoot      *gmux.Router
 }
 
@@ -30,8 +34,17 @@ const (
 	wildcardSearch   = "/**"
 )
 
-func isParamSegment(segment string) bool {
-	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+// segmentRank orders static segments before constrained
+// and constrained before unconstrained parameter segments.
+func segmentRank(segment string) int {
+	param, ok := utils.ParsePathParam(segment)
+	if !ok {
+		return 0
+	}
+	if param.Pattern != "" {
+		return 1
+	}
+	return 2
 }
 
 func SortPathPatterns(pathPatterns []string) {
@@ -53,12 +66,9 @@ func SortPathPatterns(pathPatterns []string) {
 			return false
 		}
 		for k, iSegment := range iSegments {
-			jSegment := jSegments[k]
-			if !isParamSegment(iSegment) && isParamSegment(jSegment) {
-				return true
-			}
-			if isParamSegment(iSegment) && !isParamSegment(jSegment) {
-				return false
+			iRank, jRank := segmentRank(iSegment), segmentRank(jSegments[k])
+			if iRank != jRank {
+				return iRank < jRank
 			}
 		}
 		return sort.StringSlice{pathPatterns[i], pathPatterns[j]}.Less(0, 1)
@@ -86,6 +96,7 @@ func NewMux(options *runtime.MuxOptions) *Mux {
 		opts:         opts,
 		endpointRoot: gmux.NewRouter(),
 		fileRoot:     gmux.NewRouter(),
+		paramTypes:   make(map[*gmux.Route]map[string]string),
 		spaRoot:      gmux.NewRouter(),
 	}
 
@@ -93,20 +104,137 @@ func NewMux(options *runtime.MuxOptions) *Mux {
 }
 
 func (m *Mux) RegisterConfigured() {
-	for _, path := range sortedPathPatterns(m.opts.EndpointRoutes) {
-		// TODO: handle method option per endpoint configuration
-		mustAddRoute(m.endpointRoot, path, m.opts.EndpointRoutes[path], true)
+	endpointPatterns := make(map[string]http.Handler, len(m.opts.EndpointRoutes))
+	for path, h := range m.opts.EndpointRoutes {
+		endpointPatterns[path] = h
+	}
+	for path := range m.opts.MatchedEndpointRoutes {
+		if _, exists := endpointPatterns[path]; !exists {
+			endpointPatterns[path] = nil
+		}
+	}
+
+	for _, path := range sortedPathPatterns(endpointPatterns) {
+		// matched routes take precedence over the unmatched one of the same path pattern
+		matchedRoutes := m.opts.MatchedEndpointRoutes[path]
+		sort.SliceStable(matchedRoutes, func(i, j int) bool {
+			return matchedRoutes[i].Order < matchedRoutes[j].Order
+		})
+		for _, matched := range matchedRoutes {
+			for _, route := range m.addRoute(m.endpointRoot, path, matched.Handler, true) {
+				matcher := matched.Matches
+				routeVars := pathVars(route)
+				types := m.paramTypes[route]
+				route.MatcherFunc(func(req *http.Request, _ *gmux.RouteMatch) bool {
+					// the route vars are set after all matchers, provide the path parameters to the condition
+					pathParams := pathParameters(routeVars(req.URL.Path), types)
+					return matcher(req.WithContext(context.WithValue(req.Context(), request.PathParams, pathParams)))
+				})
+			}
+		}
+
+		if h := endpointPatterns[path]; h != nil {
+			// TODO: handle method option per endpoint configuration
+			m.addRoute(m.endpointRoot, path, h, true)
+		}
 	}
 
 	for _, path := range sortedPathPatterns(m.opts.FileRoutes) {
-		mustAddRoute(m.fileRoot, path, m.opts.FileRoutes[path], false)
+		m.addRoute(m.fileRoot, path, m.opts.FileRoutes[path], false)
 	}
 
 	for _, path := range sortedPathPatterns(m.opts.SPARoutes) {
-		mustAddRoute(m.spaRoot, path, m.opts.SPARoutes[path], true)
+		m.addRoute(m.spaRoot, path, m.opts.SPARoutes[path], true)
 	}
 }
 
+// addRoute registers the given path pattern and remembers the types
+// of its typed parameters to convert their values on match.
+func (m *Mux) addRoute(root *gmux.Router, path string, handler http.Handler, trailingSlash bool) []*gmux.Route {
+	segments := strings.Split(path, "/")
+	types := make(map[string]string)
+	for i, segment := range segments {
+		param, ok := utils.ParsePathParam(segment)
+		if !ok {
+			continue
+		}
+		if param.Type != "" {
+			types[param.Name] = param.Type
+		}
+		segments[i] = param.String()
+	}
+
+	routes := mustAddRoute(root, strings.Join(segments, "/"), handler, trailingSlash)
+	if len(types) > 0 {
+		for _, route := range routes {
+			m.paramTypes[route] = types
+			route.MatcherFunc(typedParamsMatcher(route, types))
+		}
+	}
+	return routes
+}
+
+// typedParamsMatcher reports whether the typed parameter values of the route can be converted,
+// so that e.g. an overflowing int value falls through to the next matching route.
+func typedParamsMatcher(route *gmux.Route, types map[string]string) gmux.MatcherFunc {
+	routeVars := pathVars(route)
+
+	return func(req *http.Request, _ *gmux.RouteMatch) bool {
+		vars := routeVars(req.URL.Path)
+		if vars == nil {
+			return false
+		}
+		for name, value := range vars {
+			if types[name] != "int" {
+				continue
+			}
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// pathVars returns a function extracting the route vars from a request path before
+// gorilla/mux sets them on a match.
+func pathVars(route *gmux.Route) func(path string) map[string]string {
+	pathRegexp, _ := route.GetPathRegexp()
+	re := regexp.MustCompile(pathRegexp)
+	names, _ := route.GetVarNames()
+
+	return func(path string) map[string]string {
+		values := re.FindStringSubmatch(path)
+		if values == nil {
+			return nil
+		}
+		// constraints must not contain capturing groups, so there is one group per parameter
+		vars := make(map[string]string, len(names))
+		for i, name := range names {
+			if i+1 < len(values) {
+				vars[name] = values[i+1]
+			}
+		}
+		return vars
+	}
+}
+
+// pathParameters converts the typed values of the given route vars to their type.
+func pathParameters(vars map[string]string, types map[string]string) request.PathParameter {
+	pathParams := make(request.PathParameter, len(vars))
+	for k, value := range vars {
+		key := strings.TrimSuffix(strings.TrimSuffix(k, "*"), "|.+")
+		if types[key] == "int" {
+			// the value fits, see typedParamsMatcher
+			i, _ := strconv.ParseInt(value, 10, 64)
+			pathParams[key] = i
+			continue
+		}
+		pathParams[key] = value
+	}
+	return pathParams
+}
+
 var noDefaultMethods []string
 
 func registerHandler(root *gmux.Router, methods []string, path string, handler http.Handler) {
@@ -117,7 +245,7 @@ func registerHandler(root *gmux.Router, methods []string, path string, handler h
 
 func (m *Mux) FindHandler(req *http.Request) http.Handler {
 	ctx := context.WithValue(req.Context(), request.ServerName, m.opts.ServerOptions.ServerName)
-	routeMatch, matches := m.match(m.endpointRoot, req)
+	routeMatch, pathParams, matches := m.match(m.endpointRoot, req)
 	if !matches {
 		// No matches for api or free endpoints. Determine if we have entered an api basePath
 		// and handle api related errors accordingly.
@@ -133,7 +261,7 @@ func (m *Mux) FindHandler(req *http.Request) http.Handler {
 			return fileHandler
 		}
 
-		routeMatch, matches = m.match(m.spaRoot, req)
+		routeMatch, pathParams, matches = m.match(m.spaRoot, req)
 
 		if !matches {
 			if fileHandler != nil {
@@ -146,21 +274,16 @@ func (m *Mux) FindHandler(req *http.Request) http.Handler {
 		}
 	}
 
-	pathParams := make(request.PathParameter, len(routeMatch.Vars))
-	for k, value := range routeMatch.Vars {
-		key := strings.TrimSuffix(strings.TrimSuffix(k, "*"), "|.+")
-		pathParams[key] = value
-	}
-
-	pt, _ := routeMatch.Route.GetPathTemplate()
-	p := pt
-	for k, v := range routeMatch.Vars {
-		p = strings.Replace(p, "{"+k+"}", v, 1)
-	}
-	wc := strings.TrimPrefix(req.URL.Path, p)
-	wc = strings.TrimPrefix(wc, "/")
-
 	if routeMatch.Route.GetName() == "**" {
+		pt, _ := routeMatch.Route.GetPathTemplate()
+		segments := strings.Split(pt, "/")
+		for i, segment := range segments {
+			if param, ok := utils.ParsePathParam(segment); ok {
+				segments[i] = routeMatch.Vars[param.Name]
+			}
+		}
+		wc := strings.TrimPrefix(req.URL.Path, strings.Join(segments, "/"))
+		wc = strings.TrimPrefix(wc, "/")
 		ctx = context.WithValue(ctx, request.Wildcard, wc)
 	}
 
@@ -170,17 +293,19 @@ func (m *Mux) FindHandler(req *http.Request) http.Handler {
 	return routeMatch.Handler
 }
 
-func (m *Mux) match(root *gmux.Router, req *http.Request) (*gmux.RouteMatch, bool) {
+// match returns the matching route and its path parameters with typed parameter
+// values converted to their type.
+func (m *Mux) match(root *gmux.Router, req *http.Request) (*gmux.RouteMatch, request.PathParameter, bool) {
 	var routeMatch gmux.RouteMatch
-	if root.Match(req, &routeMatch) {
-		return &routeMatch, true
+	if !root.Match(req, &routeMatch) {
+		return nil, nil, false
 	}
 
-	return nil, false
+	return &routeMatch, pathParameters(routeMatch.Vars, m.paramTypes[routeMatch.Route]), true
 }
 
 func (m *Mux) hasFileResponse(req *http.Request) (http.Handler, bool) {
-	routeMatch, matches := m.match(m.fileRoot, req)
+	routeMatch, _, matches := m.match(m.fileRoot, req)
 	if !matches {
 		return nil, false
 	}
@@ -227,34 +352,33 @@ func (m *Mux) getAPIErrorTemplate(reqPath string) (*errors.Template, *config.API
 	return nil, nil
 }
 
-func mustAddRoute(root *gmux.Router, path string, handler http.Handler, trailingSlash bool) {
+func mustAddRoute(root *gmux.Router, path string, handler http.Handler, trailingSlash bool) []*gmux.Route {
 	if strings.HasSuffix(path, wildcardSearch) {
 		path = path[:len(path)-len(wildcardSearch)]
 		if len(path) == 0 {
-			root.PathPrefix("/").Name("**").Handler(handler)
-			return
+			return []*gmux.Route{root.PathPrefix("/").Name("**").Handler(handler)}
 		}
-		root.Path(path).Name("**").Handler(handler) // register /path ...
+		routes := []*gmux.Route{root.Path(path).Name("**").Handler(handler)} // register /path ...
 		if !strings.HasSuffix(path, "/") {
 			path = path + "/" // ... and /path/**
 		}
-		root.PathPrefix(path).Name("**").Handler(handler)
-		return
+		return append(routes, root.PathPrefix(path).Name("**").Handler(handler))
 	}
 
 	if len(path) == 0 {
 		path = "/" // path at least be /
 	}
+	var routes []*gmux.Route
 	// cannot use Router.StrictSlash(true) because redirect and subsequent GET request would cause problem with CORS
 	if trailingSlash {
 		path = strings.TrimSuffix(path, "/")
 
 		if len(path) > 0 {
-			root.Path(path).Handler(handler) // register /path ...
+			routes = append(routes, root.Path(path).Handler(handler)) // register /path ...
 		}
 		path = path + "/" // ... and /path/
 	}
-	root.Path(path).Handler(handler)
+	return append(routes, root.Path(path).Handler(handler))
 }
 
 // isAPIError checks the path w/ and w/o the
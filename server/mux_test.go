@@ -66,6 +66,110 @@ func TestSortPathPatterns(t *testing.T) {
 	}
 }
 
+func TestSortPathPatterns_Constraints(t *testing.T) {
+	pathPatterns := []string{
+		"/users/{name}",
+		"/users/{id:int}/**",
+		"/users/me",
+		"/users/{id:uuid}",
+		"/{a}/{b:[a-z]+}",
+		"/users/{name}/profile",
+		"/users/{id:int}/profile",
+	}
+	server.SortPathPatterns(pathPatterns)
+	expectedSortedPathPatterns := []string{
+		"/users/{id:int}/profile",
+		"/users/{name}/profile",
+		"/users/me",
+		"/users/{id:uuid}",
+		"/users/{name}",
+		"/{a}/{b:[a-z]+}",
+		"/users/{id:int}/**",
+	}
+	if !reflect.DeepEqual(expectedSortedPathPatterns, pathPatterns) {
+		t.Errorf("exp: %v\ngot:%v", expectedSortedPathPatterns, pathPatterns)
+	}
+}
+
+func TestMux_FindHandler_PathParamConstraints(t *testing.T) {
+	newHandler := func(name string) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.Header().Set("X-Route", name)
+		})
+	}
+
+	serverOptions, _ := rs.NewServerOptions(nil, nil)
+
+	testOptions := &runtime.MuxOptions{
+		EndpointRoutes: map[string]http.Handler{
+			"/users/me":              newHandler("me"),
+			"/users/{id:int}":        newHandler("int"),
+			"/users/{id:uuid}":       newHandler("uuid"),
+			"/users/{name}":          newHandler("name"),
+			"/codes/{code:[A-Z]{3}}": newHandler("code"),
+			"/files/{id:int}/**":     newHandler("files"),
+			"/orders/{id:int}":       newHandler("order"),
+			"/orders/{id}":           newHandler("order-ref"),
+		},
+		ServerOptions: serverOptions,
+	}
+
+	tests := []struct {
+		path        string
+		expRoute    string
+		expParams   request.PathParameter
+		expWildcard string
+	}{
+		{"/users/me", "me", request.PathParameter{}, ""},
+		{"/users/42", "int", request.PathParameter{"id": int64(42)}, ""},
+		{"/users/-1/", "int", request.PathParameter{"id": int64(-1)}, ""},
+		{"/users/0b6bb4c4-cb1c-4ba8-9f3b-b4d9b2b1a2c8", "uuid", request.PathParameter{"id": "0b6bb4c4-cb1c-4ba8-9f3b-b4d9b2b1a2c8"}, ""},
+		{"/users/john", "name", request.PathParameter{"name": "john"}, ""},
+		{"/users/99999999999999999999", "name", request.PathParameter{"name": "99999999999999999999"}, ""},
+		{"/codes/ABC", "code", request.PathParameter{"code": "ABC"}, ""},
+		{"/codes/ABCD", "", nil, ""},
+		{"/codes/abc", "", nil, ""},
+		{"/files/7/a/b", "files", request.PathParameter{"id": int64(7)}, "a/b"},
+		{"/files/x/a/b", "", nil, ""},
+		{"/files/99999999999999999999/a", "", nil, ""},
+		{"/orders/9223372036854775807", "order", request.PathParameter{"id": int64(9223372036854775807)}, ""},
+		{"/orders/9223372036854775808", "order-ref", request.PathParameter{"id": "9223372036854775808"}, ""},
+		{"/orders/-9223372036854775809", "order-ref", request.PathParameter{"id": "-9223372036854775809"}, ""},
+	}
+
+	mux := server.NewMux(testOptions)
+	mux.RegisterConfigured()
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(subT *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+			mux.FindHandler(req).ServeHTTP(rec, req)
+
+			if route := rec.Header().Get("X-Route"); route != tt.expRoute {
+				subT.Errorf("expected route %q, got %q", tt.expRoute, route)
+			}
+
+			if tt.expRoute == "" {
+				if rec.Code != http.StatusNotFound {
+					subT.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+				}
+				return
+			}
+
+			paramCtx, _ := req.Context().Value(request.PathParams).(request.PathParameter)
+			if !reflect.DeepEqual(paramCtx, tt.expParams) {
+				subT.Errorf("Path parameter context: %#v, want: %#v", paramCtx, tt.expParams)
+			}
+
+			wildcardCtx, _ := req.Context().Value(request.Wildcard).(string)
+			if wildcardCtx != tt.expWildcard {
+				subT.Errorf("Wildcard context: %q, want: %q", wildcardCtx, tt.expWildcard)
+			}
+		})
+	}
+}
+
 func TestMux_FindHandler_PathParamContext(t *testing.T) {
 	type noContentHandler http.Handler
 	var noContent noContentHandler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,48 @@
+server {
+  hosts = ["*:8080"]
+
+  api {
+    access_control = ["partners", "partner_rate"]
+
+    endpoint "/orders" {
+      required_permission = "orders:read"
+
+      response {
+        headers = {
+          x-owner = request.context.partners.owner
+        }
+      }
+    }
+
+    endpoint "/admin" {
+      required_permission = "admin"
+
+      response {
+        status = 204
+      }
+    }
+  }
+}
+
+definitions {
+  api_key "partners" {
+    keys = {
+      # sha256("acme-secret")
+      "307c609f87da43c3d563428a4f7efdf9857f4871fd10465732c4ab11a985a08c" = {
+        owner       = "acme"
+        permissions = ["orders:read"]
+      }
+      # sha256("globex-secret")
+      "4fe6ae1bd397d68b149f8a86069f5e6806a937d7d0b2f31830c48008b268bda0" = {
+        owner       = "globex"
+        permissions = "orders:read admin"
+      }
+    }
+  }
+
+  beta_rate_limiter "partner_rate" {
+    period     = "1m"
+    per_period = 2
+    key        = request.context.partners.owner
+  }
+}
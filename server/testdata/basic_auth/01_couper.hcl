@@ -0,0 +1,46 @@
+server {
+  hosts = ["*:8080"]
+
+  api {
+    access_control = ["corporate"]
+
+    endpoint "/orders" {
+      required_permission = "orders:read"
+
+      response {
+        headers = {
+          x-user   = request.context.corporate.user
+          x-groups = join(",", request.context.corporate.groups)
+        }
+      }
+    }
+
+    endpoint "/admin" {
+      required_permission = "orders:write"
+
+      response {
+        status = 204
+      }
+    }
+  }
+}
+
+definitions {
+  basic_auth "corporate" {
+    realm = "Corporate"
+
+    ldap {
+      url                  = "{{.url}}"
+      search_bind_dn       = "cn=couper,ou=services,dc=example,dc=com"
+      search_bind_password = "service-secret"
+      user_base_dn         = "ou=people,dc=example,dc=com"
+      user_filter          = "(uid={user})"
+      group_base_dn        = "ou=groups,dc=example,dc=com"
+      groups_map = {
+        admins  = ["orders:write"]
+        editors = ["orders:read"]
+      }
+      cache_ttl = "1m"
+    }
+  }
+}
@@ -0,0 +1,40 @@
+server {
+  api {
+    base_path = "/v1"
+
+    endpoint "/users/me" {
+      response {
+        json_body = {
+          route = "me"
+        }
+      }
+    }
+
+    endpoint "/users/{id:int}" {
+      response {
+        json_body = {
+          route = "int"
+          next  = request.path_params.id + 1
+        }
+      }
+    }
+
+    endpoint "/users/{id:uuid}" {
+      response {
+        json_body = {
+          route = "uuid"
+          id    = request.path_params.id
+        }
+      }
+    }
+
+    endpoint "/codes/{code:[A-Z]{3}}" {
+      response {
+        json_body = {
+          route = "code"
+          code  = request.path_params.code
+        }
+      }
+    }
+  }
+}
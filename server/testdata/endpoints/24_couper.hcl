@@ -0,0 +1,109 @@
+server {
+  api {
+    base_path = "/v1"
+
+    endpoint "/items" {
+      match {
+        headers = {
+          accept = "application/vnd.example.v2+json"
+        }
+      }
+
+      response {
+        json_body = {
+          version = 2
+        }
+      }
+    }
+
+    endpoint "/items" {
+      match {
+        query_params = {
+          version = "3"
+        }
+      }
+
+      response {
+        json_body = {
+          version = 3
+        }
+      }
+    }
+
+    endpoint "/items" {
+      match {
+        methods      = ["POST"]
+        content_type = ["application/json"]
+      }
+
+      response {
+        json_body = {
+          created = request.json_body.name
+        }
+      }
+    }
+
+    endpoint "/items" {
+      response {
+        json_body = {
+          version = 1
+        }
+      }
+    }
+
+    endpoint "/tenants/{id:int}" {
+      match {
+        condition = request.path_params.id >= 1000
+      }
+
+      response {
+        json_body = {
+          tenant = "large"
+        }
+      }
+    }
+
+    endpoint "/tenants/{id:int}" {
+      response {
+        json_body = {
+          tenant = "small"
+        }
+      }
+    }
+
+    endpoint "/beta" {
+      match {
+        condition = request.headers.x-beta == "1"
+      }
+
+      response {
+        json_body = {
+          beta = true
+        }
+      }
+    }
+  }
+
+  api {
+    base_path = "/v2"
+
+    cors {
+      allowed_origins = ["https://www.example.com"]
+    }
+
+    endpoint "/orders" {
+      match {
+        methods = ["POST"]
+        headers = {
+          content-type = "application/vnd.example.order+json"
+        }
+      }
+
+      response {
+        json_body = {
+          created = true
+        }
+      }
+    }
+  }
+}
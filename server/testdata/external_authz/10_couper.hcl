@@ -0,0 +1,52 @@
+server "protected" {
+  hosts = ["*:8080"]
+
+  api {
+    endpoint "/cached" {
+      access_control = ["cached"]
+
+      response {
+        status = 204
+      }
+    }
+
+    endpoint "/unavailable" {
+      access_control = ["unavailable"]
+
+      response {
+        status = 204
+        headers = {
+          x-authz-error = request.context.unavailable.error
+        }
+      }
+    }
+  }
+}
+
+server "authz-service" {
+  hosts = ["*:8081"]
+
+  api {
+    endpoint "/check" {
+      response {
+        status = 200
+        headers = {
+          cache-control = "max-age=60"
+        }
+      }
+    }
+  }
+}
+
+definitions {
+  beta_external_authz "cached" {
+    url       = "http://127.0.0.1:8081/check"
+    cache_key = request.headers.authorization
+  }
+
+  beta_external_authz "unavailable" {
+    url      = "http://127.0.0.1:8082/check"
+    timeout  = "1s"
+    on_error = "allow_with_flag"
+  }
+}
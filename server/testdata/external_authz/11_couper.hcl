@@ -0,0 +1,18 @@
+server {
+  api {
+    endpoint "/protected" {
+      access_control = ["authz"]
+
+      response {
+        status = 204
+      }
+    }
+  }
+}
+
+definitions {
+  beta_external_authz "authz" {
+    url       = "http://127.0.0.1:8081/check"
+    cache_ttl = "1m"
+  }
+}
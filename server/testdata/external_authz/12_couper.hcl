@@ -0,0 +1,26 @@
+server "protected" {
+  hosts = ["*:8080"]
+
+  api {
+    endpoint "/protected" {
+      access_control = ["authz"]
+
+      proxy {
+        backend {
+          origin = "{{.upstream}}"
+        }
+      }
+    }
+  }
+}
+
+definitions {
+  beta_external_authz "authz" {
+    protocol = "envoy_grpc"
+
+    backend {
+      origin                = "{{.origin}}"
+      http2_prior_knowledge = true
+    }
+  }
+}
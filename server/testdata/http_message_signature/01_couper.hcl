@@ -0,0 +1,47 @@
+server {
+  hosts = ["*:8080"]
+
+  endpoint "/sign/**" {
+    proxy {
+      backend {
+        origin      = "http://localhost:8080"
+        path        = "/**"
+        path_prefix = "/verify"
+
+        set_request_headers = {
+          x-partner = "couper"
+        }
+
+        http_message_signature {
+          key_id              = "couper-key"
+          signature_algorithm = "HS256"
+          key                 = "shared-secret"
+          components          = ["@method", "@path", "@query", "x-partner", "content-digest"]
+          ttl                 = "1m"
+        }
+      }
+    }
+  }
+
+  endpoint "/verify/**" {
+    access_control = ["partner"]
+
+    response {
+      headers = {
+        x-keyid      = request.context.partner.keyid
+        x-components = join(" ", request.context.partner.components)
+        x-partner    = request.headers.x-partner
+      }
+      body = request.body
+    }
+  }
+}
+
+definitions {
+  http_message_signature "partner" {
+    signature_algorithm = "HS256"
+    key                 = "shared-secret"
+    key_id              = "couper-key"
+    required_components = ["@method", "@path", "x-partner", "content-digest"]
+  }
+}
@@ -83,7 +83,7 @@ server "scoped jwt" {
       }
     }
     endpoint "/bad/type/tuple" {
-      required_permission = ["p1", "p2"]
+      required_permission = ["p1", 2]
       response {
         status = 204
         headers = {
@@ -100,6 +100,72 @@ server "scoped jwt" {
         }
       }
     }
+    endpoint "/rules/any-of" {
+      required_permission = {
+        any_of = ["z", "a"]
+      }
+      response {
+        status = 204
+        headers = {
+          x-granted-permissions = json_encode(request.context.granted_permissions)
+        }
+      }
+    }
+    endpoint "/rules/all-of" {
+      required_permission = ["a", "p1"]
+      error_handler "insufficient_permissions" {
+        response {
+          status = 403
+          headers = {
+            x-required-permission = json_encode(request.context.required_permission)
+          }
+        }
+      }
+      response {
+        status = 204
+      }
+    }
+    endpoint "/rules/any-of/missing" {
+      required_permission = {
+        any_of = ["y", "z"]
+      }
+      error_handler "insufficient_permissions" {
+        response {
+          status = 403
+          headers = {
+            x-required-permission = json_encode(request.context.required_permission)
+          }
+        }
+      }
+      response {
+        status = 204
+      }
+    }
+    endpoint "/rules/scopes/{scope}" {
+      required_permission = {
+        scopes = [request.path_params.scope]
+      }
+      response {
+        status = 204
+        headers = {
+          x-granted-permissions = json_encode(request.context.granted_permissions)
+        }
+      }
+    }
+    endpoint "/rules/tenant/{tenant}" {
+      required_permission = {
+        get = {
+          all_of = ["a"]
+          condition = request.path_params.tenant == request.context.scoped_jwt.tenant
+        }
+      }
+      response {
+        status = 204
+        headers = {
+          x-granted-permissions = json_encode(request.context.granted_permissions)
+        }
+      }
+    }
     endpoint "/permission-from-api" {
       response {
         status = 204
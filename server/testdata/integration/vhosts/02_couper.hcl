@@ -0,0 +1,63 @@
+server "exact" {
+  hosts = ["api.example.com"]
+
+  endpoint "/" {
+    response {
+      json_body = { server = "exact" }
+    }
+  }
+}
+
+server "wildcard" {
+  hosts = ["*.example.com"]
+
+  endpoint "/" {
+    response {
+      json_body = { server = "wildcard" }
+    }
+  }
+}
+
+server "specific-wildcard" {
+  hosts = ["*.eu.example.com"]
+
+  endpoint "/" {
+    response {
+      json_body = { server = "specific-wildcard" }
+    }
+  }
+}
+
+server "pattern-a" {
+  hosts = ["~^(?P<tenant>[a-z]+)-(?P<region>eu|us)\\.example\\.org$"]
+
+  endpoint "/" {
+    response {
+      json_body = {
+        server = "pattern"
+        tenant = request.host_params.tenant
+        region = request.host_params.region
+      }
+    }
+  }
+}
+
+server "pattern-b" {
+  hosts = ["~[a-z-]+\\.example\\.org:8080"]
+
+  endpoint "/" {
+    response {
+      json_body = { server = "pattern-b" }
+    }
+  }
+}
+
+server "fallback" {
+  hosts = ["*"]
+
+  endpoint "/" {
+    response {
+      json_body = { server = "fallback" }
+    }
+  }
+}
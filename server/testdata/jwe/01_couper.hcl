@@ -0,0 +1,40 @@
+server {
+  hosts = ["*:8080"]
+
+  endpoint "/login" {
+    response {
+      headers = {
+        set-cookie = "session=${jwt_sign("session", { sub = "alice" })}; HttpOnly"
+      }
+      status = 204
+    }
+  }
+
+  endpoint "/profile" {
+    access_control = ["session"]
+
+    response {
+      headers = {
+        x-sub = request.context.session.sub
+      }
+      status = 204
+    }
+  }
+}
+
+definitions {
+  jwt_signing_profile "session" {
+    signature_algorithm  = "HS256"
+    key                  = "signing-secret"
+    ttl                  = "1h"
+    encryption_algorithm = "dir"
+    encryption_key       = "0123456789abcdef0123456789abcdef"
+  }
+
+  jwt "session" {
+    signature_algorithm = "HS256"
+    key                 = "signing-secret"
+    cookie              = "session"
+    decryption_key      = "0123456789abcdef0123456789abcdef"
+  }
+}
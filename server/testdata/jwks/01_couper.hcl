@@ -0,0 +1,55 @@
+server {
+  jwks_path = "/.well-known/jwks.json"
+
+  endpoint "/token/{profile}" {
+    response {
+      body = jwt_sign(request.path_params.profile, { sub = "me" })
+    }
+  }
+
+  endpoint "/protected" {
+    access_control = ["published"]
+
+    response {
+      json_body = request.context.published
+    }
+  }
+}
+
+definitions {
+  jwt_signing_profile "rsa" {
+    signature_algorithm = "RS256"
+    key_file            = "../token_endpoint/signing.key"
+    key_id              = "rsa-2026"
+    ttl                 = "1m"
+  }
+
+  # the same key is published only once
+  jwt_signing_profile "rsa_other_claims" {
+    signature_algorithm = "RS256"
+    key_file            = "../token_endpoint/signing.key"
+    key_id              = "rsa-2026"
+    ttl                 = "1m"
+    claims = {
+      iss = "other"
+    }
+  }
+
+  # symmetric keys are not published
+  jwt_signing_profile "hmac" {
+    signature_algorithm = "HS256"
+    key                 = "s3cr3t"
+    ttl                 = "1m"
+  }
+
+  jwt "self_signed" {
+    signature_algorithm = "ES256"
+    key_file            = "../integration/files/certificate-ecdsa.pem"
+    signing_key_file    = "../integration/files/ecdsa.key"
+    signing_ttl         = "1m"
+  }
+
+  jwt "published" {
+    jwks_url = "http://127.0.0.1:8080/.well-known/jwks.json"
+  }
+}
@@ -0,0 +1,19 @@
+server {
+  jwks_path = "/jwks.json"
+}
+
+definitions {
+  jwt_signing_profile "one" {
+    signature_algorithm = "RS256"
+    key_file            = "../token_endpoint/signing.key"
+    key_id              = "shared"
+    ttl                 = "1m"
+  }
+
+  jwt_signing_profile "two" {
+    signature_algorithm = "ES256"
+    key_file            = "../token_endpoint/client.key"
+    key_id              = "shared"
+    ttl                 = "1m"
+  }
+}
@@ -0,0 +1,52 @@
+server {
+  hosts = ["*:4443"]
+
+  endpoint "/" {
+    access_control = ["mtls"]
+    required_permission = "orders:read"
+
+    response {
+      headers = {
+        x-subject     = request.context.mtls.subject
+        x-fingerprint = request.context.mtls.fingerprint_sha256
+      }
+    }
+  }
+
+  endpoint "/denied" {
+    access_control = ["other"]
+
+    response {} # OK
+  }
+
+  tls {
+    server_certificate {
+      public_key = <<-EOC
+{{ .publicKey }}
+EOC
+      private_key = <<-EOC
+{{ .privateKey }}
+EOC
+    }
+
+    client_certificate {
+      ca_certificate = <<-EOC
+{{ .clientCA }}
+EOC
+    }
+  }
+}
+
+definitions {
+  client_certificate "mtls" {
+    subjects     = ["OU=Development,O=Couper,C=DE"]
+    fingerprints = ["{{ .fingerprint }}"]
+    roles_map = {
+      "{{ .fingerprint }}" = ["orders:read"]
+    }
+  }
+
+  client_certificate "other" {
+    subjects = ["CN=other,O=Couper,C=DE"]
+  }
+}
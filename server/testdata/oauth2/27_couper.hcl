@@ -0,0 +1,21 @@
+server {
+  api {
+    endpoint "/" {
+      proxy {
+        backend {
+          origin = "{{.rsOrigin}}"
+
+          oauth2 {
+            token_endpoint       = "{{.asOrigin}}/token"
+            client_id            = "gateway"
+            client_secret        = "secret"
+            grant_type           = "urn:ietf:params:oauth:grant-type:token-exchange"
+            subject_token        = request.headers.x-token
+            audience             = "orders"
+            requested_token_type = "urn:ietf:params:oauth:token-type:access_token"
+          }
+        }
+      }
+    }
+  }
+}
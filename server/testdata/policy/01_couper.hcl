@@ -0,0 +1,34 @@
+server {
+  hosts = ["*:8080"]
+
+  api {
+    endpoint "/tenants/{tenant}/orders" {
+      access_control = ["partners", "orders"]
+
+      response {
+        status = 204
+      }
+    }
+  }
+}
+
+definitions {
+  api_key "partners" {
+    keys = {
+      # sha256("acme-secret")
+      "307c609f87da43c3d563428a4f7efdf9857f4871fd10465732c4ab11a985a08c" = {
+        tenant = "acme"
+        roles  = ["member"]
+      }
+      # sha256("globex-secret")
+      "4fe6ae1bd397d68b149f8a86069f5e6806a937d7d0b2f31830c48008b268bda0" = {
+        tenant = "globex"
+        roles  = ["member", "admin"]
+      }
+    }
+  }
+
+  beta_policy "orders" {
+    file = "orders.cel"
+  }
+}
@@ -0,0 +1,54 @@
+server {
+  hosts = ["*:8080"]
+
+  endpoint "/login" {
+    response {
+      status = 204
+      headers = {
+        set-cookie = session_write("app", {
+          sub = request.query.sub[0]
+        })
+      }
+    }
+  }
+
+  endpoint "/login-twice" {
+    response {
+      status = 204
+      headers = {
+        set-cookie = session_write("app", {
+          sub = request.query.sub[0]
+        })
+        x-set-cookie = session_write("app", {
+          sub = request.query.sub[0]
+        })
+      }
+    }
+  }
+
+  endpoint "/logout" {
+    response {
+      status = 204
+      headers = {
+        set-cookie = session_destroy("app")
+      }
+    }
+  }
+
+  endpoint "/app" {
+    access_control = ["app"]
+
+    response {
+      headers = {
+        x-sub = request.context.app.sub
+      }
+    }
+  }
+}
+
+definitions {
+  session "app" {
+    cookie_name   = "sid"
+    cookie_secure = false
+  }
+}
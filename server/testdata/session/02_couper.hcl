@@ -0,0 +1,44 @@
+server {
+  hosts = ["*:8080"]
+
+  endpoint "/callback" {
+    access_control = ["oidc"]
+
+    response {
+      status = 204
+      headers = {
+        set-cookie = session_write("app", {
+          sub = request.context.oidc.id_token_claims.sub
+        })
+      }
+    }
+  }
+
+  endpoint "/api" {
+    access_control = ["app"]
+
+    response {
+      headers = {
+        x-sub   = request.context.app.sub
+        x-token = request.context.oidc.access_token
+      }
+    }
+  }
+}
+
+definitions {
+  session "app" {
+    cookie_name   = "sid"
+    cookie_secure = false
+  }
+
+  oidc "oidc" {
+    configuration_url = "{{.asOrigin}}/.well-known/openid-configuration"
+    client_id         = "foo"
+    client_secret     = "etbinbp4in"
+    redirect_uri      = "/callback"
+    verifier_method   = "ccm_s256"
+    verifier_value    = request.cookies.pkcecv
+    session           = "app"
+  }
+}
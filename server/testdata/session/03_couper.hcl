@@ -0,0 +1,55 @@
+server {
+  hosts = ["*:8080"]
+
+  endpoint "/callback" {
+    access_control = ["oidc"]
+
+    response {
+      status = 204
+      headers = {
+        set-cookie = session_write("app", {
+          sub = request.context.oidc.id_token_claims.sub
+        })
+      }
+    }
+  }
+
+  endpoint "/api" {
+    access_control = ["app"]
+
+    response {
+      status = 204
+    }
+  }
+
+  endpoint "/logout" {
+    access_control = ["app"]
+
+    response {
+      status = 303
+      headers = {
+        location   = oidc_logout_url("oidc", request.context.oidc.id_token)
+        set-cookie = session_destroy("app")
+      }
+    }
+  }
+}
+
+definitions {
+  session "app" {
+    cookie_name   = "sid"
+    cookie_secure = false
+  }
+
+  oidc "oidc" {
+    configuration_url        = "{{.asOrigin}}/.well-known/openid-configuration"
+    client_id                = "foo"
+    client_secret            = "etbinbp4in"
+    redirect_uri             = "/callback"
+    post_logout_redirect_uri = "/logged-out"
+    verifier_method          = "ccm_s256"
+    verifier_value           = request.cookies.pkcecv
+    session                  = "app"
+    backchannel_logout_path  = "/backchannel-logout"
+  }
+}
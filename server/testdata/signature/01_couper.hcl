@@ -0,0 +1,37 @@
+server {
+  hosts = ["*:8080"]
+
+  endpoint "/github" {
+    access_control = ["github"]
+
+    proxy {
+      url = "${env.COUPER_TEST_BACKEND_ADDR}/anything"
+    }
+  }
+
+  endpoint "/payments" {
+    access_control = ["payments"]
+
+    response {
+      headers = {
+        x-timestamp = request.context.payments.timestamp
+      }
+    }
+  }
+}
+
+definitions {
+  signature "github" {
+    secret = "gh-secret"
+    header = "X-Hub-Signature-256"
+    prefix = "sha256="
+  }
+
+  signature "payments" {
+    secret              = "pay-secret"
+    encoding            = "base64"
+    components          = ["timestamp", "header.x-event-id", "body"]
+    timestamp_header    = "X-Timestamp"
+    timestamp_tolerance = "1m"
+  }
+}
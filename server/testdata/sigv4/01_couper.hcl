@@ -0,0 +1,42 @@
+server {
+  hosts = ["*:8080"]
+
+  endpoint "/objects/**" {
+    proxy {
+      backend = "minio"
+    }
+  }
+
+  endpoint "/invalid/**" {
+    proxy {
+      backend {
+        origin = "{{ .origin }}"
+        path   = "/**"
+
+        sigv4 {
+          access_key_id     = "couper"
+          secret_access_key = "wrong-secret"
+          region            = "eu-central-1"
+        }
+      }
+    }
+  }
+}
+
+definitions {
+  backend "minio" {
+    origin      = "{{ .origin }}"
+    path        = "/**"
+    path_prefix = "/bucket"
+
+    set_request_headers = {
+      x-amz-meta-owner = "couper"
+    }
+
+    sigv4 {
+      access_key_id     = "couper"
+      secret_access_key = "minio-secret"
+      region            = "eu-central-1"
+    }
+  }
+}
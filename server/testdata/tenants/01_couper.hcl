@@ -0,0 +1,52 @@
+server {
+  access_control = ["token"]
+
+  beta_tenants {
+    host_pattern = "*.example.com"
+    key = request.context.token.tenant
+    tenants = {
+      acme = {
+        origin = env.COUPER_TEST_BACKEND_ADDR
+        credentials = "acme-secret"
+      }
+      globex = {
+        origin = env.COUPER_TEST_BACKEND_ADDR
+        credentials = "globex-secret"
+      }
+    }
+    tenants_file = "tenants.json"
+  }
+
+  api {
+    endpoint "/orders" {
+      proxy {
+        backend {
+          origin = request.tenant.origin
+          path = "/anything"
+          set_request_headers = {
+            authorization = "Bearer ${request.tenant.credentials}"
+          }
+        }
+      }
+    }
+
+    endpoint "/tenant" {
+      response {
+        json_body = request.tenant
+      }
+    }
+
+    error_handler "beta_tenant" {
+      response {
+        status = 404
+      }
+    }
+  }
+}
+
+definitions {
+  jwt "token" {
+    signature_algorithm = "HS256"
+    key = "asdf"
+  }
+}
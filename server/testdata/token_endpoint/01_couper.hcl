@@ -0,0 +1,29 @@
+server {
+  token_endpoint "/oauth2/token" {
+    issuer              = "https://auth.example.com"
+    jwt_signing_profile = "tokens"
+    refresh_token_ttl   = "1h"
+
+    client "svc" {
+      client_secret_hash = "$2a$04$h6XFDQuRrDwR5Qp/CcuVWuamywplcP7GKqHLX1iAxGhgYA7DYeHAC" # s3cret
+      scope              = ["read", "write"]
+      audience           = "orders"
+    }
+
+    client "batch" {
+      key_file            = "client.pub"
+      signature_algorithm = "ES256"
+    }
+  }
+}
+
+definitions {
+  jwt_signing_profile "tokens" {
+    signature_algorithm = "RS256"
+    key_file            = "signing.key"
+    ttl                 = "10m"
+    claims = {
+      env = "test"
+    }
+  }
+}
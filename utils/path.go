@@ -47,3 +47,46 @@ func JoinOpenAPIPath(elements ...string) string {
 	}
 	return result
 }
+
+// PathParamTypes maps the predefined path parameter types
+// to the regular expression their values have to match.
+var PathParamTypes = map[string]string{
+	"int":  `-?[0-9]+`,
+	"uuid": `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+// PathParam represents a {name}, {name:type} or {name:regex} path pattern segment.
+type PathParam struct {
+	Name string
+	// Pattern is the regular expression the segment value has to match, empty for any value.
+	Pattern string
+	// Type is one of the PathParamTypes keys, empty for untyped parameters.
+	Type string
+}
+
+// ParsePathParam parses the given path pattern segment. The second return value
+// is false for static segments.
+func ParsePathParam(segment string) (*PathParam, bool) {
+	if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+		return nil, false
+	}
+
+	param := &PathParam{Name: segment[1 : len(segment)-1]}
+	if name, constraint, found := strings.Cut(param.Name, ":"); found {
+		param.Name = name
+		param.Pattern = constraint
+		if pattern, typed := PathParamTypes[constraint]; typed {
+			param.Pattern = pattern
+			param.Type = constraint
+		}
+	}
+	return param, true
+}
+
+// String returns the segment with its type resolved to the related regular expression.
+func (p *PathParam) String() string {
+	if p.Pattern == "" {
+		return "{" + p.Name + "}"
+	}
+	return "{" + p.Name + ":" + p.Pattern + "}"
+}
@@ -83,3 +83,18 @@ func TestUtils_JoinOpenAPIPath(t *testing.T) {
 		t.Errorf("Unexpected path %q given, expected %q", p, "/foo/../")
 	}
 }
+
+func TestUtils_ParsePathParam(t *testing.T) {
+	if _, ok := utils.ParsePathParam("static"); ok {
+		t.Error("Unexpected path parameter for static segment")
+	}
+	if p, ok := utils.ParsePathParam("{id}"); !ok || p.Name != "id" || p.Pattern != "" || p.String() != "{id}" {
+		t.Errorf("Unexpected path parameter %#v", p)
+	}
+	if p, ok := utils.ParsePathParam("{id:int}"); !ok || p.Name != "id" || p.Type != "int" || p.String() != "{id:-?[0-9]+}" {
+		t.Errorf("Unexpected path parameter %#v", p)
+	}
+	if p, ok := utils.ParsePathParam("{code:[A-Z]{3}}"); !ok || p.Name != "code" || p.Type != "" || p.Pattern != "[A-Z]{3}" {
+		t.Errorf("Unexpected path parameter %#v", p)
+	}
+}